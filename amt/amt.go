@@ -5,14 +5,14 @@
 // The operations exposed here are documented by Amazon:
 // http://docs.aws.amazon.com/AWSMechTurk/latest/AWSMturkAPI/ApiReference_OperationsArticle.html
 //
-// Known issues:
-// - The QuestionForm is not smart enough to marshal elements in the correct
-//   order. This is a drawback of encoding/xml.
-// - QuestionFormAnswers Marshals several empty fields.
+// Questions, answer keys, and answers are encoded by EncodeQuestion in the
+// element order required by Amazon's XML schemas, and may be checked against
+// those schemas with ValidateQuestion before they are sent.
 package amt
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/base64"
@@ -21,7 +21,9 @@ import (
 	"fmt"
 	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"reflect"
 	"strings"
 	"time"
@@ -77,7 +79,60 @@ type AmtClient interface {
 	UpdateQualificationType(qualificationTypeId string, retryDelayInSeconds int, qualificationTypeStatus, description, test, answerKey string, testDurationInSeconds int, autoGranted bool, autoGrantedValue int) (amtgen.TxsdUpdateQualificationTypeResponse, error)
 }
 
-// amtClient implements AmtClient
+// AmtClientContext is implemented by clients which can bind each request to a
+// context.Context. Cancelling the context aborts any wait on the request
+// rate limiter or retry backoff, as well as the HTTP request itself. The client
+// returned by NewClient implements both AmtClient and AmtClientContext.
+type AmtClientContext interface {
+	ApproveAssignmentCtx(ctx context.Context, assignmentId, requesterFeedback string) (amtgen.TxsdApproveAssignmentResponse, error)
+	ApproveRejectedAssignmentCtx(ctx context.Context, assignmentId, requesterFeedback string) (amtgen.TxsdApproveRejectedAssignmentResponse, error)
+	AssignQualificationCtx(ctx context.Context, qualificationTypeId, workerId string, integerValue int, sendNotification bool) (amtgen.TxsdAssignQualificationResponse, error)
+	BlockWorkerCtx(ctx context.Context, workerId, reason string) (amtgen.TxsdBlockWorkerResponse, error)
+	ChangeHITTypeOfHITCtx(ctx context.Context, hitId, hitTypeId string) (amtgen.TxsdChangeHITTypeOfHITResponse, error)
+	CreateHITCtx(ctx context.Context, title, description, question string, hitLayoutId string, hitLayoutParameters map[string]string, reward float32, assignmentDurationInSeconds, lifetimeInSeconds, maxAssignments, autoApprovalDelayInSeconds int, keywords []string, qualificationRequirements []*amtgen.TQualificationRequirement, assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy, requesterAnnotation, uniqueRequestToken string) (amtgen.TxsdCreateHITResponse, error)
+	CreateHITFromArgsCtx(ctx context.Context, args amtgen.TCreateHITRequest) (amtgen.TxsdCreateHITResponse, error)
+	CreateHITFromHITTypeIdCtx(ctx context.Context, hitTypeId, question string, hitLayoutId string, hitLayoutParameters map[string]string, lifetimeInSeconds, maxAssignments int, assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy, requesterAnnotation, uniqueRequestToken string) (amtgen.TxsdCreateHITResponse, error)
+	CreateQualificationTypeCtx(ctx context.Context, name, description string, keywords []string, retryDelayInSeconds int, qualificationTypeStatus, test, answerKey string, testDurationInSeconds int, autoGranted bool, autoGrantedValue int) (amtgen.TxsdCreateQualificationTypeResponse, error)
+	DisableHITCtx(ctx context.Context, hitId string) (amtgen.TxsdDisableHITResponse, error)
+	DisposeHITCtx(ctx context.Context, hitId string) (amtgen.TxsdDisposeHITResponse, error)
+	DisposeQualificationTypeCtx(ctx context.Context, qualificationTypeId string) (amtgen.TxsdDisposeQualificationTypeResponse, error)
+	ExtendHITCtx(ctx context.Context, hitId string, maxAssignmentsIncrement, expirationIncrementInSeconds int, uniqueRequestToken string) (amtgen.TxsdExtendHITResponse, error)
+	ForceExpireHITCtx(ctx context.Context, hitId string) (amtgen.TxsdForceExpireHITResponse, error)
+	GetAccountBalanceCtx(ctx context.Context) (amtgen.TxsdGetAccountBalanceResponse, error)
+	GetAssignmentCtx(ctx context.Context, assignmentId string) (amtgen.TxsdGetAssignmentResponse, error)
+	GetAssignmentsForHITCtx(ctx context.Context, hitId string, assignmentStatuses []string, sortProperty string, sortAscending bool, pageSize, pageNumber int) (amtgen.TxsdGetAssignmentsForHITResponse, error)
+	GetBlockedWorkersCtx(ctx context.Context, pageSize, pageNumber int) (amtgen.TxsdGetBlockedWorkersResponse, error)
+	GetBonusPaymentsCtx(ctx context.Context, hitId, assignmentId string, pageSize, pageNumber int) (amtgen.TxsdGetBonusPaymentsResponse, error)
+	GetFileUploadURLCtx(ctx context.Context, assignmentId, questionIdentifier string) (amtgen.TxsdGetFileUploadURLResponse, error)
+	GetHITCtx(ctx context.Context, hitId string) (amtgen.TxsdGetHITResponse, error)
+	GetHITsForQualificationTypeCtx(ctx context.Context, qualificationTypeId string, pageSize, pageNumber int) (amtgen.TxsdGetHITsForQualificationTypeResponse, error)
+	GetQualificationRequestsCtx(ctx context.Context, qualificationTypeId, sortProperty string, sortAscending bool, pageSize, pageNumber int) (amtgen.TxsdGetQualificationRequestsResponse, error)
+	GetQualificationScoreCtx(ctx context.Context, qualificationTypeId, subjectId string) (amtgen.TxsdGetQualificationScoreResponse, error)
+	GetQualificationsForQualificationTypeCtx(ctx context.Context, qualificationTypeId string, isGranted bool, pageSize, pageNumber int) (amtgen.TxsdGetQualificationsForQualificationTypeResponse, error)
+	GetQualificationTypeCtx(ctx context.Context, qualificationTypeId string) (amtgen.TxsdGetQualificationTypeResponse, error)
+	GetRequesterStatisticCtx(ctx context.Context, statistic, timePeriod string, count int) (amtgen.TxsdGetRequesterStatisticResponse, error)
+	GetRequesterWorkerStatisticCtx(ctx context.Context, statistic, workerId, timePeriod string, count int) (amtgen.TxsdGetRequesterWorkerStatisticResponse, error)
+	GetReviewableHITsCtx(ctx context.Context, hitTypeId, status, sortProperty string, sortAscending bool, pageSize, pageNumber int) (amtgen.TxsdGetReviewableHITsResponse, error)
+	GetReviewResultsForHITCtx(ctx context.Context, hitId string, policyLevels []string, retrieveActions, retrieveResults bool, pageSize, pageNumber int) (amtgen.TxsdGetReviewResultsForHITResponse, error)
+	GrantBonusCtx(ctx context.Context, workerId, assignmentId string, bonusAmount float32, reason, uniqueRequestToken string) (amtgen.TxsdGrantBonusResponse, error)
+	GrantQualificationCtx(ctx context.Context, qualificationRequestId string, integerValue int) (amtgen.TxsdGrantQualificationResponse, error)
+	NotifyWorkersCtx(ctx context.Context, subject, messageText string, workerIds []string) (amtgen.TxsdNotifyWorkersResponse, error)
+	RegisterHITTypeCtx(ctx context.Context, title, description string, reward float32, assignmentDurationInSeconds, autoApprovalDelayInSeconds int, keywords []string, qualificationRequirements []*amtgen.TQualificationRequirement) (amtgen.TxsdRegisterHITTypeResponse, error)
+	RegisterHITTypeFromArgsCtx(ctx context.Context, args amtgen.TRegisterHITTypeRequest) (amtgen.TxsdRegisterHITTypeResponse, error)
+	RejectAssignmentCtx(ctx context.Context, assignmentId, requesterFeedback string) (amtgen.TxsdRejectAssignmentResponse, error)
+	RejectQualificationRequestCtx(ctx context.Context, qualificationRequestId, reason string) (amtgen.TxsdRejectQualificationRequestResponse, error)
+	RevokeQualificationCtx(ctx context.Context, subjectId, qualificationTypeId, reason string) (amtgen.TxsdRevokeQualificationResponse, error)
+	SearchHITsCtx(ctx context.Context, sortProperty string, sortAscending bool, pageSize, pageNumber int) (amtgen.TxsdSearchHITsResponse, error)
+	SearchQualificationTypesCtx(ctx context.Context, query, sortProperty string, sortAscending bool, pageSize, pageNumber int, mustBeRequestable, mustBeOwnedByCaller bool) (amtgen.TxsdSearchQualificationTypesResponse, error)
+	SendTestEventNotificationCtx(ctx context.Context, notification *amtgen.TNotificationSpecification, testEventType string) (amtgen.TxsdSendTestEventNotificationResponse, error)
+	SetHITAsReviewingCtx(ctx context.Context, hitID string, revert bool) (amtgen.TxsdSetHITAsReviewingResponse, error)
+	SetHITTypeNotificationCtx(ctx context.Context, hitTypeID string, notification *amtgen.TNotificationSpecification, active bool) (amtgen.TxsdSetHITTypeNotificationResponse, error)
+	UnblockWorkerCtx(ctx context.Context, workerId, reason string) (amtgen.TxsdUnblockWorkerResponse, error)
+	UpdateQualificationScoreCtx(ctx context.Context, qualificationTypeId, subjectId string, integerValue int) (amtgen.TxsdUpdateQualificationScoreResponse, error)
+	UpdateQualificationTypeCtx(ctx context.Context, qualificationTypeId string, retryDelayInSeconds int, qualificationTypeStatus, description, test, answerKey string, testDurationInSeconds int, autoGranted bool, autoGrantedValue int) (amtgen.TxsdUpdateQualificationTypeResponse, error)
+}
+
+// amtClient implements AmtClient and AmtClientContext
 type amtClient struct {
 
 	// The access key for your AMT account
@@ -89,24 +144,70 @@ type amtClient struct {
 	// The root URL to which requests should be sent
 	UrlRoot string
 
-	// The request rate throttle, used to avoid spamming AMT
-	Throttle *time.Ticker
+	// The limiter on the request rate, used to avoid spamming AMT. If nil,
+	// requests are not limited.
+	Limiter Limiter
+
+	// The policy for retrying failed requests. If nil, requests are attempted
+	// only once.
+	Retry *RetryPolicy
+
+	// The HTTP client used to send requests. If nil, http.DefaultClient is
+	// used.
+	HTTPClient *http.Client
+
+	// The length, in bytes, of the encoded query above which a request is
+	// sent as a form-encoded POST rather than a GET. If 0, every request is
+	// a GET; if negative, every request is a POST.
+	PostThreshold int
+
+	// The log of mutating calls. If nil, calls are not logged.
+	Audit *AuditLog
+
+	// The receiver of measurements of each call. If nil, none are taken.
+	Metrics Metrics
+
+	// The rewards seen in the client's calls, used to cost calls for its
+	// Metrics
+	rewards *rewardBook
+
+	// Answers requests in place of AMT, for a DryRunClient. If nil, requests
+	// are sent.
+	dryRun func(operation string, request, response interface{}) error
 }
 
-// Initialize a new client for AMT.
-func NewClient(accessKeyId, secretKey string, sandbox bool) AmtClient {
+// Initialize a new client for AMT. Options may replace the HTTP client, wrap
+// its transport in middleware, or change the retry policy or rate limiter.
+func NewClient(accessKeyId, secretKey string, sandbox bool,
+	opts ...Option) AmtClient {
 	urlRoot := URL_PROD
 	if sandbox {
 		urlRoot = URL_SANDBOX
 	}
+	options := newClientOptions(opts)
 	return &amtClient{
 		AWSAccessKeyId: accessKeyId,
 		SecretKey:      secretKey,
 		UrlRoot:        urlRoot,
-		Throttle:       time.NewTicker(550 * time.Millisecond),
+		Limiter:        options.limiter,
+		Retry:          options.retry,
+		HTTPClient:     options.client(),
+		PostThreshold:  options.postThreshold,
+		Audit:          options.audit,
+		Metrics:        options.metrics,
+		rewards:        newRewardBook(),
 	}
 }
 
+// Initialize a new client which sends requests to the given URL rather than
+// to AMT, such as a local stand-in server.
+func NewClientWithURL(accessKeyId, secretKey, urlRoot string,
+	opts ...Option) AmtClient {
+	client := NewClient(accessKeyId, secretKey, false, opts...).(*amtClient)
+	client.UrlRoot = urlRoot
+	return client
+}
+
 // amtRequest wraps a Request type from amtgen with an operation name. It can
 // safely be marshalled into a REST request.
 type amtRequest struct {
@@ -148,7 +249,14 @@ func (client amtClient) signRequest(operation string, request interface{}) (amtR
 }
 
 func (client amtClient) signatureFor(service, operation, timestamp string) string {
-	mac := hmac.New(sha1.New, []byte(client.SecretKey))
+	return Signature(client.SecretKey, service, operation, timestamp)
+}
+
+// Signature computes the value of the Signature parameter AMT expects for a
+// request: the base64-encoded HMAC-SHA1 of the service, operation and
+// timestamp, keyed by the secret key.
+func Signature(secretKey, service, operation, timestamp string) string {
+	mac := hmac.New(sha1.New, []byte(secretKey))
 	io.WriteString(mac, service)
 	io.WriteString(mac, operation)
 	io.WriteString(mac, timestamp)
@@ -254,41 +362,132 @@ func packField(n string, v reflect.Value, justIndexed bool) map[string]string {
 	return m
 }
 
-// Send a request and decode the response into the given struct.
-func (client amtClient) sendRequest(request amtRequest, response interface{}) error {
-	if client.Throttle != nil {
-		<-client.Throttle.C
+// Send a request and decode the response into the given struct, retrying
+// transient failures according to the client's RetryPolicy. Each retry is
+// signed again so that it carries a fresh Timestamp.
+func (client amtClient) sendRequest(ctx context.Context, request amtRequest,
+	response interface{}) error {
+	if client.dryRun != nil {
+		return client.dryRun(request.Operation, request.Request, response)
+	}
+	var (
+		policy   RetryPolicy
+		attempts int
+		start    = time.Now()
+	)
+	if client.Retry != nil {
+		policy = client.Retry.forRequest(request.Operation,
+			packRequest(request.Request).Get("UniqueRequestToken") != "")
 	}
-	req, err := http.NewRequest("GET", client.UrlRoot, nil)
+	err := policy.do(ctx, func(attempt int) error {
+		attempts = attempt
+		if attempt > 1 {
+
+			// Discard any partial response and re-sign the request
+			resp := reflect.ValueOf(response).Elem()
+			resp.Set(reflect.Zero(resp.Type()))
+			var err error
+			if request, err = client.signRequest(request.Operation, request.Request); err != nil {
+				return err
+			}
+		}
+		return client.sendRequestOnce(ctx, request, response)
+	})
+	if client.Audit != nil || client.Metrics != nil {
+		args := auditArgs(packRequest(request.Request))
+		if client.Audit != nil {
+			client.Audit.record(start, request.Operation, client.AWSAccessKeyId,
+				args, responseRequestId(response), attempts, err)
+		}
+		if client.Metrics != nil {
+			client.Metrics.ObserveCall(callMetrics(start, request.Operation, args,
+				attempts, err, client.rewards))
+			if err == nil {
+				client.rewards.learn(request.Operation, args, response)
+			}
+		}
+	}
+	return err
+}
+
+// Flatten the arguments of an amtgen request struct into query parameters.
+func packRequest(request interface{}) url.Values {
+	query := url.Values{}
+	if request == nil {
+		return query
+	}
+	args := reflect.ValueOf(request).Elem().FieldByName("Requests").Index(0).Elem()
+	argType := args.Type()
+	for i := 0; i < args.NumField(); i++ {
+		fName := argType.FieldByIndex([]int{i, 0}).Name
+		fValue := args.FieldByIndex([]int{i, 0})
+		if !isEmptyValue(fValue) {
+			for key, value := range packField(fName, fValue, false) {
+				query.Add(key, value)
+			}
+		}
+	}
+	return query
+}
+
+// Build the HTTP request carrying an encoded query: a GET with the query in
+// the URL, or a form-encoded POST if the query is longer than the client's
+// PostThreshold.
+func (client amtClient) newHTTPRequest(ctx context.Context, encoded string) (
+	*http.Request, error) {
+
+	if client.PostThreshold < 0 ||
+		(client.PostThreshold > 0 && len(encoded) > client.PostThreshold) {
+		req, err := http.NewRequestWithContext(ctx, "POST", client.UrlRoot,
+			strings.NewReader(encoded))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", FORM_CONTENT_TYPE)
+		return req, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", client.UrlRoot, nil)
 	if err != nil {
+		return nil, err
+	}
+	req.URL.RawQuery = encoded
+	return req, nil
+}
+
+// Send a single request attempt and decode the response into the given struct.
+func (client amtClient) sendRequestOnce(ctx context.Context, request amtRequest,
+	response interface{}) error {
+	if err := waitLimiter(ctx, client.Limiter, client.Metrics,
+		request.Operation); err != nil {
 		return err
 	}
-	query := req.URL.Query()
+	query := url.Values{}
 	query.Add("AWSAccessKeyId", request.AWSAccessKeyId)
 	query.Add("Operation", request.Operation)
 	query.Add("Service", request.Service)
 	query.Add("Signature", request.Signature)
 	query.Add("Timestamp", request.Timestamp)
 	query.Add("Version", request.Version)
-	if request.Request != nil {
-		args := reflect.ValueOf(request.Request).Elem().FieldByName("Requests").Index(0).Elem()
-		argType := args.Type()
-		for i := 0; i < args.NumField(); i++ {
-			fName := argType.FieldByIndex([]int{i, 0}).Name
-			fValue := args.FieldByIndex([]int{i, 0})
-			if !isEmptyValue(fValue) {
-				for key, value := range packField(fName, fValue, false) {
-					query.Add(key, value)
-				}
-			}
-		}
+	for key, values := range packRequest(request.Request) {
+		query[key] = append(query[key], values...)
+	}
+	req, err := client.newHTTPRequest(ctx, query.Encode())
+	if err != nil {
+		return err
 	}
-	req.URL.RawQuery = query.Encode()
 
-	if resp, err := http.DefaultClient.Do(req); err != nil {
+	if resp, err := httpClientOrDefault(client.HTTPClient).Do(req); err != nil {
 		return err
 	} else if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Request failed with HTTP status %d: %s", resp.StatusCode, resp.Status)
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		code, message := findErrorCode(body)
+		return &HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Code:       code,
+			Message:    message,
+		}
 	} else {
 		defer resp.Body.Close()
 		var respBody bytes.Buffer
@@ -301,7 +500,9 @@ func (client amtClient) sendRequest(request amtRequest, response interface{}) er
 
 			return fmt.Errorf("%s returned an empty response struct. Parse error? Response was: %s",
 				request.Operation, string(respBody.Bytes()))
+		} else if err != nil {
+			return err
 		}
-		return err
+		return responseError(request.Operation, response)
 	}
 }
@@ -0,0 +1,233 @@
+package amt
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Answer is a worker's answer to one question of a QuestionForm, as read
+// from the QuestionFormAnswers XML of an assignment. The form of the answer
+// depends on the question: free text, a list of selections (possibly with
+// other text), or an uploaded file.
+type Answer struct {
+
+	// The question answered
+	QuestionIdentifier string
+
+	freeText             string
+	hasFreeText          bool
+	selectionIdentifiers []string
+	otherSelection       string
+	hasOtherSelection    bool
+	uploadedFileKey      string
+	uploadedFileSize     int64
+	hasUploadedFile      bool
+}
+
+// FreeText returns the text of a free text answer, and whether the answer
+// is one.
+func (answer Answer) FreeText() (string, bool) {
+	return answer.freeText, answer.hasFreeText
+}
+
+// SelectionIdentifiers returns the options chosen in a selection answer, in
+// the order given.
+func (answer Answer) SelectionIdentifiers() []string {
+	return answer.selectionIdentifiers
+}
+
+// OtherSelection returns the text entered in the "other" field of a
+// selection answer, and whether there is any.
+func (answer Answer) OtherSelection() (string, bool) {
+	return answer.otherSelection, answer.hasOtherSelection
+}
+
+// UploadedFileKey returns the key of the file uploaded in a file upload
+// answer, and whether the answer is one. Use GetFileUploadURL to download the
+// file.
+func (answer Answer) UploadedFileKey() (string, bool) {
+	return answer.uploadedFileKey, answer.hasUploadedFile
+}
+
+// UploadedFileSize returns the size in bytes of the file uploaded in a file
+// upload answer, or 0.
+func (answer Answer) UploadedFileSize() int64 {
+	return answer.uploadedFileSize
+}
+
+// Value returns the answer as a single string: the free text, the only
+// selection, the other selection text, or the uploaded file key, whichever
+// the answer has. Answers with several selections return the first.
+func (answer Answer) Value() string {
+	switch {
+	case answer.hasFreeText:
+		return answer.freeText
+	case len(answer.selectionIdentifiers) > 0:
+		return answer.selectionIdentifiers[0]
+	case answer.hasOtherSelection:
+		return answer.otherSelection
+	}
+	return answer.uploadedFileKey
+}
+
+// DecodeAnswers parses the QuestionFormAnswers XML of an assignment into a
+// map from question identifiers to answers.
+func DecodeAnswers(answerXml []byte) (map[string]Answer, error) {
+	root, err := parseXMLNode(answerXml)
+	if err != nil {
+		return nil, err
+	}
+	if root.Name.Local != "QuestionFormAnswers" {
+		return nil, fmt.Errorf("Expected QuestionFormAnswers, but found %s",
+			root.Name.Local)
+	}
+	answers := make(map[string]Answer)
+	for _, node := range root.Children {
+		if node.Name.Local != "Answer" {
+			continue
+		}
+		var answer Answer
+		for _, child := range node.Children {
+			switch child.Name.Local {
+			case "QuestionIdentifier":
+				answer.QuestionIdentifier = child.Text
+			case "FreeText":
+				answer.freeText, answer.hasFreeText = child.Text, true
+			case "SelectionIdentifier":
+				answer.selectionIdentifiers = append(answer.selectionIdentifiers,
+					child.Text)
+			case "OtherSelectionText":
+				answer.otherSelection, answer.hasOtherSelection = child.Text, true
+			case "UploadedFileKey":
+				answer.uploadedFileKey, answer.hasUploadedFile = child.Text, true
+			case "UploadedFileSizeInBytes":
+				size, err := strconv.ParseInt(strings.TrimSpace(child.Text), 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("Invalid file size for answer to %s: %q",
+						answer.QuestionIdentifier, child.Text)
+				}
+				answer.uploadedFileSize = size
+			}
+		}
+		if _, ok := answers[answer.QuestionIdentifier]; ok {
+			return nil, fmt.Errorf("Found more than one answer to %s",
+				answer.QuestionIdentifier)
+		}
+		answers[answer.QuestionIdentifier] = answer
+	}
+	return answers, nil
+}
+
+// DecodeAnswersInto parses the QuestionFormAnswers XML of an assignment into
+// the struct pointed to by v, much as encoding/json would. Each exported
+// field receives the answer to the question named by its "amt" tag, or if it
+// has none, to the question whose identifier matches the field's name,
+// preferring an exact match to a case-insensitive one. A tag of "-" skips the
+// field. Fields for unanswered questions are left alone.
+//
+// Fields may be strings, which receive Answer.Value(); string slices, which
+// receive the selections, or the free text; bools or numbers, parsed from
+// Answer.Value(); or Answer. Pointers to any of these are allocated as
+// needed.
+func DecodeAnswersInto(answerXml []byte, v interface{}) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || target.IsNil() ||
+		target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("DecodeAnswersInto needs a pointer to a struct, not %T", v)
+	}
+	answers, err := DecodeAnswers(answerXml)
+	if err != nil {
+		return err
+	}
+	target = target.Elem()
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, tagged := field.Tag.Lookup("amt")
+		if name == "-" {
+			continue
+		} else if !tagged {
+			name = field.Name
+		}
+		answer, ok := answers[name]
+		if !ok && !tagged {
+			for id, candidate := range answers {
+				if strings.EqualFold(id, name) {
+					name, answer, ok = id, candidate, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		if err := setAnswerField(target.Field(i), answer); err != nil {
+			return fmt.Errorf("Could not decode answer to %s into field %s: %v",
+				name, field.Name, err)
+		}
+	}
+	return nil
+}
+
+// Store an answer in a struct field.
+func setAnswerField(field reflect.Value, answer Answer) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return setAnswerField(field.Elem(), answer)
+	}
+	if field.Type() == reflect.TypeOf(answer) {
+		field.Set(reflect.ValueOf(answer))
+		return nil
+	}
+	value := strings.TrimSpace(answer.Value())
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(answer.Value())
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		values := answer.SelectionIdentifiers()
+		if text, ok := answer.FreeText(); ok {
+			values = []string{text}
+		}
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			slice.Index(i).SetString(value)
+		}
+		field.Set(slice)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
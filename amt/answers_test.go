@@ -0,0 +1,118 @@
+package amt
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+const ANSWERS_XML = `<QuestionFormAnswers xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/QuestionFormAnswers.xsd">
+  <Answer>
+    <QuestionIdentifier>nextmove</QuestionIdentifier>
+    <FreeText>C3</FreeText>
+  </Answer>
+  <Answer>
+    <QuestionIdentifier>colors</QuestionIdentifier>
+    <SelectionIdentifier>red</SelectionIdentifier>
+    <SelectionIdentifier>blue</SelectionIdentifier>
+    <OtherSelectionText>teal</OtherSelectionText>
+  </Answer>
+  <Answer>
+    <QuestionIdentifier>photo</QuestionIdentifier>
+    <UploadedFileSizeInBytes>1024</UploadedFileSizeInBytes>
+    <UploadedFileKey>key-1</UploadedFileKey>
+  </Answer>
+  <Answer>
+    <QuestionIdentifier>age</QuestionIdentifier>
+    <FreeText> 42 </FreeText>
+  </Answer>
+  <Answer>
+    <QuestionIdentifier>agree</QuestionIdentifier>
+    <SelectionIdentifier>true</SelectionIdentifier>
+  </Answer>
+</QuestionFormAnswers>`
+
+func TestDecodeAnswers(t *testing.T) {
+	Convey("Given QuestionFormAnswers XML", t, func() {
+		Convey("When I decode it", func() {
+			answers, err := DecodeAnswers([]byte(ANSWERS_XML))
+
+			Convey("Then each answer is available by question", func() {
+				So(err, ShouldBeNil)
+				So(answers, ShouldHaveLength, 5)
+
+				text, ok := answers["nextmove"].FreeText()
+				So(ok, ShouldBeTrue)
+				So(text, ShouldEqual, "C3")
+				So(answers["nextmove"].SelectionIdentifiers(), ShouldBeEmpty)
+
+				colors := answers["colors"]
+				So(colors.SelectionIdentifiers(), ShouldResemble, []string{"red", "blue"})
+				other, ok := colors.OtherSelection()
+				So(ok, ShouldBeTrue)
+				So(other, ShouldEqual, "teal")
+				_, ok = colors.FreeText()
+				So(ok, ShouldBeFalse)
+				So(colors.Value(), ShouldEqual, "red")
+
+				key, ok := answers["photo"].UploadedFileKey()
+				So(ok, ShouldBeTrue)
+				So(key, ShouldEqual, "key-1")
+				So(answers["photo"].UploadedFileSize(), ShouldEqual, int64(1024))
+			})
+		})
+
+		Convey("When I decode it into a struct", func() {
+			var result struct {
+				Move    string `amt:"nextmove"`
+				Colors  []string
+				Photo   Answer
+				Age     *int `amt:"age"`
+				Agree   bool `amt:"agree"`
+				Missing string
+				Skipped string `amt:"-"`
+			}
+			result.Missing = "unchanged"
+			err := DecodeAnswersInto([]byte(ANSWERS_XML), &result)
+
+			Convey("Then the fields are filled from the answers", func() {
+				So(err, ShouldBeNil)
+				So(result.Move, ShouldEqual, "C3")
+				So(result.Colors, ShouldResemble, []string{"red", "blue"})
+				So(result.Photo.QuestionIdentifier, ShouldEqual, "photo")
+				So(*result.Age, ShouldEqual, 42)
+				So(result.Agree, ShouldBeTrue)
+				So(result.Missing, ShouldEqual, "unchanged")
+			})
+		})
+
+		Convey("When I decode it into a struct with tags matching the questions", func() {
+			var result struct {
+				Colors []string `amt:"colors"`
+				Move   int      `amt:"nextmove"`
+			}
+			err := DecodeAnswersInto([]byte(ANSWERS_XML), &result)
+
+			Convey("Then a value which does not fit its field is reported", func() {
+				So(result.Colors, ShouldResemble, []string{"red", "blue"})
+				So(err.Error(), ShouldStartWith, "Could not decode answer to nextmove into field Move")
+			})
+		})
+
+		Convey("When I decode it into something other than a struct pointer", func() {
+			var result map[string]string
+			err := DecodeAnswersInto([]byte(ANSWERS_XML), &result)
+
+			Convey("Then it fails", func() {
+				So(err.Error(), ShouldEqual, "DecodeAnswersInto needs a pointer to a struct, not *map[string]string")
+			})
+		})
+	})
+
+	Convey("Given XML which is not QuestionFormAnswers", t, func() {
+		_, err := DecodeAnswers([]byte(`<AnswerKey/>`))
+
+		Convey("Then it cannot be decoded", func() {
+			So(err.Error(), ShouldEqual, "Expected QuestionFormAnswers, but found AnswerKey")
+		})
+	})
+}
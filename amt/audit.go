@@ -0,0 +1,210 @@
+package amt
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// Arguments which are never written to an audit log, at any depth
+	unauditedArgs = map[string]bool{
+		"SecretKey":     true,
+		"Signature":     true,
+		"Authorization": true,
+	}
+)
+
+// AuditRecord describes one call to an AMT operation, including all of its
+// attempts. It is written to an audit log as a single line of JSON.
+type AuditRecord struct {
+
+	// When the call started
+	Time time.Time `json:"time"`
+
+	// The operation, named as the client sends it, e.g. "GrantBonus" for a
+	// client created by NewClient or "SendBonus" for one created by
+	// NewJSONClient
+	Operation string `json:"operation"`
+
+	// The access key of the account the call was made for
+	AccessKeyId string `json:"accessKeyId"`
+
+	// The operation's arguments, as sent to AMT
+	Args map[string]interface{} `json:"args,omitempty"`
+
+	// The RequestId AMT assigned to the last attempt, if it responded
+	RequestId string `json:"requestId,omitempty"`
+
+	// The number of attempts made, and the seconds spent on all of them
+	Attempts int     `json:"attempts"`
+	Seconds  float64 `json:"seconds"`
+
+	// The error the call failed with, if any
+	Error string `json:"error,omitempty"`
+}
+
+// AuditLog writes a record of each mutating call a client makes, such as
+// CreateHIT, GrantBonus or BlockWorker, to a sink as JSON Lines. Calls which
+// only read data are not recorded. Secrets are never recorded: neither the
+// secret key nor the request signature is written.
+//
+// Install it with WithAuditLog. A log may be shared by several clients.
+type AuditLog struct {
+	mu   sync.Mutex
+	sink io.Writer
+	err  error
+}
+
+// NewAuditLog creates an audit log which writes to the given sink, e.g. a
+// file opened for appending.
+func NewAuditLog(sink io.Writer) *AuditLog {
+	return &AuditLog{sink: sink}
+}
+
+// IsMutatingOperation reports whether an operation may change the state of
+// an AMT account, and so is recorded by an AuditLog. Only the operations
+// which get, search or list data are not.
+func IsMutatingOperation(operation string) bool {
+	for _, prefix := range []string{"Get", "Search", "List"} {
+		if strings.HasPrefix(operation, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Write writes a record to the sink. A record for an operation which is not
+// mutating is ignored.
+func (log *AuditLog) Write(record AuditRecord) error {
+	if !IsMutatingOperation(record.Operation) {
+		return nil
+	}
+	record.Args = redactArgs(record.Args)
+	line, err := json.Marshal(record)
+	if err != nil {
+		return log.fail(err)
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	if _, err := log.sink.Write(append(line, '\n')); err != nil && log.err == nil {
+		log.err = err
+		return err
+	}
+	return nil
+}
+
+// Err returns the first error encountered while writing to the sink. A
+// failure to write a record does not fail the call it describes, so callers
+// which need a complete log should check Err.
+func (log *AuditLog) Err() error {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	return log.err
+}
+
+// Remember an error which prevented a record from being written.
+func (log *AuditLog) fail(err error) error {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	if log.err == nil {
+		log.err = err
+	}
+	return err
+}
+
+// Record a completed call.
+func (log *AuditLog) record(start time.Time, operation, accessKeyId string,
+	args map[string]interface{}, requestId string, attempts int, err error) {
+
+	record := AuditRecord{
+		Time:        start.UTC(),
+		Operation:   operation,
+		AccessKeyId: accessKeyId,
+		Args:        args,
+		RequestId:   requestId,
+		Attempts:    attempts,
+		Seconds:     time.Since(start).Seconds(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+
+		// The URL of a failed request carries its Signature
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+				u.RawQuery = ""
+				record.Error = strings.Replace(record.Error, urlErr.URL,
+					u.String(), -1)
+			}
+		}
+		var apiErr *APIError
+		if record.RequestId == "" && errors.As(err, &apiErr) {
+			record.RequestId = apiErr.RequestId
+		}
+	}
+	log.Write(record)
+}
+
+// Convert query parameters into audit arguments, using a plain string for
+// parameters with a single value.
+func auditArgs(query url.Values) map[string]interface{} {
+	args := make(map[string]interface{}, len(query))
+	for key, values := range query {
+		if len(values) == 1 {
+			args[key] = values[0]
+		} else {
+			args[key] = values
+		}
+	}
+	return args
+}
+
+// Copy a set of arguments without any secrets, at any depth.
+func redactArgs(args map[string]interface{}) map[string]interface{} {
+	if args == nil {
+		return nil
+	}
+	redacted := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		if !unauditedArgs[key] {
+			redacted[key] = redactValue(value)
+		}
+	}
+	return redacted
+}
+
+// Copy a single argument value without any secrets.
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return redactArgs(v)
+	case jsonArgs:
+		return redactArgs(v)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, elem := range v {
+			redacted[i] = redactValue(elem)
+		}
+		return redacted
+	}
+	return value
+}
+
+// Find the RequestId in a decoded response.
+func responseRequestId(response interface{}) string {
+	v := reflect.Indirect(reflect.ValueOf(response))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	field := v.FieldByName("OperationRequest")
+	if !field.IsValid() || field.Kind() != reflect.Ptr || field.IsNil() {
+		return ""
+	}
+	return field.Elem().FieldByName("RequestId").String()
+}
@@ -0,0 +1,156 @@
+package amt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	GRANT_BONUS_RESPONSE = `<?xml version="1.0"?>
+<GrantBonusResponse>
+  <OperationRequest><RequestId>BONUSREQ</RequestId></OperationRequest>
+  <GrantBonusResult><Request><IsValid>True</IsValid></Request></GrantBonusResult>
+</GrantBonusResponse>`
+)
+
+// Decode the records written to an audit log.
+func readAuditRecords(buf *bytes.Buffer) []AuditRecord {
+	var records []AuditRecord
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	for scanner.Scan() {
+		var record AuditRecord
+		So(json.Unmarshal(scanner.Bytes(), &record), ShouldBeNil)
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestAuditLog(t *testing.T) {
+	Convey("Given a client with an audit log", t, func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			switch r.Form.Get("Operation") {
+			case "GrantBonus":
+				fmt.Fprint(w, GRANT_BONUS_RESPONSE)
+			default:
+				fmt.Fprint(w, BALANCE_RESPONSE)
+			}
+		}))
+		defer srv.Close()
+		var buf bytes.Buffer
+		log := NewAuditLog(&buf)
+		client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, srv.URL,
+			WithAuditLog(log), WithLimiter(nil))
+
+		Convey("When I grant a bonus and check the balance", func() {
+			_, err := client.GrantBonus("W1", "A1", 1.5, "Thanks", "tok")
+			So(err, ShouldBeNil)
+			_, err = client.GetAccountBalance()
+			So(err, ShouldBeNil)
+			records := readAuditRecords(&buf)
+
+			Convey("Then only the bonus is recorded", func() {
+				So(log.Err(), ShouldBeNil)
+				So(records, ShouldHaveLength, 1)
+				record := records[0]
+				So(record.Operation, ShouldEqual, "GrantBonus")
+				So(record.AccessKeyId, ShouldEqual, FAKE_ACCESS_KEY)
+				So(record.RequestId, ShouldEqual, "BONUSREQ")
+				So(record.Attempts, ShouldEqual, 1)
+				So(record.Seconds, ShouldBeGreaterThan, 0)
+				So(record.Time.IsZero(), ShouldBeFalse)
+				So(record.Error, ShouldEqual, "")
+				So(record.Args, ShouldResemble, map[string]interface{}{
+					"WorkerId":                   "W1",
+					"AssignmentId":               "A1",
+					"BonusAmount.1.Amount":       "1.5",
+					"BonusAmount.1.CurrencyCode": "USD",
+					"Reason":                     "Thanks",
+					"UniqueRequestToken":         "tok",
+				})
+			})
+
+			Convey("Then no secret is recorded", func() {
+				So(buf.String(), ShouldNotContainSubstring, FAKE_SECRET_KEY)
+				So(buf.String(), ShouldNotContainSubstring, "Signature")
+			})
+		})
+	})
+
+	Convey("Given a JSON client with an audit log", t, func() {
+		srv, _ := newJSONServer(func(operation string,
+			input map[string]interface{}) (int, string) {
+			if operation == "SendBonus" {
+				return 200, `{}`
+			}
+			return 400, `{"__type": "com.amazonaws.mturk#RequestError",
+				"Message": "Hit HIT1 does not exist.",
+				"TurkErrorCode": "AWS.MechanicalTurk.HITDoesNotExist"}`
+		})
+		defer srv.Close()
+		var buf bytes.Buffer
+		client := newJSONTestClient(srv.URL)
+		client.Audit = NewAuditLog(&buf)
+
+		Convey("When a bonus succeeds and a deletion fails", func() {
+			client.GrantBonus("W1", "A1", 1.5, "Thanks", "tok")
+			_, err := client.DisposeHIT("HIT1")
+			So(err, ShouldNotBeNil)
+			records := readAuditRecords(&buf)
+
+			Convey("Then both are recorded with their outcome", func() {
+				So(records, ShouldHaveLength, 2)
+				So(records[0].Operation, ShouldEqual, "SendBonus")
+				So(records[0].RequestId, ShouldEqual, "REQ1")
+				So(records[0].Args["BonusAmount"], ShouldEqual, "1.50")
+				So(records[1].Operation, ShouldEqual, "DeleteHIT")
+				So(records[1].RequestId, ShouldEqual, "REQ1")
+				So(records[1].Error, ShouldContainSubstring, "HITDoesNotExist")
+			})
+		})
+	})
+
+	Convey("Given a client which cannot reach AMT", t, func() {
+		var buf bytes.Buffer
+		client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY,
+			"http://127.0.0.1:0", WithAuditLog(NewAuditLog(&buf)),
+			WithRetryPolicy(nil), WithLimiter(nil))
+
+		Convey("When a request fails", func() {
+			_, err := client.DisposeHIT("HIT1")
+
+			Convey("Then the recorded error omits the signed query", func() {
+				So(err.Error(), ShouldContainSubstring, "Signature")
+				records := readAuditRecords(&buf)
+				So(records, ShouldHaveLength, 1)
+				So(records[0].Error, ShouldContainSubstring, "http://127.0.0.1:0")
+				So(buf.String(), ShouldNotContainSubstring, "Signature")
+			})
+		})
+	})
+
+	Convey("Given a record with secrets in its arguments", t, func() {
+		var buf bytes.Buffer
+		log := NewAuditLog(&buf)
+		log.Write(AuditRecord{
+			Operation: "CreateHIT",
+			Args: map[string]interface{}{
+				"Title":     "T",
+				"Signature": "sig",
+				"Nested":    map[string]interface{}{"SecretKey": "secret"},
+			},
+		})
+
+		Convey("Then the secrets are removed", func() {
+			So(buf.String(), ShouldContainSubstring, `"Title":"T"`)
+			So(buf.String(), ShouldNotContainSubstring, "sig")
+			So(buf.String(), ShouldNotContainSubstring, "secret")
+		})
+	})
+}
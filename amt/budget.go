@@ -0,0 +1,466 @@
+package amt
+
+import (
+	"errors"
+	"fmt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	"strconv"
+	"sync"
+)
+
+var (
+	// ErrOverBudget is wrapped by every BudgetError.
+	ErrOverBudget = errors.New("Over budget")
+)
+
+// BudgetError is returned by a BudgetGuard which refuses an operation.
+type BudgetError struct {
+
+	// The operation which was refused, e.g. "CreateHIT"
+	Operation string
+
+	// What the operation would have cost
+	Cost float64
+
+	// The amount already committed against the limit
+	Committed float64
+
+	// The limit, and what it is: "spend cap" or "available balance"
+	Limit     float64
+	LimitName string
+}
+
+func (err *BudgetError) Error() string {
+	return fmt.Sprintf("%s would cost $%.2f, but $%.2f of the $%.2f %s is committed",
+		err.Operation, err.Cost, err.Committed, err.Limit, err.LimitName)
+}
+
+func (err *BudgetError) Unwrap() error {
+	return ErrOverBudget
+}
+
+// BudgetGuard is an AmtClient which refuses operations that would spend more
+// than a program is allowed to. Before a HIT is created or extended, or a
+// bonus granted, its cost is estimated with Amazon's commission, and the
+// operation fails with a BudgetError if it would take the amount committed
+// past the spend cap, or cost more than the live available balance. All
+// other operations are passed to the wrapped client.
+//
+// The amount committed against the cap is:
+//   - what remains to be paid on the HITs which were open when the guard
+//     first checked a cost, or when Refresh was last called;
+//   - the cost of the HITs created or extended, and bonuses granted, through
+//     the guard; and
+//   - the cost of the bonuses planned with PlanBonus but not yet granted.
+//
+// AMT takes the cost of a HIT from the available balance when it is
+// created, so only planned bonuses, and the operations whose calls to AMT
+// have not returned by the time the balance is fetched, count against it.
+type BudgetGuard struct {
+	AmtClient
+
+	mu          sync.Mutex
+	cap         int64
+	spent       int64
+	planned     int64
+	obligations int64
+	inflight    int64
+	settled     int64
+	scanned     bool
+	created     map[string]bool
+	rewards     map[string]float32
+}
+
+// NewBudgetGuard wraps a client so that it does not spend more than a cap,
+// in dollars. A cap of 0 leaves only the available balance as a limit.
+func NewBudgetGuard(client AmtClient, spendCap float64) *BudgetGuard {
+	return &BudgetGuard{
+		AmtClient: client,
+		cap:       cents(spendCap),
+		created:   make(map[string]bool),
+		rewards:   make(map[string]float32),
+	}
+}
+
+// Committed returns the amount committed against the spend cap.
+func (guard *BudgetGuard) Committed() float64 {
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	return dollars(guard.spent + guard.obligations + guard.planned)
+}
+
+// PlanBonus commits the cost of a bonus which will be granted later, such as
+// one promised in a HIT's description. Bonuses granted through the guard
+// use up planned bonuses before adding to the amount committed.
+func (guard *BudgetGuard) PlanBonus(amount float32) error {
+	cost := cents(EstimateBonusCost(amount).Total())
+	balance, mark, err := guard.fetch("PlanBonus")
+	if err != nil {
+		return err
+	}
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	if err := guard.check("PlanBonus", cost, 0, balance-(guard.settled-mark)); err != nil {
+		return err
+	}
+	guard.planned += cost
+	return nil
+}
+
+// SetHITTypeReward tells the guard the reward of a HIT type, so it can
+// estimate the cost of CreateHITFromHITTypeId. Rewards are learned
+// automatically from RegisterHITType, CreateHIT and the open HITs.
+func (guard *BudgetGuard) SetHITTypeReward(hitTypeId string, reward float32) {
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	guard.rewards[hitTypeId] = reward
+}
+
+// Refresh recomputes what remains to be paid on the open HITs.
+func (guard *BudgetGuard) Refresh() error {
+	return guard.scan()
+}
+
+// Sum the outstanding cost of the open HITs not created through the guard.
+// The HITs are fetched before taking the lock, so the caller must not hold
+// it.
+func (guard *BudgetGuard) scan() error {
+	var hits []*amtgen.Thit
+	it := SearchAllHITs(guard.AmtClient, "CreationTime", true, 0)
+	for it.Next() {
+		hits = append(hits, it.HIT())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	var obligations int64
+	for _, hit := range hits {
+		reward, err := priceAmount(hit.Reward)
+		if err != nil {
+			return err
+		}
+		guard.rewards[string(hit.HITTypeId)] = reward
+		switch hit.HITStatus {
+		case "Assignable", "Unassignable", "Reviewable", "Reviewing":
+		default:
+			continue
+		}
+		if guard.created[string(hit.HITId)] {
+			continue
+		}
+		outstanding := int(hit.MaxAssignments - hit.NumberOfAssignmentsCompleted)
+		obligations += cents(EstimateAssignmentCost(reward, outstanding,
+			int(hit.MaxAssignments)).Total())
+	}
+	guard.obligations = obligations
+	guard.scanned = true
+	return nil
+}
+
+// Fetch what an operation is checked against from AMT: the open HITs, if
+// they have not been scanned yet, and the available balance, in cents. The
+// caller must not hold the lock, so that other goroutines using the guard
+// are not held up by the requests. The balance may not reflect operations
+// which settle while it is fetched, so the amount settled beforehand is
+// returned with it; the caller takes what was settled since from the
+// balance.
+func (guard *BudgetGuard) fetch(operation string) (balance, mark int64, err error) {
+	guard.mu.Lock()
+	scanned := guard.scanned
+	mark = guard.settled
+	guard.mu.Unlock()
+	if !scanned {
+		if err := guard.scan(); err != nil {
+			return 0, 0, err
+		}
+	}
+	resp, err := guard.AmtClient.GetAccountBalance()
+	if err != nil {
+		return 0, 0, err
+	} else if len(resp.GetAccountBalanceResults) == 0 {
+		return 0, 0, fmt.Errorf("Could not check the balance for %s: no result", operation)
+	}
+	amount, err := priceAmount(resp.GetAccountBalanceResults[0].AvailableBalance)
+	if err != nil {
+		return 0, 0, err
+	}
+	return cents(float64(amount)), mark, nil
+}
+
+// Refuse an operation which would exceed the spend cap or the available
+// balance fetched for it, where part of its cost may already be planned. The
+// caller must hold the lock.
+func (guard *BudgetGuard) check(operation string, cost, planned, balance int64) error {
+	committed := guard.spent + guard.obligations + guard.planned
+	if guard.cap > 0 && committed+cost-planned > guard.cap {
+		return &BudgetError{
+			Operation: operation,
+			Cost:      dollars(cost),
+			Committed: dollars(committed),
+			Limit:     dollars(guard.cap),
+			LimitName: "spend cap",
+		}
+	}
+	if committed = guard.planned + guard.inflight - planned; committed+cost > balance {
+		return &BudgetError{
+			Operation: operation,
+			Cost:      dollars(cost),
+			Committed: dollars(committed),
+			Limit:     dollars(balance),
+			LimitName: "available balance",
+		}
+	}
+	return nil
+}
+
+// Check and commit the cost of an operation, which is in flight until it is
+// settled or released. Bonuses use up planned bonuses first. Returns the
+// amount taken from planned bonuses.
+func (guard *BudgetGuard) reserve(operation string, cost int64, bonus bool) (int64, error) {
+	balance, mark, err := guard.fetch(operation)
+	if err != nil {
+		return 0, err
+	}
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	balance -= guard.settled - mark
+	var planned int64
+	if bonus {
+		planned = guard.planned
+		if planned > cost {
+			planned = cost
+		}
+	}
+	if err := guard.check(operation, cost, planned, balance); err != nil {
+		return 0, err
+	}
+	guard.spent += cost
+	guard.planned -= planned
+	guard.inflight += cost
+	return planned, nil
+}
+
+// Undo a reservation for an operation which failed.
+func (guard *BudgetGuard) release(cost, planned int64) {
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	guard.spent -= cost
+	guard.planned += planned
+	guard.inflight -= cost
+}
+
+// Settle a reservation for an operation which succeeded, whose cost AMT has
+// now taken from the available balance. The caller must hold the lock.
+func (guard *BudgetGuard) settle(cost int64) {
+	guard.inflight -= cost
+	guard.settled += cost
+}
+
+// Reserve the cost of creating a HIT, call the wrapped client, and remember
+// the HIT it creates.
+func (guard *BudgetGuard) createHIT(hitTypeId string, reward *float32,
+	maxAssignments int, create func() (amtgen.TxsdCreateHITResponse, error)) (
+	amtgen.TxsdCreateHITResponse, error) {
+
+	if reward == nil {
+		guard.mu.Lock()
+		known, ok := guard.rewards[hitTypeId]
+		guard.mu.Unlock()
+		if !ok {
+			return amtgen.TxsdCreateHITResponse{}, fmt.Errorf(
+				"Could not estimate the cost of CreateHIT: the reward of HIT type %s is unknown",
+				hitTypeId)
+		}
+		reward = &known
+	}
+	if maxAssignments <= 0 {
+		maxAssignments = 1
+	}
+	cost := cents(EstimateHITCost(*reward, maxAssignments).Total())
+	if _, err := guard.reserve("CreateHIT", cost, false); err != nil {
+		return amtgen.TxsdCreateHITResponse{}, err
+	}
+	resp, err := create()
+	if err != nil {
+		guard.release(cost, 0)
+		return resp, err
+	}
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	guard.settle(cost)
+	for _, hit := range resp.Hits {
+		guard.created[string(hit.HITId)] = true
+		if hit.HITTypeId != "" {
+			guard.rewards[string(hit.HITTypeId)] = *reward
+		}
+	}
+	return resp, nil
+}
+
+// CreateHIT creates a HIT if its cost is within budget.
+func (guard *BudgetGuard) CreateHIT(title, description, question string,
+	hitLayoutId string, hitLayoutParameters map[string]string, reward float32,
+	assignmentDurationInSeconds, lifetimeInSeconds, maxAssignments,
+	autoApprovalDelayInSeconds int, keywords []string,
+	qualificationRequirements []*amtgen.TQualificationRequirement,
+	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
+	requesterAnnotation, uniqueRequestToken string) (
+	amtgen.TxsdCreateHITResponse, error) {
+
+	return guard.createHIT("", &reward, maxAssignments,
+		func() (amtgen.TxsdCreateHITResponse, error) {
+			return guard.AmtClient.CreateHIT(title, description, question,
+				hitLayoutId, hitLayoutParameters, reward,
+				assignmentDurationInSeconds, lifetimeInSeconds, maxAssignments,
+				autoApprovalDelayInSeconds, keywords, qualificationRequirements,
+				assignmentReviewPolicy, hitReviewPolicy, requesterAnnotation,
+				uniqueRequestToken)
+		})
+}
+
+// CreateHITFromArgs creates a HIT if its cost is within budget.
+func (guard *BudgetGuard) CreateHITFromArgs(args amtgen.TCreateHITRequest) (
+	amtgen.TxsdCreateHITResponse, error) {
+
+	var reward *float32
+	if args.Reward != nil {
+		amount, err := priceAmount(args.Reward)
+		if err != nil {
+			return amtgen.TxsdCreateHITResponse{}, err
+		}
+		reward = &amount
+	}
+	return guard.createHIT(string(args.HITTypeId), reward,
+		int(args.MaxAssignments), func() (amtgen.TxsdCreateHITResponse, error) {
+			return guard.AmtClient.CreateHITFromArgs(args)
+		})
+}
+
+// CreateHITFromHITTypeId creates a HIT if its cost is within budget. The
+// reward of the HIT type must be known to the guard.
+func (guard *BudgetGuard) CreateHITFromHITTypeId(hitTypeId, question string,
+	hitLayoutId string, hitLayoutParameters map[string]string,
+	lifetimeInSeconds, maxAssignments int,
+	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
+	requesterAnnotation, uniqueRequestToken string) (
+	amtgen.TxsdCreateHITResponse, error) {
+
+	return guard.createHIT(hitTypeId, nil, maxAssignments,
+		func() (amtgen.TxsdCreateHITResponse, error) {
+			return guard.AmtClient.CreateHITFromHITTypeId(hitTypeId, question,
+				hitLayoutId, hitLayoutParameters, lifetimeInSeconds,
+				maxAssignments, assignmentReviewPolicy, hitReviewPolicy,
+				requesterAnnotation, uniqueRequestToken)
+		})
+}
+
+// ExtendHIT extends a HIT if the cost of any new assignments is within
+// budget.
+func (guard *BudgetGuard) ExtendHIT(hitId string, maxAssignmentsIncrement,
+	expirationIncrementInSeconds int, uniqueRequestToken string) (
+	amtgen.TxsdExtendHITResponse, error) {
+
+	var cost int64
+	if maxAssignmentsIncrement > 0 {
+		resp, err := guard.AmtClient.GetHIT(hitId)
+		if err != nil {
+			return amtgen.TxsdExtendHITResponse{}, err
+		} else if len(resp.Hits) == 0 {
+			return amtgen.TxsdExtendHITResponse{}, fmt.Errorf(
+				"Could not estimate the cost of ExtendHIT: HIT %s was not found", hitId)
+		}
+		reward, err := priceAmount(resp.Hits[0].Reward)
+		if err != nil {
+			return amtgen.TxsdExtendHITResponse{}, err
+		}
+		cost = cents(EstimateAssignmentCost(reward, maxAssignmentsIncrement,
+			int(resp.Hits[0].MaxAssignments)+maxAssignmentsIncrement).Total())
+	}
+	if _, err := guard.reserve("ExtendHIT", cost, false); err != nil {
+		return amtgen.TxsdExtendHITResponse{}, err
+	}
+	resp, err := guard.AmtClient.ExtendHIT(hitId, maxAssignmentsIncrement,
+		expirationIncrementInSeconds, uniqueRequestToken)
+	if err != nil {
+		guard.release(cost, 0)
+		return resp, err
+	}
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	guard.settle(cost)
+	return resp, nil
+}
+
+// GrantBonus grants a bonus if its cost is within budget.
+func (guard *BudgetGuard) GrantBonus(workerId, assignmentId string,
+	bonusAmount float32, reason, uniqueRequestToken string) (
+	amtgen.TxsdGrantBonusResponse, error) {
+
+	cost := cents(EstimateBonusCost(bonusAmount).Total())
+	planned, err := guard.reserve("GrantBonus", cost, true)
+	if err != nil {
+		return amtgen.TxsdGrantBonusResponse{}, err
+	}
+	resp, err := guard.AmtClient.GrantBonus(workerId, assignmentId, bonusAmount,
+		reason, uniqueRequestToken)
+	if err != nil {
+		guard.release(cost, planned)
+		return resp, err
+	}
+	guard.mu.Lock()
+	defer guard.mu.Unlock()
+	guard.settle(cost)
+	return resp, nil
+}
+
+// RegisterHITType registers a HIT type, and remembers its reward.
+func (guard *BudgetGuard) RegisterHITType(title, description string,
+	reward float32, assignmentDurationInSeconds, autoApprovalDelayInSeconds int,
+	keywords []string, qualificationRequirements []*amtgen.TQualificationRequirement) (
+	amtgen.TxsdRegisterHITTypeResponse, error) {
+
+	resp, err := guard.AmtClient.RegisterHITType(title, description, reward,
+		assignmentDurationInSeconds, autoApprovalDelayInSeconds, keywords,
+		qualificationRequirements)
+	guard.learnHITType(resp, reward, err)
+	return resp, err
+}
+
+// RegisterHITTypeFromArgs registers a HIT type, and remembers its reward.
+func (guard *BudgetGuard) RegisterHITTypeFromArgs(args amtgen.TRegisterHITTypeRequest) (
+	amtgen.TxsdRegisterHITTypeResponse, error) {
+
+	resp, err := guard.AmtClient.RegisterHITTypeFromArgs(args)
+	if reward, priceErr := priceAmount(args.Reward); priceErr == nil {
+		guard.learnHITType(resp, reward, err)
+	}
+	return resp, err
+}
+
+// Remember the reward of a newly registered HIT type.
+func (guard *BudgetGuard) learnHITType(resp amtgen.TxsdRegisterHITTypeResponse,
+	reward float32, err error) {
+	if err != nil {
+		return
+	}
+	for _, result := range resp.RegisterHITTypeResults {
+		if result.HITTypeId != "" {
+			guard.SetHITTypeReward(string(result.HITTypeId), reward)
+		}
+	}
+}
+
+// Parse the amount of a price.
+func priceAmount(price *amtgen.TPrice) (float32, error) {
+	if price == nil {
+		return 0, errors.New("Could not read a price: it is missing")
+	}
+	amount, err := strconv.ParseFloat(string(price.Amount), 32)
+	if err != nil {
+		return 0, fmt.Errorf("Could not read the price %q: %v", price.Amount, err)
+	}
+	return float32(amount), nil
+}
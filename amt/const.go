@@ -7,4 +7,24 @@ const (
 
 	URL_SANDBOX = "https://mechanicalturk.sandbox.amazonaws.com"
 	URL_PROD    = "https://mechanicalturk.amazonaws.com"
+
+	// The URLs to which workers submit the forms of HTMLQuestion and
+	// ExternalQuestion HITs
+	SUBMIT_URL_SANDBOX = "https://workersandbox.mturk.com/mturk/externalSubmit"
+	SUBMIT_URL_PROD    = "https://www.mturk.com/mturk/externalSubmit"
+
+	// The assignmentId AMT gives an HTMLQuestion or ExternalQuestion while a
+	// worker previews the HIT before accepting it
+	PREVIEW_ASSIGNMENT_ID = "ASSIGNMENT_ID_NOT_AVAILABLE"
+
+	// The frame height, in pixels, NewHTMLQuestion uses by default
+	DEFAULT_FRAME_HEIGHT = 450
+
+	// The length of the encoded query above which NewClient sends a request
+	// as a form-encoded POST rather than a GET, to stay within URL length
+	// limits
+	DEFAULT_POST_THRESHOLD = 4096
+
+	// The Content-Type of a request sent as a POST
+	FORM_CONTENT_TYPE = "application/x-www-form-urlencoded"
 )
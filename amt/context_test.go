@@ -0,0 +1,64 @@
+package amt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestContext(t *testing.T) {
+	Convey("Given a server which responds slowly", t, func() {
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			fmt.Fprint(w, BALANCE_RESPONSE)
+		}))
+		defer srv.Close()
+		defer close(release)
+		client := newRetryClient(srv.URL, 3)
+
+		Convey("When the context deadline passes during the request", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			_, err := client.GetAccountBalanceCtx(ctx)
+
+			Convey("Then the request fails without retrying", func() {
+				So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+			})
+		})
+	})
+
+	Convey("Given a client waiting on its rate limiter", t, func() {
+		client := newRetryClient("http://127.0.0.1:0", 1)
+		limiter := NewTokenBucket(time.Hour, 1)
+		limiter.Wait(context.Background(), "SearchHITs")
+		client.Limiter = limiter
+
+		Convey("When the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := client.SearchHITsCtx(ctx, "CreationTime", true, 10, 1)
+
+			Convey("Then the wait is abandoned", func() {
+				So(err, ShouldEqual, context.Canceled)
+			})
+		})
+	})
+
+	Convey("Given a client", t, func() {
+		var client interface{} = NewClient(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, true)
+
+		Convey("Then it implements AmtClientContext", func() {
+			_, ok := client.(AmtClientContext)
+			So(ok, ShouldBeTrue)
+		})
+	})
+}
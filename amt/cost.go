@@ -0,0 +1,93 @@
+package amt
+
+import (
+	"math"
+)
+
+const (
+	// The commission Amazon charges on the rewards of a HIT with fewer than
+	// LARGE_HIT_ASSIGNMENTS assignments, and on bonuses
+	COMMISSION_RATE = 0.20
+
+	// The commission Amazon charges on the rewards of a HIT with at least
+	// LARGE_HIT_ASSIGNMENTS assignments
+	LARGE_HIT_COMMISSION_RATE = 0.40
+
+	// The number of assignments at which a HIT is charged the higher rate
+	LARGE_HIT_ASSIGNMENTS = 10
+
+	// The smallest commission charged on each assignment or bonus
+	MIN_COMMISSION = 0.01
+)
+
+// CostEstimate is the amount AMT deducts from an account for a HIT or bonus.
+type CostEstimate struct {
+
+	// The number of assignments paid for, or 0 for a bonus
+	Assignments int
+
+	// The amount paid to workers
+	Rewards float64
+
+	// The amount paid to Amazon
+	Commission float64
+}
+
+// Total returns the full cost: the rewards plus commission.
+func (cost CostEstimate) Total() float64 {
+	return dollars(cents(cost.Rewards) + cents(cost.Commission))
+}
+
+// CommissionRate returns the fraction of its rewards Amazon charges for a HIT
+// with the given number of assignments.
+func CommissionRate(maxAssignments int) float64 {
+	if maxAssignments >= LARGE_HIT_ASSIGNMENTS {
+		return LARGE_HIT_COMMISSION_RATE
+	}
+	return COMMISSION_RATE
+}
+
+// EstimateHITCost returns the cost of creating a HIT with the given reward
+// and maximum number of assignments: the reward for every assignment, plus
+// commission at the rate for the HIT's size.
+func EstimateHITCost(reward float32, maxAssignments int) CostEstimate {
+	return EstimateAssignmentCost(reward, maxAssignments, maxAssignments)
+}
+
+// EstimateBonusCost returns the cost of granting a bonus: the bonus plus
+// commission.
+func EstimateBonusCost(amount float32) CostEstimate {
+	cost := EstimateAssignmentCost(amount, 1, 1)
+	cost.Assignments = 0
+	return cost
+}
+
+// EstimateAssignmentCost returns the cost of paying the reward for some of
+// the assignments of a HIT which has maxAssignments in all, such as those
+// added by ExtendHIT, with commission at the rate for the HIT's size.
+func EstimateAssignmentCost(reward float32, assignments, maxAssignments int) CostEstimate {
+	if assignments <= 0 {
+		return CostEstimate{}
+	}
+	rewards := cents(float64(reward)) * int64(assignments)
+	commission := int64(math.Floor(
+		float64(rewards)*CommissionRate(maxAssignments) + 0.5))
+	if min := cents(MIN_COMMISSION) * int64(assignments); commission < min {
+		commission = min
+	}
+	return CostEstimate{
+		Assignments: assignments,
+		Rewards:     dollars(rewards),
+		Commission:  dollars(commission),
+	}
+}
+
+// Convert dollars to a whole number of cents.
+func cents(amount float64) int64 {
+	return int64(math.Floor(amount*100 + 0.5))
+}
+
+// Convert cents to dollars.
+func dollars(cents int64) float64 {
+	return float64(cents) / 100
+}
@@ -0,0 +1,51 @@
+package amt
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestEstimateCost(t *testing.T) {
+	Convey("Given HITs and bonuses of various sizes", t, func() {
+
+		Convey("Then a small HIT is charged the base commission", func() {
+			cost := EstimateHITCost(0.5, 3)
+			So(cost.Assignments, ShouldEqual, 3)
+			So(cost.Rewards, ShouldEqual, 1.5)
+			So(cost.Commission, ShouldEqual, 0.3)
+			So(cost.Total(), ShouldEqual, 1.8)
+		})
+
+		Convey("Then a HIT with ten assignments is charged the higher commission", func() {
+			So(CommissionRate(9), ShouldEqual, COMMISSION_RATE)
+			So(CommissionRate(10), ShouldEqual, LARGE_HIT_COMMISSION_RATE)
+			cost := EstimateHITCost(0.5, 10)
+			So(cost.Rewards, ShouldEqual, 5.0)
+			So(cost.Commission, ShouldEqual, 2.0)
+			So(cost.Total(), ShouldEqual, 7.0)
+		})
+
+		Convey("Then each assignment pays at least the minimum commission", func() {
+			cost := EstimateHITCost(0.01, 3)
+			So(cost.Commission, ShouldEqual, 0.03)
+			So(cost.Total(), ShouldEqual, 0.06)
+		})
+
+		Convey("Then assignments added to a HIT are charged at the rate for its new size", func() {
+			cost := EstimateAssignmentCost(0.5, 2, 10)
+			So(cost.Assignments, ShouldEqual, 2)
+			So(cost.Rewards, ShouldEqual, 1.0)
+			So(cost.Commission, ShouldEqual, 0.4)
+		})
+
+		Convey("Then a bonus is charged the base commission", func() {
+			cost := EstimateBonusCost(1.5)
+			So(cost.Assignments, ShouldEqual, 0)
+			So(cost.Total(), ShouldEqual, 1.8)
+		})
+
+		Convey("Then a HIT without assignments costs nothing", func() {
+			So(EstimateHITCost(0.5, 0).Total(), ShouldEqual, 0.0)
+		})
+	})
+}
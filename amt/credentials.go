@@ -0,0 +1,338 @@
+package amt
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The environment variables and profile keys read by EnvCredentials and
+// ProfileCredentials
+const (
+	ENV_ACCESS_KEY_ID         = "AWS_ACCESS_KEY_ID"
+	ENV_SECRET_ACCESS_KEY     = "AWS_SECRET_ACCESS_KEY"
+	ENV_PROFILE               = "AWS_PROFILE"
+	ENV_SHARED_CREDENTIALS    = "AWS_SHARED_CREDENTIALS_FILE"
+	DEFAULT_PROFILE           = "default"
+	PROFILE_ACCESS_KEY_ID     = "aws_access_key_id"
+	PROFILE_SECRET_ACCESS_KEY = "aws_secret_access_key"
+)
+
+var (
+	// ErrNoCredentials is wrapped by the error a CredentialProvider returns
+	// when its source holds no credentials, e.g. because an environment
+	// variable is unset. A CredentialChain moves on to its next provider
+	// after such an error, but stops at any other.
+	ErrNoCredentials = errors.New("no credentials found")
+)
+
+// Credentials are the keys used to sign requests to AMT. The JSON encoding
+// is the credentials file read by FileCredentials:
+//
+//	{"AccessKey": "AKIA...", "SecretKey": "..."}
+type Credentials struct {
+	AccessKey, SecretKey string
+}
+
+// CredentialProvider loads Credentials from a single source, such as the
+// environment or a file.
+type CredentialProvider interface {
+
+	// Source describes where the credentials come from, for use in errors
+	Source() string
+
+	// Retrieve loads the credentials. It returns a *CredentialError naming
+	// the source if they cannot be loaded, which wraps ErrNoCredentials if
+	// the source simply holds none.
+	Retrieve() (Credentials, error)
+}
+
+// CredentialError is returned when credentials cannot be loaded from a
+// source.
+type CredentialError struct {
+
+	// The source which failed, e.g. "environment"
+	Source string
+
+	// The reason it failed
+	Err error
+}
+
+func (err *CredentialError) Error() string {
+	return fmt.Sprintf("Could not load AMT credentials from %s: %v",
+		err.Source, err.Err)
+}
+
+// Unwrap returns the reason the source failed.
+func (err *CredentialError) Unwrap() error {
+	return err.Err
+}
+
+// Validate that a source provided both keys.
+func (cred Credentials) validate(source string) (Credentials, error) {
+	switch {
+	case cred.AccessKey == "" && cred.SecretKey == "":
+		return cred, &CredentialError{Source: source, Err: ErrNoCredentials}
+	case cred.AccessKey == "":
+		return cred, &CredentialError{Source: source,
+			Err: errors.New("the access key is missing")}
+	case cred.SecretKey == "":
+		return cred, &CredentialError{Source: source,
+			Err: errors.New("the secret key is missing")}
+	}
+	return cred, nil
+}
+
+// staticProvider returns credentials given by the caller
+type staticProvider struct {
+	cred Credentials
+}
+
+// StaticCredentials provides the given keys. It holds no credentials if both
+// are empty.
+func StaticCredentials(accessKey, secretKey string) CredentialProvider {
+	return staticProvider{Credentials{accessKey, secretKey}}
+}
+
+func (provider staticProvider) Source() string {
+	return "explicit credentials"
+}
+
+func (provider staticProvider) Retrieve() (Credentials, error) {
+	return provider.cred.validate(provider.Source())
+}
+
+// envProvider reads credentials from environment variables
+type envProvider struct{}
+
+// EnvCredentials provides the keys in the AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY environment variables. It holds no credentials if
+// both are unset.
+func EnvCredentials() CredentialProvider {
+	return envProvider{}
+}
+
+func (provider envProvider) Source() string {
+	return "environment"
+}
+
+func (provider envProvider) Retrieve() (Credentials, error) {
+	return Credentials{
+		AccessKey: os.Getenv(ENV_ACCESS_KEY_ID),
+		SecretKey: os.Getenv(ENV_SECRET_ACCESS_KEY),
+	}.validate(provider.Source())
+}
+
+// profileProvider reads a profile from an AWS shared credentials file
+type profileProvider struct {
+	path, profile string
+	explicit      bool
+}
+
+// ProfileCredentials provides the keys of a named profile in an AWS-style
+// shared credentials file:
+//
+//	[default]
+//	aws_access_key_id = AKIA...
+//	aws_secret_access_key = ...
+//
+// An empty path means $AWS_SHARED_CREDENTIALS_FILE, or ~/.aws/credentials if
+// that is unset. An empty profile means $AWS_PROFILE, or "default" if that is
+// unset. A missing file holds no credentials, and so does a missing default
+// profile; a profile which was named but is missing is an error.
+func ProfileCredentials(path, profile string) CredentialProvider {
+	if path == "" {
+		path = os.Getenv(ENV_SHARED_CREDENTIALS)
+	}
+	if path == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, ".aws", "credentials")
+		}
+	}
+	if profile == "" {
+		profile = os.Getenv(ENV_PROFILE)
+	}
+	explicit := profile != ""
+	if profile == "" {
+		profile = DEFAULT_PROFILE
+	}
+	return profileProvider{path: path, profile: profile, explicit: explicit}
+}
+
+func (provider profileProvider) Source() string {
+	return fmt.Sprintf("profile %q in %s", provider.profile, provider.path)
+}
+
+func (provider profileProvider) Retrieve() (Credentials, error) {
+	source := provider.Source()
+	f, err := os.Open(provider.path)
+	if os.IsNotExist(err) || provider.path == "" {
+		return Credentials{}, &CredentialError{Source: source, Err: ErrNoCredentials}
+	} else if err != nil {
+		return Credentials{}, &CredentialError{Source: source, Err: err}
+	}
+	defer f.Close()
+
+	var (
+		cred    Credentials
+		found   bool
+		section string
+		scanner = bufio.NewScanner(f)
+	)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || text[0] == '#' || text[0] == ';':
+		case text[0] == '[':
+			if !strings.HasSuffix(text, "]") {
+				return Credentials{}, &CredentialError{Source: source,
+					Err: fmt.Errorf("line %d: malformed section header", line)}
+			}
+
+			// The AWS config file names its sections "profile <name>"
+			section = strings.TrimSpace(text[1 : len(text)-1])
+			section = strings.TrimSpace(strings.TrimPrefix(section, "profile "))
+			found = found || section == provider.profile
+		case section == provider.profile:
+			eq := strings.IndexByte(text, '=')
+			if eq < 0 {
+				return Credentials{}, &CredentialError{Source: source,
+					Err: fmt.Errorf("line %d: expected key = value", line)}
+			}
+			key := strings.ToLower(strings.TrimSpace(text[:eq]))
+			value := strings.TrimSpace(text[eq+1:])
+			switch key {
+			case PROFILE_ACCESS_KEY_ID:
+				cred.AccessKey = value
+			case PROFILE_SECRET_ACCESS_KEY:
+				cred.SecretKey = value
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Credentials{}, &CredentialError{Source: source, Err: err}
+	}
+	if !found {
+		if provider.explicit {
+			return Credentials{}, &CredentialError{Source: source,
+				Err: errors.New("the profile does not exist")}
+		}
+		return Credentials{}, &CredentialError{Source: source, Err: ErrNoCredentials}
+	}
+	return cred.validate(source)
+}
+
+// fileProvider reads credentials from a JSON file
+type fileProvider struct {
+	path string
+}
+
+// FileCredentials provides the keys in a JSON file holding Credentials, the
+// format read by amtadmin's --amt flag. An empty path holds no credentials,
+// but a file which is named and cannot be read is an error.
+func FileCredentials(path string) CredentialProvider {
+	return fileProvider{path: path}
+}
+
+func (provider fileProvider) Source() string {
+	if provider.path == "" {
+		return "credentials file"
+	}
+	return "file " + provider.path
+}
+
+func (provider fileProvider) Retrieve() (Credentials, error) {
+	var cred Credentials
+	if provider.path == "" {
+		return cred, &CredentialError{Source: provider.Source(),
+			Err: ErrNoCredentials}
+	}
+	data, err := ioutil.ReadFile(provider.path)
+	if err != nil {
+		return cred, &CredentialError{Source: provider.Source(), Err: err}
+	}
+	if err := json.Unmarshal(data, &cred); err != nil {
+		return cred, &CredentialError{Source: provider.Source(), Err: err}
+	}
+	return cred.validate(provider.Source())
+}
+
+// CredentialChain is a CredentialProvider which tries a list of providers in
+// order, returning the first credentials found. It stops at the first
+// provider which fails for any reason other than holding no credentials.
+type CredentialChain []CredentialProvider
+
+// DefaultCredentialChain tries the sources the caller named: the JSON
+// credentials file at path, the given keys, and the named profile in the
+// shared credentials file, in that order. Only if none was named does it
+// fall back to the environment and then the default profile, so that keys
+// in the environment never override a source chosen explicitly. Callers
+// who want the environment to win can build their own CredentialChain, e.g.
+// CredentialChain{EnvCredentials(), ProfileCredentials("", profile)}.
+func DefaultCredentialChain(profile, path, accessKey,
+	secretKey string) CredentialChain {
+	var chain CredentialChain
+	if path != "" {
+		chain = append(chain, FileCredentials(path))
+	}
+	if accessKey != "" || secretKey != "" {
+		chain = append(chain, StaticCredentials(accessKey, secretKey))
+	}
+	if profile != "" {
+		chain = append(chain, ProfileCredentials("", profile))
+	}
+	if len(chain) == 0 {
+		chain = CredentialChain{EnvCredentials(), ProfileCredentials("", "")}
+	}
+	return chain
+}
+
+// Source lists the sources of the providers in the chain.
+func (chain CredentialChain) Source() string {
+	var sources []string
+	for _, provider := range chain {
+		sources = append(sources, provider.Source())
+	}
+	return strings.Join(sources, ", ")
+}
+
+// Retrieve returns the credentials of the first provider which has them.
+func (chain CredentialChain) Retrieve() (Credentials, error) {
+	for _, provider := range chain {
+		cred, err := provider.Retrieve()
+		if err == nil {
+			return cred, nil
+		} else if !errors.Is(err, ErrNoCredentials) {
+			return cred, err
+		}
+	}
+	return Credentials{}, &CredentialError{Source: chain.Source(),
+		Err: ErrNoCredentials}
+}
+
+// NewClientWithCredentials is like NewClient, but loads its keys from a
+// CredentialProvider.
+func NewClientWithCredentials(provider CredentialProvider, sandbox bool,
+	opts ...Option) (AmtClient, error) {
+	cred, err := provider.Retrieve()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(cred.AccessKey, cred.SecretKey, sandbox, opts...), nil
+}
+
+// NewJSONClientWithCredentials is like NewJSONClient, but loads its keys from
+// a CredentialProvider.
+func NewJSONClientWithCredentials(provider CredentialProvider, sandbox bool,
+	opts ...Option) (AmtClient, error) {
+	cred, err := provider.Retrieve()
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONClient(cred.AccessKey, cred.SecretKey, sandbox, opts...), nil
+}
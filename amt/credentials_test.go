@@ -0,0 +1,169 @@
+package amt
+
+import (
+	"errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	PROFILE_FILE = `# Shared credentials
+[default]
+aws_access_key_id = DefaultKey
+aws_secret_access_key = DefaultSecret
+
+[profile work]
+AWS_ACCESS_KEY_ID=WorkKey
+aws_secret_access_key=WorkSecret
+region = us-east-1
+
+[broken]
+aws_access_key_id = BrokenKey
+`
+)
+
+func TestCredentials(t *testing.T) {
+	Convey("Given credential files and an empty environment", t, func() {
+		dir, err := ioutil.TempDir("", "amt-credentials")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		profiles := filepath.Join(dir, "credentials")
+		So(ioutil.WriteFile(profiles, []byte(PROFILE_FILE), 0600), ShouldBeNil)
+		jsonPath := filepath.Join(dir, "amt.json")
+		So(ioutil.WriteFile(jsonPath,
+			[]byte(`{"AccessKey": "JSONKey", "SecretKey": "JSONSecret"}`), 0600),
+			ShouldBeNil)
+		for _, name := range []string{ENV_ACCESS_KEY_ID, ENV_SECRET_ACCESS_KEY,
+			ENV_PROFILE} {
+			if value, ok := os.LookupEnv(name); ok {
+				defer os.Setenv(name, value)
+				os.Unsetenv(name)
+			}
+		}
+		os.Setenv(ENV_SHARED_CREDENTIALS, profiles)
+		defer os.Unsetenv(ENV_SHARED_CREDENTIALS)
+
+		Convey("When the environment holds credentials", func() {
+			os.Setenv(ENV_ACCESS_KEY_ID, "EnvKey")
+			os.Setenv(ENV_SECRET_ACCESS_KEY, "EnvSecret")
+			defer os.Unsetenv(ENV_ACCESS_KEY_ID)
+			defer os.Unsetenv(ENV_SECRET_ACCESS_KEY)
+
+			Convey("Then they take precedence over the default profile", func() {
+				cred, err := DefaultCredentialChain("", "", "", "").Retrieve()
+				So(err, ShouldBeNil)
+				So(cred, ShouldResemble, Credentials{"EnvKey", "EnvSecret"})
+			})
+
+			Convey("Then a JSON file I name takes precedence over them", func() {
+				cred, err := DefaultCredentialChain("work", jsonPath, "Key", "Secret").Retrieve()
+				So(err, ShouldBeNil)
+				So(cred, ShouldResemble, Credentials{"JSONKey", "JSONSecret"})
+			})
+
+			Convey("Then a profile I name takes precedence over them", func() {
+				cred, err := DefaultCredentialChain("work", "", "", "").Retrieve()
+				So(err, ShouldBeNil)
+				So(cred, ShouldResemble, Credentials{"WorkKey", "WorkSecret"})
+			})
+
+			Convey("Then a missing profile I name is an error", func() {
+				_, err := DefaultCredentialChain("home", "", "", "").Retrieve()
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, `profile "home"`)
+			})
+		})
+
+		Convey("When the environment holds only an access key", func() {
+			os.Setenv(ENV_ACCESS_KEY_ID, "EnvKey")
+			defer os.Unsetenv(ENV_ACCESS_KEY_ID)
+			_, err := DefaultCredentialChain("", "", "", "").Retrieve()
+
+			Convey("Then the error names the environment", func() {
+				var credErr *CredentialError
+				So(errors.As(err, &credErr), ShouldBeTrue)
+				So(credErr.Source, ShouldEqual, "environment")
+				So(err.Error(), ShouldContainSubstring, "secret key is missing")
+			})
+		})
+
+		Convey("When I choose a profile", func() {
+			cred, err := DefaultCredentialChain("work", "", "", "").Retrieve()
+
+			Convey("Then its keys are used", func() {
+				So(err, ShouldBeNil)
+				So(cred, ShouldResemble, Credentials{"WorkKey", "WorkSecret"})
+			})
+		})
+
+		Convey("When I choose no profile", func() {
+			cred, err := DefaultCredentialChain("", "", "", "").Retrieve()
+
+			Convey("Then the default profile is used", func() {
+				So(err, ShouldBeNil)
+				So(cred, ShouldResemble, Credentials{"DefaultKey", "DefaultSecret"})
+			})
+		})
+
+		Convey("When I choose a missing profile", func() {
+			_, err := DefaultCredentialChain("home", "", "", "").Retrieve()
+
+			Convey("Then the error names the profile", func() {
+				So(errors.Is(err, ErrNoCredentials), ShouldBeFalse)
+				So(err.Error(), ShouldContainSubstring, `profile "home" in `+profiles)
+			})
+		})
+
+		Convey("When I choose an incomplete profile", func() {
+			_, err := ProfileCredentials("", "broken").Retrieve()
+
+			Convey("Then the error names the profile", func() {
+				So(err.Error(), ShouldContainSubstring, `profile "broken"`)
+				So(err.Error(), ShouldContainSubstring, "secret key is missing")
+			})
+		})
+
+		Convey("When there is no shared credentials file", func() {
+			os.Setenv(ENV_SHARED_CREDENTIALS, filepath.Join(dir, "missing"))
+			cred, err := DefaultCredentialChain("", jsonPath, "", "").Retrieve()
+
+			Convey("Then the JSON file is used", func() {
+				So(err, ShouldBeNil)
+				So(cred, ShouldResemble, Credentials{"JSONKey", "JSONSecret"})
+			})
+
+			Convey("Then without a JSON file the explicit keys are used", func() {
+				cred, err := DefaultCredentialChain("", "", "Key", "Secret").Retrieve()
+				So(err, ShouldBeNil)
+				So(cred, ShouldResemble, Credentials{"Key", "Secret"})
+			})
+
+			Convey("Then a bad JSON file is named in the error", func() {
+				So(ioutil.WriteFile(jsonPath, []byte("{"), 0600), ShouldBeNil)
+				_, err := DefaultCredentialChain("", jsonPath, "Key", "Secret").Retrieve()
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "file "+jsonPath)
+			})
+
+			Convey("Then with no other source the error lists every source", func() {
+				_, err := DefaultCredentialChain("", "", "", "").Retrieve()
+				So(errors.Is(err, ErrNoCredentials), ShouldBeTrue)
+				So(err.Error(), ShouldContainSubstring, "environment")
+				So(err.Error(), ShouldContainSubstring, `profile "default"`)
+			})
+		})
+
+		Convey("When I create a client from a provider", func() {
+			client, err := NewClientWithCredentials(FileCredentials(jsonPath), true)
+
+			Convey("Then it uses the provided keys", func() {
+				So(err, ShouldBeNil)
+				So(client.(*amtClient).AWSAccessKeyId, ShouldEqual, "JSONKey")
+				So(client.(*amtClient).SecretKey, ShouldEqual, "JSONSecret")
+			})
+		})
+	})
+}
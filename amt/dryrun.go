@@ -0,0 +1,399 @@
+package amt
+
+import (
+	"fmt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// The longest argument value written to a dry run log in full
+	DRY_RUN_MAX_VALUE = 60
+)
+
+var (
+	// The arguments each mutating operation cannot do without. An entry
+	// "A|B" is satisfied by either argument.
+	dryRunRequiredArgs = map[string][]string{
+		"ApproveAssignment":          {"AssignmentId"},
+		"ApproveRejectedAssignment":  {"AssignmentId"},
+		"AssignQualification":        {"QualificationTypeId", "WorkerId"},
+		"BlockWorker":                {"WorkerId", "Reason"},
+		"ChangeHITTypeOfHIT":         {"HITId", "HITTypeId"},
+		"CreateHIT":                  {"HITTypeId|Title", "HITLayoutId|Question", "LifetimeInSeconds"},
+		"CreateQualificationType":    {"Name", "Description", "QualificationTypeStatus"},
+		"DisableHIT":                 {"HITId"},
+		"DisposeHIT":                 {"HITId"},
+		"DisposeQualificationType":   {"QualificationTypeId"},
+		"ExtendHIT":                  {"HITId", "ExpirationIncrementInSeconds|MaxAssignmentsIncrement"},
+		"ForceExpireHIT":             {"HITId"},
+		"GrantBonus":                 {"WorkerId", "AssignmentId", "BonusAmount", "Reason"},
+		"GrantQualification":         {"QualificationRequestId"},
+		"NotifyWorkers":              {"Subject", "MessageText", "WorkerId"},
+		"RegisterHITType":            {"Title", "Description", "Reward", "AssignmentDurationInSeconds"},
+		"RejectAssignment":           {"AssignmentId"},
+		"RejectQualificationRequest": {"QualificationRequestId"},
+		"RevokeQualification":        {"SubjectId", "QualificationTypeId"},
+		"SendTestEventNotification":  {"Notification", "TestEventType"},
+		"SetHITAsReviewing":          {"HITId"},
+		"SetHITTypeNotification":     {"HITTypeId"},
+		"UnblockWorker":              {"WorkerId"},
+		"UpdateQualificationScore":   {"QualificationTypeId", "SubjectId"},
+		"UpdateQualificationType":    {"QualificationTypeId"},
+	}
+
+	// The ID fields of synthetic results, and the prefix of their values
+	dryRunIdFields = map[string]string{
+		"HITId":               "DRYRUNHIT",
+		"HITTypeId":           "DRYRUNHITTYPE",
+		"QualificationTypeId": "DRYRUNQUALTYPE",
+	}
+)
+
+// DryRunCall describes a mutating call made through a DryRunClient.
+type DryRunCall struct {
+
+	// The operation, e.g. "GrantBonus"
+	Operation string
+
+	// The operation's arguments, as they would have been sent to AMT
+	Args url.Values
+}
+
+// DryRunClient is an AmtClient which previews the changes a program would
+// make to an AMT account without making them. Operations which only read
+// data, such as GetHIT, SearchHITs and GetAccountBalance, are passed to the
+// wrapped client. Mutating operations, such as CreateHIT, GrantBonus and
+// ForceExpireHIT, are checked for missing arguments, written to a log and
+// answered with a synthetic response; nothing is sent to AMT.
+//
+// A synthetic response is valid and has RequestId "DRYRUN". Objects it
+// creates, such as the HIT for CreateHIT, are built from the call's
+// arguments and have IDs starting with "DRYRUN", so later calls can refer to
+// them, but the wrapped client will not find them.
+type DryRunClient struct {
+	AmtClient
+
+	// Builds the requests which are not sent
+	writer amtClient
+
+	mu    sync.Mutex
+	log   io.Writer
+	calls []DryRunCall
+	ids   int
+}
+
+// NewDryRunClient wraps a client so that its mutating operations are
+// previewed rather than sent. Each one is described by a line written to
+// the log, which may be nil.
+func NewDryRunClient(client AmtClient, log io.Writer) *DryRunClient {
+	dry := &DryRunClient{AmtClient: client, log: log}
+	dry.writer.dryRun = dry.respond
+	return dry
+}
+
+// Calls returns the mutating calls made so far, in order.
+func (client *DryRunClient) Calls() []DryRunCall {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	return append([]DryRunCall(nil), client.calls...)
+}
+
+// Check, record and answer a request in place of AMT.
+func (client *DryRunClient) respond(operation string, request,
+	response interface{}) error {
+
+	args := packRequest(request)
+	if err := checkDryRunArgs(operation, args); err != nil {
+		return err
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.calls = append(client.calls, DryRunCall{
+		Operation: operation,
+		Args:      args,
+	})
+	if client.log != nil {
+		fmt.Fprintf(client.log, "[dry run] %s\n", formatDryRunCall(operation, args))
+	}
+
+	// Fill in the response envelope and a single result
+	resp := reflect.ValueOf(response).Elem()
+	resp.FieldByName("OperationRequest").Set(reflect.ValueOf(
+		&amtgen.TxsdOperationRequest{}))
+	resp.FieldByName("OperationRequest").Elem().FieldByName("RequestId").
+		SetString("DRYRUN")
+	for _, field := range reflect.VisibleFields(resp.Type()) {
+		if field.Type.Kind() != reflect.Slice || field.Type.Elem().Kind() != reflect.Ptr {
+			continue
+		}
+		result := reflect.New(field.Type.Elem().Elem())
+		client.fillResult(result.Elem(), request)
+		results := resp.FieldByIndex(field.Index)
+		results.Set(reflect.Append(results, result))
+	}
+	return nil
+}
+
+// Build a synthetic result from the arguments of a request.
+func (client *DryRunClient) fillResult(result reflect.Value, request interface{}) {
+	args := reflect.ValueOf(request).Elem().FieldByName("Requests").Index(0).Elem()
+	for _, field := range reflect.VisibleFields(result.Type()) {
+		if field.Anonymous {
+			continue
+		}
+		dst := result.FieldByIndex(field.Index)
+		if arg := args.FieldByName(field.Name); arg.IsValid() &&
+			arg.Type() == field.Type {
+			dst.Set(arg)
+		}
+		switch {
+		case field.Name == "Request":
+			dst.Set(reflect.ValueOf(jsonValidRequest()))
+		case field.Name == "HITStatus":
+			dst.SetString("Assignable")
+		case field.Name == "CreationTime":
+			dst.SetString(FormatNow())
+		case dryRunIdFields[field.Name] != "" && dst.String() == "":
+			client.ids++
+			dst.SetString(fmt.Sprintf("%s%09d", dryRunIdFields[field.Name], client.ids))
+		}
+	}
+}
+
+// Check that a request has the arguments its operation requires.
+func checkDryRunArgs(operation string, args url.Values) error {
+	for _, required := range dryRunRequiredArgs[operation] {
+		var found bool
+		for _, name := range strings.Split(required, "|") {
+			for key := range args {
+				if key == name || strings.HasPrefix(key, name+".") {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("Dry run of %s failed: %s is required", operation,
+				strings.Replace(required, "|", " or ", -1))
+		}
+	}
+	for key, values := range args {
+		if !strings.HasSuffix(key, ".Amount") {
+			continue
+		}
+		for _, value := range values {
+			if amount, err := strconv.ParseFloat(value, 64); err != nil || amount <= 0 {
+				return fmt.Errorf("Dry run of %s failed: %s must be a positive amount, not %q",
+					operation, key, value)
+			}
+		}
+	}
+	return nil
+}
+
+// Describe a call on a single line, with its arguments sorted by name.
+func formatDryRunCall(operation string, args url.Values) string {
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := []string{operation}
+	for _, key := range keys {
+		for _, value := range args[key] {
+			if len(value) > DRY_RUN_MAX_VALUE {
+				value = value[:DRY_RUN_MAX_VALUE] + "..."
+			}
+			parts = append(parts, fmt.Sprintf("%s=%q", key, value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// The mutating operations, which are all previewed
+
+func (client *DryRunClient) ApproveAssignment(assignmentId, requesterFeedback string) (
+	amtgen.TxsdApproveAssignmentResponse, error) {
+	return client.writer.ApproveAssignment(assignmentId, requesterFeedback)
+}
+
+func (client *DryRunClient) ApproveRejectedAssignment(assignmentId, requesterFeedback string) (
+	amtgen.TxsdApproveRejectedAssignmentResponse, error) {
+	return client.writer.ApproveRejectedAssignment(assignmentId, requesterFeedback)
+}
+
+func (client *DryRunClient) AssignQualification(qualificationTypeId, workerId string,
+	integerValue int, sendNotification bool) (
+	amtgen.TxsdAssignQualificationResponse, error) {
+	return client.writer.AssignQualification(qualificationTypeId, workerId,
+		integerValue, sendNotification)
+}
+
+func (client *DryRunClient) BlockWorker(workerId, reason string) (
+	amtgen.TxsdBlockWorkerResponse, error) {
+	return client.writer.BlockWorker(workerId, reason)
+}
+
+func (client *DryRunClient) ChangeHITTypeOfHIT(hitId, hitTypeId string) (
+	amtgen.TxsdChangeHITTypeOfHITResponse, error) {
+	return client.writer.ChangeHITTypeOfHIT(hitId, hitTypeId)
+}
+
+func (client *DryRunClient) CreateHIT(title, description, question string,
+	hitLayoutId string, hitLayoutParameters map[string]string, reward float32,
+	assignmentDurationInSeconds, lifetimeInSeconds, maxAssignments,
+	autoApprovalDelayInSeconds int, keywords []string,
+	qualificationRequirements []*amtgen.TQualificationRequirement,
+	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
+	requesterAnnotation, uniqueRequestToken string) (
+	amtgen.TxsdCreateHITResponse, error) {
+	return client.writer.CreateHIT(title, description, question, hitLayoutId,
+		hitLayoutParameters, reward, assignmentDurationInSeconds,
+		lifetimeInSeconds, maxAssignments, autoApprovalDelayInSeconds, keywords,
+		qualificationRequirements, assignmentReviewPolicy, hitReviewPolicy,
+		requesterAnnotation, uniqueRequestToken)
+}
+
+func (client *DryRunClient) CreateHITFromArgs(args amtgen.TCreateHITRequest) (
+	amtgen.TxsdCreateHITResponse, error) {
+	return client.writer.CreateHITFromArgs(args)
+}
+
+func (client *DryRunClient) CreateHITFromHITTypeId(hitTypeId, question string,
+	hitLayoutId string, hitLayoutParameters map[string]string,
+	lifetimeInSeconds, maxAssignments int,
+	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
+	requesterAnnotation, uniqueRequestToken string) (
+	amtgen.TxsdCreateHITResponse, error) {
+	return client.writer.CreateHITFromHITTypeId(hitTypeId, question, hitLayoutId,
+		hitLayoutParameters, lifetimeInSeconds, maxAssignments,
+		assignmentReviewPolicy, hitReviewPolicy, requesterAnnotation,
+		uniqueRequestToken)
+}
+
+func (client *DryRunClient) CreateQualificationType(name, description string,
+	keywords []string, retryDelayInSeconds int, qualificationTypeStatus, test,
+	answerKey string, testDurationInSeconds int, autoGranted bool,
+	autoGrantedValue int) (amtgen.TxsdCreateQualificationTypeResponse, error) {
+	return client.writer.CreateQualificationType(name, description, keywords,
+		retryDelayInSeconds, qualificationTypeStatus, test, answerKey,
+		testDurationInSeconds, autoGranted, autoGrantedValue)
+}
+
+func (client *DryRunClient) DisableHIT(hitId string) (
+	amtgen.TxsdDisableHITResponse, error) {
+	return client.writer.DisableHIT(hitId)
+}
+
+func (client *DryRunClient) DisposeHIT(hitId string) (
+	amtgen.TxsdDisposeHITResponse, error) {
+	return client.writer.DisposeHIT(hitId)
+}
+
+func (client *DryRunClient) DisposeQualificationType(qualificationTypeId string) (
+	amtgen.TxsdDisposeQualificationTypeResponse, error) {
+	return client.writer.DisposeQualificationType(qualificationTypeId)
+}
+
+func (client *DryRunClient) ExtendHIT(hitId string, maxAssignmentsIncrement,
+	expirationIncrementInSeconds int, uniqueRequestToken string) (
+	amtgen.TxsdExtendHITResponse, error) {
+	return client.writer.ExtendHIT(hitId, maxAssignmentsIncrement,
+		expirationIncrementInSeconds, uniqueRequestToken)
+}
+
+func (client *DryRunClient) ForceExpireHIT(hitId string) (
+	amtgen.TxsdForceExpireHITResponse, error) {
+	return client.writer.ForceExpireHIT(hitId)
+}
+
+func (client *DryRunClient) GrantBonus(workerId, assignmentId string,
+	bonusAmount float32, reason, uniqueRequestToken string) (
+	amtgen.TxsdGrantBonusResponse, error) {
+	return client.writer.GrantBonus(workerId, assignmentId, bonusAmount, reason,
+		uniqueRequestToken)
+}
+
+func (client *DryRunClient) GrantQualification(qualificationRequestId string,
+	integerValue int) (amtgen.TxsdGrantQualificationResponse, error) {
+	return client.writer.GrantQualification(qualificationRequestId, integerValue)
+}
+
+func (client *DryRunClient) NotifyWorkers(subject, messageText string,
+	workerIds []string) (amtgen.TxsdNotifyWorkersResponse, error) {
+	return client.writer.NotifyWorkers(subject, messageText, workerIds)
+}
+
+func (client *DryRunClient) RegisterHITType(title, description string,
+	reward float32, assignmentDurationInSeconds, autoApprovalDelayInSeconds int,
+	keywords []string, qualificationRequirements []*amtgen.TQualificationRequirement) (
+	amtgen.TxsdRegisterHITTypeResponse, error) {
+	return client.writer.RegisterHITType(title, description, reward,
+		assignmentDurationInSeconds, autoApprovalDelayInSeconds, keywords,
+		qualificationRequirements)
+}
+
+func (client *DryRunClient) RegisterHITTypeFromArgs(args amtgen.TRegisterHITTypeRequest) (
+	amtgen.TxsdRegisterHITTypeResponse, error) {
+	return client.writer.RegisterHITTypeFromArgs(args)
+}
+
+func (client *DryRunClient) RejectAssignment(assignmentId, requesterFeedback string) (
+	amtgen.TxsdRejectAssignmentResponse, error) {
+	return client.writer.RejectAssignment(assignmentId, requesterFeedback)
+}
+
+func (client *DryRunClient) RejectQualificationRequest(qualificationRequestId,
+	reason string) (amtgen.TxsdRejectQualificationRequestResponse, error) {
+	return client.writer.RejectQualificationRequest(qualificationRequestId, reason)
+}
+
+func (client *DryRunClient) RevokeQualification(subjectId, qualificationTypeId,
+	reason string) (amtgen.TxsdRevokeQualificationResponse, error) {
+	return client.writer.RevokeQualification(subjectId, qualificationTypeId, reason)
+}
+
+func (client *DryRunClient) SendTestEventNotification(
+	notification *amtgen.TNotificationSpecification, testEventType string) (
+	amtgen.TxsdSendTestEventNotificationResponse, error) {
+	return client.writer.SendTestEventNotification(notification, testEventType)
+}
+
+func (client *DryRunClient) SetHITAsReviewing(hitID string, revert bool) (
+	amtgen.TxsdSetHITAsReviewingResponse, error) {
+	return client.writer.SetHITAsReviewing(hitID, revert)
+}
+
+func (client *DryRunClient) SetHITTypeNotification(hitTypeID string,
+	notification *amtgen.TNotificationSpecification, active bool) (
+	amtgen.TxsdSetHITTypeNotificationResponse, error) {
+	return client.writer.SetHITTypeNotification(hitTypeID, notification, active)
+}
+
+func (client *DryRunClient) UnblockWorker(workerId, reason string) (
+	amtgen.TxsdUnblockWorkerResponse, error) {
+	return client.writer.UnblockWorker(workerId, reason)
+}
+
+func (client *DryRunClient) UpdateQualificationScore(qualificationTypeId,
+	subjectId string, integerValue int) (
+	amtgen.TxsdUpdateQualificationScoreResponse, error) {
+	return client.writer.UpdateQualificationScore(qualificationTypeId, subjectId,
+		integerValue)
+}
+
+func (client *DryRunClient) UpdateQualificationType(qualificationTypeId string,
+	retryDelayInSeconds int, qualificationTypeStatus, description, test,
+	answerKey string, testDurationInSeconds int, autoGranted bool,
+	autoGrantedValue int) (amtgen.TxsdUpdateQualificationTypeResponse, error) {
+	return client.writer.UpdateQualificationType(qualificationTypeId,
+		retryDelayInSeconds, qualificationTypeStatus, description, test,
+		answerKey, testDurationInSeconds, autoGranted, autoGrantedValue)
+}
@@ -0,0 +1,116 @@
+package amt
+
+import (
+	"bytes"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDryRunClient(t *testing.T) {
+	Convey("Given a dry run client wrapping a client for a fake AMT", t, func() {
+		var sent []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			sent = append(sent, r.Form.Get("Operation"))
+			fmt.Fprint(w, BALANCE_RESPONSE)
+		}))
+		defer srv.Close()
+		var log bytes.Buffer
+		client := NewDryRunClient(NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY,
+			srv.URL, WithLimiter(nil)), &log)
+
+		Convey("When I check the balance", func() {
+			_, err := client.GetAccountBalance()
+
+			Convey("Then the request is sent", func() {
+				So(err, ShouldBeNil)
+				So(sent, ShouldResemble, []string{"GetAccountBalance"})
+				So(client.Calls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When I grant a bonus", func() {
+			resp, err := client.GrantBonus("W1", "A1", 1.5, "Thanks", "tok")
+
+			Convey("Then it is logged and answered but not sent", func() {
+				So(err, ShouldBeNil)
+				So(sent, ShouldBeEmpty)
+				So(string(resp.OperationRequest.RequestId), ShouldEqual, "DRYRUN")
+				So(resp.GrantBonusResults, ShouldHaveLength, 1)
+				So(string(resp.GrantBonusResults[0].Request.IsValid), ShouldEqual, "True")
+				So(log.String(), ShouldEqual, `[dry run] GrantBonus AssignmentId="A1" `+
+					`BonusAmount.1.Amount="1.5" BonusAmount.1.CurrencyCode="USD" `+
+					`Reason="Thanks" UniqueRequestToken="tok" WorkerId="W1"`+"\n")
+				calls := client.Calls()
+				So(calls, ShouldHaveLength, 1)
+				So(calls[0].Operation, ShouldEqual, "GrantBonus")
+				So(calls[0].Args.Get("WorkerId"), ShouldEqual, "W1")
+			})
+		})
+
+		Convey("When I grant a bonus of nothing", func() {
+			_, err := client.GrantBonus("W1", "A1", 0, "Thanks", "")
+
+			Convey("Then it fails", func() {
+				So(err.Error(), ShouldContainSubstring, "BonusAmount.1.Amount must be a positive amount")
+				So(client.Calls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When I create a HIT", func() {
+			resp, err := client.CreateHIT("Sky", "Name the sky's color", "<q/>", "",
+				nil, 0.5, 60, 3600, 3, 600, []string{"sky"}, nil, nil, nil, "", "")
+
+			Convey("Then a HIT is built from the arguments", func() {
+				So(err, ShouldBeNil)
+				So(resp.Hits, ShouldHaveLength, 1)
+				hit := resp.Hits[0]
+				So(string(hit.HITId), ShouldStartWith, "DRYRUNHIT")
+				So(string(hit.HITTypeId), ShouldStartWith, "DRYRUNHITTYPE")
+				So(string(hit.Title), ShouldEqual, "Sky")
+				So(string(hit.Reward.Amount), ShouldEqual, "0.5")
+				So(int(hit.MaxAssignments), ShouldEqual, 3)
+				So(string(hit.HITStatus), ShouldEqual, "Assignable")
+				So(sent, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When I expire a HIT without an ID", func() {
+			_, err := client.ForceExpireHIT("")
+
+			Convey("Then it fails", func() {
+				So(err.Error(), ShouldEqual, "Dry run of ForceExpireHIT failed: HITId is required")
+			})
+		})
+	})
+
+	Convey("Given a dry run client wrapping no client", t, func() {
+		client := NewDryRunClient(nil, nil)
+
+		Convey("When I call each mutating operation with no arguments", func() {
+			clientType := reflect.TypeOf((*AmtClient)(nil)).Elem()
+			var called int
+			for i := 0; i < clientType.NumMethod(); i++ {
+				method := clientType.Method(i)
+				if !IsMutatingOperation(method.Name) {
+					continue
+				}
+				fn := reflect.ValueOf(client).MethodByName(method.Name)
+				args := make([]reflect.Value, fn.Type().NumIn())
+				for j := range args {
+					args[j] = reflect.Zero(fn.Type().In(j))
+				}
+				So(func() { fn.Call(args) }, ShouldNotPanic)
+				called++
+			}
+
+			Convey("Then none reaches the wrapped client", func() {
+				So(called, ShouldEqual, 28)
+			})
+		})
+	})
+}
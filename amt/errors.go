@@ -0,0 +1,216 @@
+package amt
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	"net/http"
+	"reflect"
+)
+
+// AMT error codes for common failures
+const (
+	CODE_ASSIGNMENT_NOT_FOUND = "AWS.MechanicalTurk.AssignmentDoesNotExist"
+	CODE_DUPLICATE_REQUEST    = "AWS.MechanicalTurk.DuplicateRequest"
+	CODE_HIT_NOT_FOUND        = "AWS.MechanicalTurk.HITDoesNotExist"
+	CODE_INSUFFICIENT_FUNDS   = "AWS.MechanicalTurk.InsufficientFunds"
+	CODE_SERVICE_UNAVAILABLE  = "AWS.ServiceUnavailable"
+)
+
+var (
+	// Sentinel errors for use with errors.Is. Any *APIError with the same
+	// Code matches, regardless of the operation which produced it.
+	ErrAssignmentNotFound = &APIError{Code: CODE_ASSIGNMENT_NOT_FOUND}
+	ErrDuplicateRequest   = &APIError{Code: CODE_DUPLICATE_REQUEST}
+	ErrHITNotFound        = &APIError{Code: CODE_HIT_NOT_FOUND}
+	ErrInsufficientFunds  = &APIError{Code: CODE_INSUFFICIENT_FUNDS}
+	ErrThrottled          = &APIError{Code: CODE_SERVICE_UNAVAILABLE}
+)
+
+// APIError is returned when AMT accepts a request but reports that it could
+// not be carried out, via an <Errors> element in the response. The decoded
+// response is returned alongside the error.
+type APIError struct {
+
+	// The operation which failed, e.g. "CreateHIT"
+	Operation string
+
+	// The AMT error code, e.g. "AWS.MechanicalTurk.HITDoesNotExist"
+	Code string
+
+	// The human-readable error message
+	Message string
+
+	// Any key/value pairs AMT attached to the error
+	Data map[string]string
+
+	// The RequestId AMT assigned to the failed request
+	RequestId string
+}
+
+func (err *APIError) Error() string {
+	if err.Operation == "" {
+		return fmt.Sprintf("AMT error %s: %s", err.Code, err.Message)
+	}
+	return fmt.Sprintf("%s failed with AMT error %s: %s", err.Operation,
+		err.Code, err.Message)
+}
+
+// Is reports whether target is an *APIError with the same Code, so that
+// errors.Is(err, ErrHITNotFound) works for errors from any operation.
+func (err *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	return ok && t.Code != "" && t.Code == err.Code &&
+		(t.Operation == "" || t.Operation == err.Operation)
+}
+
+// IsThrottled reports whether err indicates that AMT throttled the request,
+// either through an HTTP status or an AMT error code.
+func IsThrottled(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return retryableCodes[httpErr.Code] ||
+			httpErr.StatusCode == http.StatusTooManyRequests
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && retryableCodes[apiErr.Code]
+}
+
+// Build an APIError for the first error in an <Errors> element, or return nil
+// if there are none.
+func newAPIError(operation, requestId string, errs *amtgen.TxsdErrors) error {
+	if errs == nil || len(errs.Errors) == 0 || errs.Errors[0] == nil {
+		return nil
+	}
+	first := errs.Errors[0]
+	apiErr := &APIError{
+		Operation: operation,
+		Code:      string(first.Code),
+		Message:   string(first.Message),
+		RequestId: requestId,
+	}
+	for _, pair := range first.Datas {
+		if pair == nil {
+			continue
+		}
+		if apiErr.Data == nil {
+			apiErr.Data = make(map[string]string)
+		}
+		apiErr.Data[string(pair.Key)] = string(pair.Value)
+	}
+	return apiErr
+}
+
+// Find any errors reported in a decoded response. AMT may report errors for
+// the operation as a whole, in the OperationRequest, or for an individual
+// result, in its Request element.
+func responseError(operation string, response interface{}) error {
+	var (
+		v         = reflect.ValueOf(response).Elem()
+		requestId string
+	)
+	if field := v.FieldByName("OperationRequest"); field.IsValid() {
+		if opReq, ok := field.Interface().(*amtgen.TxsdOperationRequest); ok && opReq != nil {
+			requestId = string(opReq.RequestId)
+			if err := newAPIError(operation, requestId, opReq.Errors); err != nil {
+				return err
+			}
+		}
+	}
+	for i := 0; i < v.NumField(); i++ {
+		embed := v.Field(i)
+		if embed.Kind() != reflect.Struct {
+			continue
+		}
+		for j := 0; j < embed.NumField(); j++ {
+			results := embed.Field(j)
+			if results.Kind() != reflect.Slice {
+				continue
+			}
+			for k := 0; k < results.Len(); k++ {
+				result := reflect.Indirect(results.Index(k))
+				if result.Kind() != reflect.Struct {
+					continue
+				}
+				field := result.FieldByName("Request")
+				if !field.IsValid() {
+					continue
+				}
+				if req, ok := field.Interface().(*amtgen.TxsdRequest); ok && req != nil {
+					if err := newAPIError(operation, requestId, req.Errors); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// HTTPError is returned when AMT answers a request with a non-200 HTTP status.
+type HTTPError struct {
+
+	// The HTTP status code, e.g. 503
+	StatusCode int
+
+	// The HTTP status line, e.g. "503 Service Unavailable"
+	Status string
+
+	// The AMT error code found in the response body, if any
+	Code string
+
+	// The AMT error message found in the response body, if any
+	Message string
+}
+
+func (err *HTTPError) Error() string {
+	if err.Code != "" {
+		return fmt.Sprintf("Request failed with HTTP status %d: %s (%s: %s)",
+			err.StatusCode, err.Status, err.Code, err.Message)
+	}
+	return fmt.Sprintf("Request failed with HTTP status %d: %s",
+		err.StatusCode, err.Status)
+}
+
+// Find the first <Error><Code> and <Message> pair in an error response body.
+// AMT does not namespace these elements consistently, so only local names are
+// matched.
+func findErrorCode(body []byte) (code, message string) {
+	var (
+		dec     = xml.NewDecoder(bytes.NewReader(body))
+		inError bool
+	)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return code, message
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "Error":
+				inError = true
+			case "Code":
+				if inError && code == "" {
+					var s string
+					if dec.DecodeElement(&s, &t) == nil {
+						code = s
+					}
+				}
+			case "Message":
+				if inError && message == "" {
+					var s string
+					if dec.DecodeElement(&s, &t) == nil {
+						message = s
+					}
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "Error" && code != "" {
+				return code, message
+			}
+		}
+	}
+}
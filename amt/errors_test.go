@@ -0,0 +1,76 @@
+package amt
+
+import (
+	"errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	client := newTestClient()
+	defer closeSrv()
+	Convey("Given an initialized client", t, func() {
+		Convey("When AMT reports an error for a result", func() {
+			srvResponse = `<?xml version="1.0"?>` + "\n" +
+				`<GetHITResponse>` +
+				`<OperationRequest><RequestId>` + REQUEST_ID + `</RequestId></OperationRequest>` +
+				`<HIT><Request><IsValid>False</IsValid><Errors><Error>` +
+				`<Code>AWS.MechanicalTurk.HITDoesNotExist</Code>` +
+				`<Message>Hit FakeHit does not exist.</Message>` +
+				`<Data><Key>HITId</Key><Value>FakeHit</Value></Data>` +
+				`</Error></Errors></Request></HIT>` +
+				`</GetHITResponse>`
+			result, err := client.GetHIT(HIT_ID)
+
+			Convey("Then an APIError is returned", func() {
+				var apiErr *APIError
+				So(errors.As(err, &apiErr), ShouldBeTrue)
+				So(apiErr, ShouldResemble, &APIError{
+					Operation: "GetHIT",
+					Code:      CODE_HIT_NOT_FOUND,
+					Message:   "Hit FakeHit does not exist.",
+					Data:      map[string]string{"HITId": HIT_ID},
+					RequestId: REQUEST_ID,
+				})
+			})
+			Convey("Then the error matches its sentinel", func() {
+				So(errors.Is(err, ErrHITNotFound), ShouldBeTrue)
+				So(errors.Is(err, ErrInsufficientFunds), ShouldBeFalse)
+				So(IsThrottled(err), ShouldBeFalse)
+			})
+			Convey("Then the decoded response is still returned", func() {
+				So(result.Hits, ShouldHaveLength, 1)
+				So(string(result.Hits[0].Request.IsValid), ShouldEqual, "False")
+			})
+		})
+
+		Convey("When AMT reports an error for the operation", func() {
+			srvResponse = `<?xml version="1.0"?>` + "\n" +
+				`<CreateHITResponse>` +
+				`<OperationRequest><RequestId>` + REQUEST_ID + `</RequestId>` +
+				`<Errors><Error>` +
+				`<Code>AWS.MechanicalTurk.InsufficientFunds</Code>` +
+				`<Message>You do not have enough funds.</Message>` +
+				`</Error></Errors></OperationRequest>` +
+				`</CreateHITResponse>`
+			_, err := client.CreateHITFromHITTypeId(HIT_TYPE_ID, "", "", nil,
+				60, 1, nil, nil, "", "")
+
+			Convey("Then an APIError is returned", func() {
+				So(errors.Is(err, ErrInsufficientFunds), ShouldBeTrue)
+				So(err.Error(), ShouldEqual, "CreateHIT failed with AMT error "+
+					"AWS.MechanicalTurk.InsufficientFunds: You do not have enough funds.")
+			})
+		})
+	})
+
+	Convey("Given a throttling error", t, func() {
+		err := error(&APIError{Operation: "GetHIT", Code: CODE_SERVICE_UNAVAILABLE})
+
+		Convey("Then it is recognized as throttling", func() {
+			So(IsThrottled(err), ShouldBeTrue)
+			So(IsRetryable(err), ShouldBeTrue)
+			So(errors.Is(err, ErrThrottled), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,270 @@
+// Package external serves the task pages behind ExternalQuestion HITs. AMT
+// shows workers an ExternalQuestion's URL in a frame, adding the assignmentId,
+// hitId, workerId and turkSubmitTo parameters to it. A Handler renders a task
+// from a template for each such request, stores the form the worker submits,
+// and passes the submission on to AMT so that the assignment is completed.
+//
+// A Handler can be served by httptest.NewServer, so task pages can be tested
+// without AMT:
+//
+//	handler, err := external.NewHandler(`Is item {{.Params.Get "item"}} a cat? <input name="cat"/>`,
+//		external.NewMemoryStore())
+//	srv := httptest.NewServer(handler)
+//	defer srv.Close()
+//	resp, err := http.Get(srv.URL + "?item=7&assignmentId=A1&hitId=H1&workerId=W1&turkSubmitTo=https://workersandbox.mturk.com")
+package external
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/jesand/crowds/amt"
+	xsdt "github.com/metaleap/go-xsd/types"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// The assignmentId AMT sends while a worker previews a HIT before
+	// accepting it
+	PREVIEW_ASSIGNMENT_ID = amt.PREVIEW_ASSIGNMENT_ID
+
+	// The path, under turkSubmitTo, to which a completed task is posted
+	SUBMIT_PATH = "/mturk/externalSubmit"
+)
+
+var (
+	// The parameters AMT adds to an ExternalQuestion's URL
+	turkParams = []string{"assignmentId", "hitId", "workerId", "turkSubmitTo"}
+)
+
+// The page a task is placed in. The form posts back to the handler at the
+// same URL, so the parameters AMT added are kept.
+const taskPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta http-equiv="Content-Type" content="text/html; charset=UTF-8"/>
+</head>
+<body>
+<form name="mturk_form" method="post" id="mturk_form" action="">
+<input type="hidden" name="assignmentId" id="assignmentId" value="{{.AssignmentId}}"/>
+{{template "task" .}}
+{{if .Preview}}<p><input type="submit" id="submitButton" value="Accept the HIT to submit" disabled/></p>
+{{else}}<p><input type="submit" id="submitButton" value="Submit"/></p>
+{{end}}</form>
+</body>
+</html>
+`
+
+// The page which passes a stored submission on to AMT.
+const submitPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta http-equiv="Content-Type" content="text/html; charset=UTF-8"/>
+</head>
+<body onload="document.forms[0].submit()">
+<form method="post" action="{{.URL}}">
+{{range $name, $values := .Form}}{{range $values}}<input type="hidden" name="{{$name}}" value="{{.}}"/>
+{{end}}{{end}}<noscript><input type="submit" value="Submit"/></noscript>
+</form>
+</body>
+</html>
+`
+
+var submitTemplate = template.Must(template.New("submit").Parse(submitPage))
+
+// Task describes a request for a task page: the parameters AMT added to the
+// ExternalQuestion's URL, and any it already had.
+type Task struct {
+	AssignmentId string
+	HITId        string
+	WorkerId     string
+
+	// Where AMT expects the completed task, e.g.
+	// "https://workersandbox.mturk.com"
+	TurkSubmitTo string
+
+	// Whether the worker is previewing the HIT, and cannot yet submit it
+	Preview bool
+
+	// The other parameters of the URL, e.g. to identify the item to show
+	Params url.Values
+}
+
+// SubmitURL returns the URL a completed task is posted to.
+func (task Task) SubmitURL() string {
+	return strings.TrimRight(task.TurkSubmitTo, "/") + SUBMIT_PATH
+}
+
+// Read the task a request is for.
+func taskFromRequest(r *http.Request) Task {
+	query := r.URL.Query()
+	task := Task{
+		AssignmentId: query.Get("assignmentId"),
+		HITId:        query.Get("hitId"),
+		WorkerId:     query.Get("workerId"),
+		TurkSubmitTo: query.Get("turkSubmitTo"),
+		Params:       url.Values{},
+	}
+	task.Preview = task.AssignmentId == PREVIEW_ASSIGNMENT_ID
+	for name, values := range query {
+		if !isTurkParam(name) {
+			task.Params[name] = values
+		}
+	}
+	return task
+}
+
+// Whether a parameter is one AMT adds to an ExternalQuestion's URL.
+func isTurkParam(name string) bool {
+	for _, param := range turkParams {
+		if name == param {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler serves an ExternalQuestion's task pages. A GET renders the task
+// from its template, and a POST stores the worker's answers and then sends
+// them on to AMT.
+type Handler struct {
+
+	// The page for a task, executed with the Task
+	Template *template.Template
+
+	// Where submissions are kept
+	Store Store
+
+	// The hosts to which submissions may be sent, to keep the handler from
+	// posting answers to any site named by turkSubmitTo
+	SubmitHosts []string
+
+	// Returns the current time, for the submission records
+	Now func() time.Time
+}
+
+// NewHandler creates a handler which renders tasks from an html/template and
+// keeps submissions in a store. The template supplies only the task itself:
+// it is placed in a form which posts back to the handler, and which ends
+// with a Submit button that is disabled while the worker previews the HIT.
+// The template is executed with the Task, so may show a different item for
+// each HIT using the parameters of the URL.
+func NewHandler(tmpl string, store Store) (*Handler, error) {
+	page, err := template.New("page").Parse(taskPage)
+	if err == nil {
+		_, err = page.New("task").Parse(tmpl)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse task template: %v", err)
+	}
+	return &Handler{
+		Template:    page,
+		Store:       store,
+		SubmitHosts: []string{"www.mturk.com", "workersandbox.mturk.com"},
+		Now:         time.Now,
+	}, nil
+}
+
+// ServeHTTP renders a task page, or stores a submission.
+func (handler *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	task := taskFromRequest(r)
+	if task.AssignmentId == "" {
+		http.Error(w, "The assignmentId parameter is required", http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		handler.render(w, task)
+	case http.MethodPost:
+		handler.submit(w, r, task)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Render a task page.
+func (handler *Handler) render(w http.ResponseWriter, task Task) {
+	var page bytes.Buffer
+	if err := handler.Template.Execute(&page, task); err != nil {
+		http.Error(w, fmt.Sprintf("Could not render task: %v", err),
+			http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	page.WriteTo(w)
+}
+
+// Store a submission and send it on to AMT.
+func (handler *Handler) submit(w http.ResponseWriter, r *http.Request, task Task) {
+	if task.Preview {
+		http.Error(w, "Accept the HIT before submitting it", http.StatusForbidden)
+		return
+	}
+	submitURL, err := url.Parse(task.SubmitURL())
+	if err != nil || !handler.allowedHost(submitURL) {
+		http.Error(w, fmt.Sprintf("Cannot submit to %q", task.TurkSubmitTo),
+			http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	form := url.Values{}
+	for name, values := range r.PostForm {
+		form[name] = values
+	}
+	form.Set("assignmentId", task.AssignmentId)
+
+	submission := Submission{
+		Time:         handler.Now().UTC(),
+		AssignmentId: task.AssignmentId,
+		HITId:        task.HITId,
+		WorkerId:     task.WorkerId,
+		Form:         form,
+	}
+	if err := handler.Store.Save(submission); err != nil {
+		http.Error(w, fmt.Sprintf("Could not save submission: %v", err),
+			http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	submitTemplate.Execute(w, struct {
+		URL  string
+		Form url.Values
+	}{submitURL.String(), form})
+}
+
+// Whether submissions may be sent to a URL.
+func (handler *Handler) allowedHost(submitURL *url.URL) bool {
+	if submitURL.Scheme != "https" && submitURL.Scheme != "http" {
+		return false
+	}
+	for _, host := range handler.SubmitHosts {
+		if submitURL.Host == host {
+			return true
+		}
+	}
+	return false
+}
+
+// NewExternalQuestion creates the ExternalQuestion for a task served at the
+// given URL, shown to workers in a frame of the given height in pixels. AMT
+// requires the URL to use HTTPS. Encode it with amt.EncodeQuestion.
+func NewExternalQuestion(taskURL string, frameHeight int) (*amt.ExternalQuestion, error) {
+	parsed, err := url.Parse(taskURL)
+	if err != nil {
+		return nil, err
+	} else if parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("The task URL must be an absolute HTTPS URL, not %q",
+			taskURL)
+	}
+	question := &amt.ExternalQuestion{}
+	question.ExternalURL = xsdt.AnyURI(taskURL)
+	question.FrameHeight = xsdt.Integer(frameHeight)
+	return question, nil
+}
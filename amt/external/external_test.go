@@ -0,0 +1,163 @@
+package external
+
+import (
+	"bytes"
+	"github.com/jesand/crowds/amt"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const TASK = `<p>Is item {{.Params.Get "item"}} a cat?</p><input name="cat"/>`
+
+// Fetch a page and return its status and body.
+func fetch(resp *http.Response, err error) (int, string) {
+	So(err, ShouldBeNil)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	So(err, ShouldBeNil)
+	return resp.StatusCode, string(body)
+}
+
+func TestHandler(t *testing.T) {
+	Convey("Given a task server", t, func() {
+		store := NewMemoryStore()
+		handler, err := NewHandler(TASK, store)
+		So(err, ShouldBeNil)
+		handler.Now = func() time.Time {
+			return time.Date(2015, 1, 2, 3, 4, 5, 0, time.UTC)
+		}
+		srv := httptest.NewServer(handler)
+		defer srv.Close()
+		params := "?item=7&hitId=H1&workerId=W1&turkSubmitTo=" +
+			url.QueryEscape("https://workersandbox.mturk.com")
+
+		Convey("When a worker previews the HIT", func() {
+			status, body := fetch(http.Get(srv.URL + params + "&assignmentId=" + PREVIEW_ASSIGNMENT_ID))
+
+			Convey("Then the task is shown but cannot be submitted", func() {
+				So(status, ShouldEqual, http.StatusOK)
+				So(body, ShouldContainSubstring, "<p>Is item 7 a cat?</p>")
+				So(body, ShouldContainSubstring, `value="Accept the HIT to submit" disabled`)
+			})
+
+			Convey("And submits it anyway", func() {
+				status, _ := fetch(http.PostForm(srv.URL+params+"&assignmentId="+PREVIEW_ASSIGNMENT_ID,
+					url.Values{"cat": {"yes"}}))
+
+				Convey("Then it is refused", func() {
+					So(status, ShouldEqual, http.StatusForbidden)
+					So(store.Submissions(), ShouldBeEmpty)
+				})
+			})
+		})
+
+		Convey("When a worker views an accepted HIT", func() {
+			status, body := fetch(http.Get(srv.URL + params + "&assignmentId=A1"))
+
+			Convey("Then the task can be submitted", func() {
+				So(status, ShouldEqual, http.StatusOK)
+				So(body, ShouldContainSubstring, `<input type="hidden" name="assignmentId" id="assignmentId" value="A1"/>`)
+				So(body, ShouldContainSubstring, `<input type="submit" id="submitButton" value="Submit"/>`)
+			})
+		})
+
+		Convey("When a worker submits the task", func() {
+			status, body := fetch(http.PostForm(srv.URL+params+"&assignmentId=A1",
+				url.Values{"cat": {"yes"}, "assignmentId": {"A1"}}))
+
+			Convey("Then the submission is stored and passed on to AMT", func() {
+				So(status, ShouldEqual, http.StatusOK)
+				So(store.Submissions(), ShouldResemble, []Submission{{
+					Time:         time.Date(2015, 1, 2, 3, 4, 5, 0, time.UTC),
+					AssignmentId: "A1",
+					HITId:        "H1",
+					WorkerId:     "W1",
+					Form:         url.Values{"cat": {"yes"}, "assignmentId": {"A1"}},
+				}})
+				So(body, ShouldContainSubstring, `action="https://workersandbox.mturk.com/mturk/externalSubmit"`)
+				So(body, ShouldContainSubstring, `<input type="hidden" name="cat" value="yes"/>`)
+				So(body, ShouldContainSubstring, `<input type="hidden" name="assignmentId" value="A1"/>`)
+			})
+		})
+
+		Convey("When a submission names an unknown submit host", func() {
+			status, _ := fetch(http.PostForm(srv.URL+"?assignmentId=A1&turkSubmitTo="+
+				url.QueryEscape("https://example.com"), url.Values{"cat": {"yes"}}))
+
+			Convey("Then it is refused", func() {
+				So(status, ShouldEqual, http.StatusBadRequest)
+				So(store.Submissions(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a request has no assignmentId", func() {
+			status, _ := fetch(http.Get(srv.URL + "?item=7"))
+
+			Convey("Then it is refused", func() {
+				So(status, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+
+	Convey("Given a malformed task template", t, func() {
+		_, err := NewHandler(`{{.Params`, NewMemoryStore())
+
+		Convey("Then no handler is created", func() {
+			So(err.Error(), ShouldStartWith, "Could not parse task template")
+		})
+	})
+}
+
+func TestJSONStore(t *testing.T) {
+	Convey("Given a JSON store", t, func() {
+		var sink bytes.Buffer
+		store := NewJSONStore(&sink)
+
+		Convey("When I save a submission", func() {
+			err := store.Save(Submission{
+				Time:         time.Date(2015, 1, 2, 3, 4, 5, 0, time.UTC),
+				AssignmentId: "A1",
+				Form:         url.Values{"cat": {"yes"}},
+			})
+
+			Convey("Then it is written as a line of JSON", func() {
+				So(err, ShouldBeNil)
+				So(sink.String(), ShouldEqual, `{"time":"2015-01-02T03:04:05Z","assignmentId":"A1",`+
+					`"hitId":"","workerId":"","form":{"cat":["yes"]}}`+"\n")
+			})
+		})
+	})
+}
+
+func TestNewExternalQuestion(t *testing.T) {
+	Convey("Given the URL of a task server", t, func() {
+		Convey("When I create an ExternalQuestion for it", func() {
+			question, err := NewExternalQuestion("https://tasks.example.com/cats?item=7", 500)
+			So(err, ShouldBeNil)
+			encoded, err := amt.EncodeQuestion(question)
+
+			Convey("Then I get valid ExternalQuestion XML", func() {
+				So(err, ShouldBeNil)
+				So(string(encoded), ShouldEqual,
+					`<ExternalQuestion xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2006-07-14/ExternalQuestion.xsd">`+
+						`<ExternalURL>https://tasks.example.com/cats?item=7</ExternalURL>`+
+						`<FrameHeight>500</FrameHeight>`+
+						`</ExternalQuestion>`)
+				So(amt.ValidateQuestion(encoded), ShouldBeNil)
+			})
+		})
+
+		Convey("When the URL is not HTTPS", func() {
+			_, err := NewExternalQuestion("http://tasks.example.com/cats", 500)
+
+			Convey("Then it is refused", func() {
+				So(err.Error(), ShouldContainSubstring, "must be an absolute HTTPS URL")
+			})
+		})
+	})
+}
@@ -0,0 +1,79 @@
+package external
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Submission is the form a worker submitted for an assignment.
+type Submission struct {
+
+	// When the form was submitted
+	Time time.Time `json:"time"`
+
+	// The assignment submitted, and the HIT and worker it belongs to
+	AssignmentId string `json:"assignmentId"`
+	HITId        string `json:"hitId"`
+	WorkerId     string `json:"workerId"`
+
+	// The form's fields
+	Form url.Values `json:"form"`
+}
+
+// Store keeps the submissions received by a Handler. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	Save(submission Submission) error
+}
+
+// MemoryStore is a Store which keeps submissions in memory, e.g. for tests.
+type MemoryStore struct {
+	mu          sync.Mutex
+	submissions []Submission
+}
+
+// NewMemoryStore creates an empty store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (store *MemoryStore) Save(submission Submission) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.submissions = append(store.submissions, submission)
+	return nil
+}
+
+// Submissions returns the submissions saved so far, in order.
+func (store *MemoryStore) Submissions() []Submission {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return append([]Submission{}, store.submissions...)
+}
+
+// JSONStore is a Store which writes each submission to a sink as a line of
+// JSON.
+type JSONStore struct {
+	mu   sync.Mutex
+	sink io.Writer
+}
+
+// NewJSONStore creates a store which writes to the given sink, e.g. a file
+// opened for appending.
+func NewJSONStore(sink io.Writer) *JSONStore {
+	return &JSONStore{sink: sink}
+}
+
+func (store *JSONStore) Save(submission Submission) error {
+	line, err := json.Marshal(submission)
+	if err != nil {
+		return err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	_, err = store.sink.Write(append(line, '\n'))
+	return err
+}
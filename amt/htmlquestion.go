@@ -0,0 +1,167 @@
+package amt
+
+import (
+	"bytes"
+	"fmt"
+	xsdt "github.com/metaleap/go-xsd/types"
+	"html/template"
+	"strings"
+)
+
+// The page NewHTMLQuestion places a task in: the standard MTurk form, with
+// the hidden assignmentId field filled in by externalHIT_v1.js
+const htmlQuestionPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta http-equiv="Content-Type" content="text/html; charset=UTF-8"/>
+<script type="text/javascript" src="https://s3.amazonaws.com/mturk-public/externalHIT_v1.js"></script>
+</head>
+<body>
+<form name="mturk_form" method="post" id="mturk_form" action="{{submitURL}}">
+<input type="hidden" value="" name="assignmentId" id="assignmentId"/>
+{{template "task" .}}
+<p><input type="submit" id="submitButton" value="Submit"/></p>
+</form>
+<script language="Javascript">turkSetAssignmentID();</script>
+</body>
+</html>
+`
+
+// HTMLQuestionOption customizes a question built by NewHTMLQuestion.
+type HTMLQuestionOption func(*htmlQuestionOptions)
+
+// The settings collected from a question's options
+type htmlQuestionOptions struct {
+	submitURL   string
+	frameHeight int
+	missingKey  string
+}
+
+// WithSandboxSubmit submits the form to the worker sandbox rather than to
+// production.
+func WithSandboxSubmit() HTMLQuestionOption {
+	return func(options *htmlQuestionOptions) {
+		options.submitURL = SUBMIT_URL_SANDBOX
+	}
+}
+
+// WithSubmitURL submits the form to the given URL, e.g. a local server in
+// tests.
+func WithSubmitURL(submitURL string) HTMLQuestionOption {
+	return func(options *htmlQuestionOptions) {
+		options.submitURL = submitURL
+	}
+}
+
+// WithFrameHeight sets the height, in pixels, of the frame workers see the
+// question in, in place of DEFAULT_FRAME_HEIGHT.
+func WithFrameHeight(height int) HTMLQuestionOption {
+	return func(options *htmlQuestionOptions) {
+		options.frameHeight = height
+	}
+}
+
+// WithMissingKeyError fails to build a question whose template reads a key
+// its data map lacks, rather than writing nothing for it.
+func WithMissingKeyError() HTMLQuestionOption {
+	return func(options *htmlQuestionOptions) {
+		options.missingKey = "error"
+	}
+}
+
+// NewHTMLQuestion builds an HTMLQuestion by executing an html/template with
+// the given data. The template supplies only the task itself: it is placed in
+// the standard MTurk form, which posts to the production submit URL unless
+// WithSandboxSubmit or WithSubmitURL is given, and ends with a Submit button.
+//
+// Besides the usual template functions, the task may use these helpers to
+// write form inputs, each of which is submitted under the given name:
+//
+//	{{textInput "name"}}
+//	{{textArea "name" rows}}
+//	{{radioButtons "name" "option" ...}}
+//	{{checkboxes "name" "option" ...}}
+//	{{selectList "name" "option" ...}}
+//	{{hiddenInput "name" "value"}}
+//
+// The result can be passed straight to EncodeQuestion.
+func NewHTMLQuestion(tmpl string, data interface{},
+	options ...HTMLQuestionOption) (*HTMLQuestion, error) {
+
+	settings := htmlQuestionOptions{
+		submitURL:   SUBMIT_URL_PROD,
+		frameHeight: DEFAULT_FRAME_HEIGHT,
+		missingKey:  "default",
+	}
+	for _, option := range options {
+		option(&settings)
+	}
+	funcs := htmlQuestionFuncs()
+	funcs["submitURL"] = func() string {
+		return settings.submitURL
+	}
+	page, err := template.New("page").Funcs(funcs).
+		Option("missingkey=" + settings.missingKey).Parse(htmlQuestionPage)
+	if err == nil {
+		_, err = page.New("task").Parse(tmpl)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse HTML question template: %v", err)
+	}
+	var content bytes.Buffer
+	if err := page.Execute(&content, data); err != nil {
+		return nil, fmt.Errorf("Could not execute HTML question template: %v", err)
+	}
+
+	question := &HTMLQuestion{}
+	question.HTMLContent = xsdt.String(content.String())
+	question.FrameHeight = xsdt.Integer(settings.frameHeight)
+	return question, nil
+}
+
+// The form input helpers available to HTMLQuestion templates
+func htmlQuestionFuncs() template.FuncMap {
+	return template.FuncMap{
+		"textInput": func(name string) template.HTML {
+			return template.HTML(fmt.Sprintf(`<input type="text" name="%s" id="%s"/>`,
+				template.HTMLEscapeString(name), template.HTMLEscapeString(name)))
+		},
+		"textArea": func(name string, rows int) template.HTML {
+			return template.HTML(fmt.Sprintf(`<textarea name="%s" id="%s" rows="%d" cols="80"></textarea>`,
+				template.HTMLEscapeString(name), template.HTMLEscapeString(name), rows))
+		},
+		"radioButtons": func(name string, options ...string) template.HTML {
+			return inputList("radio", name, options)
+		},
+		"checkboxes": func(name string, options ...string) template.HTML {
+			return inputList("checkbox", name, options)
+		},
+		"selectList": func(name string, options ...string) template.HTML {
+			var html strings.Builder
+			fmt.Fprintf(&html, `<select name="%s" id="%s">`, template.HTMLEscapeString(name),
+				template.HTMLEscapeString(name))
+			for _, option := range options {
+				fmt.Fprintf(&html, `<option value="%s">%s</option>`,
+					template.HTMLEscapeString(option), template.HTMLEscapeString(option))
+			}
+			html.WriteString(`</select>`)
+			return template.HTML(html.String())
+		},
+		"hiddenInput": func(name, value string) template.HTML {
+			return template.HTML(fmt.Sprintf(`<input type="hidden" name="%s" value="%s"/>`,
+				template.HTMLEscapeString(name), template.HTMLEscapeString(value)))
+		},
+	}
+}
+
+// Write a labelled radio button or checkbox for each option.
+func inputList(kind, name string, options []string) template.HTML {
+	var html strings.Builder
+	for i, option := range options {
+		id := fmt.Sprintf("%s_%d", name, i)
+		fmt.Fprintf(&html, `<label for="%s"><input type="%s" name="%s" id="%s" value="%s"/> %s</label><br/>`,
+			template.HTMLEscapeString(id), kind, template.HTMLEscapeString(name), template.HTMLEscapeString(id),
+			template.HTMLEscapeString(option), template.HTMLEscapeString(option))
+	}
+	return template.HTML(html.String())
+}
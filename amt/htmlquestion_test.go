@@ -0,0 +1,72 @@
+package amt
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestNewHTMLQuestion(t *testing.T) {
+	Convey("Given a task template", t, func() {
+		tmpl := `<p>Is <img src="{{.Image}}"/> a {{.Animal}}?</p>{{radioButtons "answer" "yes" "no"}}{{textArea "comment" 3}}`
+		data := map[string]string{"Image": "http://example.com/1.png", "Animal": "<cat>"}
+
+		Convey("When I build a question from it", func() {
+			question, err := NewHTMLQuestion(tmpl, data)
+
+			Convey("Then the task is placed in the production form", func() {
+				So(err, ShouldBeNil)
+				html := string(question.HTMLContent)
+				So(html, ShouldStartWith, "<!DOCTYPE html>")
+				So(html, ShouldContainSubstring, `action="https://www.mturk.com/mturk/externalSubmit"`)
+				So(html, ShouldContainSubstring, `<input type="hidden" value="" name="assignmentId" id="assignmentId"/>`)
+				So(html, ShouldContainSubstring, `<p>Is <img src="http://example.com/1.png"/> a &lt;cat&gt;?</p>`)
+				So(html, ShouldContainSubstring, `<label for="answer_1"><input type="radio" name="answer" id="answer_1" value="no"/> no</label>`)
+				So(html, ShouldContainSubstring, `<textarea name="comment" id="comment" rows="3" cols="80"></textarea>`)
+				So(html, ShouldContainSubstring, `turkSetAssignmentID();`)
+				So(int(question.FrameHeight), ShouldEqual, DEFAULT_FRAME_HEIGHT)
+			})
+
+			Convey("Then it encodes to valid XML", func() {
+				encoded, err := EncodeQuestion(question)
+				So(err, ShouldBeNil)
+				So(ValidateQuestion(encoded), ShouldBeNil)
+				decoded, err := DecodeQuestion(encoded)
+				So(err, ShouldBeNil)
+				So(decoded.(*HTMLQuestion).HTMLContent, ShouldEqual, question.HTMLContent)
+			})
+		})
+
+		Convey("When I build a question for the sandbox", func() {
+			question, err := NewHTMLQuestion(`{{selectList "size" "S" "M"}}{{hiddenInput "row" "7"}}`,
+				nil, WithSandboxSubmit(), WithFrameHeight(600))
+
+			Convey("Then it submits to the sandbox", func() {
+				So(err, ShouldBeNil)
+				html := string(question.HTMLContent)
+				So(html, ShouldContainSubstring, `action="https://workersandbox.mturk.com/mturk/externalSubmit"`)
+				So(html, ShouldContainSubstring, `<select name="size" id="size"><option value="S">S</option><option value="M">M</option></select>`)
+				So(html, ShouldContainSubstring, `<input type="hidden" name="row" value="7"/>`)
+				So(int(question.FrameHeight), ShouldEqual, 600)
+			})
+		})
+
+		Convey("When the template is malformed", func() {
+			_, err := NewHTMLQuestion(`{{.Missing`, nil)
+
+			Convey("Then it fails", func() {
+				So(err.Error(), ShouldStartWith, "Could not parse HTML question template")
+			})
+		})
+
+		Convey("When the data lacks a key the template reads", func() {
+			delete(data, "Animal")
+			_, lenient := NewHTMLQuestion(tmpl, data)
+			_, strict := NewHTMLQuestion(tmpl, data, WithMissingKeyError())
+
+			Convey("Then it fails only if I ask it to", func() {
+				So(lenient, ShouldBeNil)
+				So(strict.Error(), ShouldContainSubstring, `map has no entry for key "Animal"`)
+			})
+		})
+	})
+}
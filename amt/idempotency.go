@@ -0,0 +1,388 @@
+package amt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	xsdt "github.com/metaleap/go-xsd/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TokenRecord is what a TokenStore remembers about a request made by an
+// IdempotentClient.
+type TokenRecord struct {
+
+	// The operation, e.g. "CreateHIT"
+	Operation string
+
+	// The UniqueRequestToken sent with the request
+	Token string
+
+	// The ID of the object the request created or changed once it is known
+	// to have succeeded: the HITId for CreateHIT and ExtendHIT, or the
+	// AssignmentId for GrantBonus. Empty if the outcome is unknown.
+	ResultId string `json:",omitempty"`
+
+	// When the token was first used
+	Created time.Time
+}
+
+// TokenStore persists the tokens used by an IdempotentClient, keyed by
+// operation and caller key. Implementations must be safe for concurrent use.
+type TokenStore interface {
+
+	// Load returns the record for a key, and whether there is one.
+	Load(key string) (TokenRecord, bool, error)
+
+	// Save creates or replaces the record for a key.
+	Save(key string, record TokenRecord) error
+}
+
+// memoryTokenStore is a TokenStore which is lost when the process exits
+type memoryTokenStore struct {
+	mu      sync.Mutex
+	records map[string]TokenRecord
+}
+
+// NewMemoryTokenStore creates an empty TokenStore held in memory, which
+// protects against retries within a process but not across crashes.
+func NewMemoryTokenStore() TokenStore {
+	return &memoryTokenStore{records: make(map[string]TokenRecord)}
+}
+
+func (store *memoryTokenStore) Load(key string) (TokenRecord, bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	record, ok := store.records[key]
+	return record, ok, nil
+}
+
+func (store *memoryTokenStore) Save(key string, record TokenRecord) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.records[key] = record
+	return nil
+}
+
+// fileTokenStore is a TokenStore kept in a JSON file
+type fileTokenStore struct {
+	memoryTokenStore
+	path string
+}
+
+// OpenFileTokenStore opens a TokenStore kept in a JSON file, creating the
+// file on the first Save if it does not exist. The whole file is rewritten
+// on each Save, via a temporary file so that a crash never leaves it
+// half-written, so it suits the modest number of requests a requester makes.
+func OpenFileTokenStore(path string) (TokenStore, error) {
+	store := &fileTokenStore{
+		memoryTokenStore: memoryTokenStore{records: make(map[string]TokenRecord)},
+		path:             path,
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &store.records); err != nil {
+		return nil, fmt.Errorf("Could not parse tokens in %s: %v", path, err)
+	}
+	return store, nil
+}
+
+func (store *fileTokenStore) Save(key string, record TokenRecord) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.records[key] = record
+	data, err := json.MarshalIndent(store.records, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(store.path),
+		filepath.Base(store.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), store.path)
+}
+
+// IdempotentClient makes CreateHIT, ExtendHIT and GrantBonus safe to retry,
+// even after a crash. Rather than a UniqueRequestToken, each call takes a key
+// chosen by the caller to identify the logical request, such as a row number
+// in a batch of HITs or "bonus:<assignment id>". The client derives a stable
+// token from the key and remembers it, and the request's outcome, in its
+// TokenStore:
+//
+//   - A request whose outcome is recorded is not sent again; the original
+//     HIT or bonus is returned instead, however long ago it was made.
+//   - A request which AMT rejects as a duplicate of an earlier one with the
+//     same token is treated as a success, and the original HIT or bonus is
+//     returned.
+//
+// AMT only recognizes a token for 24 hours. If a process crashes after AMT
+// accepts a request but before its outcome is recorded, repeating the call
+// with the same key within 24 hours returns the original HIT or bonus, but
+// repeating it later posts the HIT or pays the bonus a second time.
+type IdempotentClient struct {
+	Client AmtClient
+	Store  TokenStore
+}
+
+// NewIdempotentClient wraps a client, recording its tokens in the given
+// store.
+func NewIdempotentClient(client AmtClient, store TokenStore) *IdempotentClient {
+	return &IdempotentClient{Client: client, Store: store}
+}
+
+// RequestToken derives the UniqueRequestToken used for an operation and
+// caller key: 64 hex digits, the longest token AMT accepts.
+func RequestToken(operation, key string) string {
+	sum := sha256.Sum256([]byte(operation + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Look up or create the record for a request, saving it before the request
+// is sent.
+func (client *IdempotentClient) begin(operation, key string) (
+	string, TokenRecord, error) {
+
+	storeKey := operation + ":" + key
+	record, ok, err := client.Store.Load(storeKey)
+	if err != nil {
+		return storeKey, record, err
+	} else if ok {
+		return storeKey, record, nil
+	}
+	record = TokenRecord{
+		Operation: operation,
+		Token:     RequestToken(operation, key),
+		Created:   time.Now().UTC(),
+	}
+	return storeKey, record, client.Store.Save(storeKey, record)
+}
+
+// Record the result of a request which succeeded.
+func (client *IdempotentClient) finish(storeKey string, record TokenRecord,
+	resultId string) error {
+
+	record.ResultId = resultId
+	return client.Store.Save(storeKey, record)
+}
+
+// Report whether an error rejects a request as a duplicate, and if so return
+// the ID AMT gave for the original object under dataKey, or fallback if it
+// gave none.
+func duplicateResultId(err error, dataKey, fallback string) (string, bool) {
+	var apiErr *APIError
+	if !errors.Is(err, ErrDuplicateRequest) || !errors.As(err, &apiErr) {
+		return "", false
+	} else if id := apiErr.Data[dataKey]; id != "" {
+		return id, true
+	}
+	return fallback, true
+}
+
+// Create a HIT of a HIT type with a UniqueRequestToken, and return its
+// HITId. If AMT rejects the token as a duplicate because it already created
+// a HIT for it, within the last 24 hours, that HIT's ID is returned instead.
+func createHITOnce(client AmtClient, hitTypeId, question, hitLayoutId string,
+	hitLayoutParameters map[string]string, lifetimeInSeconds,
+	maxAssignments int, token string) (string, error) {
+
+	resp, err := client.CreateHITFromHITTypeId(hitTypeId, question, hitLayoutId,
+		hitLayoutParameters, lifetimeInSeconds, maxAssignments, nil, nil, "",
+		token)
+	if err == nil && len(resp.Hits) > 0 {
+		return string(resp.Hits[0].HITId), nil
+	} else if err == nil {
+		return "", fmt.Errorf("AMT returned no HIT")
+	} else if id, ok := duplicateResultId(err, "HITId", ""); ok && id != "" {
+		return id, nil
+	}
+	return "", err
+}
+
+// Build a CreateHIT response for an existing HIT.
+func (client *IdempotentClient) existingHIT(hitId string) (
+	amtgen.TxsdCreateHITResponse, error) {
+
+	var response amtgen.TxsdCreateHITResponse
+	hit, err := client.Client.GetHIT(hitId)
+	if err != nil {
+		return response, err
+	}
+	response.OperationRequest = hit.OperationRequest
+	response.Hits = hit.Hits
+	return response, nil
+}
+
+// CreateHIT is like AmtClient.CreateHIT, but takes a caller key in place of
+// a uniqueRequestToken. If a HIT was already created for the key, it is
+// returned as if it had just been created.
+func (client *IdempotentClient) CreateHIT(key string, title, description,
+	question string, hitLayoutId string, hitLayoutParameters map[string]string,
+	reward float32, assignmentDurationInSeconds, lifetimeInSeconds,
+	maxAssignments, autoApprovalDelayInSeconds int, keywords []string,
+	qualificationRequirements []*amtgen.TQualificationRequirement,
+	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
+	requesterAnnotation string) (amtgen.TxsdCreateHITResponse, error) {
+
+	const op = "CreateHIT"
+	storeKey, record, err := client.begin(op, key)
+	if err != nil {
+		return amtgen.TxsdCreateHITResponse{}, err
+	} else if record.ResultId != "" {
+		return client.existingHIT(record.ResultId)
+	}
+	response, err := client.Client.CreateHIT(title, description, question,
+		hitLayoutId, hitLayoutParameters, reward, assignmentDurationInSeconds,
+		lifetimeInSeconds, maxAssignments, autoApprovalDelayInSeconds,
+		keywords, qualificationRequirements, assignmentReviewPolicy,
+		hitReviewPolicy, requesterAnnotation, record.Token)
+	if err == nil && len(response.Hits) > 0 {
+		return response, client.finish(storeKey, record,
+			string(response.Hits[0].HITId))
+	} else if err == nil {
+		return response, nil
+	}
+	hitId, ok := duplicateResultId(err, "HITId", "")
+	if !ok {
+		return response, err
+	} else if hitId == "" {
+		return response, fmt.Errorf("The request was a duplicate, but AMT "+
+			"did not identify the original HIT: %w", err)
+	}
+	if err := client.finish(storeKey, record, hitId); err != nil {
+		return response, err
+	}
+	return client.existingHIT(hitId)
+}
+
+// ExtendHIT is like AmtClient.ExtendHIT, but takes a caller key in place of
+// a uniqueRequestToken. If the HIT was already extended for the key, it is
+// not extended again.
+func (client *IdempotentClient) ExtendHIT(key, hitId string,
+	maxAssignmentsIncrement, expirationIncrementInSeconds int) (
+	amtgen.TxsdExtendHITResponse, error) {
+
+	const op = "ExtendHIT"
+	var response amtgen.TxsdExtendHITResponse
+	storeKey, record, err := client.begin(op, key)
+	if err != nil {
+		return response, err
+	} else if record.ResultId != "" {
+		return extendedHIT(), nil
+	}
+	response, err = client.Client.ExtendHIT(hitId, maxAssignmentsIncrement,
+		expirationIncrementInSeconds, record.Token)
+	if err == nil {
+		return response, client.finish(storeKey, record, hitId)
+	}
+	original, ok := duplicateResultId(err, "HITId", hitId)
+	if !ok {
+		return response, err
+	}
+	return extendedHIT(), client.finish(storeKey, record, original)
+}
+
+// Build the response to an ExtendHIT request which already succeeded.
+func extendedHIT() amtgen.TxsdExtendHITResponse {
+	var response amtgen.TxsdExtendHITResponse
+	result := &amtgen.TExtendHITResult{}
+	result.Request = jsonValidRequest()
+	response.ExtendHITResults = append(response.ExtendHITResults, result)
+	return response
+}
+
+// GrantBonus is like AmtClient.GrantBonus, but takes a caller key in place
+// of a uniqueRequestToken, and returns the bonus payment. If a bonus was
+// already paid for the key, it is not paid again, and the original payment
+// is returned.
+func (client *IdempotentClient) GrantBonus(key, workerId, assignmentId string,
+	bonusAmount float32, reason string) (*amtgen.TBonusPayment, error) {
+
+	const op = "GrantBonus"
+	storeKey, record, err := client.begin(op, key)
+	if err != nil {
+		return nil, err
+	} else if record.ResultId != "" {
+		return client.existingBonus(record.ResultId, workerId, bonusAmount,
+			reason, record.Created), nil
+	}
+	_, err = client.Client.GrantBonus(workerId, assignmentId, bonusAmount,
+		reason, record.Token)
+	if err == nil {
+		return newBonusPayment(workerId, assignmentId, bonusAmount, reason,
+			time.Now()), client.finish(storeKey, record, assignmentId)
+	}
+	original, ok := duplicateResultId(err, "AssignmentId", assignmentId)
+	if !ok {
+		return nil, err
+	}
+	if err := client.finish(storeKey, record, original); err != nil {
+		return nil, err
+	}
+	return client.existingBonus(original, workerId, bonusAmount, reason,
+		record.Created), nil
+}
+
+// Find a bonus paid earlier, falling back to a description of it built from
+// the request if AMT cannot list it.
+func (client *IdempotentClient) existingBonus(assignmentId, workerId string,
+	bonusAmount float32, reason string, created time.Time) *amtgen.TBonusPayment {
+
+	amount := fmt.Sprint(bonusAmount)
+	it := GetAllBonusPayments(client.Client, "", assignmentId, 0)
+	for it.Next() {
+		bonus := it.BonusPayment()
+		if string(bonus.WorkerId) == workerId && string(bonus.Reason) == reason &&
+			bonus.BonusAmount != nil && priceEqual(string(bonus.BonusAmount.Amount), amount) {
+			return bonus
+		}
+	}
+	return newBonusPayment(workerId, assignmentId, bonusAmount, reason, created)
+}
+
+// Report whether two decimal amounts are equal, e.g. "1.5" and "1.50".
+func priceEqual(a, b string) bool {
+	var x, y float64
+	if _, err := fmt.Sscan(a, &x); err != nil {
+		return a == b
+	}
+	if _, err := fmt.Sscan(b, &y); err != nil {
+		return a == b
+	}
+	return x == y
+}
+
+// Describe a bonus payment.
+func newBonusPayment(workerId, assignmentId string, bonusAmount float32,
+	reason string, granted time.Time) *amtgen.TBonusPayment {
+
+	bonus := &amtgen.TBonusPayment{}
+	bonus.WorkerId = xsdt.String(workerId)
+	bonus.AssignmentId = xsdt.String(assignmentId)
+	bonus.BonusAmount = &amtgen.TPrice{}
+	bonus.BonusAmount.Amount = xsdt.Decimal(fmt.Sprint(bonusAmount))
+	bonus.BonusAmount.CurrencyCode = CURRENCY_USD
+	bonus.Reason = xsdt.String(reason)
+	bonus.GrantTime = xsdt.DateTime(FormatTime(granted))
+	return bonus
+}
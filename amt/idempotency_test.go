@@ -0,0 +1,70 @@
+package amt
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRequestToken(t *testing.T) {
+	Convey("Given tokens derived from caller keys", t, func() {
+		token := RequestToken("CreateHIT", "row-1")
+
+		Convey("Then they are stable and fit AMT's limit", func() {
+			So(token, ShouldEqual, RequestToken("CreateHIT", "row-1"))
+			So(len(token), ShouldEqual, 64)
+		})
+
+		Convey("Then they differ by operation and key", func() {
+			So(token, ShouldNotEqual, RequestToken("GrantBonus", "row-1"))
+			So(token, ShouldNotEqual, RequestToken("CreateHIT", "row-2"))
+		})
+	})
+}
+
+func TestFileTokenStore(t *testing.T) {
+	Convey("Given a token store in a new file", t, func() {
+		dir, err := ioutil.TempDir("", "amt-tokens")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "tokens.json")
+		store, err := OpenFileTokenStore(path)
+		So(err, ShouldBeNil)
+
+		Convey("When I save a record and reopen the file", func() {
+			record := TokenRecord{
+				Operation: "CreateHIT",
+				Token:     RequestToken("CreateHIT", "row-1"),
+				ResultId:  "HIT1",
+				Created:   time.Date(2017, 3, 4, 0, 0, 0, 0, time.UTC),
+			}
+			So(store.Save("CreateHIT:row-1", record), ShouldBeNil)
+			reopened, err := OpenFileTokenStore(path)
+			So(err, ShouldBeNil)
+			loaded, ok, err := reopened.Load("CreateHIT:row-1")
+
+			Convey("Then the record is loaded", func() {
+				So(err, ShouldBeNil)
+				So(ok, ShouldBeTrue)
+				So(loaded, ShouldResemble, record)
+			})
+
+			Convey("Then no temporary file is left behind", func() {
+				files, _ := ioutil.ReadDir(dir)
+				So(files, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When I load a missing key", func() {
+			_, ok, err := store.Load("CreateHIT:row-2")
+
+			Convey("Then there is no record", func() {
+				So(err, ShouldBeNil)
+				So(ok, ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -0,0 +1,685 @@
+package amt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	xsdt "github.com/metaleap/go-xsd/types"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// The version of the JSON MTurk API spoken by NewJSONClient
+	JSON_API_VERSION = "2017-01-17"
+
+	// The endpoints of the JSON MTurk API
+	URL_JSON_SANDBOX = "https://mturk-requester-sandbox.us-east-1.amazonaws.com"
+	URL_JSON_PROD    = "https://mturk-requester.us-east-1.amazonaws.com"
+
+	// The AWS region and service name used to sign JSON API requests
+	JSON_REGION  = "us-east-1"
+	JSON_SERVICE = "mturk-requester"
+
+	// The prefix of the X-Amz-Target header naming each operation
+	jsonTargetPrefix = "MTurkRequesterServiceV20170117."
+
+	// The page size used when a paged operation is given none
+	jsonDefaultPageSize = 10
+
+	// The most page tokens a JSON client remembers
+	jsonMaxPageTokens = 1000
+)
+
+var (
+	// ErrUnsupportedOperation is wrapped by the errors returned for
+	// operations which the JSON MTurk API no longer offers.
+	ErrUnsupportedOperation = errors.New("operation not supported by this API version")
+)
+
+// jsonClient implements AmtClient and AmtClientContext on top of the JSON
+// MTurk API, translating each legacy operation into its JSON equivalent.
+type jsonClient struct {
+
+	// The access key for your AWS account
+	AWSAccessKeyId string
+
+	// The secret key (password) for your AWS account
+	SecretKey string
+
+	// The URL to which requests should be sent
+	Endpoint string
+
+	// The AWS region used to sign requests
+	Region string
+
+	// The limiter on the request rate, used to avoid spamming AMT. If nil,
+	// requests are not limited.
+	Limiter Limiter
+
+	// The policy for retrying failed requests. If nil, requests are attempted
+	// only once.
+	Retry *RetryPolicy
+
+	// The HTTP client used to send requests. If nil, http.DefaultClient is
+	// used.
+	HTTPClient *http.Client
+
+	// The log of mutating calls. If nil, calls are not logged.
+	Audit *AuditLog
+
+	// The receiver of measurements of each call. If nil, none are taken.
+	Metrics Metrics
+
+	// The rewards seen in the client's calls, used to cost calls for its
+	// Metrics
+	rewards *rewardBook
+
+	// The NextToken leading to each page requested so far, so that walking a
+	// list page by page does not start over from the first page each time
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// Initialize a new client for the JSON MTurk API (version 2017-01-17). The
+// client implements the same AmtClient interface as NewClient, so switching
+// APIs only requires changing the constructor. Legacy operations with no JSON
+// equivalent fail with an error wrapping ErrUnsupportedOperation. It accepts
+// the same options as NewClient.
+func NewJSONClient(accessKeyId, secretKey string, sandbox bool,
+	opts ...Option) AmtClient {
+	endpoint := URL_JSON_PROD
+	if sandbox {
+		endpoint = URL_JSON_SANDBOX
+	}
+	options := newClientOptions(opts)
+	return &jsonClient{
+		AWSAccessKeyId: accessKeyId,
+		SecretKey:      secretKey,
+		Endpoint:       endpoint,
+		Region:         JSON_REGION,
+		Limiter:        options.limiter,
+		Retry:          options.retry,
+		HTTPClient:     options.client(),
+		Audit:          options.audit,
+		Metrics:        options.metrics,
+		rewards:        newRewardBook(),
+	}
+}
+
+// Initialize a new JSON API client which sends requests to the given URL
+// rather than to AMT.
+func NewJSONClientWithURL(accessKeyId, secretKey, endpoint string,
+	opts ...Option) AmtClient {
+	client := NewJSONClient(accessKeyId, secretKey, false, opts...).(*jsonClient)
+	client.Endpoint = endpoint
+	return client
+}
+
+// jsonArgs holds the input of a JSON API operation.
+type jsonArgs map[string]interface{}
+
+// Set an input field, unless its value is empty.
+func (args jsonArgs) set(name string, value interface{}) {
+	if !isEmptyValue(reflect.ValueOf(value)) {
+		args[name] = value
+	}
+}
+
+// The body of a JSON API error response
+type jsonError struct {
+	Type          string `json:"__type"`
+	Message       string
+	TurkErrorCode string
+}
+
+// Call a JSON API operation, decoding its output into the given struct
+// pointer, and return the RequestId AWS assigned to it. Transient failures
+// are retried according to the client's RetryPolicy.
+func (client *jsonClient) call(ctx context.Context, operation string,
+	input jsonArgs, output interface{}) (string, error) {
+
+	if input == nil {
+		input = jsonArgs{}
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	var (
+		policy    RetryPolicy
+		requestId string
+		attempts  int
+		start     = time.Now()
+	)
+	if client.Retry != nil {
+		_, hasToken := input["UniqueRequestToken"]
+		policy = client.Retry.forRequest(operation, hasToken)
+	}
+	err = policy.do(ctx, func(attempt int) error {
+		var err error
+		attempts = attempt
+		requestId, err = client.callOnce(ctx, operation, body, output)
+		return err
+	})
+	if client.Audit != nil {
+		client.Audit.record(start, operation, client.AWSAccessKeyId, input,
+			requestId, attempts, err)
+	}
+	if client.Metrics != nil {
+		client.Metrics.ObserveCall(callMetrics(start, operation, input, attempts,
+			err, client.rewards))
+		if err == nil {
+			client.rewards.learn(operation, input, output)
+		}
+	}
+	return requestId, err
+}
+
+// Send a single attempt at a JSON API operation.
+func (client *jsonClient) callOnce(ctx context.Context, operation string,
+	body []byte, output interface{}) (string, error) {
+
+	if err := waitLimiter(ctx, client.Limiter, client.Metrics, operation); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", client.Endpoint,
+		bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", jsonTargetPrefix+operation)
+	signV4(req, body, client.AWSAccessKeyId, client.SecretKey, client.Region,
+		JSON_SERVICE, time.Now())
+
+	resp, err := httpClientOrDefault(client.HTTPClient).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	requestId := resp.Header.Get("X-Amzn-RequestId")
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return requestId, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e jsonError
+		json.Unmarshal(respBody, &e)
+		code := e.TurkErrorCode
+		if code == "" {
+			code = e.Type[strings.LastIndex(e.Type, "#")+1:]
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			return requestId, &HTTPError{
+				StatusCode: resp.StatusCode,
+				Status:     resp.Status,
+				Code:       code,
+				Message:    e.Message,
+			}
+		}
+		return requestId, &APIError{
+			Operation: operation,
+			Code:      code,
+			Message:   e.Message,
+			RequestId: requestId,
+		}
+	}
+
+	// Discard anything left by an earlier attempt before decoding
+	out := reflect.ValueOf(output).Elem()
+	out.Set(reflect.Zero(out.Type()))
+	if err := json.Unmarshal(respBody, output); err != nil {
+		return requestId, fmt.Errorf("%s returned an invalid response: %v",
+			operation, err)
+	}
+	return requestId, nil
+}
+
+// The paging fields of a JSON API list operation's output
+type jsonPage struct {
+	NextToken  string
+	NumResults int
+}
+
+func (page *jsonPage) nextToken() string {
+	return page.NextToken
+}
+
+// jsonPaged is implemented by outputs which embed jsonPage.
+type jsonPaged interface {
+	nextToken() string
+}
+
+// Fetch one page of a JSON API list operation. The JSON API pages with
+// opaque tokens rather than page numbers, so the token leading to each page
+// is remembered; a page whose token is unknown is reached by walking from the
+// first page. A page past the end of the list decodes as empty. The page
+// number used is returned, along with the total number of results once the
+// last page has been reached, or 0 if there are more pages.
+func (client *jsonClient) listPage(ctx context.Context, operation string,
+	input jsonArgs, pageSize, pageNumber int, output jsonPaged) (
+	requestId string, number, total int, err error) {
+
+	if pageSize <= 0 {
+		pageSize = jsonDefaultPageSize
+	}
+	if pageNumber <= 0 {
+		pageNumber = 1
+	}
+	input["MaxResults"] = pageSize
+	query, _ := json.Marshal(input)
+	key := func(page int) string {
+		return fmt.Sprintf("%s %d %s", operation, page, query)
+	}
+
+	page := 1
+	client.mu.Lock()
+	if token, ok := client.tokens[key(pageNumber)]; ok {
+		page = pageNumber
+		input["NextToken"] = token
+	}
+	client.mu.Unlock()
+	for ; ; page++ {
+		if requestId, err = client.call(ctx, operation, input, output); err != nil {
+			return requestId, pageNumber, 0, err
+		}
+		token := output.nextToken()
+		if token != "" {
+			client.rememberToken(key(page+1), token)
+		}
+		if page == pageNumber {
+			break
+		} else if token == "" {
+			out := reflect.ValueOf(output).Elem()
+			out.Set(reflect.Zero(out.Type()))
+			return requestId, pageNumber, (page - 1) * pageSize, nil
+		}
+		input["NextToken"] = token
+	}
+	if output.nextToken() == "" {
+		count := reflect.ValueOf(output).Elem().FieldByName("NumResults").Int()
+		total = (pageNumber-1)*pageSize + int(count)
+	}
+	return requestId, pageNumber, total, nil
+}
+
+// Remember the token which leads to a page.
+func (client *jsonClient) rememberToken(key, token string) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.tokens == nil || len(client.tokens) >= jsonMaxPageTokens {
+		client.tokens = make(map[string]string)
+	}
+	client.tokens[key] = token
+}
+
+// An error for a legacy operation which the JSON API does not offer
+func unsupported(operation string) error {
+	return fmt.Errorf("%s: %w", operation, ErrUnsupportedOperation)
+}
+
+// A timestamp in the JSON API: seconds since the Unix epoch
+type jsonTime float64
+
+func (t jsonTime) dateTime() xsdt.DateTime {
+	if t == 0 {
+		return ""
+	}
+	sec, frac := math.Modf(float64(t))
+	return xsdt.DateTime(FormatTime(time.Unix(int64(sec), int64(frac*1e9))))
+}
+
+type jsonLocale struct {
+	Country     string
+	Subdivision string `json:",omitempty"`
+}
+
+type jsonQualificationRequirement struct {
+	QualificationTypeId string
+	Comparator          string
+	IntegerValues       []int        `json:",omitempty"`
+	LocaleValues        []jsonLocale `json:",omitempty"`
+	RequiredToPreview   bool         `json:",omitempty"`
+}
+
+type jsonHIT struct {
+	HITId                        string
+	HITTypeId                    string
+	HITGroupId                   string
+	HITLayoutId                  string
+	CreationTime                 jsonTime
+	Title                        string
+	Description                  string
+	Question                     string
+	Keywords                     string
+	HITStatus                    string
+	MaxAssignments               int
+	Reward                       string
+	AutoApprovalDelayInSeconds   int64
+	Expiration                   jsonTime
+	AssignmentDurationInSeconds  int64
+	RequesterAnnotation          string
+	QualificationRequirements    []jsonQualificationRequirement
+	HITReviewStatus              string
+	NumberOfAssignmentsPending   int
+	NumberOfAssignmentsAvailable int
+	NumberOfAssignmentsCompleted int
+}
+
+type jsonAssignment struct {
+	AssignmentId      string
+	WorkerId          string
+	HITId             string
+	AssignmentStatus  string
+	AutoApprovalTime  jsonTime
+	AcceptTime        jsonTime
+	SubmitTime        jsonTime
+	ApprovalTime      jsonTime
+	RejectionTime     jsonTime
+	Deadline          jsonTime
+	Answer            string
+	RequesterFeedback string
+}
+
+type jsonQualificationType struct {
+	QualificationTypeId     string
+	CreationTime            jsonTime
+	Name                    string
+	Description             string
+	Keywords                string
+	QualificationTypeStatus string
+	Test                    string
+	TestDurationInSeconds   int64
+	AnswerKey               string
+	RetryDelayInSeconds     int64
+	IsRequestable           bool
+	AutoGranted             bool
+	AutoGrantedValue        int
+}
+
+type jsonQualification struct {
+	QualificationTypeId string
+	WorkerId            string
+	GrantTime           jsonTime
+	IntegerValue        int
+	LocaleValue         *jsonLocale
+	Status              string
+}
+
+type jsonQualificationRequest struct {
+	QualificationRequestId string
+	QualificationTypeId    string
+	WorkerId               string
+	Test                   string
+	Answer                 string
+	SubmitTime             jsonTime
+}
+
+type jsonBonusPayment struct {
+	WorkerId     string
+	BonusAmount  string
+	AssignmentId string
+	Reason       string
+	GrantTime    jsonTime
+}
+
+type jsonWorkerBlock struct {
+	WorkerId string
+	Reason   string
+}
+
+type jsonNotifyWorkersFailureStatus struct {
+	NotifyWorkersFailureCode    string
+	NotifyWorkersFailureMessage string
+	WorkerId                    string
+}
+
+// Format a reward or bonus as the JSON API expects.
+func jsonAmount(amount float32) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+// Build a legacy price from a JSON API amount.
+func priceFromJSON(amount string) *amtgen.TPrice {
+	p := &amtgen.TPrice{}
+	p.Amount = xsdt.Decimal(amount)
+	p.CurrencyCode = CURRENCY_USD
+	p.FormattedPrice = xsdt.String("$" + amount)
+	return p
+}
+
+// Build the RequestId element shared by all legacy responses.
+func jsonOperationRequest(requestId string) *amtgen.TxsdOperationRequest {
+	opReq := &amtgen.TxsdOperationRequest{}
+	opReq.RequestId = xsdt.String(requestId)
+	return opReq
+}
+
+// Build the Request element which marks a legacy result as valid.
+func jsonValidRequest() *amtgen.TxsdRequest {
+	req := &amtgen.TxsdRequest{}
+	req.IsValid = "True"
+	return req
+}
+
+func requirementsToJSON(reqs []*amtgen.TQualificationRequirement) []jsonQualificationRequirement {
+	var out []jsonQualificationRequirement
+	for _, req := range reqs {
+		if req == nil {
+			continue
+		}
+		r := jsonQualificationRequirement{
+			QualificationTypeId: string(req.QualificationTypeId),
+			Comparator:          string(req.Comparator),
+			RequiredToPreview:   bool(req.RequiredToPreview),
+		}
+		for _, value := range req.IntegerValues {
+			r.IntegerValues = append(r.IntegerValues, int(value))
+		}
+		for _, locale := range req.LocaleValues {
+			if locale != nil {
+				r.LocaleValues = append(r.LocaleValues, jsonLocale{
+					Country:     string(locale.Country),
+					Subdivision: string(locale.Subdivision),
+				})
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func requirementsFromJSON(reqs []jsonQualificationRequirement) []*amtgen.TQualificationRequirement {
+	var out []*amtgen.TQualificationRequirement
+	for _, r := range reqs {
+		req := &amtgen.TQualificationRequirement{}
+		req.QualificationTypeId = xsdt.String(r.QualificationTypeId)
+		req.Comparator = amtgen.TComparator(r.Comparator)
+		req.RequiredToPreview = xsdt.Boolean(r.RequiredToPreview)
+		for _, value := range r.IntegerValues {
+			req.IntegerValues = append(req.IntegerValues, xsdt.Int(value))
+		}
+		for _, l := range r.LocaleValues {
+			locale := &amtgen.TLocale{}
+			locale.Country = xsdt.String(l.Country)
+			locale.Subdivision = xsdt.String(l.Subdivision)
+			req.LocaleValues = append(req.LocaleValues, locale)
+		}
+		out = append(out, req)
+	}
+	return out
+}
+
+// Translate a review policy into the JSON API's representation.
+func reviewPolicyToJSON(policy *amtgen.TReviewPolicy) interface{} {
+	if policy == nil {
+		return nil
+	}
+	var params []jsonArgs
+	for _, param := range policy.Parameters {
+		if param == nil {
+			continue
+		}
+		p := jsonArgs{"Key": string(param.Key)}
+		p.set("Values", stringsFromXSD(param.Values))
+		var entries []jsonArgs
+		for _, entry := range param.MapEntries {
+			if entry != nil {
+				entries = append(entries, jsonArgs{
+					"Key":    string(entry.Key),
+					"Values": stringsFromXSD(entry.Values),
+				})
+			}
+		}
+		p.set("MapEntries", entries)
+		params = append(params, p)
+	}
+	out := jsonArgs{"PolicyName": string(policy.PolicyName)}
+	out.set("Parameters", params)
+	return out
+}
+
+// Translate a notification specification into the JSON API's representation.
+func notificationToJSON(n *amtgen.TNotificationSpecification) interface{} {
+	if n == nil {
+		return nil
+	}
+	var eventTypes []string
+	for _, eventType := range n.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+	return jsonArgs{
+		"Destination": string(n.Destination),
+		"Transport":   string(n.Transport),
+		"Version":     string(n.Version),
+		"EventTypes":  eventTypes,
+	}
+}
+
+func stringsFromXSD(values []xsdt.String) []string {
+	var out []string
+	for _, value := range values {
+		out = append(out, string(value))
+	}
+	return out
+}
+
+func (h *jsonHIT) view() *amtgen.Thit {
+	view := &amtgen.Thit{}
+	view.Request = jsonValidRequest()
+	view.HITId = xsdt.String(h.HITId)
+	view.HITTypeId = xsdt.String(h.HITTypeId)
+	view.HITGroupId = xsdt.String(h.HITGroupId)
+	view.HITLayoutId = xsdt.String(h.HITLayoutId)
+	view.CreationTime = h.CreationTime.dateTime()
+	view.Expiration = h.Expiration.dateTime()
+	view.Title = xsdt.String(h.Title)
+	view.Description = xsdt.String(h.Description)
+	view.Keywords = xsdt.String(h.Keywords)
+	view.Question = xsdt.String(h.Question)
+	view.HITStatus = amtgen.THITStatus(h.HITStatus)
+	view.HITReviewStatus = amtgen.THITReviewStatus(h.HITReviewStatus)
+	view.MaxAssignments = xsdt.Int(h.MaxAssignments)
+	if h.Reward != "" {
+		view.Reward = priceFromJSON(h.Reward)
+	}
+	view.AssignmentDurationInSeconds = xsdt.Long(h.AssignmentDurationInSeconds)
+	view.AutoApprovalDelayInSeconds = xsdt.Long(h.AutoApprovalDelayInSeconds)
+	view.RequesterAnnotation = xsdt.String(h.RequesterAnnotation)
+	view.QualificationRequirements = requirementsFromJSON(h.QualificationRequirements)
+	view.NumberOfAssignmentsAvailable = xsdt.Int(h.NumberOfAssignmentsAvailable)
+	view.NumberOfAssignmentsPending = xsdt.Int(h.NumberOfAssignmentsPending)
+	view.NumberOfAssignmentsCompleted = xsdt.Int(h.NumberOfAssignmentsCompleted)
+	return view
+}
+
+func hitsFromJSON(hits []jsonHIT) []*amtgen.Thit {
+	var out []*amtgen.Thit
+	for i := range hits {
+		out = append(out, hits[i].view())
+	}
+	return out
+}
+
+func (a *jsonAssignment) view() *amtgen.TAssignment {
+	view := &amtgen.TAssignment{}
+	view.AssignmentId = xsdt.String(a.AssignmentId)
+	view.HITId = xsdt.String(a.HITId)
+	view.WorkerId = xsdt.String(a.WorkerId)
+	view.AssignmentStatus = amtgen.TAssignmentStatus(a.AssignmentStatus)
+	view.AcceptTime = a.AcceptTime.dateTime()
+	view.Deadline = a.Deadline.dateTime()
+	view.SubmitTime = a.SubmitTime.dateTime()
+	view.AutoApprovalTime = a.AutoApprovalTime.dateTime()
+	view.ApprovalTime = a.ApprovalTime.dateTime()
+	view.RejectionTime = a.RejectionTime.dateTime()
+	view.Answer = xsdt.String(a.Answer)
+	view.RequesterFeedback = xsdt.String(a.RequesterFeedback)
+	return view
+}
+
+func (qt *jsonQualificationType) view() *amtgen.TQualificationType {
+	view := &amtgen.TQualificationType{}
+	view.Request = jsonValidRequest()
+	view.QualificationTypeId = xsdt.String(qt.QualificationTypeId)
+	view.CreationTime = qt.CreationTime.dateTime()
+	view.Name = xsdt.String(qt.Name)
+	view.Description = xsdt.String(qt.Description)
+	view.Keywords = xsdt.String(qt.Keywords)
+	view.QualificationTypeStatus = amtgen.TQualificationTypeStatus(qt.QualificationTypeStatus)
+	view.RetryDelayInSeconds = xsdt.Long(qt.RetryDelayInSeconds)
+	view.Test = xsdt.String(qt.Test)
+	view.AnswerKey = xsdt.String(qt.AnswerKey)
+	view.TestDurationInSeconds = xsdt.Long(qt.TestDurationInSeconds)
+	view.AutoGranted = xsdt.Boolean(qt.AutoGranted)
+	view.AutoGrantedValue = xsdt.Int(qt.AutoGrantedValue)
+	view.IsRequestable = xsdt.Boolean(qt.IsRequestable)
+	return view
+}
+
+func (q *jsonQualification) view() *amtgen.TQualification {
+	view := &amtgen.TQualification{}
+	view.Request = jsonValidRequest()
+	view.QualificationTypeId = xsdt.String(q.QualificationTypeId)
+	view.SubjectId = xsdt.String(q.WorkerId)
+	view.GrantTime = q.GrantTime.dateTime()
+	view.IntegerValue = xsdt.Int(q.IntegerValue)
+	view.Status = amtgen.TQualificationStatus(q.Status)
+	if q.LocaleValue != nil {
+		view.LocaleValue = &amtgen.TLocale{}
+		view.LocaleValue.Country = xsdt.String(q.LocaleValue.Country)
+		view.LocaleValue.Subdivision = xsdt.String(q.LocaleValue.Subdivision)
+	}
+	return view
+}
+
+func (qr *jsonQualificationRequest) view() *amtgen.TQualificationRequest {
+	view := &amtgen.TQualificationRequest{}
+	view.QualificationRequestId = xsdt.String(qr.QualificationRequestId)
+	view.QualificationTypeId = xsdt.String(qr.QualificationTypeId)
+	view.SubjectId = xsdt.String(qr.WorkerId)
+	view.Test = xsdt.String(qr.Test)
+	view.Answer = xsdt.String(qr.Answer)
+	view.SubmitTime = qr.SubmitTime.dateTime()
+	return view
+}
+
+func (b *jsonBonusPayment) view() *amtgen.TBonusPayment {
+	view := &amtgen.TBonusPayment{}
+	view.WorkerId = xsdt.String(b.WorkerId)
+	view.AssignmentId = xsdt.String(b.AssignmentId)
+	view.BonusAmount = priceFromJSON(b.BonusAmount)
+	view.Reason = xsdt.String(b.Reason)
+	view.GrantTime = b.GrantTime.dateTime()
+	return view
+}
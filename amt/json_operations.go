@@ -0,0 +1,1646 @@
+package amt
+
+import (
+	"context"
+	"fmt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	xsdt "github.com/metaleap/go-xsd/types"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ApproveAssignment approves the results of a completed assignment.
+func (client *jsonClient) ApproveAssignment(assignmentId,
+	requesterFeedback string) (amtgen.TxsdApproveAssignmentResponse, error) {
+	return client.ApproveAssignmentCtx(context.Background(), assignmentId,
+		requesterFeedback)
+}
+
+// ApproveAssignmentCtx is like ApproveAssignment, but the request is bound
+// to ctx.
+func (client *jsonClient) ApproveAssignmentCtx(ctx context.Context, assignmentId,
+	requesterFeedback string) (amtgen.TxsdApproveAssignmentResponse, error) {
+
+	var response amtgen.TxsdApproveAssignmentResponse
+	args := jsonArgs{"AssignmentId": assignmentId}
+	args.set("RequesterFeedback", requesterFeedback)
+	requestId, err := client.call(ctx, "ApproveAssignment", args, &struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TApproveAssignmentResult{}
+		result.Request = jsonValidRequest()
+		response.ApproveAssignmentResults = append(
+			response.ApproveAssignmentResults, result)
+	}
+	return response, err
+}
+
+// ApproveRejectedAssignment approves an assignment that was previously
+// rejected.
+func (client *jsonClient) ApproveRejectedAssignment(assignmentId,
+	requesterFeedback string) (amtgen.TxsdApproveRejectedAssignmentResponse, error) {
+	return client.ApproveRejectedAssignmentCtx(context.Background(),
+		assignmentId, requesterFeedback)
+}
+
+// ApproveRejectedAssignmentCtx is like ApproveRejectedAssignment, but the
+// request is bound to ctx. It calls ApproveAssignment with OverrideRejection.
+func (client *jsonClient) ApproveRejectedAssignmentCtx(ctx context.Context,
+	assignmentId,
+	requesterFeedback string) (amtgen.TxsdApproveRejectedAssignmentResponse, error) {
+
+	var response amtgen.TxsdApproveRejectedAssignmentResponse
+	args := jsonArgs{"AssignmentId": assignmentId, "OverrideRejection": true}
+	args.set("RequesterFeedback", requesterFeedback)
+	requestId, err := client.call(ctx, "ApproveAssignment", args, &struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TApproveRejectedAssignmentResult{}
+		result.Request = jsonValidRequest()
+		response.ApproveRejectedAssignmentResults = append(
+			response.ApproveRejectedAssignmentResults, result)
+	}
+	return response, err
+}
+
+// AssignQualification gives a Worker a Qualification.
+func (client *jsonClient) AssignQualification(qualificationTypeId,
+	workerId string, integerValue int, sendNotification bool) (
+	amtgen.TxsdAssignQualificationResponse, error) {
+	return client.AssignQualificationCtx(context.Background(),
+		qualificationTypeId, workerId, integerValue, sendNotification)
+}
+
+// AssignQualificationCtx is like AssignQualification, but the request is
+// bound to ctx. It calls AssociateQualificationWithWorker.
+func (client *jsonClient) AssignQualificationCtx(ctx context.Context,
+	qualificationTypeId, workerId string,
+	integerValue int, sendNotification bool) (
+	amtgen.TxsdAssignQualificationResponse, error) {
+
+	var response amtgen.TxsdAssignQualificationResponse
+	args := jsonArgs{
+		"QualificationTypeId": qualificationTypeId,
+		"WorkerId":            workerId,
+		"IntegerValue":        integerValue,
+		"SendNotification":    sendNotification,
+	}
+	requestId, err := client.call(ctx, "AssociateQualificationWithWorker",
+		args, &struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TAssignQualificationResult{}
+		result.Request = jsonValidRequest()
+		response.AssignQualificationResults = append(
+			response.AssignQualificationResults, result)
+	}
+	return response, err
+}
+
+// BlockWorker allows you to prevent a Worker from working on your HITs.
+func (client *jsonClient) BlockWorker(workerId, reason string) (
+	amtgen.TxsdBlockWorkerResponse, error) {
+	return client.BlockWorkerCtx(context.Background(), workerId, reason)
+}
+
+// BlockWorkerCtx is like BlockWorker, but the request is bound to ctx. It
+// calls CreateWorkerBlock.
+func (client *jsonClient) BlockWorkerCtx(ctx context.Context,
+	workerId, reason string) (
+	amtgen.TxsdBlockWorkerResponse, error) {
+
+	var response amtgen.TxsdBlockWorkerResponse
+	args := jsonArgs{"WorkerId": workerId, "Reason": reason}
+	requestId, err := client.call(ctx, "CreateWorkerBlock", args, &struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TBlockWorkerResult{}
+		result.Request = jsonValidRequest()
+		response.BlockWorkerResults = append(response.BlockWorkerResults,
+			result)
+	}
+	return response, err
+}
+
+// ChangeHITTypeOfHIT allows you to change the HITType properties of a HIT.
+func (client *jsonClient) ChangeHITTypeOfHIT(hitId, hitTypeId string) (
+	amtgen.TxsdChangeHITTypeOfHITResponse, error) {
+	return client.ChangeHITTypeOfHITCtx(context.Background(), hitId, hitTypeId)
+}
+
+// ChangeHITTypeOfHITCtx is like ChangeHITTypeOfHIT, but the request is bound
+// to ctx. It calls UpdateHITTypeOfHIT.
+func (client *jsonClient) ChangeHITTypeOfHITCtx(ctx context.Context,
+	hitId, hitTypeId string) (
+	amtgen.TxsdChangeHITTypeOfHITResponse, error) {
+
+	var response amtgen.TxsdChangeHITTypeOfHITResponse
+	args := jsonArgs{"HITId": hitId, "HITTypeId": hitTypeId}
+	requestId, err := client.call(ctx, "UpdateHITTypeOfHIT", args, &struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TChangeHITTypeOfHITResult{}
+		result.Request = jsonValidRequest()
+		response.ChangeHITTypeOfHITResults = append(
+			response.ChangeHITTypeOfHITResults, result)
+	}
+	return response, err
+}
+
+// CreateHIT creates a new Human Intelligence Task (HIT) without a HITTypeId.
+func (client *jsonClient) CreateHIT(title, description, question string,
+	hitLayoutId string, hitLayoutParameters map[string]string,
+	reward float32, assignmentDurationInSeconds,
+	lifetimeInSeconds, maxAssignments, autoApprovalDelayInSeconds int,
+	keywords []string,
+	qualificationRequirements []*amtgen.TQualificationRequirement,
+	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
+	requesterAnnotation, uniqueRequestToken string) (
+	amtgen.TxsdCreateHITResponse, error) {
+	return client.CreateHITCtx(context.Background(), title, description,
+		question, hitLayoutId, hitLayoutParameters, reward,
+		assignmentDurationInSeconds, lifetimeInSeconds, maxAssignments,
+		autoApprovalDelayInSeconds, keywords, qualificationRequirements,
+		assignmentReviewPolicy, hitReviewPolicy, requesterAnnotation,
+		uniqueRequestToken)
+}
+
+// CreateHITCtx is like CreateHIT, but the request is bound to ctx.
+func (client *jsonClient) CreateHITCtx(ctx context.Context,
+	title, description, question string,
+	hitLayoutId string, hitLayoutParameters map[string]string,
+	reward float32, assignmentDurationInSeconds,
+	lifetimeInSeconds, maxAssignments, autoApprovalDelayInSeconds int,
+	keywords []string,
+	qualificationRequirements []*amtgen.TQualificationRequirement,
+	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
+	requesterAnnotation, uniqueRequestToken string) (
+	amtgen.TxsdCreateHITResponse, error) {
+
+	// Prepare the request
+	var (
+		args amtgen.TCreateHITRequest
+	)
+	args.Title = xsdt.String(title)
+	args.Description = xsdt.String(description)
+	args.Question = xsdt.String(question)
+	args.HITLayoutId = xsdt.String(hitLayoutId)
+	var hitLayoutParameterOrder []string
+	for name, _ := range hitLayoutParameters {
+		hitLayoutParameterOrder = append(hitLayoutParameterOrder, name)
+	}
+	sort.Strings(hitLayoutParameterOrder)
+	for _, name := range hitLayoutParameterOrder {
+		value := hitLayoutParameters[name]
+		var param amtgen.THITLayoutParameter
+		param.Name = xsdt.String(name)
+		param.Value = xsdt.String(value)
+		args.HITLayoutParameters = append(args.HITLayoutParameters, &param)
+	}
+	args.Reward = &amtgen.TPrice{}
+	args.Reward.Amount = xsdt.Decimal(fmt.Sprint(reward))
+	args.Reward.CurrencyCode = CURRENCY_USD
+	args.AssignmentDurationInSeconds = xsdt.Long(assignmentDurationInSeconds)
+	args.LifetimeInSeconds = xsdt.Long(lifetimeInSeconds)
+	args.MaxAssignments = xsdt.Int(maxAssignments)
+	args.AutoApprovalDelayInSeconds = xsdt.Long(autoApprovalDelayInSeconds)
+	args.Keywords = xsdt.String(strings.Join(keywords, ","))
+	args.QualificationRequirements = qualificationRequirements
+	args.AssignmentReviewPolicy = assignmentReviewPolicy
+	args.HITReviewPolicy = hitReviewPolicy
+	args.RequesterAnnotation = xsdt.String(requesterAnnotation)
+	args.UniqueRequestToken = xsdt.String(uniqueRequestToken)
+	return client.CreateHITFromArgsCtx(ctx, args)
+}
+
+// CreateHITFromHITTypeId creates a new Human Intelligence Task (HIT) from a
+// HITTypeId.
+func (client *jsonClient) CreateHITFromHITTypeId(hitTypeId, question string,
+	hitLayoutId string, hitLayoutParameters map[string]string,
+	lifetimeInSeconds, maxAssignments int,
+	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
+	requesterAnnotation, uniqueRequestToken string) (
+	amtgen.TxsdCreateHITResponse, error) {
+	return client.CreateHITFromHITTypeIdCtx(context.Background(), hitTypeId,
+		question, hitLayoutId, hitLayoutParameters, lifetimeInSeconds,
+		maxAssignments, assignmentReviewPolicy, hitReviewPolicy,
+		requesterAnnotation, uniqueRequestToken)
+}
+
+// CreateHITFromHITTypeIdCtx is like CreateHITFromHITTypeId, but the request
+// is bound to ctx.
+func (client *jsonClient) CreateHITFromHITTypeIdCtx(ctx context.Context,
+	hitTypeId, question string,
+	hitLayoutId string, hitLayoutParameters map[string]string,
+	lifetimeInSeconds, maxAssignments int,
+	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
+	requesterAnnotation, uniqueRequestToken string) (
+	amtgen.TxsdCreateHITResponse, error) {
+
+	// Prepare the request
+	var (
+		args amtgen.TCreateHITRequest
+	)
+	args.HITTypeId = xsdt.String(hitTypeId)
+	args.Question = xsdt.String(question)
+	args.HITLayoutId = xsdt.String(hitLayoutId)
+	var hitLayoutParameterOrder []string
+	for name, _ := range hitLayoutParameters {
+		hitLayoutParameterOrder = append(hitLayoutParameterOrder, name)
+	}
+	sort.Strings(hitLayoutParameterOrder)
+	for _, name := range hitLayoutParameterOrder {
+		value := hitLayoutParameters[name]
+		var param amtgen.THITLayoutParameter
+		param.Name = xsdt.String(name)
+		param.Value = xsdt.String(value)
+		args.HITLayoutParameters = append(args.HITLayoutParameters, &param)
+	}
+	args.LifetimeInSeconds = xsdt.Long(lifetimeInSeconds)
+	args.MaxAssignments = xsdt.Int(maxAssignments)
+	args.AssignmentReviewPolicy = assignmentReviewPolicy
+	args.HITReviewPolicy = hitReviewPolicy
+	args.RequesterAnnotation = xsdt.String(requesterAnnotation)
+	args.UniqueRequestToken = xsdt.String(uniqueRequestToken)
+	return client.CreateHITFromArgsCtx(ctx, args)
+}
+
+// CreateHITFromArgs creates a new Human Intelligence Task (HIT) from the given
+// argument values.
+func (client *jsonClient) CreateHITFromArgs(args amtgen.TCreateHITRequest) (
+	amtgen.TxsdCreateHITResponse, error) {
+	return client.CreateHITFromArgsCtx(context.Background(), args)
+}
+
+// CreateHITFromArgsCtx is like CreateHITFromArgs, but the request is bound
+// to ctx. It calls CreateHITWithHITType if args names a HIT type, and
+// CreateHIT otherwise.
+func (client *jsonClient) CreateHITFromArgsCtx(ctx context.Context,
+	args amtgen.TCreateHITRequest) (
+	amtgen.TxsdCreateHITResponse, error) {
+
+	var (
+		response  amtgen.TxsdCreateHITResponse
+		operation = "CreateHIT"
+		input     = jsonArgs{}
+		output    struct{ HIT jsonHIT }
+	)
+	if args.HITTypeId != "" {
+		operation = "CreateHITWithHITType"
+		input.set("HITTypeId", string(args.HITTypeId))
+	} else {
+		input.set("Title", string(args.Title))
+		input.set("Description", string(args.Description))
+		if args.Reward != nil {
+			input.set("Reward", string(args.Reward.Amount))
+		}
+		input.set("AssignmentDurationInSeconds",
+			int64(args.AssignmentDurationInSeconds))
+		input.set("AutoApprovalDelayInSeconds",
+			int64(args.AutoApprovalDelayInSeconds))
+		input.set("Keywords", string(args.Keywords))
+		input.set("QualificationRequirements",
+			requirementsToJSON(args.QualificationRequirements))
+	}
+	input.set("Question", string(args.Question))
+	input.set("HITLayoutId", string(args.HITLayoutId))
+	var params []jsonArgs
+	for _, param := range args.HITLayoutParameters {
+		if param != nil {
+			params = append(params, jsonArgs{
+				"Name":  string(param.Name),
+				"Value": string(param.Value),
+			})
+		}
+	}
+	input.set("HITLayoutParameters", params)
+	input.set("LifetimeInSeconds", int64(args.LifetimeInSeconds))
+	input.set("MaxAssignments", int(args.MaxAssignments))
+	input.set("AssignmentReviewPolicy",
+		reviewPolicyToJSON(args.AssignmentReviewPolicy))
+	input.set("HITReviewPolicy", reviewPolicyToJSON(args.HITReviewPolicy))
+	input.set("RequesterAnnotation", string(args.RequesterAnnotation))
+	input.set("UniqueRequestToken", string(args.UniqueRequestToken))
+
+	requestId, err := client.call(ctx, operation, input, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		response.Hits = append(response.Hits, output.HIT.view())
+	}
+	return response, err
+}
+
+// CreateQualificationType creates a new Qualification type.
+func (client *jsonClient) CreateQualificationType(name, description string,
+	keywords []string, retryDelayInSeconds int,
+	qualificationTypeStatus, test, answerKey string,
+	testDurationInSeconds int, autoGranted bool,
+	autoGrantedValue int) (amtgen.TxsdCreateQualificationTypeResponse, error) {
+	return client.CreateQualificationTypeCtx(context.Background(), name,
+		description, keywords, retryDelayInSeconds, qualificationTypeStatus,
+		test, answerKey, testDurationInSeconds, autoGranted, autoGrantedValue)
+}
+
+// CreateQualificationTypeCtx is like CreateQualificationType, but the
+// request is bound to ctx.
+func (client *jsonClient) CreateQualificationTypeCtx(ctx context.Context,
+	name, description string,
+	keywords []string, retryDelayInSeconds int,
+	qualificationTypeStatus, test, answerKey string,
+	testDurationInSeconds int, autoGranted bool,
+	autoGrantedValue int) (amtgen.TxsdCreateQualificationTypeResponse, error) {
+
+	var (
+		response amtgen.TxsdCreateQualificationTypeResponse
+		output   struct{ QualificationType jsonQualificationType }
+	)
+	args := jsonArgs{
+		"Name":                    name,
+		"Description":             description,
+		"QualificationTypeStatus": qualificationTypeStatus,
+	}
+	args.set("Keywords", strings.Join(keywords, ","))
+	args.set("RetryDelayInSeconds", retryDelayInSeconds)
+	args.set("Test", test)
+	args.set("AnswerKey", answerKey)
+	args.set("TestDurationInSeconds", testDurationInSeconds)
+	if autoGranted {
+		args["AutoGranted"] = true
+		args["AutoGrantedValue"] = autoGrantedValue
+	}
+	requestId, err := client.call(ctx, "CreateQualificationType", args,
+		&output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		response.QualificationTypes = append(response.QualificationTypes,
+			output.QualificationType.view())
+	}
+	return response, err
+}
+
+// DisableHIT removes a HIT from the Amazon Mechanical Turk marketplace.
+func (client *jsonClient) DisableHIT(hitId string) (
+	amtgen.TxsdDisableHITResponse, error) {
+	return client.DisableHITCtx(context.Background(), hitId)
+}
+
+// DisableHITCtx is like DisableHIT, but the request is bound to ctx. The JSON
+// API has no DisableHIT, so the HIT is expired and then deleted; unlike
+// DisableHIT, this fails if any assignment is still awaiting review.
+func (client *jsonClient) DisableHITCtx(ctx context.Context, hitId string) (
+	amtgen.TxsdDisableHITResponse, error) {
+
+	var response amtgen.TxsdDisableHITResponse
+	args := jsonArgs{"HITId": hitId, "ExpireAt": 0}
+	requestId, err := client.call(ctx, "UpdateExpirationForHIT", args,
+		&struct{}{})
+	if err == nil {
+		requestId, err = client.call(ctx, "DeleteHIT",
+			jsonArgs{"HITId": hitId}, &struct{}{})
+	}
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TDisableHITResult{}
+		result.Request = jsonValidRequest()
+		response.DisableHITResults = append(response.DisableHITResults, result)
+	}
+	return response, err
+}
+
+// DisposeHIT disposes of a HIT that is no longer needed.
+func (client *jsonClient) DisposeHIT(hitId string) (
+	amtgen.TxsdDisposeHITResponse, error) {
+	return client.DisposeHITCtx(context.Background(), hitId)
+}
+
+// DisposeHITCtx is like DisposeHIT, but the request is bound to ctx. It
+// calls DeleteHIT.
+func (client *jsonClient) DisposeHITCtx(ctx context.Context, hitId string) (
+	amtgen.TxsdDisposeHITResponse, error) {
+
+	var response amtgen.TxsdDisposeHITResponse
+	requestId, err := client.call(ctx, "DeleteHIT", jsonArgs{"HITId": hitId},
+		&struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TDisposeHITResult{}
+		result.Request = jsonValidRequest()
+		response.DisposeHITResults = append(response.DisposeHITResults, result)
+	}
+	return response, err
+}
+
+// DisposeQualificationType disposes of a Qualification type that is no
+// longer needed.
+func (client *jsonClient) DisposeQualificationType(qualificationTypeId string) (
+	amtgen.TxsdDisposeQualificationTypeResponse, error) {
+	return client.DisposeQualificationTypeCtx(context.Background(),
+		qualificationTypeId)
+}
+
+// DisposeQualificationTypeCtx is like DisposeQualificationType, but the
+// request is bound to ctx. It calls DeleteQualificationType.
+func (client *jsonClient) DisposeQualificationTypeCtx(ctx context.Context,
+	qualificationTypeId string) (
+	amtgen.TxsdDisposeQualificationTypeResponse, error) {
+
+	var response amtgen.TxsdDisposeQualificationTypeResponse
+	args := jsonArgs{"QualificationTypeId": qualificationTypeId}
+	requestId, err := client.call(ctx, "DeleteQualificationType", args,
+		&struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TDisposeQualificationTypeResult{}
+		result.Request = jsonValidRequest()
+		response.DisposeQualificationTypeResults = append(
+			response.DisposeQualificationTypeResults, result)
+	}
+	return response, err
+}
+
+// ExtendHIT increases the maximum number of assignments, or extends the
+// expiration date, of an existing HIT.
+func (client *jsonClient) ExtendHIT(hitId string,
+	maxAssignmentsIncrement, expirationIncrementInSeconds int,
+	uniqueRequestToken string) (
+	amtgen.TxsdExtendHITResponse, error) {
+	return client.ExtendHITCtx(context.Background(), hitId,
+		maxAssignmentsIncrement, expirationIncrementInSeconds,
+		uniqueRequestToken)
+}
+
+// ExtendHITCtx is like ExtendHIT, but the request is bound to ctx. New
+// assignments are added with CreateAdditionalAssignmentsForHIT, and the
+// expiration is moved with UpdateExpirationForHIT after looking up the
+// current one with GetHIT. An expired HIT is extended from the current time.
+func (client *jsonClient) ExtendHITCtx(ctx context.Context, hitId string,
+	maxAssignmentsIncrement, expirationIncrementInSeconds int,
+	uniqueRequestToken string) (
+	amtgen.TxsdExtendHITResponse, error) {
+
+	var (
+		response  amtgen.TxsdExtendHITResponse
+		requestId string
+		err       error
+	)
+	if maxAssignmentsIncrement > 0 {
+		args := jsonArgs{
+			"HITId":                         hitId,
+			"NumberOfAdditionalAssignments": maxAssignmentsIncrement,
+		}
+		args.set("UniqueRequestToken", uniqueRequestToken)
+		requestId, err = client.call(ctx, "CreateAdditionalAssignmentsForHIT",
+			args, &struct{}{})
+	}
+	if err == nil && expirationIncrementInSeconds > 0 {
+		var output struct{ HIT jsonHIT }
+		requestId, err = client.call(ctx, "GetHIT", jsonArgs{"HITId": hitId},
+			&output)
+		if err == nil {
+
+			// Extend an expired HIT from now, as the legacy API does
+			expiration := float64(output.HIT.Expiration)
+			if now := float64(time.Now().UnixNano()) / 1e9; expiration < now {
+				expiration = now
+			}
+			args := jsonArgs{
+				"HITId":    hitId,
+				"ExpireAt": expiration + float64(expirationIncrementInSeconds),
+			}
+			requestId, err = client.call(ctx, "UpdateExpirationForHIT", args,
+				&struct{}{})
+		}
+	}
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TExtendHITResult{}
+		result.Request = jsonValidRequest()
+		response.ExtendHITResults = append(response.ExtendHITResults, result)
+	}
+	return response, err
+}
+
+// ForceExpireHIT causes a HIT to expire immediately, as if the
+// LifetimeInSeconds parameter of the HIT had elapsed.
+func (client *jsonClient) ForceExpireHIT(hitId string) (
+	amtgen.TxsdForceExpireHITResponse, error) {
+	return client.ForceExpireHITCtx(context.Background(), hitId)
+}
+
+// ForceExpireHITCtx is like ForceExpireHIT, but the request is bound to ctx.
+// It calls UpdateExpirationForHIT with a time in the past.
+func (client *jsonClient) ForceExpireHITCtx(ctx context.Context, hitId string) (
+	amtgen.TxsdForceExpireHITResponse, error) {
+
+	var response amtgen.TxsdForceExpireHITResponse
+	args := jsonArgs{"HITId": hitId, "ExpireAt": 0}
+	requestId, err := client.call(ctx, "UpdateExpirationForHIT", args,
+		&struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TForceExpireHITResult{}
+		result.Request = jsonValidRequest()
+		response.ForceExpireHITResults = append(response.ForceExpireHITResults,
+			result)
+	}
+	return response, err
+}
+
+// GetAccountBalance retrieves the amount of money in your account.
+func (client *jsonClient) GetAccountBalance() (
+	amtgen.TxsdGetAccountBalanceResponse, error) {
+	return client.GetAccountBalanceCtx(context.Background())
+}
+
+// GetAccountBalanceCtx is like GetAccountBalance, but the request is bound
+// to ctx.
+func (client *jsonClient) GetAccountBalanceCtx(ctx context.Context) (
+	amtgen.TxsdGetAccountBalanceResponse, error) {
+
+	var (
+		response amtgen.TxsdGetAccountBalanceResponse
+		output   struct{ AvailableBalance, OnHoldBalance string }
+	)
+	requestId, err := client.call(ctx, "GetAccountBalance", nil, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TGetAccountBalanceResult{}
+		result.Request = jsonValidRequest()
+		result.AvailableBalance = priceFromJSON(output.AvailableBalance)
+		if output.OnHoldBalance != "" {
+			result.OnHoldBalance = priceFromJSON(output.OnHoldBalance)
+		}
+		response.GetAccountBalanceResults = append(
+			response.GetAccountBalanceResults, result)
+	}
+	return response, err
+}
+
+// GetAssignment retrieves an assignment with an AssignmentStatus value of
+// Submitted, Approved, or Rejected.
+func (client *jsonClient) GetAssignment(assignmentId string) (
+	amtgen.TxsdGetAssignmentResponse, error) {
+	return client.GetAssignmentCtx(context.Background(), assignmentId)
+}
+
+// GetAssignmentCtx is like GetAssignment, but the request is bound to ctx.
+func (client *jsonClient) GetAssignmentCtx(ctx context.Context,
+	assignmentId string) (
+	amtgen.TxsdGetAssignmentResponse, error) {
+
+	var (
+		response amtgen.TxsdGetAssignmentResponse
+		output   struct {
+			Assignment jsonAssignment
+			HIT        jsonHIT
+		}
+	)
+	args := jsonArgs{"AssignmentId": assignmentId}
+	requestId, err := client.call(ctx, "GetAssignment", args, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TGetAssignmentResult{}
+		result.Request = jsonValidRequest()
+		result.Assignment = output.Assignment.view()
+		result.Hit = output.HIT.view()
+		response.GetAssignmentResults = append(response.GetAssignmentResults,
+			result)
+	}
+	return response, err
+}
+
+// GetAssignmentsForHIT retrieves completed assignments for a HIT.
+func (client *jsonClient) GetAssignmentsForHIT(hitId string,
+	assignmentStatuses []string, sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetAssignmentsForHITResponse, error) {
+	return client.GetAssignmentsForHITCtx(context.Background(), hitId,
+		assignmentStatuses, sortProperty, sortAscending, pageSize, pageNumber)
+}
+
+// GetAssignmentsForHITCtx is like GetAssignmentsForHIT, but the request is
+// bound to ctx. It calls ListAssignmentsForHIT, which does not support
+// sorting, so sortProperty and sortAscending are ignored.
+func (client *jsonClient) GetAssignmentsForHITCtx(ctx context.Context,
+	hitId string, assignmentStatuses []string,
+	sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetAssignmentsForHITResponse, error) {
+
+	var (
+		response amtgen.TxsdGetAssignmentsForHITResponse
+		output   struct {
+			jsonPage
+			Assignments []jsonAssignment
+		}
+	)
+	args := jsonArgs{"HITId": hitId}
+	args.set("AssignmentStatuses", assignmentStatuses)
+	requestId, number, total, err := client.listPage(ctx,
+		"ListAssignmentsForHIT", args, pageSize, pageNumber, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TGetAssignmentsForHITResult{}
+		result.Request = jsonValidRequest()
+		result.PageNumber = xsdt.Int(number)
+		result.NumResults = xsdt.Int(len(output.Assignments))
+		result.TotalNumResults = xsdt.Int(total)
+		for i := range output.Assignments {
+			result.Assignments = append(result.Assignments,
+				output.Assignments[i].view())
+		}
+		response.GetAssignmentsForHITResults = append(
+			response.GetAssignmentsForHITResults, result)
+	}
+	return response, err
+}
+
+// GetBlockedWorkers retrieves a list of Workers who are blocked from working
+// on your HITs.
+func (client *jsonClient) GetBlockedWorkers(pageSize, pageNumber int) (
+	amtgen.TxsdGetBlockedWorkersResponse, error) {
+	return client.GetBlockedWorkersCtx(context.Background(), pageSize,
+		pageNumber)
+}
+
+// GetBlockedWorkersCtx is like GetBlockedWorkers, but the request is bound to
+// ctx. It calls ListWorkerBlocks.
+func (client *jsonClient) GetBlockedWorkersCtx(ctx context.Context,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetBlockedWorkersResponse, error) {
+
+	var (
+		response amtgen.TxsdGetBlockedWorkersResponse
+		output   struct {
+			jsonPage
+			WorkerBlocks []jsonWorkerBlock
+		}
+	)
+	requestId, number, total, err := client.listPage(ctx, "ListWorkerBlocks",
+		jsonArgs{}, pageSize, pageNumber, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TGetBlockedWorkersResult{}
+		result.Request = jsonValidRequest()
+		result.PageNumber = xsdt.Int(number)
+		result.NumResults = xsdt.Int(len(output.WorkerBlocks))
+		result.TotalNumResults = xsdt.Int(total)
+		for _, b := range output.WorkerBlocks {
+			block := &amtgen.TWorkerBlock{}
+			block.WorkerId = xsdt.String(b.WorkerId)
+			block.Reason = xsdt.String(b.Reason)
+			result.WorkerBlocks = append(result.WorkerBlocks, block)
+		}
+		response.GetBlockedWorkersResults = append(
+			response.GetBlockedWorkersResults, result)
+	}
+	return response, err
+}
+
+// GetBonusPayments retrieves the amounts of bonuses you have paid to Workers
+// for a given HIT or assignment.
+func (client *jsonClient) GetBonusPayments(hitId, assignmentId string,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetBonusPaymentsResponse, error) {
+	return client.GetBonusPaymentsCtx(context.Background(), hitId, assignmentId,
+		pageSize, pageNumber)
+}
+
+// GetBonusPaymentsCtx is like GetBonusPayments, but the request is bound to
+// ctx. It calls ListBonusPayments.
+func (client *jsonClient) GetBonusPaymentsCtx(ctx context.Context,
+	hitId, assignmentId string,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetBonusPaymentsResponse, error) {
+
+	var (
+		response amtgen.TxsdGetBonusPaymentsResponse
+		output   struct {
+			jsonPage
+			BonusPayments []jsonBonusPayment
+		}
+	)
+	args := jsonArgs{}
+	args.set("HITId", hitId)
+	args.set("AssignmentId", assignmentId)
+	requestId, number, total, err := client.listPage(ctx, "ListBonusPayments",
+		args, pageSize, pageNumber, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TGetBonusPaymentsResult{}
+		result.Request = jsonValidRequest()
+		result.PageNumber = xsdt.Int(number)
+		result.NumResults = xsdt.Int(len(output.BonusPayments))
+		result.TotalNumResults = xsdt.Int(total)
+		for i := range output.BonusPayments {
+			result.BonusPayments = append(result.BonusPayments,
+				output.BonusPayments[i].view())
+		}
+		response.GetBonusPaymentsResults = append(
+			response.GetBonusPaymentsResults, result)
+	}
+	return response, err
+}
+
+// GetFileUploadURL generates and returns a temporary URL.
+func (client *jsonClient) GetFileUploadURL(assignmentId,
+	questionIdentifier string) (
+	amtgen.TxsdGetFileUploadURLResponse, error) {
+	return client.GetFileUploadURLCtx(context.Background(), assignmentId,
+		questionIdentifier)
+}
+
+// GetFileUploadURLCtx is like GetFileUploadURL, but the request is bound to
+// ctx. The JSON API has no equivalent, so it always fails.
+func (client *jsonClient) GetFileUploadURLCtx(ctx context.Context, assignmentId,
+	questionIdentifier string) (
+	amtgen.TxsdGetFileUploadURLResponse, error) {
+	var response amtgen.TxsdGetFileUploadURLResponse
+	return response, unsupported("GetFileUploadURL")
+}
+
+// GetHIT retrieves the details of the specified HIT.
+func (client *jsonClient) GetHIT(hitId string) (
+	amtgen.TxsdGetHITResponse, error) {
+	return client.GetHITCtx(context.Background(), hitId)
+}
+
+// GetHITCtx is like GetHIT, but the request is bound to ctx.
+func (client *jsonClient) GetHITCtx(ctx context.Context, hitId string) (
+	amtgen.TxsdGetHITResponse, error) {
+
+	var (
+		response amtgen.TxsdGetHITResponse
+		output   struct{ HIT jsonHIT }
+	)
+	requestId, err := client.call(ctx, "GetHIT", jsonArgs{"HITId": hitId},
+		&output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		response.Hits = append(response.Hits, output.HIT.view())
+	}
+	return response, err
+}
+
+// GetHITsForQualificationType returns the HITs that use the given Qualification
+// type for a Qualification requirement.
+func (client *jsonClient) GetHITsForQualificationType(qualificationTypeId string,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetHITsForQualificationTypeResponse, error) {
+	return client.GetHITsForQualificationTypeCtx(context.Background(),
+		qualificationTypeId, pageSize, pageNumber)
+}
+
+// GetHITsForQualificationTypeCtx is like GetHITsForQualificationType, but the
+// request is bound to ctx. It calls ListHITsForQualificationType.
+func (client *jsonClient) GetHITsForQualificationTypeCtx(ctx context.Context,
+	qualificationTypeId string,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetHITsForQualificationTypeResponse, error) {
+
+	var (
+		response amtgen.TxsdGetHITsForQualificationTypeResponse
+		output   struct {
+			jsonPage
+			HITs []jsonHIT
+		}
+	)
+	args := jsonArgs{"QualificationTypeId": qualificationTypeId}
+	requestId, number, total, err := client.listPage(ctx,
+		"ListHITsForQualificationType", args, pageSize, pageNumber, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TGetHITsForQualificationTypeResult{}
+		result.Request = jsonValidRequest()
+		result.PageNumber = xsdt.Int(number)
+		result.NumResults = xsdt.Int(len(output.HITs))
+		result.TotalNumResults = xsdt.Int(total)
+		result.Hits = hitsFromJSON(output.HITs)
+		response.GetHITsForQualificationTypeResults = append(
+			response.GetHITsForQualificationTypeResults, result)
+	}
+	return response, err
+}
+
+// GetQualificationsForQualificationType returns all of the Qualifications
+// granted to Workers for a given Qualification type.
+func (client *jsonClient) GetQualificationsForQualificationType(
+	qualificationTypeId string, isGranted bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetQualificationsForQualificationTypeResponse, error) {
+	return client.GetQualificationsForQualificationTypeCtx(context.Background(),
+		qualificationTypeId, isGranted, pageSize, pageNumber)
+}
+
+// GetQualificationsForQualificationTypeCtx is like
+// GetQualificationsForQualificationType, but the request is bound to ctx. It
+// calls ListWorkersWithQualificationType.
+func (client *jsonClient) GetQualificationsForQualificationTypeCtx(ctx context.Context,
+	qualificationTypeId string, isGranted bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetQualificationsForQualificationTypeResponse, error) {
+
+	var (
+		response amtgen.TxsdGetQualificationsForQualificationTypeResponse
+		output   struct {
+			jsonPage
+			Qualifications []jsonQualification
+		}
+	)
+	args := jsonArgs{"QualificationTypeId": qualificationTypeId}
+	if isGranted {
+		args["Status"] = "Granted"
+	} else {
+		args["Status"] = "Revoked"
+	}
+	requestId, number, total, err := client.listPage(ctx,
+		"ListWorkersWithQualificationType", args, pageSize, pageNumber,
+		&output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TGetQualificationsForQualificationTypeResult{}
+		result.Request = jsonValidRequest()
+		result.PageNumber = xsdt.Int(number)
+		result.NumResults = xsdt.Int(len(output.Qualifications))
+		result.TotalNumResults = xsdt.Int(total)
+		for i := range output.Qualifications {
+			result.Qualifications = append(result.Qualifications,
+				output.Qualifications[i].view())
+		}
+		response.GetQualificationsForQualificationTypeResults = append(
+			response.GetQualificationsForQualificationTypeResults, result)
+	}
+	return response, err
+}
+
+// GetQualificationRequests retrieves requests for Qualifications of a
+// given Qualification type, or of any type you own.
+func (client *jsonClient) GetQualificationRequests(
+	qualificationTypeId, sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetQualificationRequestsResponse, error) {
+	return client.GetQualificationRequestsCtx(context.Background(),
+		qualificationTypeId, sortProperty, sortAscending, pageSize, pageNumber)
+}
+
+// GetQualificationRequestsCtx is like GetQualificationRequests, but the
+// request is bound to ctx. It calls ListQualificationRequests, which does not
+// support sorting, so sortProperty and sortAscending are ignored.
+func (client *jsonClient) GetQualificationRequestsCtx(ctx context.Context,
+	qualificationTypeId, sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetQualificationRequestsResponse, error) {
+
+	var (
+		response amtgen.TxsdGetQualificationRequestsResponse
+		output   struct {
+			jsonPage
+			QualificationRequests []jsonQualificationRequest
+		}
+	)
+	args := jsonArgs{}
+	args.set("QualificationTypeId", qualificationTypeId)
+	requestId, number, total, err := client.listPage(ctx,
+		"ListQualificationRequests", args, pageSize, pageNumber, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TGetQualificationRequestsResult{}
+		result.Request = jsonValidRequest()
+		result.PageNumber = xsdt.Int(number)
+		result.NumResults = xsdt.Int(len(output.QualificationRequests))
+		result.TotalNumResults = xsdt.Int(total)
+		for i := range output.QualificationRequests {
+			result.QualificationRequests = append(result.QualificationRequests,
+				output.QualificationRequests[i].view())
+		}
+		response.GetQualificationRequestsResults = append(
+			response.GetQualificationRequestsResults, result)
+	}
+	return response, err
+}
+
+// GetQualificationScore returns the value of a Worker's Qualification for a
+// given Qualification type.
+func (client *jsonClient) GetQualificationScore(
+	qualificationTypeId, subjectId string) (
+	amtgen.TxsdGetQualificationScoreResponse, error) {
+	return client.GetQualificationScoreCtx(context.Background(),
+		qualificationTypeId, subjectId)
+}
+
+// GetQualificationScoreCtx is like GetQualificationScore, but the request is
+// bound to ctx.
+func (client *jsonClient) GetQualificationScoreCtx(ctx context.Context,
+	qualificationTypeId, subjectId string) (
+	amtgen.TxsdGetQualificationScoreResponse, error) {
+
+	var (
+		response amtgen.TxsdGetQualificationScoreResponse
+		output   struct{ Qualification jsonQualification }
+	)
+	args := jsonArgs{
+		"QualificationTypeId": qualificationTypeId,
+		"WorkerId":            subjectId,
+	}
+	requestId, err := client.call(ctx, "GetQualificationScore", args, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		response.Qualifications = append(response.Qualifications,
+			output.Qualification.view())
+	}
+	return response, err
+}
+
+// GetQualificationType retrieves information about a Qualification type using
+// its ID.
+func (client *jsonClient) GetQualificationType(qualificationTypeId string) (
+	amtgen.TxsdGetQualificationTypeResponse, error) {
+	return client.GetQualificationTypeCtx(context.Background(),
+		qualificationTypeId)
+}
+
+// GetQualificationTypeCtx is like GetQualificationType, but the request is
+// bound to ctx.
+func (client *jsonClient) GetQualificationTypeCtx(ctx context.Context,
+	qualificationTypeId string) (
+	amtgen.TxsdGetQualificationTypeResponse, error) {
+
+	var (
+		response amtgen.TxsdGetQualificationTypeResponse
+		output   struct{ QualificationType jsonQualificationType }
+	)
+	args := jsonArgs{"QualificationTypeId": qualificationTypeId}
+	requestId, err := client.call(ctx, "GetQualificationType", args, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		response.QualificationTypes = append(response.QualificationTypes,
+			output.QualificationType.view())
+	}
+	return response, err
+}
+
+// GetRequesterStatistic retrieves statistics about you (the Requester calling
+// the operation).
+func (client *jsonClient) GetRequesterStatistic(statistic, timePeriod string,
+	count int) (amtgen.TxsdGetRequesterStatisticResponse, error) {
+	return client.GetRequesterStatisticCtx(context.Background(), statistic,
+		timePeriod, count)
+}
+
+// GetRequesterStatisticCtx is like GetRequesterStatistic, but the request is
+// bound to ctx. The JSON API has no equivalent, so it always fails.
+func (client *jsonClient) GetRequesterStatisticCtx(ctx context.Context,
+	statistic, timePeriod string, count int) (
+	amtgen.TxsdGetRequesterStatisticResponse, error) {
+	var response amtgen.TxsdGetRequesterStatisticResponse
+	return response, unsupported("GetRequesterStatistic")
+}
+
+// GetRequesterWorkerStatistic retrieves statistics about a specific Worker who
+// has completed Human Intelligence Tasks (HITs) for you.
+func (client *jsonClient) GetRequesterWorkerStatistic(statistic, workerId,
+	timePeriod string, count int) (amtgen.TxsdGetRequesterWorkerStatisticResponse, error) {
+	return client.GetRequesterWorkerStatisticCtx(context.Background(),
+		statistic, workerId, timePeriod, count)
+}
+
+// GetRequesterWorkerStatisticCtx is like GetRequesterWorkerStatistic, but the
+// request is bound to ctx. The JSON API has no equivalent, so it always
+// fails.
+func (client *jsonClient) GetRequesterWorkerStatisticCtx(ctx context.Context,
+	statistic, workerId, timePeriod string, count int) (
+	amtgen.TxsdGetRequesterWorkerStatisticResponse, error) {
+	var response amtgen.TxsdGetRequesterWorkerStatisticResponse
+	return response, unsupported("GetRequesterWorkerStatistic")
+}
+
+// GetReviewableHITs retrieves the HITs with Status equal to Reviewable or
+// Status equal to Reviewing that belong to the Requester calling the operation.
+func (client *jsonClient) GetReviewableHITs(hitTypeId, status,
+	sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (amtgen.TxsdGetReviewableHITsResponse, error) {
+	return client.GetReviewableHITsCtx(context.Background(), hitTypeId, status,
+		sortProperty, sortAscending, pageSize, pageNumber)
+}
+
+// GetReviewableHITsCtx is like GetReviewableHITs, but the request is bound to
+// ctx. It calls ListReviewableHITs, which does not support sorting, so
+// sortProperty and sortAscending are ignored.
+func (client *jsonClient) GetReviewableHITsCtx(ctx context.Context,
+	hitTypeId, status, sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetReviewableHITsResponse, error) {
+
+	var (
+		response amtgen.TxsdGetReviewableHITsResponse
+		output   struct {
+			jsonPage
+			HITs []jsonHIT
+		}
+	)
+	args := jsonArgs{}
+	args.set("HITTypeId", hitTypeId)
+	args.set("Status", status)
+	requestId, number, total, err := client.listPage(ctx, "ListReviewableHITs",
+		args, pageSize, pageNumber, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TGetReviewableHITsResult{}
+		result.Request = jsonValidRequest()
+		result.PageNumber = xsdt.Int(number)
+		result.NumResults = xsdt.Int(len(output.HITs))
+		result.TotalNumResults = xsdt.Int(total)
+		result.Hits = hitsFromJSON(output.HITs)
+		response.GetReviewableHITsResults = append(
+			response.GetReviewableHITsResults, result)
+	}
+	return response, err
+}
+
+// GetReviewResultsForHIT retrieves the computed results and the actions taken
+// in the course of executing your Review Policies during a CreateHIT operation.
+func (client *jsonClient) GetReviewResultsForHIT(hitId string,
+	policyLevels []string,
+	retrieveActions, retrieveResults bool,
+	pageSize, pageNumber int) (amtgen.TxsdGetReviewResultsForHITResponse, error) {
+	return client.GetReviewResultsForHITCtx(context.Background(), hitId,
+		policyLevels, retrieveActions, retrieveResults, pageSize, pageNumber)
+}
+
+// GetReviewResultsForHITCtx is like GetReviewResultsForHIT, but the request
+// is bound to ctx. The JSON API reports review results in a different shape,
+// which this client does not translate, so it always fails.
+func (client *jsonClient) GetReviewResultsForHITCtx(ctx context.Context,
+	hitId string, policyLevels []string,
+	retrieveActions, retrieveResults bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetReviewResultsForHITResponse, error) {
+	var response amtgen.TxsdGetReviewResultsForHITResponse
+	return response, unsupported("GetReviewResultsForHIT")
+}
+
+// GrantBonus issues a payment of money from your account to a Worker.
+func (client *jsonClient) GrantBonus(workerId, assignmentId string,
+	bonusAmount float32, reason, uniqueRequestToken string) (
+	amtgen.TxsdGrantBonusResponse, error) {
+	return client.GrantBonusCtx(context.Background(), workerId, assignmentId,
+		bonusAmount, reason, uniqueRequestToken)
+}
+
+// GrantBonusCtx is like GrantBonus, but the request is bound to ctx. It calls
+// SendBonus.
+func (client *jsonClient) GrantBonusCtx(ctx context.Context,
+	workerId, assignmentId string,
+	bonusAmount float32, reason, uniqueRequestToken string) (
+	amtgen.TxsdGrantBonusResponse, error) {
+
+	var response amtgen.TxsdGrantBonusResponse
+	args := jsonArgs{
+		"WorkerId":     workerId,
+		"AssignmentId": assignmentId,
+		"BonusAmount":  jsonAmount(bonusAmount),
+		"Reason":       reason,
+	}
+	args.set("UniqueRequestToken", uniqueRequestToken)
+	requestId, err := client.call(ctx, "SendBonus", args, &struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TGrantBonusResult{}
+		result.Request = jsonValidRequest()
+		response.GrantBonusResults = append(response.GrantBonusResults, result)
+	}
+	return response, err
+}
+
+// GrantQualification grants a user's request for a Qualification.
+func (client *jsonClient) GrantQualification(qualificationRequestId string,
+	integerValue int) (
+	amtgen.TxsdGrantQualificationResponse, error) {
+	return client.GrantQualificationCtx(context.Background(),
+		qualificationRequestId, integerValue)
+}
+
+// GrantQualificationCtx is like GrantQualification, but the request is bound
+// to ctx. It calls AcceptQualificationRequest.
+func (client *jsonClient) GrantQualificationCtx(ctx context.Context,
+	qualificationRequestId string,
+	integerValue int) (
+	amtgen.TxsdGrantQualificationResponse, error) {
+
+	var response amtgen.TxsdGrantQualificationResponse
+	args := jsonArgs{
+		"QualificationRequestId": qualificationRequestId,
+		"IntegerValue":           integerValue,
+	}
+	requestId, err := client.call(ctx, "AcceptQualificationRequest", args,
+		&struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TGrantQualificationResult{}
+		result.Request = jsonValidRequest()
+		response.GrantQualificationResults = append(
+			response.GrantQualificationResults, result)
+	}
+	return response, err
+}
+
+// NotifyWorkers sends an email to one or more Workers that you specify with
+// the Worker ID.
+func (client *jsonClient) NotifyWorkers(subject, messageText string,
+	workerIds []string) (amtgen.TxsdNotifyWorkersResponse, error) {
+	return client.NotifyWorkersCtx(context.Background(), subject, messageText,
+		workerIds)
+}
+
+// NotifyWorkersCtx is like NotifyWorkers, but the request is bound to ctx.
+func (client *jsonClient) NotifyWorkersCtx(ctx context.Context,
+	subject, messageText string, workerIds []string) (
+	amtgen.TxsdNotifyWorkersResponse, error) {
+
+	var (
+		response amtgen.TxsdNotifyWorkersResponse
+		output   struct {
+			NotifyWorkersFailureStatuses []jsonNotifyWorkersFailureStatus
+		}
+	)
+	args := jsonArgs{
+		"Subject":     subject,
+		"MessageText": messageText,
+		"WorkerIds":   workerIds,
+	}
+	requestId, err := client.call(ctx, "NotifyWorkers", args, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TNotifyWorkersResult{}
+		result.Request = jsonValidRequest()
+		for _, f := range output.NotifyWorkersFailureStatuses {
+			status := &amtgen.TNotifyWorkersFailureStatus{}
+			status.NotifyWorkersFailureCode = amtgen.TNotifyWorkersFailureCode(
+				f.NotifyWorkersFailureCode)
+			status.NotifyWorkersFailureMessage = xsdt.String(
+				f.NotifyWorkersFailureMessage)
+			status.WorkerId = xsdt.String(f.WorkerId)
+			result.NotifyWorkersFailureStatuses = append(
+				result.NotifyWorkersFailureStatuses, status)
+		}
+		response.NotifyWorkersResults = append(response.NotifyWorkersResults,
+			result)
+	}
+	return response, err
+}
+
+// RegisterHITType creates a new HIT type.
+func (client *jsonClient) RegisterHITType(title, description string,
+	reward float32, assignmentDurationInSeconds, autoApprovalDelayInSeconds int,
+	keywords []string,
+	qualificationRequirements []*amtgen.TQualificationRequirement) (
+	amtgen.TxsdRegisterHITTypeResponse, error) {
+	return client.RegisterHITTypeCtx(context.Background(), title, description,
+		reward, assignmentDurationInSeconds, autoApprovalDelayInSeconds,
+		keywords, qualificationRequirements)
+}
+
+// RegisterHITTypeCtx is like RegisterHITType, but the request is bound to ctx.
+func (client *jsonClient) RegisterHITTypeCtx(ctx context.Context,
+	title, description string,
+	reward float32, assignmentDurationInSeconds, autoApprovalDelayInSeconds int,
+	keywords []string,
+	qualificationRequirements []*amtgen.TQualificationRequirement) (
+	amtgen.TxsdRegisterHITTypeResponse, error) {
+
+	// Prepare the request
+	var (
+		args amtgen.TRegisterHITTypeRequest
+	)
+	args.Title = xsdt.String(title)
+	args.Description = xsdt.String(description)
+	args.Reward = &amtgen.TPrice{}
+	args.Reward.Amount = xsdt.Decimal(fmt.Sprint(reward))
+	args.Reward.CurrencyCode = CURRENCY_USD
+	args.AssignmentDurationInSeconds = xsdt.Long(assignmentDurationInSeconds)
+	args.AutoApprovalDelayInSeconds = xsdt.Long(autoApprovalDelayInSeconds)
+	args.Keywords = xsdt.String(strings.Join(keywords, ","))
+	args.QualificationRequirements = qualificationRequirements
+
+	return client.RegisterHITTypeFromArgsCtx(ctx, args)
+}
+
+// RegisterHITTypeFromArgs creates a new HIT type from the given argument
+// values.
+func (client *jsonClient) RegisterHITTypeFromArgs(args amtgen.TRegisterHITTypeRequest) (
+	amtgen.TxsdRegisterHITTypeResponse, error) {
+	return client.RegisterHITTypeFromArgsCtx(context.Background(), args)
+}
+
+// RegisterHITTypeFromArgsCtx is like RegisterHITTypeFromArgs, but the
+// request is bound to ctx. It calls CreateHITType.
+func (client *jsonClient) RegisterHITTypeFromArgsCtx(ctx context.Context,
+	args amtgen.TRegisterHITTypeRequest) (
+	amtgen.TxsdRegisterHITTypeResponse, error) {
+
+	var (
+		response amtgen.TxsdRegisterHITTypeResponse
+		input    = jsonArgs{}
+		output   struct{ HITTypeId string }
+	)
+	input.set("Title", string(args.Title))
+	input.set("Description", string(args.Description))
+	if args.Reward != nil {
+		input.set("Reward", string(args.Reward.Amount))
+	}
+	input.set("AssignmentDurationInSeconds",
+		int64(args.AssignmentDurationInSeconds))
+	input.set("AutoApprovalDelayInSeconds",
+		int64(args.AutoApprovalDelayInSeconds))
+	input.set("Keywords", string(args.Keywords))
+	input.set("QualificationRequirements",
+		requirementsToJSON(args.QualificationRequirements))
+	requestId, err := client.call(ctx, "CreateHITType", input, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TRegisterHITTypeResult{}
+		result.Request = jsonValidRequest()
+		result.HITTypeId = xsdt.String(output.HITTypeId)
+		response.RegisterHITTypeResults = append(
+			response.RegisterHITTypeResults, result)
+	}
+	return response, err
+}
+
+// RejectAssignment rejects the results of a completed assignment.
+func (client *jsonClient) RejectAssignment(assignmentId,
+	requesterFeedback string) (
+	amtgen.TxsdRejectAssignmentResponse, error) {
+	return client.RejectAssignmentCtx(context.Background(), assignmentId,
+		requesterFeedback)
+}
+
+// RejectAssignmentCtx is like RejectAssignment, but the request is bound to
+// ctx. The JSON API requires requesterFeedback.
+func (client *jsonClient) RejectAssignmentCtx(ctx context.Context, assignmentId,
+	requesterFeedback string) (
+	amtgen.TxsdRejectAssignmentResponse, error) {
+
+	var response amtgen.TxsdRejectAssignmentResponse
+	args := jsonArgs{
+		"AssignmentId":      assignmentId,
+		"RequesterFeedback": requesterFeedback,
+	}
+	requestId, err := client.call(ctx, "RejectAssignment", args, &struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TRejectAssignmentResult{}
+		result.Request = jsonValidRequest()
+		response.RejectAssignmentResults = append(
+			response.RejectAssignmentResults, result)
+	}
+	return response, err
+}
+
+// RejectQualificationRequest rejects a user's request for a Qualification.
+func (client *jsonClient) RejectQualificationRequest(qualificationRequestId,
+	reason string) (
+	amtgen.TxsdRejectQualificationRequestResponse, error) {
+	return client.RejectQualificationRequestCtx(context.Background(),
+		qualificationRequestId, reason)
+}
+
+// RejectQualificationRequestCtx is like RejectQualificationRequest, but the
+// request is bound to ctx.
+func (client *jsonClient) RejectQualificationRequestCtx(ctx context.Context,
+	qualificationRequestId, reason string) (
+	amtgen.TxsdRejectQualificationRequestResponse, error) {
+
+	var response amtgen.TxsdRejectQualificationRequestResponse
+	args := jsonArgs{"QualificationRequestId": qualificationRequestId}
+	args.set("Reason", reason)
+	requestId, err := client.call(ctx, "RejectQualificationRequest", args,
+		&struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TRejectQualificationRequestResult{}
+		result.Request = jsonValidRequest()
+		response.RejectQualificationRequestResults = append(
+			response.RejectQualificationRequestResults, result)
+	}
+	return response, err
+}
+
+// RevokeQualification revokes a previously granted Qualification from a user.
+func (client *jsonClient) RevokeQualification(subjectId, qualificationTypeId,
+	reason string) (
+	amtgen.TxsdRevokeQualificationResponse, error) {
+	return client.RevokeQualificationCtx(context.Background(), subjectId,
+		qualificationTypeId, reason)
+}
+
+// RevokeQualificationCtx is like RevokeQualification, but the request is
+// bound to ctx. It calls DisassociateQualificationFromWorker.
+func (client *jsonClient) RevokeQualificationCtx(ctx context.Context,
+	subjectId, qualificationTypeId, reason string) (
+	amtgen.TxsdRevokeQualificationResponse, error) {
+
+	var response amtgen.TxsdRevokeQualificationResponse
+	args := jsonArgs{
+		"WorkerId":            subjectId,
+		"QualificationTypeId": qualificationTypeId,
+	}
+	args.set("Reason", reason)
+	requestId, err := client.call(ctx, "DisassociateQualificationFromWorker",
+		args, &struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TRevokeQualificationResult{}
+		result.Request = jsonValidRequest()
+		response.RevokeQualificationResults = append(
+			response.RevokeQualificationResults, result)
+	}
+	return response, err
+}
+
+// SearchHITs returns all of a Requester's HITs, on behalf of the Requester.
+func (client *jsonClient) SearchHITs(sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdSearchHITsResponse, error) {
+	return client.SearchHITsCtx(context.Background(), sortProperty,
+		sortAscending, pageSize, pageNumber)
+}
+
+// SearchHITsCtx is like SearchHITs, but the request is bound to ctx. It calls
+// ListHITs, which does not support sorting, so sortProperty and
+// sortAscending are ignored.
+func (client *jsonClient) SearchHITsCtx(ctx context.Context,
+	sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdSearchHITsResponse, error) {
+
+	var (
+		response amtgen.TxsdSearchHITsResponse
+		output   struct {
+			jsonPage
+			HITs []jsonHIT
+		}
+	)
+	requestId, number, total, err := client.listPage(ctx, "ListHITs",
+		jsonArgs{}, pageSize, pageNumber, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TSearchHITsResult{}
+		result.Request = jsonValidRequest()
+		result.PageNumber = xsdt.Int(number)
+		result.NumResults = xsdt.Int(len(output.HITs))
+		result.TotalNumResults = xsdt.Int(total)
+		result.Hits = hitsFromJSON(output.HITs)
+		response.SearchHITsResults = append(response.SearchHITsResults, result)
+	}
+	return response, err
+}
+
+// SearchQualificationTypes searches for Qualification types using the specified
+// search query, and returns a list of Qualification types.
+func (client *jsonClient) SearchQualificationTypes(
+	query, sortProperty string, sortAscending bool,
+	pageSize, pageNumber int, mustBeRequestable, mustBeOwnedByCaller bool) (
+	amtgen.TxsdSearchQualificationTypesResponse, error) {
+	return client.SearchQualificationTypesCtx(context.Background(), query,
+		sortProperty, sortAscending, pageSize, pageNumber, mustBeRequestable,
+		mustBeOwnedByCaller)
+}
+
+// SearchQualificationTypesCtx is like SearchQualificationTypes, but the
+// request is bound to ctx. It calls ListQualificationTypes, which does not
+// support sorting, so sortProperty and sortAscending are ignored.
+func (client *jsonClient) SearchQualificationTypesCtx(ctx context.Context,
+	query, sortProperty string, sortAscending bool,
+	pageSize, pageNumber int, mustBeRequestable, mustBeOwnedByCaller bool) (
+	amtgen.TxsdSearchQualificationTypesResponse, error) {
+
+	var (
+		response amtgen.TxsdSearchQualificationTypesResponse
+		output   struct {
+			jsonPage
+			QualificationTypes []jsonQualificationType
+		}
+	)
+	args := jsonArgs{"MustBeRequestable": mustBeRequestable}
+	args.set("Query", query)
+	args.set("MustBeOwnedByCaller", mustBeOwnedByCaller)
+	requestId, number, total, err := client.listPage(ctx,
+		"ListQualificationTypes", args, pageSize, pageNumber, &output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TSearchQualificationTypesResult{}
+		result.Request = jsonValidRequest()
+		result.PageNumber = xsdt.Int(number)
+		result.NumResults = xsdt.Int(len(output.QualificationTypes))
+		result.TotalNumResults = xsdt.Int(total)
+		for i := range output.QualificationTypes {
+			result.QualificationTypes = append(result.QualificationTypes,
+				output.QualificationTypes[i].view())
+		}
+		response.SearchQualificationTypesResults = append(
+			response.SearchQualificationTypesResults, result)
+	}
+	return response, err
+}
+
+// SendTestEventNotification causes Amazon Mechanical Turk to send a
+// notification message as if a HIT event occurred, according to the provided
+// notification specification.
+func (client *jsonClient) SendTestEventNotification(
+	notification *amtgen.TNotificationSpecification, testEventType string) (
+	amtgen.TxsdSendTestEventNotificationResponse, error) {
+	return client.SendTestEventNotificationCtx(context.Background(),
+		notification, testEventType)
+}
+
+// SendTestEventNotificationCtx is like SendTestEventNotification, but the
+// request is bound to ctx.
+func (client *jsonClient) SendTestEventNotificationCtx(ctx context.Context,
+	notification *amtgen.TNotificationSpecification, testEventType string) (
+	amtgen.TxsdSendTestEventNotificationResponse, error) {
+
+	var response amtgen.TxsdSendTestEventNotificationResponse
+	args := jsonArgs{
+		"Notification":  notificationToJSON(notification),
+		"TestEventType": testEventType,
+	}
+	requestId, err := client.call(ctx, "SendTestEventNotification", args,
+		&struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TSendTestEventNotificationResult{}
+		result.Request = jsonValidRequest()
+		response.SendTestEventNotificationResults = append(
+			response.SendTestEventNotificationResults, result)
+	}
+	return response, err
+}
+
+// SetHITAsReviewing updates the status of a HIT. If the status is Reviewable,
+// this operation updates the status to Reviewing, or reverts a Reviewing HIT
+// back to the Reviewable status.
+func (client *jsonClient) SetHITAsReviewing(hitID string, revert bool) (
+	amtgen.TxsdSetHITAsReviewingResponse, error) {
+	return client.SetHITAsReviewingCtx(context.Background(), hitID, revert)
+}
+
+// SetHITAsReviewingCtx is like SetHITAsReviewing, but the request is bound
+// to ctx. It calls UpdateHITReviewStatus.
+func (client *jsonClient) SetHITAsReviewingCtx(ctx context.Context,
+	hitID string, revert bool) (
+	amtgen.TxsdSetHITAsReviewingResponse, error) {
+
+	var response amtgen.TxsdSetHITAsReviewingResponse
+	args := jsonArgs{"HITId": hitID, "Revert": revert}
+	requestId, err := client.call(ctx, "UpdateHITReviewStatus", args,
+		&struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TSetHITAsReviewingResult{}
+		result.Request = jsonValidRequest()
+		response.SetHITAsReviewingResults = append(
+			response.SetHITAsReviewingResults, result)
+	}
+	return response, err
+}
+
+// SetHITTypeNotification creates, updates, disables or re-enables notifications
+// for a HIT type.
+func (client *jsonClient) SetHITTypeNotification(hitTypeID string,
+	notification *amtgen.TNotificationSpecification, active bool) (
+	amtgen.TxsdSetHITTypeNotificationResponse, error) {
+	return client.SetHITTypeNotificationCtx(context.Background(), hitTypeID,
+		notification, active)
+}
+
+// SetHITTypeNotificationCtx is like SetHITTypeNotification, but the request
+// is bound to ctx. It calls UpdateNotificationSettings.
+func (client *jsonClient) SetHITTypeNotificationCtx(ctx context.Context,
+	hitTypeID string,
+	notification *amtgen.TNotificationSpecification, active bool) (
+	amtgen.TxsdSetHITTypeNotificationResponse, error) {
+
+	var response amtgen.TxsdSetHITTypeNotificationResponse
+	args := jsonArgs{"HITTypeId": hitTypeID, "Active": active}
+	args.set("Notification", notificationToJSON(notification))
+	requestId, err := client.call(ctx, "UpdateNotificationSettings", args,
+		&struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TSetHITTypeNotificationResult{}
+		result.Request = jsonValidRequest()
+		response.SetHITTypeNotificationResults = append(
+			response.SetHITTypeNotificationResults, result)
+	}
+	return response, err
+}
+
+// UnblockWorker allows you to reinstate a blocked Worker to work on your HITs.
+func (client *jsonClient) UnblockWorker(workerId, reason string) (
+	amtgen.TxsdUnblockWorkerResponse, error) {
+	return client.UnblockWorkerCtx(context.Background(), workerId, reason)
+}
+
+// UnblockWorkerCtx is like UnblockWorker, but the request is bound to ctx. It
+// calls DeleteWorkerBlock.
+func (client *jsonClient) UnblockWorkerCtx(ctx context.Context,
+	workerId, reason string) (
+	amtgen.TxsdUnblockWorkerResponse, error) {
+
+	var response amtgen.TxsdUnblockWorkerResponse
+	args := jsonArgs{"WorkerId": workerId}
+	args.set("Reason", reason)
+	requestId, err := client.call(ctx, "DeleteWorkerBlock", args, &struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TUnblockWorkerResult{}
+		result.Request = jsonValidRequest()
+		response.UnblockWorkerResults = append(response.UnblockWorkerResults,
+			result)
+	}
+	return response, err
+}
+
+// UpdateQualificationScore changes the value of a Qualification previously
+// granted to a Worker.
+func (client *jsonClient) UpdateQualificationScore(qualificationTypeId,
+	subjectId string, integerValue int) (
+	amtgen.TxsdUpdateQualificationScoreResponse, error) {
+	return client.UpdateQualificationScoreCtx(context.Background(),
+		qualificationTypeId, subjectId, integerValue)
+}
+
+// UpdateQualificationScoreCtx is like UpdateQualificationScore, but the
+// request is bound to ctx. It calls AssociateQualificationWithWorker.
+func (client *jsonClient) UpdateQualificationScoreCtx(ctx context.Context,
+	qualificationTypeId,
+	subjectId string, integerValue int) (
+	amtgen.TxsdUpdateQualificationScoreResponse, error) {
+
+	var response amtgen.TxsdUpdateQualificationScoreResponse
+	args := jsonArgs{
+		"QualificationTypeId": qualificationTypeId,
+		"WorkerId":            subjectId,
+		"IntegerValue":        integerValue,
+		"SendNotification":    false,
+	}
+	requestId, err := client.call(ctx, "AssociateQualificationWithWorker",
+		args, &struct{}{})
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		result := &amtgen.TUpdateQualificationScoreResult{}
+		result.Request = jsonValidRequest()
+		response.UpdateQualificationScoreResults = append(
+			response.UpdateQualificationScoreResults, result)
+	}
+	return response, err
+}
+
+// UpdateQualificationType modifies the attributes of an existing Qualification
+// type.
+func (client *jsonClient) UpdateQualificationType(qualificationTypeId string,
+	retryDelayInSeconds int, qualificationTypeStatus, description, test,
+	answerKey string, testDurationInSeconds int, autoGranted bool,
+	autoGrantedValue int) (
+	amtgen.TxsdUpdateQualificationTypeResponse, error) {
+	return client.UpdateQualificationTypeCtx(context.Background(),
+		qualificationTypeId, retryDelayInSeconds, qualificationTypeStatus,
+		description, test, answerKey, testDurationInSeconds, autoGranted,
+		autoGrantedValue)
+}
+
+// UpdateQualificationTypeCtx is like UpdateQualificationType, but the
+// request is bound to ctx.
+func (client *jsonClient) UpdateQualificationTypeCtx(ctx context.Context,
+	qualificationTypeId string,
+	retryDelayInSeconds int, qualificationTypeStatus, description, test,
+	answerKey string, testDurationInSeconds int, autoGranted bool,
+	autoGrantedValue int) (
+	amtgen.TxsdUpdateQualificationTypeResponse, error) {
+
+	var (
+		response amtgen.TxsdUpdateQualificationTypeResponse
+		output   struct{ QualificationType jsonQualificationType }
+	)
+	args := jsonArgs{"QualificationTypeId": qualificationTypeId}
+	args.set("RetryDelayInSeconds", retryDelayInSeconds)
+	args.set("QualificationTypeStatus", qualificationTypeStatus)
+	args.set("Description", description)
+	args.set("Test", test)
+	args.set("AnswerKey", answerKey)
+	args.set("TestDurationInSeconds", testDurationInSeconds)
+	if autoGranted {
+		args["AutoGranted"] = true
+		args["AutoGrantedValue"] = autoGrantedValue
+	}
+	requestId, err := client.call(ctx, "UpdateQualificationType", args,
+		&output)
+	response.OperationRequest = jsonOperationRequest(requestId)
+	if err == nil {
+		response.QualificationTypes = append(response.QualificationTypes,
+			output.QualificationType.view())
+	}
+	return response, err
+}
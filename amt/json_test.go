@@ -0,0 +1,257 @@
+package amt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	xsdt "github.com/metaleap/go-xsd/types"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A JSON API request as seen by a test server
+type jsonCall struct {
+	Target, Authorization string
+	Input                 map[string]interface{}
+}
+
+// Start a server which answers JSON API requests with the given function,
+// recording each request it receives.
+func newJSONServer(answer func(operation string,
+	input map[string]interface{}) (int, string)) (*httptest.Server, *[]jsonCall) {
+
+	var calls []jsonCall
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		call := jsonCall{
+			Target:        r.Header.Get("X-Amz-Target"),
+			Authorization: r.Header.Get("Authorization"),
+		}
+		json.Unmarshal(body, &call.Input)
+		calls = append(calls, call)
+		status, out := answer(strings.TrimPrefix(call.Target, jsonTargetPrefix),
+			call.Input)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.Header().Set("X-Amzn-RequestId", "REQ1")
+		w.WriteHeader(status)
+		fmt.Fprint(w, out)
+	}))
+	return srv, &calls
+}
+
+func newJSONTestClient(url string) *jsonClient {
+	return &jsonClient{
+		AWSAccessKeyId: FAKE_ACCESS_KEY,
+		SecretKey:      FAKE_SECRET_KEY,
+		Endpoint:       url,
+		Region:         JSON_REGION,
+		Retry: &RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+			Multiplier:     2,
+		},
+	}
+}
+
+func TestSignV4(t *testing.T) {
+	Convey("Given the AWS get-vanilla test request", t, func() {
+		req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+		now, _ := time.Parse(SIGV4_TIME_FORMAT, "20150830T123600Z")
+		signV4(req, nil, "AKIDEXAMPLE",
+			"wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "service",
+			now)
+
+		Convey("Then it carries the expected signature", func() {
+			So(req.Header.Get("X-Amz-Date"), ShouldEqual, "20150830T123600Z")
+			So(req.Header.Get("Authorization"), ShouldEqual,
+				"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+					"SignedHeaders=host;x-amz-date, "+
+					"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31")
+		})
+	})
+}
+
+func TestJSONClient(t *testing.T) {
+	Convey("Given a JSON API server", t, func() {
+		srv, calls := newJSONServer(func(operation string,
+			input map[string]interface{}) (int, string) {
+			switch operation {
+			case "CreateHIT", "CreateHITWithHITType", "GetHIT":
+				return 200, `{"HIT": {"HITId": "HIT1", "Title": "Title",
+					"Reward": "0.25", "CreationTime": 1.4886e9,
+					"Expiration": 1.4887e9,
+					"QualificationRequirements": [{"QualificationTypeId": "Q1",
+						"Comparator": "GreaterThan", "IntegerValues": [95]}]}}`
+			case "GetAccountBalance":
+				return 200, `{"AvailableBalance": "10000.00"}`
+			case "UpdateExpirationForHIT":
+				return 200, `{}`
+			case "ListHITs":
+				switch input["NextToken"] {
+				case nil:
+					return 200, `{"NextToken": "T2", "NumResults": 2,
+						"HITs": [{"HITId": "HIT1"}, {"HITId": "HIT2"}]}`
+				case "T2":
+					return 200, `{"NextToken": "T3", "NumResults": 2,
+						"HITs": [{"HITId": "HIT3"}, {"HITId": "HIT4"}]}`
+				default:
+					return 200, `{"NumResults": 1, "HITs": [{"HITId": "HIT5"}]}`
+				}
+			case "DeleteHIT":
+				return 400, `{"__type": "com.amazonaws.mturk#RequestError",
+					"Message": "Hit HIT1 does not exist.",
+					"TurkErrorCode": "AWS.MechanicalTurk.HITDoesNotExist"}`
+			default:
+				return 500, `{"__type": "com.amazonaws.mturk#ServiceFault",
+					"Message": "Oops"}`
+			}
+		})
+		defer srv.Close()
+		client := newJSONTestClient(srv.URL)
+
+		Convey("When I create a HIT", func() {
+			req := &amtgen.TQualificationRequirement{}
+			req.QualificationTypeId = "Q1"
+			req.Comparator = "GreaterThan"
+			req.IntegerValues = []xsdt.Int{95}
+			resp, err := client.CreateHIT("Title", "Description", "<Question/>",
+				"", nil, 0.25, 60, 3600, 1, 600, []string{"a", "b"},
+				[]*amtgen.TQualificationRequirement{req}, nil, nil, "", "tok")
+
+			Convey("Then the JSON operation is signed and sent", func() {
+				So(err, ShouldBeNil)
+				So(len(*calls), ShouldEqual, 1)
+				call := (*calls)[0]
+				So(call.Target, ShouldEqual, "MTurkRequesterServiceV20170117.CreateHIT")
+				So(call.Authorization, ShouldStartWith, "AWS4-HMAC-SHA256 Credential="+
+					FAKE_ACCESS_KEY+"/")
+				So(call.Input["Reward"], ShouldEqual, "0.25")
+				So(call.Input["Keywords"], ShouldEqual, "a,b")
+				So(call.Input["LifetimeInSeconds"], ShouldEqual, 3600.0)
+				So(call.Input["UniqueRequestToken"], ShouldEqual, "tok")
+				So(call.Input["QualificationRequirements"], ShouldResemble,
+					[]interface{}{map[string]interface{}{
+						"QualificationTypeId": "Q1",
+						"Comparator":          "GreaterThan",
+						"IntegerValues":       []interface{}{95.0},
+					}})
+			})
+
+			Convey("Then the output is translated into the legacy response", func() {
+				So(len(resp.Hits), ShouldEqual, 1)
+				So(string(resp.Hits[0].HITId), ShouldEqual, "HIT1")
+				So(string(resp.Hits[0].Reward.Amount), ShouldEqual, "0.25")
+				So(string(resp.Hits[0].CreationTime), ShouldEqual,
+					"2017-03-04T04:00:00Z")
+				So(int(resp.Hits[0].QualificationRequirements[0].IntegerValues[0]),
+					ShouldEqual, 95)
+				So(string(resp.OperationRequest.RequestId), ShouldEqual, "REQ1")
+			})
+		})
+
+		Convey("When I create a HIT of an existing HIT type", func() {
+			_, err := client.CreateHITFromHITTypeId("TYPE1", "<Question/>", "",
+				nil, 3600, 1, nil, nil, "", "")
+
+			Convey("Then CreateHITWithHITType is called", func() {
+				So(err, ShouldBeNil)
+				So((*calls)[0].Target, ShouldEqual,
+					"MTurkRequesterServiceV20170117.CreateHITWithHITType")
+				So((*calls)[0].Input["HITTypeId"], ShouldEqual, "TYPE1")
+			})
+		})
+
+		Convey("When I get the account balance", func() {
+			resp, err := client.GetAccountBalance()
+
+			Convey("Then the balance is returned", func() {
+				So(err, ShouldBeNil)
+				So(string(resp.GetAccountBalanceResults[0].AvailableBalance.Amount),
+					ShouldEqual, "10000.00")
+			})
+		})
+
+		Convey("When I iterate over every HIT", func() {
+			it := SearchAllHITs(client, "CreationTime", true, 2)
+			var hitIds []string
+			for it.Next() {
+				hitIds = append(hitIds, string(it.HIT().HITId))
+			}
+
+			Convey("Then each page is fetched once by following NextToken", func() {
+				So(it.Err(), ShouldBeNil)
+				So(hitIds, ShouldResemble, []string{"HIT1", "HIT2", "HIT3", "HIT4", "HIT5"})
+				So(len(*calls), ShouldEqual, 3)
+				So(it.TotalNumResults(), ShouldEqual, 5)
+			})
+		})
+
+		Convey("When I request a later page directly", func() {
+			resp, err := client.SearchHITs("", true, 2, 3)
+
+			Convey("Then the earlier pages are walked to reach it", func() {
+				So(err, ShouldBeNil)
+				So(len(*calls), ShouldEqual, 3)
+				result := resp.SearchHITsResults[0]
+				So(int(result.PageNumber), ShouldEqual, 3)
+				So(int(result.TotalNumResults), ShouldEqual, 5)
+				So(string(result.Hits[0].HITId), ShouldEqual, "HIT5")
+			})
+		})
+
+		Convey("When the server reports a request error", func() {
+			_, err := client.DisposeHIT("HIT1")
+
+			Convey("Then an APIError is returned", func() {
+				So(errors.Is(err, ErrHITNotFound), ShouldBeTrue)
+				var apiErr *APIError
+				So(errors.As(err, &apiErr), ShouldBeTrue)
+				So(apiErr.Operation, ShouldEqual, "DeleteHIT")
+				So(apiErr.RequestId, ShouldEqual, "REQ1")
+				So(len(*calls), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When I extend a HIT which has expired", func() {
+			_, err := client.ExtendHIT("HIT1", 0, 600, "")
+
+			Convey("Then it is extended from now", func() {
+				So(err, ShouldBeNil)
+				So(len(*calls), ShouldEqual, 2)
+				call := (*calls)[1]
+				So(call.Target, ShouldEqual, "MTurkRequesterServiceV20170117.UpdateExpirationForHIT")
+				expected := float64(time.Now().Unix() + 600)
+				So(call.Input["ExpireAt"], ShouldAlmostEqual, expected, 5)
+			})
+		})
+
+		Convey("When the server fails", func() {
+			_, err := client.GetAssignment("A1")
+
+			Convey("Then an HTTPError is returned after retrying", func() {
+				var httpErr *HTTPError
+				So(errors.As(err, &httpErr), ShouldBeTrue)
+				So(httpErr.StatusCode, ShouldEqual, 500)
+				So(httpErr.Code, ShouldEqual, "ServiceFault")
+				So(len(*calls), ShouldEqual, 3)
+			})
+		})
+
+		Convey("When I call an operation the JSON API lacks", func() {
+			_, err := client.GetRequesterStatistic("NumberHITsCreated",
+				"LifeToDate", 1)
+
+			Convey("Then it fails without a request", func() {
+				So(errors.Is(err, ErrUnsupportedOperation), ShouldBeTrue)
+				So(len(*calls), ShouldEqual, 0)
+			})
+		})
+	})
+}
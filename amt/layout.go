@@ -0,0 +1,273 @@
+package amt
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// A HIT layout parameter placeholder, e.g. ${image_url}
+	reLayoutParameter = regexp.MustCompile(`\$\{([A-Za-z0-9_]+)\}`)
+)
+
+// HITLayout is a local copy of a HIT layout created in the requester UI. The
+// layout's HTML refers to each parameter with a ${name} placeholder, which
+// AMT replaces with the value given to CreateHIT.
+type HITLayout struct {
+
+	// The HITLayoutId AMT assigned to the layout
+	Id string
+
+	// The layout's HTML
+	Template string
+
+	// The names of the layout's parameters, in order of first use
+	Parameters []string
+}
+
+// NewHITLayout creates a layout from its ID and HTML, finding the parameters
+// it uses.
+func NewHITLayout(id, tmpl string) *HITLayout {
+	layout := &HITLayout{Id: id, Template: tmpl}
+	seen := make(map[string]bool)
+	for _, match := range reLayoutParameter.FindAllStringSubmatch(tmpl, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			layout.Parameters = append(layout.Parameters, match[1])
+		}
+	}
+	return layout
+}
+
+// LayoutParameterError reports a set of parameters which does not match the
+// placeholders of a HIT layout.
+type LayoutParameterError struct {
+	LayoutId string
+
+	// Parameters the layout uses which were not given a value
+	Missing []string
+
+	// Parameters given a value which the layout does not use
+	Unexpected []string
+}
+
+func (err *LayoutParameterError) Error() string {
+	var problems []string
+	if len(err.Missing) > 0 {
+		problems = append(problems, "missing "+strings.Join(err.Missing, ", "))
+	}
+	if len(err.Unexpected) > 0 {
+		problems = append(problems, "unexpected "+strings.Join(err.Unexpected, ", "))
+	}
+	return fmt.Sprintf("Parameters do not match HIT layout %s: %s", err.LayoutId,
+		strings.Join(problems, "; "))
+}
+
+// Validate checks that a set of parameters gives a value, which may be
+// empty, to each of the layout's parameters and to nothing else. It returns a
+// *LayoutParameterError if not.
+func (layout *HITLayout) Validate(params map[string]string) error {
+	err := &LayoutParameterError{LayoutId: layout.Id}
+	used := make(map[string]bool)
+	for _, name := range layout.Parameters {
+		used[name] = true
+		if _, ok := params[name]; !ok {
+			err.Missing = append(err.Missing, name)
+		}
+	}
+	for name := range params {
+		if !used[name] {
+			err.Unexpected = append(err.Unexpected, name)
+		}
+	}
+	if len(err.Missing) > 0 || len(err.Unexpected) > 0 {
+		sort.Strings(err.Unexpected)
+		return err
+	}
+	return nil
+}
+
+// EscapeParameters validates a set of parameters and returns them ready to
+// pass to CreateHIT. AMT places values into the layout's HTML as they are,
+// so each is HTML-escaped: parameters hold text, such as a caption or a URL,
+// which workers then see exactly as given.
+func (layout *HITLayout) EscapeParameters(params map[string]string) (map[string]string, error) {
+	if err := layout.Validate(params); err != nil {
+		return nil, err
+	}
+	escaped := make(map[string]string, len(params))
+	for name, value := range params {
+		escaped[name] = html.EscapeString(value)
+	}
+	return escaped, nil
+}
+
+// Render validates a set of parameters and returns the layout's HTML with
+// the placeholders replaced by the escaped values, as AMT would show it.
+func (layout *HITLayout) Render(params map[string]string) (string, error) {
+	escaped, err := layout.EscapeParameters(params)
+	if err != nil {
+		return "", err
+	}
+	return reLayoutParameter.ReplaceAllStringFunc(layout.Template, func(placeholder string) string {
+		return escaped[placeholder[2:len(placeholder)-1]]
+	}), nil
+}
+
+// HITLayoutRegistry holds local copies of HIT layouts, so that parameters can
+// be checked and previewed before HITs are created from them.
+type HITLayoutRegistry struct {
+	layouts map[string]*HITLayout
+}
+
+// NewHITLayoutRegistry creates an empty registry.
+func NewHITLayoutRegistry() *HITLayoutRegistry {
+	return &HITLayoutRegistry{layouts: make(map[string]*HITLayout)}
+}
+
+// LoadHITLayouts creates a registry from a directory holding a file of HTML
+// for each layout, named by its HITLayoutId with any extension, e.g.
+// "3MCDHXBQ4Z7SJ2ZT2XZACNE142JWN5.html".
+func LoadHITLayouts(dir string) (*HITLayoutRegistry, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	registry := NewHITLayoutRegistry()
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		tmpl, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		id := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		registry.Register(NewHITLayout(id, string(tmpl)))
+	}
+	return registry, nil
+}
+
+// Register adds a layout to the registry, replacing any with the same ID.
+func (registry *HITLayoutRegistry) Register(layout *HITLayout) {
+	registry.layouts[layout.Id] = layout
+}
+
+// Layout returns the layout with an ID, and whether there is one.
+func (registry *HITLayoutRegistry) Layout(id string) (*HITLayout, bool) {
+	layout, ok := registry.layouts[id]
+	return layout, ok
+}
+
+// Find a layout, or report that it is not registered.
+func (registry *HITLayoutRegistry) lookup(id string) (*HITLayout, error) {
+	if layout, ok := registry.layouts[id]; ok {
+		return layout, nil
+	}
+	return nil, fmt.Errorf("Unknown HIT layout %s", id)
+}
+
+// Validate checks a set of parameters against a registered layout.
+func (registry *HITLayoutRegistry) Validate(layoutId string, params map[string]string) error {
+	layout, err := registry.lookup(layoutId)
+	if err != nil {
+		return err
+	}
+	return layout.Validate(params)
+}
+
+// Preview returns a registered layout's HTML for a set of parameters.
+func (registry *HITLayoutRegistry) Preview(layoutId string, params map[string]string) (string, error) {
+	layout, err := registry.lookup(layoutId)
+	if err != nil {
+		return "", err
+	}
+	return layout.Render(params)
+}
+
+// ReadBatchRows reads the rows of a batch of HITs from a CSV file, as
+// uploaded to the requester UI to create a batch: a header row names the
+// columns, such as a layout's parameters, and each following row gives their
+// values for one HIT.
+func ReadBatchRows(r io.Reader) ([]map[string]string, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Could not read CSV: %v", err)
+	} else if len(records) == 0 {
+		return nil, fmt.Errorf("The CSV has no header row")
+	}
+	header := records[0]
+	var rows []map[string]string
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			row[strings.TrimSpace(name)] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// CreateHITs creates a HIT of the given HIT type from a registered layout for
+// each set of parameters, as a batch uploaded to the requester UI would, and
+// returns their HITIds in order. Every set is validated before any HIT is
+// created. If a request fails, the HITIds of the HITs already created are
+// returned with the error.
+//
+// Each HIT is created with a UniqueRequestToken derived from its row and
+// parameters, so a request AMT has already carried out is not repeated when
+// retried, and creating the same rows again within 24 hours, e.g. after a
+// failure, returns the HITs already created rather than duplicating them.
+func (registry *HITLayoutRegistry) CreateHITs(client AmtClient, hitTypeId,
+	layoutId string, rows []map[string]string,
+	lifetimeInSeconds, maxAssignments int) ([]string, error) {
+
+	layout, err := registry.lookup(layoutId)
+	if err != nil {
+		return nil, err
+	}
+	var escaped []map[string]string
+	for i, row := range rows {
+		params, err := layout.EscapeParameters(row)
+		if err != nil {
+			return nil, fmt.Errorf("Row %d: %v", i+1, err)
+		}
+		escaped = append(escaped, params)
+	}
+
+	var hitIds []string
+	for i, params := range escaped {
+		token := RequestToken("CreateHIT", fmt.Sprintf("%s:%s:%d:%s", hitTypeId,
+			layoutId, i+1, parametersDigest(params)))
+		hitId, err := createHITOnce(client, hitTypeId, "", layoutId, params,
+			lifetimeInSeconds, maxAssignments, token)
+		if err != nil {
+			return hitIds, fmt.Errorf("Could not create HIT for row %d: %v", i+1, err)
+		}
+		hitIds = append(hitIds, hitId)
+	}
+	return hitIds, nil
+}
+
+// A digest of a set of parameters, for use in a request token.
+func parametersDigest(params map[string]string) string {
+	var names []string
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hash := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s\x00%s\x00", name, params[name])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,107 @@
+package amt
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const LAYOUT_TEMPLATE = `<img src="${image_url}" alt="${caption}"/><p>${caption}</p>`
+
+func TestHITLayout(t *testing.T) {
+	Convey("Given a layout with placeholders", t, func() {
+		layout := NewHITLayout("LAYOUT1", LAYOUT_TEMPLATE)
+
+		Convey("Then its parameters are found in order", func() {
+			So(layout.Parameters, ShouldResemble, []string{"image_url", "caption"})
+		})
+
+		Convey("When I render it", func() {
+			page, err := layout.Render(map[string]string{
+				"image_url": "http://example.com/a.png?w=1&h=2",
+				"caption":   `Tom & "Jerry" <b>`,
+			})
+
+			Convey("Then the values are escaped", func() {
+				So(err, ShouldBeNil)
+				So(page, ShouldEqual, `<img src="http://example.com/a.png?w=1&amp;h=2" alt="Tom &amp; &#34;Jerry&#34; &lt;b&gt;"/><p>Tom &amp; &#34;Jerry&#34; &lt;b&gt;</p>`)
+			})
+		})
+
+		Convey("When I validate parameters which do not match", func() {
+			err := layout.Validate(map[string]string{"caption": "", "width": "5", "height": "7"})
+
+			Convey("Then the mismatches are reported", func() {
+				So(err, ShouldResemble, &LayoutParameterError{
+					LayoutId:   "LAYOUT1",
+					Missing:    []string{"image_url"},
+					Unexpected: []string{"height", "width"},
+				})
+				So(err.Error(), ShouldEqual, "Parameters do not match HIT layout LAYOUT1: missing image_url; unexpected height, width")
+			})
+		})
+	})
+}
+
+func TestHITLayoutRegistry(t *testing.T) {
+	Convey("Given a directory of layouts", t, func() {
+		dir, err := ioutil.TempDir("", "amt-layouts")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		So(ioutil.WriteFile(filepath.Join(dir, "LAYOUT1.html"), []byte(LAYOUT_TEMPLATE), 0600), ShouldBeNil)
+		registry, err := LoadHITLayouts(dir)
+		So(err, ShouldBeNil)
+
+		Convey("Then the layouts are registered by ID", func() {
+			layout, ok := registry.Layout("LAYOUT1")
+			So(ok, ShouldBeTrue)
+			So(layout.Parameters, ShouldResemble, []string{"image_url", "caption"})
+			So(registry.Validate("LAYOUT2", nil).Error(), ShouldEqual, "Unknown HIT layout LAYOUT2")
+		})
+
+		Convey("When I create HITs from a CSV", func() {
+			rows, err := ReadBatchRows(strings.NewReader(
+				"image_url,caption\nhttp://example.com/1.png,One\nhttp://example.com/2.png,A & B\n"))
+			So(err, ShouldBeNil)
+			client := NewDryRunClient(nil, nil)
+			hitIds, err := registry.CreateHITs(client, "HITTYPE1", "LAYOUT1", rows, 3600, 2)
+
+			Convey("Then one HIT is created per row with escaped parameters", func() {
+				So(err, ShouldBeNil)
+				So(hitIds, ShouldHaveLength, 2)
+				calls := client.Calls()
+				So(calls, ShouldHaveLength, 2)
+				So(calls[1].Operation, ShouldEqual, "CreateHIT")
+				So(calls[1].Args.Get("HITLayoutId"), ShouldEqual, "LAYOUT1")
+				So(calls[1].Args.Get("HITLayoutParameters.1.Name"), ShouldEqual, "caption")
+				So(calls[1].Args.Get("HITLayoutParameters.1.Value"), ShouldEqual, "A &amp; B")
+			})
+
+			Convey("Then each HIT is created with its own request token", func() {
+				calls := client.Calls()
+				So(calls[0].Args.Get("UniqueRequestToken"), ShouldNotBeEmpty)
+				So(calls[1].Args.Get("UniqueRequestToken"), ShouldNotBeEmpty)
+				So(calls[1].Args.Get("UniqueRequestToken"), ShouldNotEqual,
+					calls[0].Args.Get("UniqueRequestToken"))
+			})
+		})
+
+		Convey("When a row does not match the layout", func() {
+			rows, err := ReadBatchRows(strings.NewReader(
+				"image_url,caption\nhttp://example.com/1.png,One\n"))
+			So(err, ShouldBeNil)
+			rows = append(rows, map[string]string{"image_url": "http://example.com/2.png"})
+			client := NewDryRunClient(nil, nil)
+			hitIds, err := registry.CreateHITs(client, "HITTYPE1", "LAYOUT1", rows, 3600, 2)
+
+			Convey("Then no HITs are created", func() {
+				So(err.Error(), ShouldEqual, "Row 2: Parameters do not match HIT layout LAYOUT1: missing caption")
+				So(hitIds, ShouldBeEmpty)
+				So(client.Calls(), ShouldBeEmpty)
+			})
+		})
+	})
+}
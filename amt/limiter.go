@@ -0,0 +1,183 @@
+package amt
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// The interval between requests allowed by DefaultLimiter
+	DEFAULT_REQUEST_INTERVAL = 550 * time.Millisecond
+)
+
+var (
+	// The limiters returned by SharedLimiter, by access key
+	sharedLimiters   = make(map[string]Limiter)
+	sharedLimitersMu sync.Mutex
+)
+
+// Limiter controls the rate at which a client sends requests to AMT. A
+// client waits on its limiter before every attempt at a request, including
+// retries. Implementations must be safe for concurrent use, so that one
+// limiter can be shared by several clients and goroutines.
+type Limiter interface {
+
+	// Wait blocks until a request for the given operation may be sent, or
+	// until ctx is done, in which case it returns ctx.Err(). The operation is
+	// named as the client sends it, e.g. SearchHITs for a client created by
+	// NewClient or ListHITs for one created by NewJSONClient.
+	Wait(ctx context.Context, operation string) error
+
+	// Stats reports how long requests have waited on the limiter so far.
+	Stats() LimiterStats
+}
+
+// LimiterStats summarizes the waits imposed by a Limiter, to help tune its
+// rate and burst.
+type LimiterStats struct {
+
+	// The number of requests allowed through the limiter
+	Requests int
+
+	// The number of those requests which had to wait
+	Delayed int
+
+	// The total and longest time spent waiting
+	TotalWait, MaxWait time.Duration
+}
+
+// MeanWait returns the average wait per request.
+func (stats LimiterStats) MeanWait() time.Duration {
+	if stats.Requests == 0 {
+		return 0
+	}
+	return stats.TotalWait / time.Duration(stats.Requests)
+}
+
+// TokenBucket is a Limiter which allows bursts of requests. The bucket holds
+// up to burst tokens and gains one every interval; each request takes one
+// token, or its operation's weight if one is set, and waits while the bucket
+// is empty. Waiting requests are served in the order they arrive.
+type TokenBucket struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	weights  map[string]float64
+	tokens   float64
+	last     time.Time
+	stats    LimiterStats
+}
+
+// NewTokenBucket creates a full bucket which holds up to burst tokens and
+// gains one every interval. An interval of zero does not limit requests.
+func NewTokenBucket(interval time.Duration, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		interval: interval,
+		burst:    float64(burst),
+		weights:  make(map[string]float64),
+		tokens:   float64(burst),
+	}
+}
+
+// DefaultLimiter returns the limiter used by NewClient: one request every
+// DEFAULT_REQUEST_INTERVAL, with no bursts.
+func DefaultLimiter() Limiter {
+	return NewTokenBucket(DEFAULT_REQUEST_INTERVAL, 1)
+}
+
+// SharedLimiter returns a DefaultLimiter which is shared by every caller
+// passing the same access key, so that several clients using one account
+// stay within a single budget:
+//
+//	client := NewClient(key, secret, false, WithLimiter(SharedLimiter(key)))
+func SharedLimiter(accessKeyId string) Limiter {
+	sharedLimitersMu.Lock()
+	defer sharedLimitersMu.Unlock()
+	limiter, ok := sharedLimiters[accessKeyId]
+	if !ok {
+		limiter = DefaultLimiter()
+		sharedLimiters[accessKeyId] = limiter
+	}
+	return limiter
+}
+
+// SetWeight sets the number of tokens taken by each request for an operation,
+// e.g. more than 1 for operations which AMT throttles more aggressively, or 0
+// for operations which should not be limited at all. Operations without a
+// weight take one token.
+func (bucket *TokenBucket) SetWeight(operation string, weight float64) {
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	bucket.weights[operation] = weight
+}
+
+// Wait takes the operation's tokens from the bucket, waiting for them to
+// accumulate if necessary.
+func (bucket *TokenBucket) Wait(ctx context.Context, operation string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	bucket.mu.Lock()
+	weight, ok := bucket.weights[operation]
+	if !ok {
+		weight = 1
+	}
+	bucket.refill(time.Now())
+	bucket.tokens -= weight
+	var delay time.Duration
+	if bucket.tokens < 0 {
+		delay = time.Duration(-bucket.tokens * float64(bucket.interval))
+	}
+	bucket.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+
+			// Return the reserved tokens for use by later requests
+			bucket.mu.Lock()
+			bucket.tokens += weight
+			bucket.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	bucket.stats.Requests++
+	if delay > 0 {
+		bucket.stats.Delayed++
+		bucket.stats.TotalWait += delay
+		if delay > bucket.stats.MaxWait {
+			bucket.stats.MaxWait = delay
+		}
+	}
+	return nil
+}
+
+// Stats reports the waits imposed so far.
+func (bucket *TokenBucket) Stats() LimiterStats {
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	return bucket.stats
+}
+
+// Add the tokens gained since the last refill. The caller must hold mu.
+func (bucket *TokenBucket) refill(now time.Time) {
+	if bucket.interval <= 0 {
+		bucket.tokens = bucket.burst
+	} else if !bucket.last.IsZero() {
+		bucket.tokens += float64(now.Sub(bucket.last)) / float64(bucket.interval)
+		if bucket.tokens > bucket.burst {
+			bucket.tokens = bucket.burst
+		}
+	}
+	bucket.last = now
+}
@@ -0,0 +1,122 @@
+package amt
+
+import (
+	"context"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	Convey("Given a token bucket allowing a burst of three", t, func() {
+		interval := 20 * time.Millisecond
+		bucket := NewTokenBucket(interval, 3)
+		ctx := context.Background()
+
+		Convey("When I send four requests", func() {
+			start := time.Now()
+			for i := 0; i < 4; i++ {
+				So(bucket.Wait(ctx, "GetHIT"), ShouldBeNil)
+			}
+			elapsed := time.Since(start)
+
+			Convey("Then only the fourth waits", func() {
+				So(elapsed, ShouldBeGreaterThanOrEqualTo, interval-time.Millisecond)
+				stats := bucket.Stats()
+				So(stats.Requests, ShouldEqual, 4)
+				So(stats.Delayed, ShouldEqual, 1)
+				So(stats.MaxWait, ShouldBeGreaterThan, 0)
+				So(stats.MaxWait, ShouldBeLessThanOrEqualTo, interval)
+				So(stats.TotalWait, ShouldEqual, stats.MaxWait)
+				So(stats.MeanWait(), ShouldEqual, stats.TotalWait/4)
+			})
+		})
+
+		Convey("When an operation is weighted", func() {
+			bucket.SetWeight("SearchHITs", 3)
+			bucket.SetWeight("GetAccountBalance", 0)
+			So(bucket.Wait(ctx, "SearchHITs"), ShouldBeNil)
+			So(bucket.Wait(ctx, "GetAccountBalance"), ShouldBeNil)
+			So(bucket.Wait(ctx, "SearchHITs"), ShouldBeNil)
+
+			Convey("Then it takes that many tokens", func() {
+				stats := bucket.Stats()
+				So(stats.Delayed, ShouldEqual, 1)
+				So(stats.MaxWait, ShouldBeGreaterThan, 2*interval)
+			})
+		})
+
+		Convey("When the context is cancelled during a wait", func() {
+			bucket := NewTokenBucket(time.Hour, 1)
+			So(bucket.Wait(ctx, "GetHIT"), ShouldBeNil)
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+			err := bucket.Wait(ctx, "GetHIT")
+
+			Convey("Then the wait is abandoned and its tokens returned", func() {
+				So(err, ShouldEqual, context.DeadlineExceeded)
+				So(bucket.Stats().Requests, ShouldEqual, 1)
+				So(bucket.tokens, ShouldBeGreaterThan, -0.01)
+			})
+		})
+
+		Convey("When many goroutines share it", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 6; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					bucket.Wait(ctx, "GetHIT")
+				}()
+			}
+			wg.Wait()
+
+			Convey("Then their waits are spread over the interval", func() {
+				stats := bucket.Stats()
+				So(stats.Requests, ShouldEqual, 6)
+				So(stats.Delayed, ShouldEqual, 3)
+				So(stats.MaxWait, ShouldBeGreaterThan, 2*interval)
+			})
+		})
+	})
+
+	Convey("Given clients sharing a limiter", t, func() {
+		srv := newFlakyServer(0, http.StatusOK)
+		defer srv.Close()
+		limiter := SharedLimiter("SharedLimiterTestKey")
+		client1 := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, srv.URL,
+			WithLimiter(limiter))
+		client2 := NewJSONClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, srv.URL,
+			WithLimiter(SharedLimiter("SharedLimiterTestKey")))
+
+		Convey("Then each sees the same limiter", func() {
+			bucket := limiter.(*TokenBucket)
+			So(client1.(*amtClient).Limiter.(*TokenBucket), ShouldPointTo, bucket)
+			So(client2.(*jsonClient).Limiter.(*TokenBucket), ShouldPointTo, bucket)
+			So(SharedLimiter("OtherKey").(*TokenBucket), ShouldNotPointTo, bucket)
+		})
+
+		Convey("When one of them sends requests", func() {
+			_, err := client1.GetAccountBalance()
+			So(err, ShouldBeNil)
+			_, err = client1.GetAccountBalance()
+			So(err, ShouldBeNil)
+
+			Convey("Then the requests are counted by the shared limiter", func() {
+				So(limiter.Stats().Requests, ShouldBeGreaterThanOrEqualTo, 2)
+				So(limiter.Stats().Delayed, ShouldBeGreaterThanOrEqualTo, 1)
+			})
+		})
+	})
+
+	Convey("Given a client without a limiter", t, func() {
+		client := NewClient(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, true,
+			WithLimiter(nil))
+
+		Convey("Then its requests are not limited", func() {
+			So(client.(*amtClient).Limiter, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,445 @@
+package amt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// The Content-Type of the Prometheus text exposition format
+	METRICS_CONTENT_TYPE = "text/plain; version=0.0.4; charset=utf-8"
+
+	// The error code reported for errors which did not come from AMT, such as
+	// network failures
+	METRICS_TRANSPORT_ERROR = "Transport"
+)
+
+var (
+	// The upper bounds, in seconds, of the histogram buckets used by a
+	// MetricsRegistry
+	MetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+	// The metrics exposed by a MetricsRegistry, in the order they are written
+	registryMetrics = []struct{ name, kind, help string }{
+		{"amt_requests_total", "counter", "Calls made to AMT, by operation."},
+		{"amt_request_duration_seconds", "histogram", "Time spent on each call, including retries and rate limiting."},
+		{"amt_retries_total", "counter", "Attempts made after the first, by operation."},
+		{"amt_throttle_wait_seconds", "histogram", "Time spent waiting on the rate limiter before each attempt."},
+		{"amt_errors_total", "counter", "Calls which failed, by operation and AMT error code."},
+		{"amt_spent_dollars_total", "counter", "Money committed by CreateHIT, ExtendHIT and GrantBonus calls, including commission."},
+	}
+
+	// Escapes label values in the text exposition format
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+// CallMetrics describes a call a client made to AMT, including all of its
+// attempts.
+type CallMetrics struct {
+
+	// The operation, named as the client sends it
+	Operation string
+
+	// The number of attempts made, and the time spent on all of them
+	Attempts int
+	Duration time.Duration
+
+	// The AMT error code the call failed with, "HTTP 503" for an HTTP error
+	// without one, or METRICS_TRANSPORT_ERROR. Empty if the call succeeded.
+	ErrorCode string
+
+	// The money the call committed, in dollars: the estimated cost of a HIT
+	// created, of the assignments added to a HIT, or of a bonus granted. A
+	// HIT created from a HIT type ID is costed with the reward the client
+	// saw when the HIT type was registered, and assignments added to a HIT
+	// with the reward the client saw when the HIT was created or fetched. If
+	// the client has not seen the reward, the cost is 0.
+	Spent float64
+}
+
+// Metrics receives measurements from a client. Install it with WithMetrics.
+// Implementations must be safe for concurrent use; MetricsRegistry is one.
+type Metrics interface {
+
+	// ObserveCall is called once for each call, after its last attempt.
+	ObserveCall(call CallMetrics)
+
+	// ObserveWait is called each time an attempt waits on the rate limiter.
+	ObserveWait(operation string, wait time.Duration)
+}
+
+// MetricsRegistry is a Metrics which keeps counters and histograms in memory
+// and serves them in the Prometheus text exposition format, e.g. at
+// "/metrics". A registry may be shared by several clients.
+type MetricsRegistry struct {
+	mu         sync.Mutex
+	counters   map[string]map[string]float64
+	histograms map[string]map[string]*histogram
+}
+
+// A histogram of observations in seconds
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewMetricsRegistry creates an empty registry.
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{
+		counters:   make(map[string]map[string]float64),
+		histograms: make(map[string]map[string]*histogram),
+	}
+}
+
+func (registry *MetricsRegistry) ObserveCall(call CallMetrics) {
+	operation := metricLabels("operation", call.Operation)
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.add("amt_requests_total", operation, 1)
+	registry.observe("amt_request_duration_seconds", operation, call.Duration)
+	if call.Attempts > 1 {
+		registry.add("amt_retries_total", operation, float64(call.Attempts-1))
+	}
+	if call.ErrorCode != "" {
+		registry.add("amt_errors_total", metricLabels("operation", call.Operation,
+			"code", call.ErrorCode), 1)
+	}
+	if call.Spent > 0 {
+		registry.add("amt_spent_dollars_total", operation, call.Spent)
+	}
+}
+
+func (registry *MetricsRegistry) ObserveWait(operation string, wait time.Duration) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.observe("amt_throttle_wait_seconds",
+		metricLabels("operation", operation), wait)
+}
+
+// Add to a counter. The caller must hold the lock.
+func (registry *MetricsRegistry) add(name, labels string, value float64) {
+	if registry.counters[name] == nil {
+		registry.counters[name] = make(map[string]float64)
+	}
+	registry.counters[name][labels] += value
+}
+
+// Add an observation to a histogram. The caller must hold the lock.
+func (registry *MetricsRegistry) observe(name, labels string, value time.Duration) {
+	if registry.histograms[name] == nil {
+		registry.histograms[name] = make(map[string]*histogram)
+	}
+	h := registry.histograms[name][labels]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(MetricsBuckets))}
+		registry.histograms[name][labels] = h
+	}
+	seconds := value.Seconds()
+	for i, bound := range MetricsBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// WriteTo writes every metric in the Prometheus text exposition format.
+func (registry *MetricsRegistry) WriteTo(w io.Writer) (int64, error) {
+	var buf strings.Builder
+	registry.mu.Lock()
+	for _, metric := range registryMetrics {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s %s\n", metric.name,
+			metric.help, metric.name, metric.kind)
+		if metric.kind == "counter" {
+			series := registry.counters[metric.name]
+			var keys []string
+			for labels := range series {
+				keys = append(keys, labels)
+			}
+			sort.Strings(keys)
+			for _, labels := range keys {
+				fmt.Fprintf(&buf, "%s{%s} %s\n", metric.name, labels,
+					formatMetric(series[labels]))
+			}
+			continue
+		}
+		series := registry.histograms[metric.name]
+		var keys []string
+		for labels := range series {
+			keys = append(keys, labels)
+		}
+		sort.Strings(keys)
+		for _, labels := range keys {
+			h := series[labels]
+			for i, bound := range MetricsBuckets {
+				fmt.Fprintf(&buf, "%s_bucket{%s,le=\"%s\"} %d\n", metric.name,
+					labels, formatMetric(bound), h.counts[i])
+			}
+			fmt.Fprintf(&buf, "%s_bucket{%s,le=\"+Inf\"} %d\n", metric.name,
+				labels, h.count)
+			fmt.Fprintf(&buf, "%s_sum{%s} %s\n", metric.name, labels,
+				formatMetric(h.sum))
+			fmt.Fprintf(&buf, "%s_count{%s} %d\n", metric.name, labels, h.count)
+		}
+	}
+	registry.mu.Unlock()
+	n, err := io.WriteString(w, buf.String())
+	return int64(n), err
+}
+
+// ServeHTTP serves every metric in the Prometheus text exposition format.
+func (registry *MetricsRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", METRICS_CONTENT_TYPE)
+	registry.WriteTo(w)
+}
+
+// Format a set of label names and values, e.g. `operation="GetHIT"`.
+func metricLabels(namesAndValues ...string) string {
+	var parts []string
+	for i := 0; i+1 < len(namesAndValues); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, namesAndValues[i],
+			labelEscaper.Replace(namesAndValues[i+1])))
+	}
+	return strings.Join(parts, ",")
+}
+
+// Format a sample value.
+func formatMetric(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Wait on a client's limiter, if it has one, and report the wait.
+func waitLimiter(ctx context.Context, limiter Limiter, metrics Metrics,
+	operation string) error {
+	if limiter == nil {
+		return nil
+	}
+	start := time.Now()
+	err := limiter.Wait(ctx, operation)
+	if metrics != nil {
+		metrics.ObserveWait(operation, time.Since(start))
+	}
+	return err
+}
+
+// Describe a completed call for a client's metrics, costed with the rewards
+// the client has seen.
+func callMetrics(start time.Time, operation string, args map[string]interface{},
+	attempts int, err error, rewards *rewardBook) CallMetrics {
+
+	call := CallMetrics{
+		Operation: operation,
+		Attempts:  attempts,
+		Duration:  time.Since(start),
+	}
+	var (
+		apiErr  *APIError
+		httpErr *HTTPError
+	)
+	switch {
+	case err == nil:
+		call.Spent = rewards.spentBy(operation, args)
+	case errors.As(err, &apiErr):
+		call.ErrorCode = apiErr.Code
+	case errors.As(err, &httpErr) && httpErr.Code != "":
+		call.ErrorCode = httpErr.Code
+	case errors.As(err, &httpErr):
+		call.ErrorCode = fmt.Sprintf("HTTP %d", httpErr.StatusCode)
+	default:
+		call.ErrorCode = METRICS_TRANSPORT_ERROR
+	}
+	return call
+}
+
+// The rewards a client has seen in its calls, so that it can estimate the
+// cost of calls which do not name one: creating a HIT of a registered HIT
+// type, or adding assignments to a HIT. A nil book has seen nothing.
+type rewardBook struct {
+	mu       sync.Mutex
+	hitTypes map[string]float32
+	hits     map[string]hitReward
+}
+
+// The reward of a HIT and its number of assignments
+type hitReward struct {
+	reward         float32
+	maxAssignments int
+}
+
+// Create a book which has seen no rewards.
+func newRewardBook() *rewardBook {
+	return &rewardBook{
+		hitTypes: make(map[string]float32),
+		hits:     make(map[string]hitReward),
+	}
+}
+
+// Estimate the money committed by a successful call, from its arguments as
+// sent by either client.
+func (book *rewardBook) spentBy(operation string, args map[string]interface{}) float64 {
+	switch operation {
+	case "CreateHIT", "CreateHITWithHITType":
+		reward, ok := book.argReward(args)
+		if !ok {
+			return 0
+		}
+		maxAssignments, ok := argNumber(args, "MaxAssignments")
+		if !ok {
+			maxAssignments = 1
+		}
+		return EstimateHITCost(float32(reward), int(maxAssignments)).Total()
+	case "ExtendHIT", "CreateAdditionalAssignmentsForHIT":
+		increment, _ := argNumber(args, "MaxAssignmentsIncrement",
+			"NumberOfAdditionalAssignments")
+		hit, seen := book.hitReward(fmt.Sprint(args["HITId"]))
+		if increment <= 0 || !seen {
+			return 0
+		}
+		return EstimateAssignmentCost(hit.reward, int(increment),
+			hit.maxAssignments+int(increment)).Total()
+	case "GrantBonus", "SendBonus":
+		amount, _ := argNumber(args, "BonusAmount.1.Amount", "BonusAmount")
+		return EstimateBonusCost(float32(amount)).Total()
+	}
+	return 0
+}
+
+// Remember the rewards revealed by a successful call, from its arguments
+// and its response as decoded by either client.
+func (book *rewardBook) learn(operation string, args map[string]interface{},
+	response interface{}) {
+	if book == nil {
+		return
+	}
+	switch response := response.(type) {
+	case *amtgen.TxsdRegisterHITTypeResponse:
+		if reward, ok := argNumber(args, "Reward.1.Amount"); ok {
+			for _, result := range response.RegisterHITTypeResults {
+				book.learnHITType(string(result.HITTypeId), float32(reward))
+			}
+		}
+	case *struct{ HITTypeId string }:
+		if reward, ok := argNumber(args, "Reward"); ok {
+			book.learnHITType(response.HITTypeId, float32(reward))
+		}
+	case *amtgen.TxsdCreateHITResponse:
+		reward, ok := book.argReward(args)
+		if !ok {
+			return
+		}
+		maxAssignments, ok := argNumber(args, "MaxAssignments")
+		if !ok {
+			maxAssignments = 1
+		}
+		for _, hit := range response.Hits {
+			book.learnHIT(string(hit.HITId), string(hit.HITTypeId),
+				float32(reward), int(maxAssignments))
+		}
+	case *amtgen.TxsdGetHITResponse:
+		for _, hit := range response.Hits {
+			if reward, err := priceAmount(hit.Reward); err == nil {
+				book.learnHIT(string(hit.HITId), string(hit.HITTypeId), reward,
+					int(hit.MaxAssignments))
+			}
+		}
+	case *struct{ HIT jsonHIT }:
+		reward, err := strconv.ParseFloat(response.HIT.Reward, 32)
+		if err != nil {
+			var ok bool
+			if reward, ok = book.argReward(args); !ok {
+				return
+			}
+		}
+		book.learnHIT(response.HIT.HITId, response.HIT.HITTypeId,
+			float32(reward), response.HIT.MaxAssignments)
+	}
+	switch operation {
+	case "ExtendHIT", "CreateAdditionalAssignmentsForHIT":
+		increment, _ := argNumber(args, "MaxAssignmentsIncrement",
+			"NumberOfAdditionalAssignments")
+		book.mu.Lock()
+		defer book.mu.Unlock()
+		if hit, ok := book.hits[fmt.Sprint(args["HITId"])]; ok {
+			hit.maxAssignments += int(increment)
+			book.hits[fmt.Sprint(args["HITId"])] = hit
+		}
+	}
+}
+
+// Remember the reward of a HIT type.
+func (book *rewardBook) learnHITType(hitTypeId string, reward float32) {
+	if hitTypeId == "" {
+		return
+	}
+	book.mu.Lock()
+	defer book.mu.Unlock()
+	book.hitTypes[hitTypeId] = reward
+}
+
+// Remember the reward and assignments of a HIT, and the reward of its HIT
+// type.
+func (book *rewardBook) learnHIT(hitId, hitTypeId string, reward float32,
+	maxAssignments int) {
+	book.learnHITType(hitTypeId, reward)
+	if hitId == "" {
+		return
+	}
+	book.mu.Lock()
+	defer book.mu.Unlock()
+	book.hits[hitId] = hitReward{reward, maxAssignments}
+}
+
+// The reward of a HIT created with the given arguments: the reward they
+// name, or else the reward of the HIT type they name, if it has been seen.
+func (book *rewardBook) argReward(args map[string]interface{}) (float64, bool) {
+	if reward, ok := argNumber(args, "Reward.1.Amount", "Reward"); ok {
+		return reward, true
+	}
+	reward, ok := book.hitTypeReward(fmt.Sprint(args["HITTypeId"]))
+	return float64(reward), ok
+}
+
+// The reward of a HIT type, if it has been seen.
+func (book *rewardBook) hitTypeReward(hitTypeId string) (float32, bool) {
+	if book == nil {
+		return 0, false
+	}
+	book.mu.Lock()
+	defer book.mu.Unlock()
+	reward, ok := book.hitTypes[hitTypeId]
+	return reward, ok
+}
+
+// The reward and assignments of a HIT, if it has been seen.
+func (book *rewardBook) hitReward(hitId string) (hitReward, bool) {
+	if book == nil {
+		return hitReward{}, false
+	}
+	book.mu.Lock()
+	defer book.mu.Unlock()
+	hit, ok := book.hits[hitId]
+	return hit, ok
+}
+
+// Read the first of several arguments which holds a number.
+func argNumber(args map[string]interface{}, names ...string) (float64, bool) {
+	for _, name := range names {
+		if value, ok := args[name]; ok {
+			if number, err := strconv.ParseFloat(fmt.Sprint(value), 64); err == nil {
+				return number, true
+			}
+		}
+	}
+	return 0, false
+}
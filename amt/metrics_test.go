@@ -0,0 +1,167 @@
+package amt
+
+import (
+	"bytes"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	Convey("Given a metrics registry", t, func() {
+		registry := NewMetricsRegistry()
+
+		Convey("When a call succeeds after a throttled attempt", func() {
+			srv := newFlakyServer(1, http.StatusServiceUnavailable)
+			defer srv.Close()
+			client := newRetryClient(srv.URL, 3)
+			client.Metrics = registry
+			client.Limiter = NewTokenBucket(time.Millisecond, 10)
+			_, err := client.GetAccountBalance()
+			So(err, ShouldBeNil)
+			var buf bytes.Buffer
+			registry.WriteTo(&buf)
+
+			Convey("Then the call, retry and limiter waits are counted", func() {
+				So(buf.String(), ShouldContainSubstring,
+					"amt_requests_total{operation=\"GetAccountBalance\"} 1\n")
+				So(buf.String(), ShouldContainSubstring,
+					"amt_retries_total{operation=\"GetAccountBalance\"} 1\n")
+				So(buf.String(), ShouldContainSubstring,
+					"amt_request_duration_seconds_count{operation=\"GetAccountBalance\"} 1\n")
+				So(buf.String(), ShouldContainSubstring,
+					"amt_throttle_wait_seconds_count{operation=\"GetAccountBalance\"} 2\n")
+				So(buf.String(), ShouldNotContainSubstring, "amt_errors_total{")
+			})
+		})
+
+		Convey("When a call fails", func() {
+			srv := newFlakyServer(5, http.StatusServiceUnavailable)
+			defer srv.Close()
+			client := newRetryClient(srv.URL, 2)
+			client.Metrics = registry
+			_, err := client.GetAccountBalance()
+			So(err, ShouldNotBeNil)
+			var buf bytes.Buffer
+			registry.WriteTo(&buf)
+
+			Convey("Then its error code is counted", func() {
+				So(buf.String(), ShouldContainSubstring,
+					"amt_errors_total{operation=\"GetAccountBalance\",code=\"AWS.ServiceUnavailable\"} 1\n")
+			})
+		})
+
+		Convey("When a bonus is granted", func() {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, GRANT_BONUS_RESPONSE)
+			}))
+			defer srv.Close()
+			client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, srv.URL,
+				WithMetrics(registry), WithLimiter(nil))
+			_, err := client.GrantBonus("W1", "A1", 1.5, "Thanks", "")
+			So(err, ShouldBeNil)
+
+			Convey("Then the money spent is served", func() {
+				w := httptest.NewRecorder()
+				registry.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+				So(w.Header().Get("Content-Type"), ShouldEqual, METRICS_CONTENT_TYPE)
+				So(w.Body.String(), ShouldContainSubstring,
+					"# TYPE amt_spent_dollars_total counter\n"+
+						"amt_spent_dollars_total{operation=\"GrantBonus\"} 1.8\n")
+			})
+		})
+
+		Convey("When a HIT of a registered HIT type is created and extended", func() {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				operation := r.FormValue("Operation")
+				fmt.Fprintf(w, `<?xml version="1.0"?>`+"\n"+
+					`<%sResponse><OperationRequest><RequestId>R1</RequestId></OperationRequest>`,
+					operation)
+				switch operation {
+				case "RegisterHITType":
+					fmt.Fprint(w, `<RegisterHITTypeResult><Request><IsValid>True</IsValid></Request>`+
+						`<HITTypeId>HT1</HITTypeId></RegisterHITTypeResult>`)
+				case "CreateHIT":
+					fmt.Fprint(w, `<HIT><Request><IsValid>True</IsValid></Request>`+
+						`<HITId>H1</HITId><HITTypeId>HT1</HITTypeId></HIT>`)
+				case "ExtendHIT":
+					fmt.Fprint(w, `<ExtendHITResult><Request><IsValid>True</IsValid></Request></ExtendHITResult>`)
+				}
+				fmt.Fprintf(w, `</%sResponse>`, operation)
+			}))
+			defer srv.Close()
+			client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, srv.URL,
+				WithMetrics(registry), WithLimiter(nil))
+			_, err := client.RegisterHITType("Title", "Description", 0.5, 60, 600,
+				nil, nil)
+			So(err, ShouldBeNil)
+			_, err = client.CreateHITFromHITTypeId("HT1", "Question", "", nil,
+				3600, 3, nil, nil, "", "")
+			So(err, ShouldBeNil)
+			_, err = client.ExtendHIT("H1", 2, 0, "")
+			So(err, ShouldBeNil)
+			var buf bytes.Buffer
+			registry.WriteTo(&buf)
+
+			Convey("Then both are costed with the reward of the HIT type", func() {
+				So(buf.String(), ShouldContainSubstring,
+					"amt_spent_dollars_total{operation=\"CreateHIT\"} 1.8\n")
+				So(buf.String(), ShouldContainSubstring,
+					"amt_spent_dollars_total{operation=\"ExtendHIT\"} 1.2\n")
+			})
+		})
+
+		Convey("When the JSON client does the same", func() {
+			srv, _ := newJSONServer(func(operation string,
+				input map[string]interface{}) (int, string) {
+				switch operation {
+				case "CreateHITType":
+					return 200, `{"HITTypeId": "HT1"}`
+				case "CreateHITWithHITType":
+					return 200, `{"HIT": {"HITId": "H1", "HITTypeId": "HT1", "MaxAssignments": 3}}`
+				}
+				return 200, "{}"
+			})
+			defer srv.Close()
+			client := NewJSONClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, srv.URL,
+				WithMetrics(registry), WithLimiter(nil))
+			_, err := client.RegisterHITType("Title", "Description", 0.5, 60, 600,
+				nil, nil)
+			So(err, ShouldBeNil)
+			_, err = client.CreateHITFromHITTypeId("HT1", "Question", "", nil,
+				3600, 3, nil, nil, "", "")
+			So(err, ShouldBeNil)
+			_, err = client.ExtendHIT("H1", 2, 0, "")
+			So(err, ShouldBeNil)
+			var buf bytes.Buffer
+			registry.WriteTo(&buf)
+
+			Convey("Then both are costed with the reward of the HIT type", func() {
+				So(buf.String(), ShouldContainSubstring,
+					"amt_spent_dollars_total{operation=\"CreateHITWithHITType\"} 1.8\n")
+				So(buf.String(), ShouldContainSubstring,
+					"amt_spent_dollars_total{operation=\"CreateAdditionalAssignmentsForHIT\"} 1.2\n")
+			})
+		})
+
+		Convey("When I observe waits directly", func() {
+			registry.ObserveWait("Get\"HIT\"", 30*time.Millisecond)
+			registry.ObserveWait("Get\"HIT\"", 2*time.Second)
+			var buf bytes.Buffer
+			registry.WriteTo(&buf)
+
+			Convey("Then they are bucketed, with labels escaped", func() {
+				So(buf.String(), ShouldContainSubstring,
+					`amt_throttle_wait_seconds_bucket{operation="Get\"HIT\"",le="0.025"} 0`+"\n"+
+						`amt_throttle_wait_seconds_bucket{operation="Get\"HIT\"",le="0.05"} 1`+"\n")
+				So(buf.String(), ShouldContainSubstring,
+					`amt_throttle_wait_seconds_bucket{operation="Get\"HIT\"",le="+Inf"} 2`+"\n"+
+						`amt_throttle_wait_seconds_sum{operation="Get\"HIT\""} 2.03`+"\n"+
+						`amt_throttle_wait_seconds_count{operation="Get\"HIT\""} 2`+"\n")
+			})
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package amt
 
 import (
+	"context"
 	"fmt"
 	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
 	xsdt "github.com/metaleap/go-xsd/types"
@@ -11,6 +12,14 @@ import (
 // ApproveAssignment approves the results of a completed assignment.
 func (client amtClient) ApproveAssignment(assignmentId,
 	requesterFeedback string) (amtgen.TxsdApproveAssignmentResponse, error) {
+	return client.ApproveAssignmentCtx(context.Background(), assignmentId,
+		requesterFeedback)
+}
+
+// ApproveAssignmentCtx is like ApproveAssignment, but the request is bound
+// to ctx.
+func (client amtClient) ApproveAssignmentCtx(ctx context.Context, assignmentId,
+	requesterFeedback string) (amtgen.TxsdApproveAssignmentResponse, error) {
 
 	// Prepare the request
 	var (
@@ -25,7 +34,7 @@ func (client amtClient) ApproveAssignment(assignmentId,
 	// Send the request
 	req, err := client.signRequest("ApproveAssignment", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -34,6 +43,15 @@ func (client amtClient) ApproveAssignment(assignmentId,
 // rejected.
 func (client amtClient) ApproveRejectedAssignment(assignmentId,
 	requesterFeedback string) (amtgen.TxsdApproveRejectedAssignmentResponse, error) {
+	return client.ApproveRejectedAssignmentCtx(context.Background(),
+		assignmentId, requesterFeedback)
+}
+
+// ApproveRejectedAssignmentCtx is like ApproveRejectedAssignment, but the
+// request is bound to ctx.
+func (client amtClient) ApproveRejectedAssignmentCtx(ctx context.Context,
+	assignmentId,
+	requesterFeedback string) (amtgen.TxsdApproveRejectedAssignmentResponse, error) {
 
 	// Prepare the request
 	var (
@@ -48,7 +66,7 @@ func (client amtClient) ApproveRejectedAssignment(assignmentId,
 	// Send the request
 	req, err := client.signRequest("ApproveRejectedAssignment", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -57,6 +75,16 @@ func (client amtClient) ApproveRejectedAssignment(assignmentId,
 func (client amtClient) AssignQualification(qualificationTypeId,
 	workerId string, integerValue int, sendNotification bool) (
 	amtgen.TxsdAssignQualificationResponse, error) {
+	return client.AssignQualificationCtx(context.Background(),
+		qualificationTypeId, workerId, integerValue, sendNotification)
+}
+
+// AssignQualificationCtx is like AssignQualification, but the request is
+// bound to ctx.
+func (client amtClient) AssignQualificationCtx(ctx context.Context,
+	qualificationTypeId,
+	workerId string, integerValue int, sendNotification bool) (
+	amtgen.TxsdAssignQualificationResponse, error) {
 
 	// Prepare the request
 	var (
@@ -73,7 +101,7 @@ func (client amtClient) AssignQualification(qualificationTypeId,
 	// Send the request
 	req, err := client.signRequest("AssignQualification", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -81,6 +109,13 @@ func (client amtClient) AssignQualification(qualificationTypeId,
 // BlockWorker allows you to prevent a Worker from working on your HITs.
 func (client amtClient) BlockWorker(workerId, reason string) (
 	amtgen.TxsdBlockWorkerResponse, error) {
+	return client.BlockWorkerCtx(context.Background(), workerId, reason)
+}
+
+// BlockWorkerCtx is like BlockWorker, but the request is bound to ctx.
+func (client amtClient) BlockWorkerCtx(ctx context.Context,
+	workerId, reason string) (
+	amtgen.TxsdBlockWorkerResponse, error) {
 
 	// Prepare the request
 	var (
@@ -95,7 +130,7 @@ func (client amtClient) BlockWorker(workerId, reason string) (
 	// Send the request
 	req, err := client.signRequest("BlockWorker", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -103,6 +138,14 @@ func (client amtClient) BlockWorker(workerId, reason string) (
 // ChangeHITTypeOfHIT allows you to change the HITType properties of a HIT.
 func (client amtClient) ChangeHITTypeOfHIT(hitId, hitTypeId string) (
 	amtgen.TxsdChangeHITTypeOfHITResponse, error) {
+	return client.ChangeHITTypeOfHITCtx(context.Background(), hitId, hitTypeId)
+}
+
+// ChangeHITTypeOfHITCtx is like ChangeHITTypeOfHIT, but the request is bound
+// to ctx.
+func (client amtClient) ChangeHITTypeOfHITCtx(ctx context.Context,
+	hitId, hitTypeId string) (
+	amtgen.TxsdChangeHITTypeOfHITResponse, error) {
 
 	// Prepare the request
 	var (
@@ -117,7 +160,7 @@ func (client amtClient) ChangeHITTypeOfHIT(hitId, hitTypeId string) (
 	// Send the request
 	req, err := client.signRequest("ChangeHITTypeOfHIT", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -132,6 +175,25 @@ func (client amtClient) CreateHIT(title, description, question string,
 	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
 	requesterAnnotation, uniqueRequestToken string) (
 	amtgen.TxsdCreateHITResponse, error) {
+	return client.CreateHITCtx(context.Background(), title, description,
+		question, hitLayoutId, hitLayoutParameters, reward,
+		assignmentDurationInSeconds, lifetimeInSeconds, maxAssignments,
+		autoApprovalDelayInSeconds, keywords, qualificationRequirements,
+		assignmentReviewPolicy, hitReviewPolicy, requesterAnnotation,
+		uniqueRequestToken)
+}
+
+// CreateHITCtx is like CreateHIT, but the request is bound to ctx.
+func (client amtClient) CreateHITCtx(ctx context.Context,
+	title, description, question string,
+	hitLayoutId string, hitLayoutParameters map[string]string,
+	reward float32, assignmentDurationInSeconds,
+	lifetimeInSeconds, maxAssignments, autoApprovalDelayInSeconds int,
+	keywords []string,
+	qualificationRequirements []*amtgen.TQualificationRequirement,
+	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
+	requesterAnnotation, uniqueRequestToken string) (
+	amtgen.TxsdCreateHITResponse, error) {
 
 	// Prepare the request
 	var (
@@ -166,7 +228,7 @@ func (client amtClient) CreateHIT(title, description, question string,
 	args.HITReviewPolicy = hitReviewPolicy
 	args.RequesterAnnotation = xsdt.String(requesterAnnotation)
 	args.UniqueRequestToken = xsdt.String(uniqueRequestToken)
-	return client.CreateHITFromArgs(args)
+	return client.CreateHITFromArgsCtx(ctx, args)
 }
 
 // CreateHITFromHITTypeId creates a new Human Intelligence Task (HIT) from a
@@ -177,6 +239,21 @@ func (client amtClient) CreateHITFromHITTypeId(hitTypeId, question string,
 	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
 	requesterAnnotation, uniqueRequestToken string) (
 	amtgen.TxsdCreateHITResponse, error) {
+	return client.CreateHITFromHITTypeIdCtx(context.Background(), hitTypeId,
+		question, hitLayoutId, hitLayoutParameters, lifetimeInSeconds,
+		maxAssignments, assignmentReviewPolicy, hitReviewPolicy,
+		requesterAnnotation, uniqueRequestToken)
+}
+
+// CreateHITFromHITTypeIdCtx is like CreateHITFromHITTypeId, but the request
+// is bound to ctx.
+func (client amtClient) CreateHITFromHITTypeIdCtx(ctx context.Context,
+	hitTypeId, question string,
+	hitLayoutId string, hitLayoutParameters map[string]string,
+	lifetimeInSeconds, maxAssignments int,
+	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
+	requesterAnnotation, uniqueRequestToken string) (
+	amtgen.TxsdCreateHITResponse, error) {
 
 	// Prepare the request
 	var (
@@ -203,13 +280,21 @@ func (client amtClient) CreateHITFromHITTypeId(hitTypeId, question string,
 	args.HITReviewPolicy = hitReviewPolicy
 	args.RequesterAnnotation = xsdt.String(requesterAnnotation)
 	args.UniqueRequestToken = xsdt.String(uniqueRequestToken)
-	return client.CreateHITFromArgs(args)
+	return client.CreateHITFromArgsCtx(ctx, args)
 }
 
 // CreateHITFromArgs creates a new Human Intelligence Task (HIT) from the given
 // argument values.
 func (client amtClient) CreateHITFromArgs(args amtgen.TCreateHITRequest) (
 	amtgen.TxsdCreateHITResponse, error) {
+	return client.CreateHITFromArgsCtx(context.Background(), args)
+}
+
+// CreateHITFromArgsCtx is like CreateHITFromArgs, but the request is bound
+// to ctx.
+func (client amtClient) CreateHITFromArgsCtx(ctx context.Context,
+	args amtgen.TCreateHITRequest) (
+	amtgen.TxsdCreateHITResponse, error) {
 
 	// Prepare the request
 	var (
@@ -221,7 +306,7 @@ func (client amtClient) CreateHITFromArgs(args amtgen.TCreateHITRequest) (
 	// Send the request
 	req, err := client.signRequest("CreateHIT", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -232,6 +317,19 @@ func (client amtClient) CreateQualificationType(name, description string,
 	qualificationTypeStatus, test, answerKey string,
 	testDurationInSeconds int, autoGranted bool,
 	autoGrantedValue int) (amtgen.TxsdCreateQualificationTypeResponse, error) {
+	return client.CreateQualificationTypeCtx(context.Background(), name,
+		description, keywords, retryDelayInSeconds, qualificationTypeStatus,
+		test, answerKey, testDurationInSeconds, autoGranted, autoGrantedValue)
+}
+
+// CreateQualificationTypeCtx is like CreateQualificationType, but the
+// request is bound to ctx.
+func (client amtClient) CreateQualificationTypeCtx(ctx context.Context,
+	name, description string,
+	keywords []string, retryDelayInSeconds int,
+	qualificationTypeStatus, test, answerKey string,
+	testDurationInSeconds int, autoGranted bool,
+	autoGrantedValue int) (amtgen.TxsdCreateQualificationTypeResponse, error) {
 
 	// Prepare the request
 	var (
@@ -255,7 +353,7 @@ func (client amtClient) CreateQualificationType(name, description string,
 	// Send the request
 	req, err := client.signRequest("CreateQualificationType", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -263,6 +361,12 @@ func (client amtClient) CreateQualificationType(name, description string,
 // DisableHIT removes a HIT from the Amazon Mechanical Turk marketplace.
 func (client amtClient) DisableHIT(hitId string) (
 	amtgen.TxsdDisableHITResponse, error) {
+	return client.DisableHITCtx(context.Background(), hitId)
+}
+
+// DisableHITCtx is like DisableHIT, but the request is bound to ctx.
+func (client amtClient) DisableHITCtx(ctx context.Context, hitId string) (
+	amtgen.TxsdDisableHITResponse, error) {
 
 	// Prepare the request
 	var (
@@ -276,7 +380,7 @@ func (client amtClient) DisableHIT(hitId string) (
 	// Send the request
 	req, err := client.signRequest("DisableHIT", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -284,6 +388,12 @@ func (client amtClient) DisableHIT(hitId string) (
 // DisposeHIT disposes of a HIT that is no longer needed.
 func (client amtClient) DisposeHIT(hitId string) (
 	amtgen.TxsdDisposeHITResponse, error) {
+	return client.DisposeHITCtx(context.Background(), hitId)
+}
+
+// DisposeHITCtx is like DisposeHIT, but the request is bound to ctx.
+func (client amtClient) DisposeHITCtx(ctx context.Context, hitId string) (
+	amtgen.TxsdDisposeHITResponse, error) {
 
 	// Prepare the request
 	var (
@@ -297,7 +407,7 @@ func (client amtClient) DisposeHIT(hitId string) (
 	// Send the request
 	req, err := client.signRequest("DisposeHIT", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -305,6 +415,15 @@ func (client amtClient) DisposeHIT(hitId string) (
 // DisposeQualificationType disposes of a HIT that is no longer needed.
 func (client amtClient) DisposeQualificationType(qualificationTypeId string) (
 	amtgen.TxsdDisposeQualificationTypeResponse, error) {
+	return client.DisposeQualificationTypeCtx(context.Background(),
+		qualificationTypeId)
+}
+
+// DisposeQualificationTypeCtx is like DisposeQualificationType, but the
+// request is bound to ctx.
+func (client amtClient) DisposeQualificationTypeCtx(ctx context.Context,
+	qualificationTypeId string) (
+	amtgen.TxsdDisposeQualificationTypeResponse, error) {
 
 	// Prepare the request
 	var (
@@ -318,7 +437,7 @@ func (client amtClient) DisposeQualificationType(qualificationTypeId string) (
 	// Send the request
 	req, err := client.signRequest("DisposeQualificationType", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -329,6 +448,16 @@ func (client amtClient) ExtendHIT(hitId string,
 	maxAssignmentsIncrement, expirationIncrementInSeconds int,
 	uniqueRequestToken string) (
 	amtgen.TxsdExtendHITResponse, error) {
+	return client.ExtendHITCtx(context.Background(), hitId,
+		maxAssignmentsIncrement, expirationIncrementInSeconds,
+		uniqueRequestToken)
+}
+
+// ExtendHITCtx is like ExtendHIT, but the request is bound to ctx.
+func (client amtClient) ExtendHITCtx(ctx context.Context, hitId string,
+	maxAssignmentsIncrement, expirationIncrementInSeconds int,
+	uniqueRequestToken string) (
+	amtgen.TxsdExtendHITResponse, error) {
 
 	// Prepare the request
 	var (
@@ -345,7 +474,7 @@ func (client amtClient) ExtendHIT(hitId string,
 	// Send the request
 	req, err := client.signRequest("ExtendHIT", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -354,6 +483,12 @@ func (client amtClient) ExtendHIT(hitId string,
 // LifetimeInSeconds parameter of the HIT had elapsed.
 func (client amtClient) ForceExpireHIT(hitId string) (
 	amtgen.TxsdForceExpireHITResponse, error) {
+	return client.ForceExpireHITCtx(context.Background(), hitId)
+}
+
+// ForceExpireHITCtx is like ForceExpireHIT, but the request is bound to ctx.
+func (client amtClient) ForceExpireHITCtx(ctx context.Context, hitId string) (
+	amtgen.TxsdForceExpireHITResponse, error) {
 
 	// Prepare the request
 	var (
@@ -367,7 +502,7 @@ func (client amtClient) ForceExpireHIT(hitId string) (
 	// Send the request
 	req, err := client.signRequest("ForceExpireHIT", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -376,6 +511,13 @@ func (client amtClient) ForceExpireHIT(hitId string) (
 // LifetimeInSeconds parameter of the HIT had elapsed.
 func (client amtClient) GetAccountBalance() (
 	amtgen.TxsdGetAccountBalanceResponse, error) {
+	return client.GetAccountBalanceCtx(context.Background())
+}
+
+// GetAccountBalanceCtx is like GetAccountBalance, but the request is bound
+// to ctx.
+func (client amtClient) GetAccountBalanceCtx(ctx context.Context) (
+	amtgen.TxsdGetAccountBalanceResponse, error) {
 
 	// Prepare the request
 	var (
@@ -388,7 +530,7 @@ func (client amtClient) GetAccountBalance() (
 	// Send the request
 	req, err := client.signRequest("GetAccountBalance", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -397,6 +539,13 @@ func (client amtClient) GetAccountBalance() (
 // Submitted, Approved, or Rejected.
 func (client amtClient) GetAssignment(assignmentId string) (
 	amtgen.TxsdGetAssignmentResponse, error) {
+	return client.GetAssignmentCtx(context.Background(), assignmentId)
+}
+
+// GetAssignmentCtx is like GetAssignment, but the request is bound to ctx.
+func (client amtClient) GetAssignmentCtx(ctx context.Context,
+	assignmentId string) (
+	amtgen.TxsdGetAssignmentResponse, error) {
 
 	// Prepare the request
 	var (
@@ -410,7 +559,7 @@ func (client amtClient) GetAssignment(assignmentId string) (
 	// Send the request
 	req, err := client.signRequest("GetAssignment", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -420,6 +569,17 @@ func (client amtClient) GetAssignmentsForHIT(hitId string,
 	assignmentStatuses []string, sortProperty string, sortAscending bool,
 	pageSize, pageNumber int) (
 	amtgen.TxsdGetAssignmentsForHITResponse, error) {
+	return client.GetAssignmentsForHITCtx(context.Background(), hitId,
+		assignmentStatuses, sortProperty, sortAscending, pageSize, pageNumber)
+}
+
+// GetAssignmentsForHITCtx is like GetAssignmentsForHIT, but the request is
+// bound to ctx.
+func (client amtClient) GetAssignmentsForHITCtx(ctx context.Context,
+	hitId string,
+	assignmentStatuses []string, sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetAssignmentsForHITResponse, error) {
 
 	// Prepare the request
 	var (
@@ -445,7 +605,7 @@ func (client amtClient) GetAssignmentsForHIT(hitId string,
 	// Send the request
 	req, err := client.signRequest("GetAssignmentsForHIT", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -454,6 +614,15 @@ func (client amtClient) GetAssignmentsForHIT(hitId string,
 // on your HITs.
 func (client amtClient) GetBlockedWorkers(pageSize, pageNumber int) (
 	amtgen.TxsdGetBlockedWorkersResponse, error) {
+	return client.GetBlockedWorkersCtx(context.Background(), pageSize,
+		pageNumber)
+}
+
+// GetBlockedWorkersCtx is like GetBlockedWorkers, but the request is bound
+// to ctx.
+func (client amtClient) GetBlockedWorkersCtx(ctx context.Context,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetBlockedWorkersResponse, error) {
 
 	// Prepare the request
 	var (
@@ -468,7 +637,7 @@ func (client amtClient) GetBlockedWorkers(pageSize, pageNumber int) (
 	// Send the request
 	req, err := client.signRequest("GetBlockedWorkers", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -478,6 +647,16 @@ func (client amtClient) GetBlockedWorkers(pageSize, pageNumber int) (
 func (client amtClient) GetBonusPayments(hitId, assignmentId string,
 	pageSize, pageNumber int) (
 	amtgen.TxsdGetBonusPaymentsResponse, error) {
+	return client.GetBonusPaymentsCtx(context.Background(), hitId, assignmentId,
+		pageSize, pageNumber)
+}
+
+// GetBonusPaymentsCtx is like GetBonusPayments, but the request is bound to
+// ctx.
+func (client amtClient) GetBonusPaymentsCtx(ctx context.Context,
+	hitId, assignmentId string,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetBonusPaymentsResponse, error) {
 
 	// Prepare the request
 	var (
@@ -494,7 +673,7 @@ func (client amtClient) GetBonusPayments(hitId, assignmentId string,
 	// Send the request
 	req, err := client.signRequest("GetBonusPayments", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -503,6 +682,15 @@ func (client amtClient) GetBonusPayments(hitId, assignmentId string,
 func (client amtClient) GetFileUploadURL(assignmentId,
 	questionIdentifier string) (
 	amtgen.TxsdGetFileUploadURLResponse, error) {
+	return client.GetFileUploadURLCtx(context.Background(), assignmentId,
+		questionIdentifier)
+}
+
+// GetFileUploadURLCtx is like GetFileUploadURL, but the request is bound to
+// ctx.
+func (client amtClient) GetFileUploadURLCtx(ctx context.Context, assignmentId,
+	questionIdentifier string) (
+	amtgen.TxsdGetFileUploadURLResponse, error) {
 
 	// Prepare the request
 	var (
@@ -517,7 +705,7 @@ func (client amtClient) GetFileUploadURL(assignmentId,
 	// Send the request
 	req, err := client.signRequest("GetFileUploadURL", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -525,6 +713,12 @@ func (client amtClient) GetFileUploadURL(assignmentId,
 // GetHIT retrieves the details of the specified HIT.
 func (client amtClient) GetHIT(hitId string) (
 	amtgen.TxsdGetHITResponse, error) {
+	return client.GetHITCtx(context.Background(), hitId)
+}
+
+// GetHITCtx is like GetHIT, but the request is bound to ctx.
+func (client amtClient) GetHITCtx(ctx context.Context, hitId string) (
+	amtgen.TxsdGetHITResponse, error) {
 
 	// Prepare the request
 	var (
@@ -538,7 +732,7 @@ func (client amtClient) GetHIT(hitId string) (
 	// Send the request
 	req, err := client.signRequest("GetHIT", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -548,6 +742,16 @@ func (client amtClient) GetHIT(hitId string) (
 func (client amtClient) GetHITsForQualificationType(qualificationTypeId string,
 	pageSize, pageNumber int) (
 	amtgen.TxsdGetHITsForQualificationTypeResponse, error) {
+	return client.GetHITsForQualificationTypeCtx(context.Background(),
+		qualificationTypeId, pageSize, pageNumber)
+}
+
+// GetHITsForQualificationTypeCtx is like GetHITsForQualificationType, but
+// the request is bound to ctx.
+func (client amtClient) GetHITsForQualificationTypeCtx(ctx context.Context,
+	qualificationTypeId string,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetHITsForQualificationTypeResponse, error) {
 
 	// Prepare the request
 	var (
@@ -563,7 +767,7 @@ func (client amtClient) GetHITsForQualificationType(qualificationTypeId string,
 	// Send the request
 	req, err := client.signRequest("GetHITsForQualificationType", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -574,6 +778,16 @@ func (client amtClient) GetQualificationsForQualificationType(
 	qualificationTypeId string, isGranted bool,
 	pageSize, pageNumber int) (
 	amtgen.TxsdGetQualificationsForQualificationTypeResponse, error) {
+	return client.GetQualificationsForQualificationTypeCtx(context.Background(),
+		qualificationTypeId, isGranted, pageSize, pageNumber)
+}
+
+// GetQualificationsForQualificationTypeCtx is like
+// GetQualificationsForQualificationType, but the request is bound to ctx.
+func (client amtClient) GetQualificationsForQualificationTypeCtx(ctx context.Context,
+	qualificationTypeId string, isGranted bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetQualificationsForQualificationTypeResponse, error) {
 
 	// Prepare the request
 	var (
@@ -594,7 +808,7 @@ func (client amtClient) GetQualificationsForQualificationType(
 	// Send the request
 	req, err := client.signRequest("GetQualificationsForQualificationType", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -605,6 +819,16 @@ func (client amtClient) GetQualificationRequests(
 	qualificationTypeId, sortProperty string, sortAscending bool,
 	pageSize, pageNumber int) (
 	amtgen.TxsdGetQualificationRequestsResponse, error) {
+	return client.GetQualificationRequestsCtx(context.Background(),
+		qualificationTypeId, sortProperty, sortAscending, pageSize, pageNumber)
+}
+
+// GetQualificationRequestsCtx is like GetQualificationRequests, but the
+// request is bound to ctx.
+func (client amtClient) GetQualificationRequestsCtx(ctx context.Context,
+	qualificationTypeId, sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetQualificationRequestsResponse, error) {
 
 	// Prepare the request
 	var (
@@ -627,7 +851,7 @@ func (client amtClient) GetQualificationRequests(
 	// Send the request
 	req, err := client.signRequest("GetQualificationRequests", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -637,6 +861,15 @@ func (client amtClient) GetQualificationRequests(
 func (client amtClient) GetQualificationScore(
 	qualificationTypeId, subjectId string) (
 	amtgen.TxsdGetQualificationScoreResponse, error) {
+	return client.GetQualificationScoreCtx(context.Background(),
+		qualificationTypeId, subjectId)
+}
+
+// GetQualificationScoreCtx is like GetQualificationScore, but the request is
+// bound to ctx.
+func (client amtClient) GetQualificationScoreCtx(ctx context.Context,
+	qualificationTypeId, subjectId string) (
+	amtgen.TxsdGetQualificationScoreResponse, error) {
 
 	// Prepare the request
 	var (
@@ -651,7 +884,7 @@ func (client amtClient) GetQualificationScore(
 	// Send the request
 	req, err := client.signRequest("GetQualificationScore", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -660,6 +893,15 @@ func (client amtClient) GetQualificationScore(
 // its ID.
 func (client amtClient) GetQualificationType(qualificationTypeId string) (
 	amtgen.TxsdGetQualificationTypeResponse, error) {
+	return client.GetQualificationTypeCtx(context.Background(),
+		qualificationTypeId)
+}
+
+// GetQualificationTypeCtx is like GetQualificationType, but the request is
+// bound to ctx.
+func (client amtClient) GetQualificationTypeCtx(ctx context.Context,
+	qualificationTypeId string) (
+	amtgen.TxsdGetQualificationTypeResponse, error) {
 
 	// Prepare the request
 	var (
@@ -673,7 +915,7 @@ func (client amtClient) GetQualificationType(qualificationTypeId string) (
 	// Send the request
 	req, err := client.signRequest("GetQualificationType", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -682,6 +924,15 @@ func (client amtClient) GetQualificationType(qualificationTypeId string) (
 // the operation).
 func (client amtClient) GetRequesterStatistic(statistic, timePeriod string,
 	count int) (amtgen.TxsdGetRequesterStatisticResponse, error) {
+	return client.GetRequesterStatisticCtx(context.Background(), statistic,
+		timePeriod, count)
+}
+
+// GetRequesterStatisticCtx is like GetRequesterStatistic, but the request is
+// bound to ctx.
+func (client amtClient) GetRequesterStatisticCtx(ctx context.Context,
+	statistic, timePeriod string,
+	count int) (amtgen.TxsdGetRequesterStatisticResponse, error) {
 
 	// Prepare the request
 	var (
@@ -697,7 +948,7 @@ func (client amtClient) GetRequesterStatistic(statistic, timePeriod string,
 	// Send the request
 	req, err := client.signRequest("GetRequesterStatistic", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -706,6 +957,15 @@ func (client amtClient) GetRequesterStatistic(statistic, timePeriod string,
 // has completed Human Intelligence Tasks (HITs) for you.
 func (client amtClient) GetRequesterWorkerStatistic(statistic, workerId,
 	timePeriod string, count int) (amtgen.TxsdGetRequesterWorkerStatisticResponse, error) {
+	return client.GetRequesterWorkerStatisticCtx(context.Background(),
+		statistic, workerId, timePeriod, count)
+}
+
+// GetRequesterWorkerStatisticCtx is like GetRequesterWorkerStatistic, but
+// the request is bound to ctx.
+func (client amtClient) GetRequesterWorkerStatisticCtx(ctx context.Context,
+	statistic, workerId,
+	timePeriod string, count int) (amtgen.TxsdGetRequesterWorkerStatisticResponse, error) {
 
 	// Prepare the request
 	var (
@@ -722,7 +982,7 @@ func (client amtClient) GetRequesterWorkerStatistic(statistic, workerId,
 	// Send the request
 	req, err := client.signRequest("GetRequesterWorkerStatistic", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -732,6 +992,16 @@ func (client amtClient) GetRequesterWorkerStatistic(statistic, workerId,
 func (client amtClient) GetReviewableHITs(hitTypeId, status,
 	sortProperty string, sortAscending bool,
 	pageSize, pageNumber int) (amtgen.TxsdGetReviewableHITsResponse, error) {
+	return client.GetReviewableHITsCtx(context.Background(), hitTypeId, status,
+		sortProperty, sortAscending, pageSize, pageNumber)
+}
+
+// GetReviewableHITsCtx is like GetReviewableHITs, but the request is bound
+// to ctx.
+func (client amtClient) GetReviewableHITsCtx(ctx context.Context,
+	hitTypeId, status,
+	sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (amtgen.TxsdGetReviewableHITsResponse, error) {
 
 	// Prepare the request
 	var (
@@ -754,7 +1024,7 @@ func (client amtClient) GetReviewableHITs(hitTypeId, status,
 	// Send the request
 	req, err := client.signRequest("GetReviewableHITs", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -765,6 +1035,17 @@ func (client amtClient) GetReviewResultsForHIT(hitId string,
 	policyLevels []string,
 	retrieveActions, retrieveResults bool,
 	pageSize, pageNumber int) (amtgen.TxsdGetReviewResultsForHITResponse, error) {
+	return client.GetReviewResultsForHITCtx(context.Background(), hitId,
+		policyLevels, retrieveActions, retrieveResults, pageSize, pageNumber)
+}
+
+// GetReviewResultsForHITCtx is like GetReviewResultsForHIT, but the request
+// is bound to ctx.
+func (client amtClient) GetReviewResultsForHITCtx(ctx context.Context,
+	hitId string,
+	policyLevels []string,
+	retrieveActions, retrieveResults bool,
+	pageSize, pageNumber int) (amtgen.TxsdGetReviewResultsForHITResponse, error) {
 
 	// Prepare the request
 	var (
@@ -786,7 +1067,7 @@ func (client amtClient) GetReviewResultsForHIT(hitId string,
 	// Send the request
 	req, err := client.signRequest("GetReviewResultsForHIT", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -795,6 +1076,15 @@ func (client amtClient) GetReviewResultsForHIT(hitId string,
 func (client amtClient) GrantBonus(workerId, assignmentId string,
 	bonusAmount float32, reason, uniqueRequestToken string) (
 	amtgen.TxsdGrantBonusResponse, error) {
+	return client.GrantBonusCtx(context.Background(), workerId, assignmentId,
+		bonusAmount, reason, uniqueRequestToken)
+}
+
+// GrantBonusCtx is like GrantBonus, but the request is bound to ctx.
+func (client amtClient) GrantBonusCtx(ctx context.Context,
+	workerId, assignmentId string,
+	bonusAmount float32, reason, uniqueRequestToken string) (
+	amtgen.TxsdGrantBonusResponse, error) {
 
 	// Prepare the request
 	var (
@@ -814,7 +1104,7 @@ func (client amtClient) GrantBonus(workerId, assignmentId string,
 	// Send the request
 	req, err := client.signRequest("GrantBonus", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -823,6 +1113,16 @@ func (client amtClient) GrantBonus(workerId, assignmentId string,
 func (client amtClient) GrantQualification(qualificationRequestId string,
 	integerValue int) (
 	amtgen.TxsdGrantQualificationResponse, error) {
+	return client.GrantQualificationCtx(context.Background(),
+		qualificationRequestId, integerValue)
+}
+
+// GrantQualificationCtx is like GrantQualification, but the request is bound
+// to ctx.
+func (client amtClient) GrantQualificationCtx(ctx context.Context,
+	qualificationRequestId string,
+	integerValue int) (
+	amtgen.TxsdGrantQualificationResponse, error) {
 
 	// Prepare the request
 	var (
@@ -837,7 +1137,7 @@ func (client amtClient) GrantQualification(qualificationRequestId string,
 	// Send the request
 	req, err := client.signRequest("GrantQualification", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -846,6 +1146,14 @@ func (client amtClient) GrantQualification(qualificationRequestId string,
 // the Worker ID.
 func (client amtClient) NotifyWorkers(subject, messageText string,
 	workerIds []string) (amtgen.TxsdNotifyWorkersResponse, error) {
+	return client.NotifyWorkersCtx(context.Background(), subject, messageText,
+		workerIds)
+}
+
+// NotifyWorkersCtx is like NotifyWorkers, but the request is bound to ctx.
+func (client amtClient) NotifyWorkersCtx(ctx context.Context,
+	subject, messageText string,
+	workerIds []string) (amtgen.TxsdNotifyWorkersResponse, error) {
 
 	// Prepare the request
 	var (
@@ -863,7 +1171,7 @@ func (client amtClient) NotifyWorkers(subject, messageText string,
 	// Send the request
 	req, err := client.signRequest("NotifyWorkers", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -874,6 +1182,18 @@ func (client amtClient) RegisterHITType(title, description string,
 	keywords []string,
 	qualificationRequirements []*amtgen.TQualificationRequirement) (
 	amtgen.TxsdRegisterHITTypeResponse, error) {
+	return client.RegisterHITTypeCtx(context.Background(), title, description,
+		reward, assignmentDurationInSeconds, autoApprovalDelayInSeconds,
+		keywords, qualificationRequirements)
+}
+
+// RegisterHITTypeCtx is like RegisterHITType, but the request is bound to ctx.
+func (client amtClient) RegisterHITTypeCtx(ctx context.Context,
+	title, description string,
+	reward float32, assignmentDurationInSeconds, autoApprovalDelayInSeconds int,
+	keywords []string,
+	qualificationRequirements []*amtgen.TQualificationRequirement) (
+	amtgen.TxsdRegisterHITTypeResponse, error) {
 
 	// Prepare the request
 	var (
@@ -889,12 +1209,20 @@ func (client amtClient) RegisterHITType(title, description string,
 	args.Keywords = xsdt.String(strings.Join(keywords, ","))
 	args.QualificationRequirements = qualificationRequirements
 
-	return client.RegisterHITTypeFromArgs(args)
+	return client.RegisterHITTypeFromArgsCtx(ctx, args)
 }
 
 // RegisterHITType creates a new HIT type.
 func (client amtClient) RegisterHITTypeFromArgs(args amtgen.TRegisterHITTypeRequest) (
 	amtgen.TxsdRegisterHITTypeResponse, error) {
+	return client.RegisterHITTypeFromArgsCtx(context.Background(), args)
+}
+
+// RegisterHITTypeFromArgsCtx is like RegisterHITTypeFromArgs, but the
+// request is bound to ctx.
+func (client amtClient) RegisterHITTypeFromArgsCtx(ctx context.Context,
+	args amtgen.TRegisterHITTypeRequest) (
+	amtgen.TxsdRegisterHITTypeResponse, error) {
 
 	// Prepare the request
 	var (
@@ -906,7 +1234,7 @@ func (client amtClient) RegisterHITTypeFromArgs(args amtgen.TRegisterHITTypeRequ
 	// Send the request
 	req, err := client.signRequest("RegisterHITType", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -915,6 +1243,15 @@ func (client amtClient) RegisterHITTypeFromArgs(args amtgen.TRegisterHITTypeRequ
 func (client amtClient) RejectAssignment(assignmentId,
 	requesterFeedback string) (
 	amtgen.TxsdRejectAssignmentResponse, error) {
+	return client.RejectAssignmentCtx(context.Background(), assignmentId,
+		requesterFeedback)
+}
+
+// RejectAssignmentCtx is like RejectAssignment, but the request is bound to
+// ctx.
+func (client amtClient) RejectAssignmentCtx(ctx context.Context, assignmentId,
+	requesterFeedback string) (
+	amtgen.TxsdRejectAssignmentResponse, error) {
 
 	// Prepare the request
 	var (
@@ -929,7 +1266,7 @@ func (client amtClient) RejectAssignment(assignmentId,
 	// Send the request
 	req, err := client.signRequest("RejectAssignment", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -938,6 +1275,16 @@ func (client amtClient) RejectAssignment(assignmentId,
 func (client amtClient) RejectQualificationRequest(qualificationRequestId,
 	reason string) (
 	amtgen.TxsdRejectQualificationRequestResponse, error) {
+	return client.RejectQualificationRequestCtx(context.Background(),
+		qualificationRequestId, reason)
+}
+
+// RejectQualificationRequestCtx is like RejectQualificationRequest, but the
+// request is bound to ctx.
+func (client amtClient) RejectQualificationRequestCtx(ctx context.Context,
+	qualificationRequestId,
+	reason string) (
+	amtgen.TxsdRejectQualificationRequestResponse, error) {
 
 	// Prepare the request
 	var (
@@ -952,7 +1299,7 @@ func (client amtClient) RejectQualificationRequest(qualificationRequestId,
 	// Send the request
 	req, err := client.signRequest("RejectQualificationRequest", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -961,6 +1308,16 @@ func (client amtClient) RejectQualificationRequest(qualificationRequestId,
 func (client amtClient) RevokeQualification(subjectId, qualificationTypeId,
 	reason string) (
 	amtgen.TxsdRevokeQualificationResponse, error) {
+	return client.RevokeQualificationCtx(context.Background(), subjectId,
+		qualificationTypeId, reason)
+}
+
+// RevokeQualificationCtx is like RevokeQualification, but the request is
+// bound to ctx.
+func (client amtClient) RevokeQualificationCtx(ctx context.Context,
+	subjectId, qualificationTypeId,
+	reason string) (
+	amtgen.TxsdRevokeQualificationResponse, error) {
 
 	// Prepare the request
 	var (
@@ -976,7 +1333,7 @@ func (client amtClient) RevokeQualification(subjectId, qualificationTypeId,
 	// Send the request
 	req, err := client.signRequest("RevokeQualification", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -985,6 +1342,15 @@ func (client amtClient) RevokeQualification(subjectId, qualificationTypeId,
 func (client amtClient) SearchHITs(sortProperty string, sortAscending bool,
 	pageSize, pageNumber int) (
 	amtgen.TxsdSearchHITsResponse, error) {
+	return client.SearchHITsCtx(context.Background(), sortProperty,
+		sortAscending, pageSize, pageNumber)
+}
+
+// SearchHITsCtx is like SearchHITs, but the request is bound to ctx.
+func (client amtClient) SearchHITsCtx(ctx context.Context,
+	sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdSearchHITsResponse, error) {
 
 	// Prepare the request
 	var (
@@ -1005,7 +1371,7 @@ func (client amtClient) SearchHITs(sortProperty string, sortAscending bool,
 	// Send the request
 	req, err := client.signRequest("SearchHITs", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -1016,6 +1382,17 @@ func (client amtClient) SearchQualificationTypes(
 	query, sortProperty string, sortAscending bool,
 	pageSize, pageNumber int, mustBeRequestable, mustBeOwnedByCaller bool) (
 	amtgen.TxsdSearchQualificationTypesResponse, error) {
+	return client.SearchQualificationTypesCtx(context.Background(), query,
+		sortProperty, sortAscending, pageSize, pageNumber, mustBeRequestable,
+		mustBeOwnedByCaller)
+}
+
+// SearchQualificationTypesCtx is like SearchQualificationTypes, but the
+// request is bound to ctx.
+func (client amtClient) SearchQualificationTypesCtx(ctx context.Context,
+	query, sortProperty string, sortAscending bool,
+	pageSize, pageNumber int, mustBeRequestable, mustBeOwnedByCaller bool) (
+	amtgen.TxsdSearchQualificationTypesResponse, error) {
 
 	// Prepare the request
 	var (
@@ -1039,7 +1416,7 @@ func (client amtClient) SearchQualificationTypes(
 	// Send the request
 	req, err := client.signRequest("SearchQualificationTypes", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -1050,6 +1427,15 @@ func (client amtClient) SearchQualificationTypes(
 func (client amtClient) SendTestEventNotification(
 	notification *amtgen.TNotificationSpecification, testEventType string) (
 	amtgen.TxsdSendTestEventNotificationResponse, error) {
+	return client.SendTestEventNotificationCtx(context.Background(),
+		notification, testEventType)
+}
+
+// SendTestEventNotificationCtx is like SendTestEventNotification, but the
+// request is bound to ctx.
+func (client amtClient) SendTestEventNotificationCtx(ctx context.Context,
+	notification *amtgen.TNotificationSpecification, testEventType string) (
+	amtgen.TxsdSendTestEventNotificationResponse, error) {
 
 	// Prepare the request
 	var (
@@ -1064,7 +1450,7 @@ func (client amtClient) SendTestEventNotification(
 	// Send the request
 	req, err := client.signRequest("SendTestEventNotification", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -1074,6 +1460,14 @@ func (client amtClient) SendTestEventNotification(
 // back to the Reviewable status.
 func (client amtClient) SetHITAsReviewing(hitID string, revert bool) (
 	amtgen.TxsdSetHITAsReviewingResponse, error) {
+	return client.SetHITAsReviewingCtx(context.Background(), hitID, revert)
+}
+
+// SetHITAsReviewingCtx is like SetHITAsReviewing, but the request is bound
+// to ctx.
+func (client amtClient) SetHITAsReviewingCtx(ctx context.Context,
+	hitID string, revert bool) (
+	amtgen.TxsdSetHITAsReviewingResponse, error) {
 
 	// Prepare the request
 	var (
@@ -1088,7 +1482,7 @@ func (client amtClient) SetHITAsReviewing(hitID string, revert bool) (
 	// Send the request
 	req, err := client.signRequest("SetHITAsReviewing", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -1098,6 +1492,16 @@ func (client amtClient) SetHITAsReviewing(hitID string, revert bool) (
 func (client amtClient) SetHITTypeNotification(hitTypeID string,
 	notification *amtgen.TNotificationSpecification, active bool) (
 	amtgen.TxsdSetHITTypeNotificationResponse, error) {
+	return client.SetHITTypeNotificationCtx(context.Background(), hitTypeID,
+		notification, active)
+}
+
+// SetHITTypeNotificationCtx is like SetHITTypeNotification, but the request
+// is bound to ctx.
+func (client amtClient) SetHITTypeNotificationCtx(ctx context.Context,
+	hitTypeID string,
+	notification *amtgen.TNotificationSpecification, active bool) (
+	amtgen.TxsdSetHITTypeNotificationResponse, error) {
 
 	// Prepare the request
 	var (
@@ -1113,7 +1517,7 @@ func (client amtClient) SetHITTypeNotification(hitTypeID string,
 	// Send the request
 	req, err := client.signRequest("SetHITTypeNotification", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -1121,6 +1525,13 @@ func (client amtClient) SetHITTypeNotification(hitTypeID string,
 // UnblockWorker allows you to reinstate a blocked Worker to work on your HITs.
 func (client amtClient) UnblockWorker(workerId, reason string) (
 	amtgen.TxsdUnblockWorkerResponse, error) {
+	return client.UnblockWorkerCtx(context.Background(), workerId, reason)
+}
+
+// UnblockWorkerCtx is like UnblockWorker, but the request is bound to ctx.
+func (client amtClient) UnblockWorkerCtx(ctx context.Context,
+	workerId, reason string) (
+	amtgen.TxsdUnblockWorkerResponse, error) {
 
 	// Prepare the request
 	var (
@@ -1135,7 +1546,7 @@ func (client amtClient) UnblockWorker(workerId, reason string) (
 	// Send the request
 	req, err := client.signRequest("UnblockWorker", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -1145,6 +1556,16 @@ func (client amtClient) UnblockWorker(workerId, reason string) (
 func (client amtClient) UpdateQualificationScore(qualificationTypeId,
 	subjectId string, integerValue int) (
 	amtgen.TxsdUpdateQualificationScoreResponse, error) {
+	return client.UpdateQualificationScoreCtx(context.Background(),
+		qualificationTypeId, subjectId, integerValue)
+}
+
+// UpdateQualificationScoreCtx is like UpdateQualificationScore, but the
+// request is bound to ctx.
+func (client amtClient) UpdateQualificationScoreCtx(ctx context.Context,
+	qualificationTypeId,
+	subjectId string, integerValue int) (
+	amtgen.TxsdUpdateQualificationScoreResponse, error) {
 
 	// Prepare the request
 	var (
@@ -1160,7 +1581,7 @@ func (client amtClient) UpdateQualificationScore(qualificationTypeId,
 	// Send the request
 	req, err := client.signRequest("UpdateQualificationScore", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -1172,6 +1593,20 @@ func (client amtClient) UpdateQualificationType(qualificationTypeId string,
 	answerKey string, testDurationInSeconds int, autoGranted bool,
 	autoGrantedValue int) (
 	amtgen.TxsdUpdateQualificationTypeResponse, error) {
+	return client.UpdateQualificationTypeCtx(context.Background(),
+		qualificationTypeId, retryDelayInSeconds, qualificationTypeStatus,
+		description, test, answerKey, testDurationInSeconds, autoGranted,
+		autoGrantedValue)
+}
+
+// UpdateQualificationTypeCtx is like UpdateQualificationType, but the
+// request is bound to ctx.
+func (client amtClient) UpdateQualificationTypeCtx(ctx context.Context,
+	qualificationTypeId string,
+	retryDelayInSeconds int, qualificationTypeStatus, description, test,
+	answerKey string, testDurationInSeconds int, autoGranted bool,
+	autoGrantedValue int) (
+	amtgen.TxsdUpdateQualificationTypeResponse, error) {
 
 	// Prepare the request
 	var (
@@ -1194,7 +1629,7 @@ func (client amtClient) UpdateQualificationType(qualificationTypeId string,
 	// Send the request
 	req, err := client.signRequest("UpdateQualificationType", &request)
 	if err == nil {
-		err = client.sendRequest(req, &response)
+		err = client.sendRequest(ctx, req, &response)
 	}
 	return response, err
 }
@@ -7,6 +7,8 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 	"testing"
@@ -66,228 +68,118 @@ func closeSrv() {
 	srv = nil
 }
 
-func TestApproveAssignment(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call ApproveAssignment", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<ApproveAssignmentResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<ApproveAssignmentResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</ApproveAssignmentResult>
-				</ApproveAssignmentResponse>
-				`
-			result, err := client.ApproveAssignment(ASSIGNMENT_ID, FEEDBACK)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TApproveAssignmentResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.ApproveAssignmentResults, ShouldHaveLength, 1)
-				So(result.ApproveAssignmentResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"AssignmentId=" + ASSIGNMENT_ID,
-					"Operation=ApproveAssignment",
-					"RequesterFeedback=" + FEEDBACK,
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "ApproveAssignment", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
+// Operations which only report whether their request was valid. Each is
+// tested by replaying testdata/operations.json, so a call which flattens its
+// arguments differently from the recorded request finds no fixture.
+var replayedOperations = []struct {
+	operation string
+	call      func(client AmtClient) (interface{}, error)
+}{
+	{"ApproveAssignment", func(client AmtClient) (interface{}, error) {
+		return client.ApproveAssignment(ASSIGNMENT_ID, FEEDBACK)
+	}},
+	{"ApproveRejectedAssignment", func(client AmtClient) (interface{}, error) {
+		return client.ApproveRejectedAssignment(ASSIGNMENT_ID, FEEDBACK)
+	}},
+	{"AssignQualification", func(client AmtClient) (interface{}, error) {
+		return client.AssignQualification(QUAL_ID, WORKER_ID, QUAL_VALUE, false)
+	}},
+	{"BlockWorker", func(client AmtClient) (interface{}, error) {
+		return client.BlockWorker(WORKER_ID, FEEDBACK)
+	}},
+	{"ChangeHITTypeOfHIT", func(client AmtClient) (interface{}, error) {
+		return client.ChangeHITTypeOfHIT(HIT_ID, HIT_TYPE_ID)
+	}},
+	{"DisableHIT", func(client AmtClient) (interface{}, error) {
+		return client.DisableHIT(HIT_ID)
+	}},
+	{"DisposeHIT", func(client AmtClient) (interface{}, error) {
+		return client.DisposeHIT(HIT_ID)
+	}},
+	{"DisposeQualificationType", func(client AmtClient) (interface{}, error) {
+		return client.DisposeQualificationType(QUAL_ID)
+	}},
+	{"ExtendHIT", func(client AmtClient) (interface{}, error) {
+		return client.ExtendHIT(HIT_ID, 10, 20, "uniqueRequestToken")
+	}},
+	{"ForceExpireHIT", func(client AmtClient) (interface{}, error) {
+		return client.ForceExpireHIT(HIT_ID)
+	}},
+	{"GrantBonus", func(client AmtClient) (interface{}, error) {
+		return client.GrantBonus(WORKER_ID, ASSIGNMENT_ID, 1.5, FEEDBACK,
+			"uniqueRequestToken")
+	}},
+	{"GrantQualification", func(client AmtClient) (interface{}, error) {
+		return client.GrantQualification(QUAL_ID, QUAL_VALUE)
+	}},
+	{"NotifyWorkers", func(client AmtClient) (interface{}, error) {
+		return client.NotifyWorkers("subject", "messageText", []string{WORKER_ID})
+	}},
+	{"RejectAssignment", func(client AmtClient) (interface{}, error) {
+		return client.RejectAssignment(ASSIGNMENT_ID, FEEDBACK)
+	}},
+	{"RejectQualificationRequest", func(client AmtClient) (interface{}, error) {
+		return client.RejectQualificationRequest(QUAL_ID, FEEDBACK)
+	}},
+	{"RevokeQualification", func(client AmtClient) (interface{}, error) {
+		return client.RevokeQualification(WORKER_ID, QUAL_ID, FEEDBACK)
+	}},
+	{"SendTestEventNotification", func(client AmtClient) (interface{}, error) {
+		return client.SendTestEventNotification(nil, "testEventType")
+	}},
+	{"SetHITAsReviewing", func(client AmtClient) (interface{}, error) {
+		return client.SetHITAsReviewing(HIT_ID, true)
+	}},
+	{"SetHITTypeNotification", func(client AmtClient) (interface{}, error) {
+		return client.SetHITTypeNotification(HIT_TYPE_ID, nil, true)
+	}},
+	{"UnblockWorker", func(client AmtClient) (interface{}, error) {
+		return client.UnblockWorker(WORKER_ID, FEEDBACK)
+	}},
+	{"UpdateQualificationScore", func(client AmtClient) (interface{}, error) {
+		return client.UpdateQualificationScore(QUAL_ID, WORKER_ID, QUAL_VALUE)
+	}},
 }
 
-func TestApproveRejectedAssignment(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call ApproveRejectedAssignment", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<ApproveRejectedAssignmentResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<ApproveRejectedAssignmentResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</ApproveRejectedAssignmentResult>
-				</ApproveRejectedAssignmentResponse>`
-			result, err := client.ApproveRejectedAssignment(ASSIGNMENT_ID, FEEDBACK)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TApproveRejectedAssignmentResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.ApproveRejectedAssignmentResults, ShouldHaveLength, 1)
-				So(result.ApproveRejectedAssignmentResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"AssignmentId=" + ASSIGNMENT_ID,
-					"Operation=ApproveRejectedAssignment",
-					"RequesterFeedback=" + FEEDBACK,
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "ApproveRejectedAssignment", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
+// The IsValid flags of the requests echoed in an operation's results
+func requestValidity(operation string, response interface{}) []string {
+	var valid []string
+	results := reflect.ValueOf(response).FieldByName(operation + "Results")
+	for i := 0; i < results.Len(); i++ {
+		request := results.Index(i).Elem().FieldByName("Request").Interface()
+		valid = append(valid, string(request.(*amtgen.TxsdRequest).IsValid))
+	}
+	return valid
 }
 
-func TestAssignQualification(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call AssignQualification", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<AssignQualificationResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<AssignQualificationResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</AssignQualificationResult>
-				</AssignQualificationResponse>`
-			result, err := client.AssignQualification(QUAL_ID, WORKER_ID, QUAL_VALUE, false)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TAssignQualificationResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.AssignQualificationResults, ShouldHaveLength, 1)
-				So(result.AssignQualificationResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"IntegerValue=" + QUAL_VALUE_STR,
-					"Operation=AssignQualification",
-					"QualificationTypeId=" + QUAL_ID,
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "AssignQualification", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-					"WorkerId=" + WORKER_ID,
+func TestReplayedOperations(t *testing.T) {
+	Convey("Given a client replaying testdata/operations.json", t, func() {
+		replayer, err := LoadReplayer(filepath.Join("testdata", "operations.json"))
+		So(err, ShouldBeNil)
+		client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY,
+			"http://amt.invalid", WithHTTPClient(&http.Client{Transport: replayer}),
+			WithRetryPolicy(nil))
+
+		for _, op := range replayedOperations {
+			op := op
+			Convey("When I call "+op.operation, func() {
+				response, err := op.call(client)
+
+				Convey("Then the recorded request was sent and was valid", func() {
+					So(err, ShouldBeNil)
+					So(requestValidity(op.operation, response), ShouldResemble,
+						[]string{"True"})
 				})
 			})
-		})
-	})
-}
+		}
 
-func TestBlockWorker(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call BlockWorker", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<BlockWorkerResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<BlockWorkerResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</BlockWorkerResult>
-				</BlockWorkerResponse>`
-			result, err := client.BlockWorker(WORKER_ID, FEEDBACK)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TBlockWorkerResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.BlockWorkerResults, ShouldHaveLength, 1)
-				So(result.BlockWorkerResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"Operation=BlockWorker",
-					"Reason=" + FEEDBACK,
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "BlockWorker", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-					"WorkerId=" + WORKER_ID,
-				})
-			})
-		})
-	})
-}
+		Convey("When I call every operation", func() {
+			for _, op := range replayedOperations {
+				op.call(client)
+			}
 
-func TestChangeHITTypeOfHIT(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call ChangeHITTypeOfHIT", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<ChangeHITTypeOfHITResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<ChangeHITTypeOfHITResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</ChangeHITTypeOfHITResult>
-				</ChangeHITTypeOfHITResponse>`
-			result, err := client.ChangeHITTypeOfHIT(HIT_ID, HIT_TYPE_ID)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TChangeHITTypeOfHITResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.ChangeHITTypeOfHITResults, ShouldHaveLength, 1)
-				So(result.ChangeHITTypeOfHITResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"HITId=" + HIT_ID,
-					"HITTypeId=" + HIT_TYPE_ID,
-					"Operation=ChangeHITTypeOfHIT",
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "ChangeHITTypeOfHIT", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
+			Convey("Then every fixture was replayed", func() {
+				So(replayer.Unused(), ShouldBeEmpty)
 			})
 		})
 	})
@@ -498,229 +390,6 @@ func TestCreateQualificationType(t *testing.T) {
 	})
 }
 
-func TestDisableHIT(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call DisableHIT", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<DisableHITResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<DisableHITResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</DisableHITResult>
-				</DisableHITResponse>`
-			result, err := client.DisableHIT(HIT_ID)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TDisableHITResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.DisableHITResults, ShouldHaveLength, 1)
-				So(result.DisableHITResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"HITId=" + HIT_ID,
-					"Operation=DisableHIT",
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "DisableHIT", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
-}
-
-func TestDisposeHIT(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call DisposeHIT", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<DisposeHITResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<DisposeHITResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</DisposeHITResult>
-				</DisposeHITResponse>`
-			result, err := client.DisposeHIT(HIT_ID)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TDisposeHITResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.DisposeHITResults, ShouldHaveLength, 1)
-				So(result.DisposeHITResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"HITId=" + HIT_ID,
-					"Operation=DisposeHIT",
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "DisposeHIT", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
-}
-
-func TestDisposeQualificationType(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call DisposeQualificationType", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<DisposeQualificationTypeResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<DisposeQualificationTypeResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</DisposeQualificationTypeResult>
-				</DisposeQualificationTypeResponse>`
-			result, err := client.DisposeQualificationType(QUAL_ID)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TDisposeQualificationTypeResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.DisposeQualificationTypeResults, ShouldHaveLength, 1)
-				So(result.DisposeQualificationTypeResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"Operation=DisposeQualificationType",
-					"QualificationTypeId=" + QUAL_ID,
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "DisposeQualificationType", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
-}
-
-func TestExtendHIT(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call ExtendHIT", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<ExtendHITResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<ExtendHITResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</ExtendHITResult>
-				</ExtendHITResponse>`
-			result, err := client.ExtendHIT(HIT_ID, 10, 20, "uniqueRequestToken")
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TExtendHITResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.ExtendHITResults, ShouldHaveLength, 1)
-				So(result.ExtendHITResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"ExpirationIncrementInSeconds=20",
-					"HITId=" + HIT_ID,
-					"MaxAssignmentsIncrement=10",
-					"Operation=ExtendHIT",
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "ExtendHIT", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"UniqueRequestToken=uniqueRequestToken",
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
-}
-
-func TestForceExpireHIT(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call ForceExpireHIT", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<ForceExpireHITResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<ForceExpireHITResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</ForceExpireHITResult>
-				</ForceExpireHITResponse>`
-			result, err := client.ForceExpireHIT(HIT_ID)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TForceExpireHITResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.ForceExpireHITResults, ShouldHaveLength, 1)
-				So(result.ForceExpireHITResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"HITId=" + HIT_ID,
-					"Operation=ForceExpireHIT",
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "ForceExpireHIT", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
-}
-
 func TestGetAccountBalance(t *testing.T) {
 	client := newTestClient()
 	defer closeSrv()
@@ -2034,146 +1703,6 @@ func TestGetReviewResultsForHIT(t *testing.T) {
 	})
 }
 
-func TestGrantBonus(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call GrantBonus", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<GrantBonusResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<GrantBonusResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</GrantBonusResult>
-				</GrantBonusResponse>`
-			result, err := client.GrantBonus(WORKER_ID, ASSIGNMENT_ID, 1.5, FEEDBACK, "uniqueRequestToken")
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TGrantBonusResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.GrantBonusResults, ShouldHaveLength, 1)
-				So(result.GrantBonusResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"AssignmentId=" + ASSIGNMENT_ID,
-					"BonusAmount.1.Amount=1.5",
-					"BonusAmount.1.CurrencyCode=USD",
-					"Operation=GrantBonus",
-					"Reason=" + FEEDBACK,
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "GrantBonus", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"UniqueRequestToken=uniqueRequestToken",
-					"Version=2014-08-15",
-					"WorkerId=" + WORKER_ID,
-				})
-			})
-		})
-	})
-}
-
-func TestGrantQualification(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call GrantQualification", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<GrantQualificationResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<GrantQualificationResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</GrantQualificationResult>
-				</GrantQualificationResponse>`
-			result, err := client.GrantQualification(QUAL_ID, QUAL_VALUE)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TGrantQualificationResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.GrantQualificationResults, ShouldHaveLength, 1)
-				So(result.GrantQualificationResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"IntegerValue=" + QUAL_VALUE_STR,
-					"Operation=GrantQualification",
-					"QualificationRequestId=" + QUAL_ID,
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "GrantQualification", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
-}
-
-func TestNotifyWorkers(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call NotifyWorkers", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<NotifyWorkersResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<NotifyWorkersResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</NotifyWorkersResult>
-				</NotifyWorkersResponse>`
-			result, err := client.NotifyWorkers("subject", "messageText", []string{WORKER_ID})
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TNotifyWorkersResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.NotifyWorkersResults, ShouldHaveLength, 1)
-				So(result.NotifyWorkersResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"MessageText=messageText",
-					"Operation=NotifyWorkers",
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "NotifyWorkers", srvUrlTimestamp),
-					"Subject=subject",
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-					"WorkerId=" + WORKER_ID,
-				})
-			})
-		})
-	})
-}
-
 func TestRegisterHITType(t *testing.T) {
 	client := newTestClient()
 	defer closeSrv()
@@ -2227,142 +1756,6 @@ func TestRegisterHITType(t *testing.T) {
 	})
 }
 
-func TestRejectAssignment(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call RejectAssignment", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<RejectAssignmentResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<RejectAssignmentResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</RejectAssignmentResult>
-				</RejectAssignmentResponse>`
-			result, err := client.RejectAssignment(ASSIGNMENT_ID, FEEDBACK)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TRejectAssignmentResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.RejectAssignmentResults, ShouldHaveLength, 1)
-				So(result.RejectAssignmentResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"AssignmentId=" + ASSIGNMENT_ID,
-					"Operation=RejectAssignment",
-					"RequesterFeedback=" + FEEDBACK,
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "RejectAssignment", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
-}
-
-func TestRejectQualificationRequest(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call RejectQualificationRequest", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<RejectQualificationRequestResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<RejectQualificationRequestResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</RejectQualificationRequestResult>
-				</RejectQualificationRequestResponse>`
-			result, err := client.RejectQualificationRequest(QUAL_ID, FEEDBACK)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TRejectQualificationRequestResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.RejectQualificationRequestResults, ShouldHaveLength, 1)
-				So(result.RejectQualificationRequestResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"Operation=RejectQualificationRequest",
-					"QualificationRequestId=" + QUAL_ID,
-					"Reason=" + FEEDBACK,
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "RejectQualificationRequest", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
-}
-
-func TestRevokeQualification(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call RevokeQualification", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<RevokeQualificationResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<RevokeQualificationResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</RevokeQualificationResult>
-				</RevokeQualificationResponse>`
-			result, err := client.RevokeQualification(WORKER_ID, QUAL_ID, FEEDBACK)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TRevokeQualificationResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.RevokeQualificationResults, ShouldHaveLength, 1)
-				So(result.RevokeQualificationResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"Operation=RevokeQualification",
-					"QualificationTypeId=" + QUAL_ID,
-					"Reason=" + FEEDBACK,
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "RevokeQualification", srvUrlTimestamp),
-					"SubjectId=" + WORKER_ID,
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
-}
-
 func TestSearchHITs(t *testing.T) {
 	client := newTestClient()
 	defer closeSrv()
@@ -2580,231 +1973,6 @@ func TestSearchQualificationTypes(t *testing.T) {
 	})
 }
 
-func TestSendTestEventNotification(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call SendTestEventNotification", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<SendTestEventNotificationResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<SendTestEventNotificationResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</SendTestEventNotificationResult>
-				</SendTestEventNotificationResponse>`
-			result, err := client.SendTestEventNotification(nil, "testEventType")
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TSendTestEventNotificationResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.SendTestEventNotificationResults, ShouldHaveLength, 1)
-				So(result.SendTestEventNotificationResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"Operation=SendTestEventNotification",
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "SendTestEventNotification", srvUrlTimestamp),
-					"TestEventType=testEventType",
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
-}
-
-func TestSetHITAsReviewing(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call SetHITAsReviewing", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<SetHITAsReviewingResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<SetHITAsReviewingResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</SetHITAsReviewingResult>
-				</SetHITAsReviewingResponse>`
-			result, err := client.SetHITAsReviewing(HIT_ID, true)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TSetHITAsReviewingResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.SetHITAsReviewingResults, ShouldHaveLength, 1)
-				So(result.SetHITAsReviewingResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"HITId=" + HIT_ID,
-					"Operation=SetHITAsReviewing",
-					"Revert=true",
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "SetHITAsReviewing", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
-}
-
-func TestSetHITTypeNotification(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call SetHITTypeNotification", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<SetHITTypeNotificationResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<SetHITTypeNotificationResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</SetHITTypeNotificationResult>
-				</SetHITTypeNotificationResponse>`
-			result, err := client.SetHITTypeNotification(HIT_TYPE_ID, nil, true)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TSetHITTypeNotificationResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.SetHITTypeNotificationResults, ShouldHaveLength, 1)
-				So(result.SetHITTypeNotificationResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"Active=true",
-					"HITTypeId=" + HIT_TYPE_ID,
-					"Operation=SetHITTypeNotification",
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "SetHITTypeNotification", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
-}
-
-func TestUnblockWorker(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call UnblockWorker", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<UnblockWorkerResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<UnblockWorkerResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</UnblockWorkerResult>
-				</UnblockWorkerResponse>`
-			result, err := client.UnblockWorker(WORKER_ID, FEEDBACK)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TUnblockWorkerResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.UnblockWorkerResults, ShouldHaveLength, 1)
-				So(result.UnblockWorkerResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"Operation=UnblockWorker",
-					"Reason=" + FEEDBACK,
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "UnblockWorker", srvUrlTimestamp),
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-					"WorkerId=" + WORKER_ID,
-				})
-			})
-		})
-	})
-}
-
-func TestUpdateQualificationScore(t *testing.T) {
-	client := newTestClient()
-	defer closeSrv()
-	Convey("Given an initialized client", t, func() {
-		Convey("When I call UpdateQualificationScore", func() {
-			srvResponse = `<?xml version="1.0"?>` + "\n" +
-				`<UpdateQualificationScoreResponse>` +
-				`<OperationRequest>` +
-				`<RequestId>5d38ca17-77d6-4caa-b3f0-7cabc17c56ad</RequestId>` +
-				`</OperationRequest>
-				<UpdateQualificationScoreResult>
-					<Request>
-						<IsValid>True</IsValid>
-					</Request>
-				</UpdateQualificationScoreResult>
-				</UpdateQualificationScoreResponse>`
-			result, err := client.UpdateQualificationScore(QUAL_ID, WORKER_ID, QUAL_VALUE)
-			Convey("Then the correct result was returned", func() {
-				var (
-					res amtgen.TUpdateQualificationScoreResult
-				)
-				res.Request = &amtgen.TxsdRequest{}
-				res.Request.IsValid = "True"
-				So(result.UpdateQualificationScoreResults, ShouldHaveLength, 1)
-				So(result.UpdateQualificationScoreResults[0], ShouldResemble, &res)
-			})
-			Convey("Then the operation succeeded", func() {
-				So(err, ShouldBeNil)
-			})
-			Convey("Then the correct URL was fetched", func() {
-				So(srvUrlArgs, ShouldResemble, []string{
-					"AWSAccessKeyId=" + FAKE_ACCESS_KEY,
-					"IntegerValue=" + QUAL_VALUE_STR,
-					"Operation=UpdateQualificationScore",
-					"QualificationTypeId=" + QUAL_ID,
-					"Service=AWSMechanicalTurkRequester",
-					"Signature=" + client.signatureFor("AWSMechanicalTurkRequester", "UpdateQualificationScore", srvUrlTimestamp),
-					"SubjectId=" + WORKER_ID,
-					"Timestamp=" + srvUrlTimestamp,
-					"Version=2014-08-15",
-				})
-			})
-		})
-	})
-}
-
 func TestUpdateQualificationType(t *testing.T) {
 	client := newTestClient()
 	defer closeSrv()
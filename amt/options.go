@@ -0,0 +1,137 @@
+package amt
+
+import (
+	"net/http"
+)
+
+// Option customizes a client created by NewClient or NewJSONClient.
+type Option func(*clientOptions)
+
+// Middleware wraps the transport used to send requests, e.g. to log them,
+// record metrics or add headers. It sees every attempt at a request: the
+// rate limiter and retry logic sit above the transport, so a request retried
+// three times passes through the middleware three times, each time after
+// waiting on the limiter.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to the http.RoundTripper interface,
+// which is convenient when writing Middleware.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls fn(req).
+func (fn RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// The settings collected from a client's options
+type clientOptions struct {
+	httpClient    *http.Client
+	middleware    []Middleware
+	retry         *RetryPolicy
+	limiter       Limiter
+	audit         *AuditLog
+	metrics       Metrics
+	postThreshold int
+}
+
+// WithHTTPClient sends requests through the given client instead of
+// http.DefaultClient, e.g. to set a timeout, a proxy or custom TLS roots. The
+// client is not modified.
+func WithHTTPClient(client *http.Client) Option {
+	return func(options *clientOptions) {
+		options.httpClient = client
+	}
+}
+
+// WithMiddleware wraps the client's transport in the given middleware. The
+// first middleware given is the outermost, so it sees each request first and
+// each response last. Repeated uses append to the chain.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(options *clientOptions) {
+		options.middleware = append(options.middleware, middleware...)
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy. A nil policy disables retries.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(options *clientOptions) {
+		options.retry = policy
+	}
+}
+
+// WithLimiter replaces the client's DefaultLimiter, e.g. with a TokenBucket
+// allowing bursts, or with a SharedLimiter to share one budget between
+// clients. A nil limiter disables rate limiting.
+func WithLimiter(limiter Limiter) Option {
+	return func(options *clientOptions) {
+		options.limiter = limiter
+	}
+}
+
+// WithAuditLog records each mutating call the client makes in the given log.
+func WithAuditLog(log *AuditLog) Option {
+	return func(options *clientOptions) {
+		options.audit = log
+	}
+}
+
+// WithMetrics reports the client's calls and rate limiter waits to the given
+// metrics, such as a MetricsRegistry.
+func WithMetrics(metrics Metrics) Option {
+	return func(options *clientOptions) {
+		options.metrics = metrics
+	}
+}
+
+// WithPostThreshold sets the length, in bytes, of the encoded arguments above
+// which a request is sent as a form-encoded POST rather than a GET, in place
+// of DEFAULT_POST_THRESHOLD. A threshold of 0 sends every request as a GET,
+// and a negative one sends every request as a POST. The JSON API always uses
+// POST, so NewJSONClient ignores this option.
+func WithPostThreshold(threshold int) Option {
+	return func(options *clientOptions) {
+		options.postThreshold = threshold
+	}
+}
+
+// Apply a list of options to the defaults.
+func newClientOptions(opts []Option) clientOptions {
+	options := clientOptions{
+		retry:         DefaultRetryPolicy(),
+		limiter:       DefaultLimiter(),
+		postThreshold: DEFAULT_POST_THRESHOLD,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// Build the HTTP client described by the options: a copy of the chosen
+// client whose transport is wrapped in the middleware chain.
+func (options clientOptions) client() *http.Client {
+	if len(options.middleware) == 0 {
+		return options.httpClient
+	}
+	client := http.Client{}
+	if options.httpClient != nil {
+		client = *options.httpClient
+	}
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(options.middleware) - 1; i >= 0; i-- {
+		transport = options.middleware[i](transport)
+	}
+	client.Transport = transport
+	return &client
+}
+
+// Return the client to send requests with, defaulting to http.DefaultClient.
+func httpClientOrDefault(client *http.Client) *http.Client {
+	if client == nil {
+		return http.DefaultClient
+	}
+	return client
+}
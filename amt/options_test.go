@@ -0,0 +1,164 @@
+package amt
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Middleware which records its name before and after each request
+func recordingMiddleware(name string, log *[]string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			*log = append(*log, name+">")
+			req.Header.Add("X-Test", name)
+			resp, err := next.RoundTrip(req)
+			*log = append(*log, "<"+name)
+			return resp, err
+		})
+	}
+}
+
+// A transport which answers every request with BALANCE_RESPONSE, recording
+// the X-Test header of each request
+type cannedTransport struct {
+	headers []string
+}
+
+func (transport *cannedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport.headers = append(transport.headers, req.Header.Get("X-Test"))
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Body:       ioutil.NopCloser(strings.NewReader(BALANCE_RESPONSE)),
+		Request:    req,
+	}, nil
+}
+
+func TestOptions(t *testing.T) {
+	Convey("Given a server which is throttling requests", t, func() {
+		srv := newFlakyServer(1, http.StatusServiceUnavailable)
+		defer srv.Close()
+
+		Convey("When I send a request through two middleware", func() {
+			var log []string
+			client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, srv.URL,
+				WithMiddleware(recordingMiddleware("outer", &log)),
+				WithMiddleware(recordingMiddleware("inner", &log)),
+				WithRetryPolicy(&RetryPolicy{MaxAttempts: 2}))
+			_, err := client.GetAccountBalance()
+
+			Convey("Then each attempt passes through both, outermost first", func() {
+				So(err, ShouldBeNil)
+				So(srv.attempts, ShouldEqual, 2)
+				So(log, ShouldResemble, []string{
+					"outer>", "inner>", "<inner", "<outer",
+					"outer>", "inner>", "<inner", "<outer",
+				})
+			})
+		})
+
+		Convey("When retries are disabled", func() {
+			client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, srv.URL,
+				WithRetryPolicy(nil))
+			_, err := client.GetAccountBalance()
+
+			Convey("Then the request is attempted once", func() {
+				So(err, ShouldNotBeNil)
+				So(srv.attempts, ShouldEqual, 1)
+			})
+		})
+	})
+
+	Convey("Given a server which records how requests are sent", t, func() {
+		var (
+			methods   []string
+			questions []string
+			rawQuery  []string
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rawQuery = append(rawQuery, r.URL.RawQuery)
+			r.ParseForm()
+			methods = append(methods, r.Method)
+			questions = append(questions, r.Form.Get("Question"))
+			fmt.Fprint(w, BALANCE_RESPONSE)
+		}))
+		defer srv.Close()
+		question := "<HTMLQuestion>" + strings.Repeat("&x=y ", 1000) + "</HTMLQuestion>"
+
+		Convey("When a client sends a short and a long request", func() {
+			client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, srv.URL,
+				WithLimiter(nil), WithRetryPolicy(nil))
+			client.GetAccountBalance()
+			client.CreateHITFromHITTypeId("HT1", question, "", nil, 60, 1, nil, nil,
+				"", "")
+
+			Convey("Then only the long one is sent as a POST", func() {
+				So(methods, ShouldResemble, []string{"GET", "POST"})
+				So(rawQuery[0], ShouldContainSubstring, "Operation=GetAccountBalance")
+				So(rawQuery[1], ShouldEqual, "")
+				So(questions[1], ShouldEqual, question)
+			})
+		})
+
+		Convey("When a client always sends POSTs", func() {
+			client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, srv.URL,
+				WithLimiter(nil), WithPostThreshold(-1))
+			_, err := client.GetAccountBalance()
+
+			Convey("Then a short request is sent as a POST", func() {
+				So(err, ShouldBeNil)
+				So(methods, ShouldResemble, []string{"POST"})
+			})
+		})
+
+		Convey("When a client never sends POSTs", func() {
+			client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, srv.URL,
+				WithLimiter(nil), WithRetryPolicy(nil), WithPostThreshold(0))
+			client.CreateHITFromHITTypeId("HT1", question, "", nil, 60, 1, nil, nil,
+				"", "")
+
+			Convey("Then a long request is sent as a GET", func() {
+				So(methods, ShouldResemble, []string{"GET"})
+				So(questions[0], ShouldEqual, question)
+			})
+		})
+	})
+
+	Convey("Given an HTTP client with its own transport", t, func() {
+		transport := &cannedTransport{}
+		httpClient := &http.Client{Timeout: time.Minute, Transport: transport}
+
+		Convey("When a client uses it with middleware", func() {
+			var log []string
+			client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY,
+				"http://amt.invalid", WithHTTPClient(httpClient),
+				WithMiddleware(recordingMiddleware("mw", &log)))
+			_, err := client.GetAccountBalance()
+
+			Convey("Then requests are sent through it, wrapped in the middleware", func() {
+				So(err, ShouldBeNil)
+				So(transport.headers, ShouldResemble, []string{"mw"})
+				So(client.(*amtClient).HTTPClient.Timeout, ShouldEqual, time.Minute)
+			})
+
+			Convey("Then the given client is not modified", func() {
+				So(httpClient.Transport, ShouldEqual, http.RoundTripper(transport))
+			})
+		})
+
+		Convey("When a JSON client uses it", func() {
+			client := NewJSONClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY,
+				"http://amt.invalid", WithHTTPClient(httpClient))
+
+			Convey("Then it is the client's HTTP client", func() {
+				So(client.(*jsonClient).HTTPClient, ShouldEqual, httpClient)
+			})
+		})
+	})
+}
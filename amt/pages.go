@@ -0,0 +1,454 @@
+package amt
+
+import (
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+)
+
+const (
+	// The largest page size AMT accepts for paged operations
+	MAX_PAGE_SIZE = 100
+)
+
+// pager walks the pages of a paged operation, fetching each page only when
+// the previous one has been consumed.
+type pager struct {
+
+	// Fetches the given page, returning the number of items on it and the
+	// total number of results reported by AMT
+	fetch func(pageNumber int) (count, total int, err error)
+
+	// Set by fetch when the page is known to be the last, for operations
+	// whose pages hold more than one list of items
+	last bool
+
+	pageSize   int
+	pageNumber int
+	count      int
+	index      int
+	seen       int
+	total      int
+	done       bool
+	err        error
+}
+
+func newPager(pageSize int) pager {
+	if pageSize <= 0 || pageSize > MAX_PAGE_SIZE {
+		pageSize = MAX_PAGE_SIZE
+	}
+	return pager{pageSize: pageSize, index: -1}
+}
+
+// Advance to the next item, fetching a new page if necessary.
+func (p *pager) next() bool {
+	if p.done || p.err != nil {
+		return false
+	}
+	p.index++
+	for p.index >= p.count {
+		if p.pageNumber > 0 && (p.last || p.count < p.pageSize ||
+			(p.total > 0 && p.seen >= p.total)) {
+			p.done = true
+			return false
+		}
+		p.pageNumber++
+		count, total, err := p.fetch(p.pageNumber)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.count, p.total, p.index = count, total, 0
+		if count == 0 {
+			p.done = true
+			return false
+		}
+	}
+	p.seen++
+	return true
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (p *pager) Err() error {
+	return p.err
+}
+
+// TotalNumResults returns the total number of results reported by AMT, or 0
+// if no page has been fetched yet.
+func (p *pager) TotalNumResults() int {
+	return p.total
+}
+
+// HITIterator streams HITs from every page of a paged operation. Call Next
+// before each call to HIT, and check Err once Next returns false.
+type HITIterator struct {
+	pager
+	page []*amtgen.Thit
+}
+
+// Next advances to the next HIT, returning false when there are no more HITs
+// or a request fails.
+func (it *HITIterator) Next() bool {
+	return it.next()
+}
+
+// HIT returns the current HIT.
+func (it *HITIterator) HIT() *amtgen.Thit {
+	return it.page[it.index]
+}
+
+// AssignmentIterator streams assignments from every page of a paged
+// operation. Call Next before each call to Assignment, and check Err once
+// Next returns false.
+type AssignmentIterator struct {
+	pager
+	page []*amtgen.TAssignment
+}
+
+// Next advances to the next assignment, returning false when there are no
+// more assignments or a request fails.
+func (it *AssignmentIterator) Next() bool {
+	return it.next()
+}
+
+// Assignment returns the current assignment.
+func (it *AssignmentIterator) Assignment() *amtgen.TAssignment {
+	return it.page[it.index]
+}
+
+// WorkerBlockIterator streams blocked workers from every page of
+// GetBlockedWorkers.
+type WorkerBlockIterator struct {
+	pager
+	page []*amtgen.TWorkerBlock
+}
+
+// Next advances to the next blocked worker, returning false when there are no
+// more workers or a request fails.
+func (it *WorkerBlockIterator) Next() bool {
+	return it.next()
+}
+
+// WorkerBlock returns the current blocked worker.
+func (it *WorkerBlockIterator) WorkerBlock() *amtgen.TWorkerBlock {
+	return it.page[it.index]
+}
+
+// BonusPaymentIterator streams bonus payments from every page of
+// GetBonusPayments.
+type BonusPaymentIterator struct {
+	pager
+	page []*amtgen.TBonusPayment
+}
+
+// Next advances to the next bonus payment, returning false when there are no
+// more payments or a request fails.
+func (it *BonusPaymentIterator) Next() bool {
+	return it.next()
+}
+
+// BonusPayment returns the current bonus payment.
+func (it *BonusPaymentIterator) BonusPayment() *amtgen.TBonusPayment {
+	return it.page[it.index]
+}
+
+// QualificationIterator streams Qualifications from every page of
+// GetQualificationsForQualificationType.
+type QualificationIterator struct {
+	pager
+	page []*amtgen.TQualification
+}
+
+// Next advances to the next Qualification, returning false when there are no
+// more Qualifications or a request fails.
+func (it *QualificationIterator) Next() bool {
+	return it.next()
+}
+
+// Qualification returns the current Qualification.
+func (it *QualificationIterator) Qualification() *amtgen.TQualification {
+	return it.page[it.index]
+}
+
+// QualificationRequestIterator streams Qualification requests from every page
+// of GetQualificationRequests.
+type QualificationRequestIterator struct {
+	pager
+	page []*amtgen.TQualificationRequest
+}
+
+// Next advances to the next Qualification request, returning false when there
+// are no more requests or a request fails.
+func (it *QualificationRequestIterator) Next() bool {
+	return it.next()
+}
+
+// QualificationRequest returns the current Qualification request.
+func (it *QualificationRequestIterator) QualificationRequest() *amtgen.TQualificationRequest {
+	return it.page[it.index]
+}
+
+// QualificationTypeIterator streams Qualification types from every page of
+// SearchQualificationTypes.
+type QualificationTypeIterator struct {
+	pager
+	page []*amtgen.TQualificationType
+}
+
+// Next advances to the next Qualification type, returning false when there
+// are no more types or a request fails.
+func (it *QualificationTypeIterator) Next() bool {
+	return it.next()
+}
+
+// QualificationType returns the current Qualification type.
+func (it *QualificationTypeIterator) QualificationType() *amtgen.TQualificationType {
+	return it.page[it.index]
+}
+
+// ReviewIterator streams the review results and actions for a HIT and its
+// assignments from every page of GetReviewResultsForHIT. Each item is either
+// a result or an action. Call Next before each call to ReviewResult or
+// ReviewAction, and check Err once Next returns false.
+type ReviewIterator struct {
+	pager
+	results []*amtgen.TReviewResultDetail
+	actions []*amtgen.TReviewActionDetail
+}
+
+// Next advances to the next review result or action, returning false when
+// there are no more or a request fails.
+func (it *ReviewIterator) Next() bool {
+	return it.next()
+}
+
+// ReviewResult returns the current item if it is a review result, or nil if
+// it is an action.
+func (it *ReviewIterator) ReviewResult() *amtgen.TReviewResultDetail {
+	if it.index < len(it.results) {
+		return it.results[it.index]
+	}
+	return nil
+}
+
+// ReviewAction returns the current item if it is a review action, or nil if
+// it is a result.
+func (it *ReviewIterator) ReviewAction() *amtgen.TReviewActionDetail {
+	if it.index >= len(it.results) {
+		return it.actions[it.index-len(it.results)]
+	}
+	return nil
+}
+
+// SearchAllHITs iterates over every HIT returned by SearchHITs. A pageSize of
+// 0 uses the largest page size AMT allows.
+func SearchAllHITs(client AmtClient, sortProperty string, sortAscending bool,
+	pageSize int) *HITIterator {
+
+	it := &HITIterator{pager: newPager(pageSize)}
+	it.fetch = func(pageNumber int) (int, int, error) {
+		resp, err := client.SearchHITs(sortProperty, sortAscending,
+			it.pageSize, pageNumber)
+		if err != nil || len(resp.SearchHITsResults) == 0 {
+			return 0, 0, err
+		}
+		result := resp.SearchHITsResults[0]
+		it.page = result.Hits
+		return len(it.page), int(result.TotalNumResults), nil
+	}
+	return it
+}
+
+// GetAllReviewableHITs iterates over every HIT returned by GetReviewableHITs.
+// A pageSize of 0 uses the largest page size AMT allows.
+func GetAllReviewableHITs(client AmtClient, hitTypeId, status,
+	sortProperty string, sortAscending bool, pageSize int) *HITIterator {
+
+	it := &HITIterator{pager: newPager(pageSize)}
+	it.fetch = func(pageNumber int) (int, int, error) {
+		resp, err := client.GetReviewableHITs(hitTypeId, status, sortProperty,
+			sortAscending, it.pageSize, pageNumber)
+		if err != nil || len(resp.GetReviewableHITsResults) == 0 {
+			return 0, 0, err
+		}
+		result := resp.GetReviewableHITsResults[0]
+		it.page = result.Hits
+		return len(it.page), int(result.TotalNumResults), nil
+	}
+	return it
+}
+
+// GetAllHITsForQualificationType iterates over every HIT returned by
+// GetHITsForQualificationType. A pageSize of 0 uses the largest page size AMT
+// allows.
+func GetAllHITsForQualificationType(client AmtClient,
+	qualificationTypeId string, pageSize int) *HITIterator {
+
+	it := &HITIterator{pager: newPager(pageSize)}
+	it.fetch = func(pageNumber int) (int, int, error) {
+		resp, err := client.GetHITsForQualificationType(qualificationTypeId,
+			it.pageSize, pageNumber)
+		if err != nil || len(resp.GetHITsForQualificationTypeResults) == 0 {
+			return 0, 0, err
+		}
+		result := resp.GetHITsForQualificationTypeResults[0]
+		it.page = result.Hits
+		return len(it.page), int(result.TotalNumResults), nil
+	}
+	return it
+}
+
+// GetAllAssignmentsForHIT iterates over every assignment returned by
+// GetAssignmentsForHIT. A pageSize of 0 uses the largest page size AMT allows.
+func GetAllAssignmentsForHIT(client AmtClient, hitId string,
+	assignmentStatuses []string, sortProperty string, sortAscending bool,
+	pageSize int) *AssignmentIterator {
+
+	it := &AssignmentIterator{pager: newPager(pageSize)}
+	it.fetch = func(pageNumber int) (int, int, error) {
+		resp, err := client.GetAssignmentsForHIT(hitId, assignmentStatuses,
+			sortProperty, sortAscending, it.pageSize, pageNumber)
+		if err != nil || len(resp.GetAssignmentsForHITResults) == 0 {
+			return 0, 0, err
+		}
+		result := resp.GetAssignmentsForHITResults[0]
+		it.page = result.Assignments
+		return len(it.page), int(result.TotalNumResults), nil
+	}
+	return it
+}
+
+// GetAllBlockedWorkers iterates over every worker returned by
+// GetBlockedWorkers. A pageSize of 0 uses the largest page size AMT allows.
+func GetAllBlockedWorkers(client AmtClient, pageSize int) *WorkerBlockIterator {
+	it := &WorkerBlockIterator{pager: newPager(pageSize)}
+	it.fetch = func(pageNumber int) (int, int, error) {
+		resp, err := client.GetBlockedWorkers(it.pageSize, pageNumber)
+		if err != nil || len(resp.GetBlockedWorkersResults) == 0 {
+			return 0, 0, err
+		}
+		result := resp.GetBlockedWorkersResults[0]
+		it.page = result.WorkerBlocks
+		return len(it.page), int(result.TotalNumResults), nil
+	}
+	return it
+}
+
+// GetAllBonusPayments iterates over every payment returned by
+// GetBonusPayments. A pageSize of 0 uses the largest page size AMT allows.
+func GetAllBonusPayments(client AmtClient, hitId, assignmentId string,
+	pageSize int) *BonusPaymentIterator {
+
+	it := &BonusPaymentIterator{pager: newPager(pageSize)}
+	it.fetch = func(pageNumber int) (int, int, error) {
+		resp, err := client.GetBonusPayments(hitId, assignmentId, it.pageSize,
+			pageNumber)
+		if err != nil || len(resp.GetBonusPaymentsResults) == 0 {
+			return 0, 0, err
+		}
+		result := resp.GetBonusPaymentsResults[0]
+		it.page = result.BonusPayments
+		return len(it.page), int(result.TotalNumResults), nil
+	}
+	return it
+}
+
+// GetAllQualificationsForQualificationType iterates over every Qualification
+// returned by GetQualificationsForQualificationType. A pageSize of 0 uses the
+// largest page size AMT allows.
+func GetAllQualificationsForQualificationType(client AmtClient,
+	qualificationTypeId string, isGranted bool,
+	pageSize int) *QualificationIterator {
+
+	it := &QualificationIterator{pager: newPager(pageSize)}
+	it.fetch = func(pageNumber int) (int, int, error) {
+		resp, err := client.GetQualificationsForQualificationType(
+			qualificationTypeId, isGranted, it.pageSize, pageNumber)
+		if err != nil ||
+			len(resp.GetQualificationsForQualificationTypeResults) == 0 {
+			return 0, 0, err
+		}
+		result := resp.GetQualificationsForQualificationTypeResults[0]
+		it.page = result.Qualifications
+		return len(it.page), int(result.TotalNumResults), nil
+	}
+	return it
+}
+
+// GetAllQualificationRequests iterates over every request returned by
+// GetQualificationRequests. A pageSize of 0 uses the largest page size AMT
+// allows.
+func GetAllQualificationRequests(client AmtClient, qualificationTypeId,
+	sortProperty string, sortAscending bool,
+	pageSize int) *QualificationRequestIterator {
+
+	it := &QualificationRequestIterator{pager: newPager(pageSize)}
+	it.fetch = func(pageNumber int) (int, int, error) {
+		resp, err := client.GetQualificationRequests(qualificationTypeId,
+			sortProperty, sortAscending, it.pageSize, pageNumber)
+		if err != nil || len(resp.GetQualificationRequestsResults) == 0 {
+			return 0, 0, err
+		}
+		result := resp.GetQualificationRequestsResults[0]
+		it.page = result.QualificationRequests
+		return len(it.page), int(result.TotalNumResults), nil
+	}
+	return it
+}
+
+// SearchAllQualificationTypes iterates over every Qualification type returned
+// by SearchQualificationTypes. A pageSize of 0 uses the largest page size AMT
+// allows.
+func SearchAllQualificationTypes(client AmtClient, query,
+	sortProperty string, sortAscending bool, pageSize int,
+	mustBeRequestable, mustBeOwnedByCaller bool) *QualificationTypeIterator {
+
+	it := &QualificationTypeIterator{pager: newPager(pageSize)}
+	it.fetch = func(pageNumber int) (int, int, error) {
+		resp, err := client.SearchQualificationTypes(query, sortProperty,
+			sortAscending, it.pageSize, pageNumber, mustBeRequestable,
+			mustBeOwnedByCaller)
+		if err != nil || len(resp.SearchQualificationTypesResults) == 0 {
+			return 0, 0, err
+		}
+		result := resp.SearchQualificationTypesResults[0]
+		it.page = result.QualificationTypes
+		return len(it.page), int(result.TotalNumResults), nil
+	}
+	return it
+}
+
+// GetAllReviewResultsForHIT iterates over every review result and action
+// returned by GetReviewResultsForHIT, from both the HIT's review report and
+// its assignments'. The results of each page come before its actions. A
+// pageSize of 0 uses the largest page size AMT allows. AMT reports a total
+// for each list, so TotalNumResults is always 0.
+func GetAllReviewResultsForHIT(client AmtClient, hitId string,
+	policyLevels []string, retrieveActions, retrieveResults bool,
+	pageSize int) *ReviewIterator {
+
+	it := &ReviewIterator{pager: newPager(pageSize)}
+	it.fetch = func(pageNumber int) (int, int, error) {
+		it.results, it.actions, it.last = nil, nil, true
+		resp, err := client.GetReviewResultsForHIT(hitId, policyLevels,
+			retrieveActions, retrieveResults, it.pageSize, pageNumber)
+		if err != nil || len(resp.GetReviewResultsForHITResults) == 0 {
+			return 0, 0, err
+		}
+		result := resp.GetReviewResultsForHITResults[0]
+		for _, report := range []*amtgen.TReviewReport{result.HITReviewReport,
+			result.AssignmentReviewReport} {
+			if report == nil {
+				continue
+			}
+			it.results = append(it.results, report.ReviewResults...)
+			it.actions = append(it.actions, report.ReviewActions...)
+
+			// Another page is needed if any list filled this one
+			if len(report.ReviewResults) >= it.pageSize ||
+				len(report.ReviewActions) >= it.pageSize {
+				it.last = false
+			}
+		}
+		return len(it.results) + len(it.actions), 0, nil
+	}
+	return it
+}
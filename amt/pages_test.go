@@ -0,0 +1,167 @@
+package amt
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// A test server which pages through the given number of HITs
+func newPagingServer(totalHits int, pages *[]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		pageSize, _ := strconv.Atoi(r.Form.Get("PageSize"))
+		pageNumber, _ := strconv.Atoi(r.Form.Get("PageNumber"))
+		*pages = append(*pages, pageNumber)
+		var hits string
+		for i := (pageNumber - 1) * pageSize; i < pageNumber*pageSize && i < totalHits; i++ {
+			hits += fmt.Sprintf("<HIT><HITId>HIT%d</HITId></HIT>", i)
+		}
+		fmt.Fprintf(w, `<?xml version="1.0"?>`+"\n"+
+			`<SearchHITsResponse>`+
+			`<OperationRequest><RequestId>%s</RequestId></OperationRequest>`+
+			`<SearchHITsResult><Request><IsValid>True</IsValid></Request>`+
+			`<PageNumber>%d</PageNumber><TotalNumResults>%d</TotalNumResults>%s`+
+			`</SearchHITsResult></SearchHITsResponse>`,
+			REQUEST_ID, pageNumber, totalHits, hits)
+	}))
+}
+
+func TestSearchAllHITs(t *testing.T) {
+	Convey("Given an account with five HITs", t, func() {
+		var pages []int
+		srv := newPagingServer(5, &pages)
+		defer srv.Close()
+		client := newRetryClient(srv.URL, 1)
+
+		Convey("When I iterate over every HIT two at a time", func() {
+			var hitIds []string
+			it := SearchAllHITs(client, "CreationTime", true, 2)
+			for it.Next() {
+				hitIds = append(hitIds, string(it.HIT().HITId))
+			}
+
+			Convey("Then every HIT is returned once", func() {
+				So(it.Err(), ShouldBeNil)
+				So(hitIds, ShouldResemble, []string{"HIT0", "HIT1", "HIT2", "HIT3", "HIT4"})
+				So(it.TotalNumResults(), ShouldEqual, 5)
+			})
+			Convey("Then each page is fetched once", func() {
+				So(pages, ShouldResemble, []int{1, 2, 3})
+			})
+		})
+
+		Convey("When I stop iterating early", func() {
+			it := SearchAllHITs(client, "CreationTime", true, 2)
+			it.Next()
+
+			Convey("Then later pages are not fetched", func() {
+				So(pages, ShouldResemble, []int{1})
+			})
+		})
+
+		Convey("When the page size divides the total", func() {
+			var count int
+			it := SearchAllHITs(client, "CreationTime", true, 5)
+			for it.Next() {
+				count++
+			}
+
+			Convey("Then no empty page is requested", func() {
+				So(count, ShouldEqual, 5)
+				So(pages, ShouldResemble, []int{1})
+			})
+		})
+	})
+
+	Convey("Given an account with no HITs", t, func() {
+		var pages []int
+		srv := newPagingServer(0, &pages)
+		defer srv.Close()
+		client := newRetryClient(srv.URL, 1)
+
+		Convey("When I iterate over every HIT", func() {
+			it := SearchAllHITs(client, "CreationTime", true, 0)
+
+			Convey("Then nothing is returned", func() {
+				So(it.Next(), ShouldBeFalse)
+				So(it.Err(), ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a server which fails", t, func() {
+		srv := newFlakyServer(1, http.StatusBadRequest)
+		defer srv.Close()
+		client := newRetryClient(srv.URL, 1)
+
+		Convey("When I iterate over every HIT", func() {
+			it := SearchAllHITs(client, "CreationTime", true, 0)
+
+			Convey("Then the error is reported", func() {
+				So(it.Next(), ShouldBeFalse)
+				So(it.Err(), ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+// A test server which pages through a HIT review report with the given
+// number of results and an assignment review report with the given number of
+// actions
+func newReviewServer(results, actions int, pages *[]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		pageSize, _ := strconv.Atoi(r.Form.Get("PageSize"))
+		pageNumber, _ := strconv.Atoi(r.Form.Get("PageNumber"))
+		*pages = append(*pages, pageNumber)
+		var hitReport, assignmentReport string
+		for i := (pageNumber - 1) * pageSize; i < pageNumber*pageSize && i < results; i++ {
+			hitReport += fmt.Sprintf("<ReviewResult><ActionId>R%d</ActionId></ReviewResult>", i)
+		}
+		for i := (pageNumber - 1) * pageSize; i < pageNumber*pageSize && i < actions; i++ {
+			assignmentReport += fmt.Sprintf("<ReviewAction><ActionId>A%d</ActionId></ReviewAction>", i)
+		}
+		fmt.Fprintf(w, `<?xml version="1.0"?>`+"\n"+
+			`<GetReviewResultsForHITResponse>`+
+			`<OperationRequest><RequestId>%s</RequestId></OperationRequest>`+
+			`<GetReviewResultsForHITResult><Request><IsValid>True</IsValid></Request>`+
+			`<HITId>HIT1</HITId>`+
+			`<AssignmentReviewReport>%s</AssignmentReviewReport>`+
+			`<HITReviewReport>%s</HITReviewReport>`+
+			`</GetReviewResultsForHITResult></GetReviewResultsForHITResponse>`,
+			REQUEST_ID, assignmentReport, hitReport)
+	}))
+}
+
+func TestGetAllReviewResultsForHIT(t *testing.T) {
+	Convey("Given a HIT with three review results and one review action", t, func() {
+		var pages []int
+		srv := newReviewServer(3, 1, &pages)
+		defer srv.Close()
+		client := newRetryClient(srv.URL, 1)
+
+		Convey("When I iterate over them two at a time", func() {
+			var ids []string
+			it := GetAllReviewResultsForHIT(client, "HIT1", nil, true, true, 2)
+			for it.Next() {
+				if result := it.ReviewResult(); result != nil {
+					ids = append(ids, string(result.ActionId))
+				} else {
+					ids = append(ids, string(it.ReviewAction().ActionId))
+				}
+			}
+
+			Convey("Then every result and action is returned once", func() {
+				So(it.Err(), ShouldBeNil)
+				So(ids, ShouldResemble, []string{"R0", "R1", "A0", "R2"})
+			})
+			Convey("Then pages are fetched until no list fills one", func() {
+				So(pages, ShouldResemble, []int{1, 2})
+			})
+		})
+	})
+}
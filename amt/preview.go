@@ -0,0 +1,344 @@
+package amt
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// The page RenderPreview writes. A QuestionForm is laid out much as AMT shows
+// it, and an HTMLQuestion or ExternalQuestion is shown in a frame of its
+// FrameHeight, as workers previewing the HIT see it.
+const previewPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta http-equiv="Content-Type" content="text/html; charset=UTF-8"/>
+<title>HIT preview</title>
+<style>
+body { font-family: Verdana, Arial, sans-serif; font-size: 13px; margin: 0; }
+.banner { background: #f0f0f0; border-bottom: 1px solid #ccc; padding: 8px 12px; color: #555; }
+.hit { padding: 12px; }
+.overview, .question { border: 1px solid #ccc; margin-bottom: 12px; padding: 8px 12px; }
+.question .id { color: #888; font-size: 11px; }
+.required { color: #c00; }
+.placeholder { background: #eee; border: 1px dashed #999; color: #555; display: inline-block; padding: 8px; }
+iframe { border: 1px solid #ccc; width: 100%; }
+</style>
+</head>
+<body>
+<div class="banner">Preview: {{.Kind}}</div>
+<div class="hit">
+{{if .Form}}<form name="mturk_form" method="post" id="mturk_form" action="#" onsubmit="return false">
+{{range .Form}}{{if .Overview}}<div class="overview">
+{{template "content" .Content}}</div>
+{{else}}<div class="question">
+<div class="id">{{or .DisplayName .Id}}{{if .Required}} <span class="required">(required)</span>{{end}}</div>
+{{template "content" .Content}}{{template "answer" .}}</div>
+{{end}}{{end}}<p><input type="submit" id="submitButton" value="Submit" disabled/></p>
+</form>
+{{else if .FrameURL}}<iframe src="{{.FrameURL}}" height="{{.FrameHeight}}"></iframe>
+{{else}}<iframe srcdoc="{{.FrameContent}}" height="{{.FrameHeight}}"></iframe>
+{{end}}</div>
+</body>
+</html>
+{{- define "content"}}{{range .}}{{if eq .Kind "Title"}}<h2>{{.Text}}</h2>
+{{else if eq .Kind "Text"}}<p>{{.Text}}</p>
+{{else if eq .Kind "FormattedContent"}}<div>{{.HTML}}</div>
+{{else if eq .Kind "List"}}<ul>{{range .Items}}<li>{{.}}</li>{{end}}</ul>
+{{else if eq .Kind "Binary"}}{{template "binary" .}}
+{{else}}<div class="placeholder" style="width: {{.Width}}px; height: {{.Height}}px">{{.Text}}</div>
+{{end}}{{end}}{{end}}
+{{- define "binary"}}{{if eq .MimeType "image"}}<img src="{{.URL}}" alt="{{.Text}}"/>{{else if eq .MimeType "audio"}}<audio src="{{.URL}}" controls>{{.Text}}</audio>{{else if eq .MimeType "video"}}<video src="{{.URL}}" controls>{{.Text}}</video>{{else}}<a href="{{.URL}}">{{or .Text .URL}}</a>{{end}}{{end}}
+{{- define "answer"}}{{$id := .Id}}{{with .Answer}}{{if eq .Kind "FreeText"}}{{if gt .Lines 1}}<textarea name="{{$id}}" rows="{{.Lines}}" cols="80">{{.DefaultText}}</textarea>{{else}}<input type="text" name="{{$id}}" value="{{.DefaultText}}" size="60"/>{{end}}
+{{else if eq .Kind "FileUpload"}}<input type="file" name="{{$id}}"/>
+{{else if eq .Kind "Selection"}}{{if .List}}<select name="{{$id}}"{{if .Multiple}} multiple{{end}}>{{range .Selections}}<option value="{{.Id}}">{{.Text}}</option>{{end}}</select>
+{{else}}{{$type := "radio"}}{{if .Multiple}}{{$type = "checkbox"}}{{end}}{{range .Selections}}<label><input type="{{$type}}" name="{{$id}}" value="{{.Id}}"/> {{if .Binary}}{{template "binary" .Binary}}{{else if .HTML}}{{.HTML}}{{else}}{{.Text}}{{end}}</label><br/>
+{{end}}{{end}}{{if .Other}}<label>Other: <input type="text" name="{{$id}}.other"/></label>
+{{end}}{{end}}{{end}}{{end}}`
+
+var previewTemplate = template.Must(template.New("preview").Parse(previewPage))
+
+// The data previewTemplate is executed with
+type previewData struct {
+	Kind string
+
+	// The overviews and questions of a QuestionForm
+	Form []previewItem
+
+	// The frame of an HTMLQuestion or ExternalQuestion
+	FrameURL     string
+	FrameContent string
+	FrameHeight  int
+}
+
+// An Overview or Question of a QuestionForm
+type previewItem struct {
+	Overview    bool
+	Id          string
+	DisplayName string
+	Required    bool
+	Content     []previewContent
+	Answer      *previewAnswer
+}
+
+// One content element: a Title, Text, FormattedContent, List, Binary,
+// Application, or EmbeddedBinary
+type previewContent struct {
+	Kind          string
+	Text          string
+	HTML          template.HTML
+	Items         []string
+	MimeType      string
+	URL           string
+	Width, Height string
+}
+
+// The answer specification of a question
+type previewAnswer struct {
+	Kind        string
+	DefaultText string
+	Lines       int
+	List        bool
+	Multiple    bool
+	Other       bool
+	Selections  []previewSelection
+}
+
+// One option of a selection answer
+type previewSelection struct {
+	Id     string
+	Text   string
+	HTML   template.HTML
+	Binary *previewContent
+}
+
+// RenderPreview writes a standalone HTML page showing a question roughly as
+// workers see it, for reviewing a design or taking screenshots without
+// creating a HIT.
+//
+// A QuestionForm's overviews and questions are drawn in order, with each
+// answer specification as the matching form input: a text box or text area
+// for free text, radio buttons, checkboxes or a list for selections as the
+// StyleSuggestion asks, and a file input for uploads. FormattedContent is
+// written as is, so should only come from trusted questions. Flash, Java
+// applet and embedded binary content is shown as a placeholder of its size.
+//
+// An HTMLQuestion's content is shown in a frame of its FrameHeight. An
+// ExternalQuestion's URL is shown in a frame with the parameters AMT adds
+// while a worker previews the HIT, so its assignmentId is
+// PREVIEW_ASSIGNMENT_ID.
+func RenderPreview(w io.Writer, question HITQuestion) error {
+	var data previewData
+	switch q := question.(type) {
+	case *HTMLQuestion:
+		data = previewData{
+			Kind:         "HTMLQuestion",
+			FrameContent: string(q.HTMLContent),
+			FrameHeight:  int(q.FrameHeight),
+		}
+	case HTMLQuestion:
+		return RenderPreview(w, &q)
+	case *ExternalQuestion:
+		frameURL, err := previewURL(string(q.ExternalURL))
+		if err != nil {
+			return err
+		}
+		data = previewData{
+			Kind:        "ExternalQuestion",
+			FrameURL:    frameURL,
+			FrameHeight: int(q.FrameHeight),
+		}
+	case ExternalQuestion:
+		return RenderPreview(w, &q)
+	case *QuestionForm, QuestionForm:
+		questionXml, err := EncodeQuestion(question)
+		if err != nil {
+			return err
+		}
+		root, err := parseXMLNode(questionXml)
+		if err != nil {
+			return err
+		}
+		data = previewData{Kind: "QuestionForm"}
+		for _, child := range root.Children {
+			data.Form = append(data.Form, previewFormItem(child))
+		}
+	default:
+		return fmt.Errorf("Cannot preview a question of type %T", question)
+	}
+
+	var page bytes.Buffer
+	if err := previewTemplate.Execute(&page, data); err != nil {
+		return fmt.Errorf("Could not render preview: %v", err)
+	}
+	_, err := page.WriteTo(w)
+	return err
+}
+
+// RenderPreviewXML writes a preview page, as RenderPreview does, for the XML
+// of a question, e.g. the Question of a HIT returned by GetHIT.
+func RenderPreviewXML(w io.Writer, questionXml []byte) error {
+	question, err := DecodeQuestion(questionXml)
+	if err != nil {
+		return fmt.Errorf("Could not decode question: %v", err)
+	}
+	return RenderPreview(w, question)
+}
+
+// Add the parameters AMT adds to an ExternalQuestion's URL while a worker
+// previews the HIT.
+func previewURL(externalURL string) (string, error) {
+	parsed, err := url.Parse(externalURL)
+	if err != nil {
+		return "", fmt.Errorf("Invalid ExternalURL %q: %v", externalURL, err)
+	}
+	query := parsed.Query()
+	query.Set("assignmentId", PREVIEW_ASSIGNMENT_ID)
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// Read an Overview or Question element of an encoded QuestionForm.
+func previewFormItem(node *xmlNode) previewItem {
+	if node.Name.Local == "Overview" {
+		return previewItem{Overview: true, Content: previewContents(node)}
+	}
+	item := previewItem{
+		Id:          childText(node, "QuestionIdentifier"),
+		DisplayName: childText(node, "DisplayName"),
+		Required:    childText(node, "IsRequired") == "true",
+	}
+	if content := childNode(node, "QuestionContent"); content != nil {
+		item.Content = previewContents(content)
+	}
+	if spec := childNode(node, "AnswerSpecification"); spec != nil &&
+		len(spec.Children) > 0 {
+		item.Answer = previewAnswerSpec(spec.Children[0])
+	}
+	return item
+}
+
+// Read the content elements of an Overview or QuestionContent.
+func previewContents(node *xmlNode) []previewContent {
+	var contents []previewContent
+	for _, child := range node.Children {
+		content := previewContent{Kind: child.Name.Local}
+		switch child.Name.Local {
+		case "Title", "Text":
+			content.Text = child.Text
+		case "FormattedContent":
+			content.HTML = template.HTML(child.Text)
+		case "List":
+			for _, item := range child.Children {
+				content.Items = append(content.Items, item.Text)
+			}
+		case "Binary":
+			content = previewBinary(child)
+		case "Application":
+			var app *xmlNode
+			if len(child.Children) > 0 {
+				app = child.Children[0]
+			} else {
+				app = child
+			}
+			content.Text = app.Name.Local + " application"
+			content.Width = childText(app, "Width")
+			content.Height = childText(app, "Height")
+		case "EmbeddedBinary":
+			content.Text = "Embedded binary: " + childText(child, "AltText")
+			content.Width = childText(child, "Width")
+			content.Height = childText(child, "Height")
+		default:
+			continue
+		}
+		contents = append(contents, content)
+	}
+	return contents
+}
+
+// Read a Binary element.
+func previewBinary(node *xmlNode) previewContent {
+	content := previewContent{
+		Kind: "Binary",
+		Text: childText(node, "AltText"),
+		URL:  childText(node, "DataURL"),
+	}
+	if mimeType := childNode(node, "MimeType"); mimeType != nil {
+		content.MimeType = childText(mimeType, "Type")
+	}
+	return content
+}
+
+// Read the FreeTextAnswer, SelectionAnswer or FileUploadAnswer of an
+// AnswerSpecification.
+func previewAnswerSpec(node *xmlNode) *previewAnswer {
+	switch node.Name.Local {
+	case "FreeTextAnswer":
+		lines, _ := strconv.Atoi(childText(node, "NumberOfLinesSuggestion"))
+		return &previewAnswer{
+			Kind:        "FreeText",
+			DefaultText: childText(node, "DefaultText"),
+			Lines:       lines,
+		}
+	case "FileUploadAnswer":
+		return &previewAnswer{Kind: "FileUpload"}
+	case "SelectionAnswer":
+		maxSelections, _ := strconv.Atoi(childText(node, "MaxSelectionCount"))
+		answer := &previewAnswer{Kind: "Selection", Multiple: maxSelections > 1}
+		switch childText(node, "StyleSuggestion") {
+		case "radiobutton":
+			answer.Multiple = false
+		case "checkbox":
+			answer.Multiple = true
+		case "list", "dropdown":
+			answer.List = true
+		case "combobox", "multichooser":
+			answer.List, answer.Multiple = true, true
+		}
+		if selections := childNode(node, "Selections"); selections != nil {
+			for _, child := range selections.Children {
+				if child.Name.Local == "OtherSelection" {
+					answer.Other = true
+					continue
+				}
+				selection := previewSelection{
+					Id:   childText(child, "SelectionIdentifier"),
+					Text: childText(child, "Text"),
+					HTML: template.HTML(childText(child, "FormattedContent")),
+				}
+				if binary := childNode(child, "Binary"); binary != nil {
+					content := previewBinary(binary)
+					selection.Binary = &content
+				}
+				if selection.Text == "" {
+					selection.Text = selection.Id
+				}
+				answer.Selections = append(answer.Selections, selection)
+			}
+		}
+		return answer
+	}
+	return nil
+}
+
+// The first child element with a name, or nil.
+func childNode(node *xmlNode, name string) *xmlNode {
+	for _, child := range node.Children {
+		if child.Name.Local == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// The text of the first child element with a name, or "".
+func childText(node *xmlNode, name string) string {
+	if child := childNode(node, name); child != nil {
+		return strings.TrimSpace(child.Text)
+	}
+	return ""
+}
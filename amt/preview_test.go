@@ -0,0 +1,127 @@
+package amt
+
+import (
+	"bytes"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/url"
+	"testing"
+)
+
+func TestRenderPreview(t *testing.T) {
+	Convey("Given a QuestionForm", t, func() {
+		imageURL, _ := url.Parse("http://example.com/cat.png")
+		form := &QuestionForm{}
+		form.AddOverview()
+		form.AddTitleContent("Animals")
+		form.AddFormattedContent("<b>Look closely</b>")
+		form.AddQuestion("q1", "Which animal?", true)
+		form.AddBinaryContent("image", "png", imageURL, "A picture")
+		form.AddTextContent("Pick one")
+		form.AddSelectionAnswerStyle("radiobutton")
+		form.AddSelectionAnswerTextSelection("cat", "Cat")
+		form.AddSelectionAnswerTextSelection("dog", "<Dog>")
+		form.AddQuestion("q2", "", false)
+		form.AddListContent([]string{"one", "two"})
+		form.AddFreeTextAnswerDefaultText("none")
+		form.AddFreeTextAnswerNumberOfLinesSuggestion(4)
+		form.AddQuestion("q3", "", false)
+		form.AddSelectionAnswerStyle("dropdown")
+		form.AddSelectionAnswerTextSelection("s", "Small")
+		form.AddQuestion("q4", "", false)
+		form.AddFileUploadAnswer(1, 1000)
+
+		Convey("When I render a preview", func() {
+			var page bytes.Buffer
+			err := RenderPreview(&page, form)
+			html := page.String()
+
+			Convey("Then the content and answers are shown in order", func() {
+				So(err, ShouldBeNil)
+				So(html, ShouldStartWith, "<!DOCTYPE html>")
+				So(html, ShouldContainSubstring, "<h2>Animals</h2>\n<div><b>Look closely</b></div>")
+				So(html, ShouldContainSubstring, `Which animal? <span class="required">(required)</span>`)
+				So(html, ShouldContainSubstring, `<img src="http://example.com/cat.png" alt="A picture"/>
+<p>Pick one</p>`)
+				So(html, ShouldContainSubstring, `<label><input type="radio" name="q1" value="dog"/> &lt;Dog&gt;</label>`)
+				So(html, ShouldContainSubstring, "<ul><li>one</li><li>two</li></ul>")
+				So(html, ShouldContainSubstring, `<textarea name="q2" rows="4" cols="80">none</textarea>`)
+				So(html, ShouldContainSubstring, `<select name="q3"><option value="s">Small</option></select>`)
+				So(html, ShouldContainSubstring, `<input type="file" name="q4"/>`)
+				So(html, ShouldContainSubstring, `<input type="submit" id="submitButton" value="Submit" disabled/>`)
+			})
+		})
+
+		Convey("When I render a preview of its XML", func() {
+			encoded, err := EncodeQuestion(form)
+			So(err, ShouldBeNil)
+			var fromForm, fromXml bytes.Buffer
+			So(RenderPreview(&fromForm, form), ShouldBeNil)
+			err = RenderPreviewXML(&fromXml, encoded)
+
+			Convey("Then it matches the preview of the form", func() {
+				So(err, ShouldBeNil)
+				So(fromXml.String(), ShouldEqual, fromForm.String())
+			})
+		})
+	})
+
+	Convey("Given the XML of a question with an unconstrained free text answer", t, func() {
+		questionXml := []byte(`<QuestionForm xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/QuestionForm.xsd"><Question><QuestionIdentifier>q1</QuestionIdentifier><QuestionContent><Text>Why?</Text></QuestionContent><AnswerSpecification><FreeTextAnswer/></AnswerSpecification></Question></QuestionForm>`)
+
+		Convey("When I render a preview", func() {
+			var page bytes.Buffer
+			err := RenderPreviewXML(&page, questionXml)
+
+			Convey("Then the question has a text box", func() {
+				So(err, ShouldBeNil)
+				So(page.String(), ShouldContainSubstring,
+					`<p>Why?</p>
+<input type="text" name="q1" value="" size="60"/>`)
+			})
+		})
+	})
+
+	Convey("Given an HTMLQuestion", t, func() {
+		question := &HTMLQuestion{}
+		question.HTMLContent = `<p class="x">Hi & bye</p>`
+		question.FrameHeight = 300
+
+		Convey("When I render a preview", func() {
+			var page bytes.Buffer
+			err := RenderPreview(&page, question)
+
+			Convey("Then the content is shown in a frame", func() {
+				So(err, ShouldBeNil)
+				So(page.String(), ShouldContainSubstring,
+					`<iframe srcdoc="&lt;p class=&#34;x&#34;&gt;Hi &amp; bye&lt;/p&gt;" height="300"></iframe>`)
+			})
+		})
+	})
+
+	Convey("Given an ExternalQuestion", t, func() {
+		question := &ExternalQuestion{}
+		question.ExternalURL = "https://example.com/task?item=7"
+		question.FrameHeight = 500
+
+		Convey("When I render a preview", func() {
+			var page bytes.Buffer
+			err := RenderPreview(&page, question)
+
+			Convey("Then the URL is framed as a worker previewing the HIT sees it", func() {
+				So(err, ShouldBeNil)
+				So(page.String(), ShouldContainSubstring,
+					`<iframe src="https://example.com/task?assignmentId=ASSIGNMENT_ID_NOT_AVAILABLE&amp;item=7" height="500"></iframe>`)
+			})
+		})
+	})
+
+	Convey("Given something other than a question", t, func() {
+		Convey("When I render a preview", func() {
+			err := RenderPreview(&bytes.Buffer{}, "question")
+
+			Convey("Then it fails", func() {
+				So(err.Error(), ShouldEqual, "Cannot preview a question of type string")
+			})
+		})
+	})
+}
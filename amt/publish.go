@@ -0,0 +1,287 @@
+package amt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+)
+
+// RowQuestion builds the question for one row of a batch.
+type RowQuestion func(row map[string]string) (HITQuestion, error)
+
+// TemplateRowQuestion parses a template for the questions of a batch, which
+// is executed with each row, a map from column names to values.
+//
+// A template which starts with a <QuestionForm> element is QuestionForm XML,
+// executed as a text/template with the row's values XML-escaped, so
+// {{.caption}} writes the caption column as text. Any other template is the
+// task of an HTMLQuestion, built by NewHTMLQuestion with the given options.
+// Either kind fails for a row which lacks a column the template reads.
+func TemplateRowQuestion(tmpl string, options ...HTMLQuestionOption) (RowQuestion, error) {
+	if !strings.HasPrefix(strings.TrimSpace(tmpl), "<QuestionForm") {
+		if _, err := template.New("task").Funcs(htmlQuestionFuncs()).Parse(tmpl); err != nil {
+			return nil, fmt.Errorf("Could not parse HTML question template: %v", err)
+		}
+		options = append(append([]HTMLQuestionOption(nil), options...),
+			WithMissingKeyError())
+		return func(row map[string]string) (HITQuestion, error) {
+			return NewHTMLQuestion(tmpl, row, options...)
+		}, nil
+	}
+
+	form, err := texttemplate.New("form").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse QuestionForm template: %v", err)
+	}
+	return func(row map[string]string) (HITQuestion, error) {
+		escaped := make(map[string]string, len(row))
+		for name, value := range row {
+			var buf bytes.Buffer
+			xml.EscapeText(&buf, []byte(value))
+			escaped[name] = buf.String()
+		}
+		var questionXml bytes.Buffer
+		if err := form.Execute(&questionXml, escaped); err != nil {
+			return nil, fmt.Errorf("Could not execute QuestionForm template: %v", err)
+		}
+		if err := ValidateQuestion(questionXml.Bytes()); err != nil {
+			return nil, err
+		}
+		return DecodeQuestion(questionXml.Bytes())
+	}, nil
+}
+
+// Batch describes a batch of HITs of a single HIT type, with one HIT for
+// each row of input, as published by PublishBatch.
+type Batch struct {
+
+	// The HIT type, registered with RegisterHITType
+	Title                       string
+	Description                 string
+	Reward                      float32
+	AssignmentDurationInSeconds int
+	AutoApprovalDelayInSeconds  int
+	Keywords                    []string
+	QualificationRequirements   []*amtgen.TQualificationRequirement
+
+	// The settings for each HIT
+	LifetimeInSeconds int
+	MaxAssignments    int
+
+	// Builds the question for a row
+	Question RowQuestion
+}
+
+// ManifestEntry records the HIT created for one row of a batch. It is written
+// to a manifest as a single line of JSON.
+type ManifestEntry struct {
+
+	// The row, counting the first row of values as 1
+	Row int `json:"row"`
+
+	// The HIT created for the row, and its HIT type
+	HITTypeId string `json:"hitTypeId"`
+	HITId     string `json:"hitId"`
+
+	// A digest of the row's question XML, to detect edits to the input
+	Digest string `json:"digest"`
+
+	// When the HIT was created
+	Time time.Time `json:"time"`
+}
+
+// BatchManifest maps the rows of a batch to the HITs created for them, so
+// that a batch which is published again, e.g. after a crash, creates HITs
+// only for the rows which have none. Entries are written to a sink as JSON
+// Lines as they are recorded. It is safe for concurrent use.
+type BatchManifest struct {
+	mu      sync.Mutex
+	entries map[int]ManifestEntry
+	sink    io.Writer
+	file    *os.File
+}
+
+// NewBatchManifest creates an empty manifest which writes to the given sink,
+// which may be nil.
+func NewBatchManifest(sink io.Writer) *BatchManifest {
+	return &BatchManifest{entries: make(map[int]ManifestEntry), sink: sink}
+}
+
+// ReadBatchManifest reads the entries of a manifest written earlier. Entries
+// recorded later are kept in memory only.
+func ReadBatchManifest(r io.Reader) (*BatchManifest, error) {
+	manifest := NewBatchManifest(nil)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry ManifestEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("Could not read line %d of the manifest: %v", line, err)
+		}
+		manifest.entries[entry.Row] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// OpenBatchManifest opens a manifest kept in a file, reading the entries it
+// already holds and appending new ones, and creating the file if it does not
+// exist. Close the manifest when done.
+func OpenBatchManifest(path string) (*BatchManifest, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := ReadBatchManifest(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("Could not read %s: %v", path, err)
+	}
+	manifest.sink, manifest.file = file, file
+	return manifest, nil
+}
+
+// Entry returns the entry for a row, and whether there is one.
+func (manifest *BatchManifest) Entry(row int) (ManifestEntry, bool) {
+	manifest.mu.Lock()
+	defer manifest.mu.Unlock()
+	entry, ok := manifest.entries[row]
+	return entry, ok
+}
+
+// Entries returns every entry, in row order.
+func (manifest *BatchManifest) Entries() []ManifestEntry {
+	manifest.mu.Lock()
+	defer manifest.mu.Unlock()
+	var entries []ManifestEntry
+	for _, entry := range manifest.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Row < entries[j].Row
+	})
+	return entries
+}
+
+// Record adds an entry, writing it to the sink.
+func (manifest *BatchManifest) Record(entry ManifestEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	manifest.mu.Lock()
+	defer manifest.mu.Unlock()
+	manifest.entries[entry.Row] = entry
+	if manifest.sink == nil {
+		return nil
+	} else if _, err := manifest.sink.Write(append(line, '\n')); err != nil {
+		return err
+	} else if manifest.file != nil {
+		return manifest.file.Sync()
+	}
+	return nil
+}
+
+// Close closes the file of a manifest opened by OpenBatchManifest.
+func (manifest *BatchManifest) Close() error {
+	if manifest.file != nil {
+		return manifest.file.Close()
+	}
+	return nil
+}
+
+// PublishBatch creates a HIT for each row of a batch which does not yet have
+// one in the manifest, and records each in the manifest as it is created. It
+// returns the manifest's entries for every row.
+//
+// Every row's question is built and encoded before anything is sent to AMT,
+// so a bad row stops the batch before any HIT is created. A row which has a
+// HIT, but whose question has changed since, is reported as an error rather
+// than published again. The HIT type is then registered once, and each HIT
+// is created with CreateHITFromHITTypeId and a UniqueRequestToken derived
+// from the row, so a HIT created just before a crash, but not yet recorded,
+// is found rather than duplicated when the batch is published again. AMT
+// only remembers a token for 24 hours: a HIT which was created but not
+// recorded will be created again if the batch is published after that.
+func PublishBatch(client AmtClient, batch Batch, rows []map[string]string,
+	manifest *BatchManifest) ([]ManifestEntry, error) {
+
+	var (
+		questions = make([]string, len(rows))
+		digests   = make([]string, len(rows))
+		pending   []int
+	)
+	for i, row := range rows {
+		question, err := batch.Question(row)
+		if err != nil {
+			return nil, fmt.Errorf("Row %d: %v", i+1, err)
+		}
+		questionXml, err := EncodeQuestion(question)
+		if err != nil {
+			return nil, fmt.Errorf("Row %d: %v", i+1, err)
+		}
+		sum := sha256.Sum256(questionXml)
+		questions[i], digests[i] = string(questionXml), hex.EncodeToString(sum[:])
+		if entry, ok := manifest.Entry(i + 1); !ok {
+			pending = append(pending, i)
+		} else if entry.Digest != digests[i] {
+			return nil, fmt.Errorf("Row %d has changed since HIT %s was created for it",
+				i+1, entry.HITId)
+		}
+	}
+	if len(pending) == 0 {
+		return manifest.Entries(), nil
+	}
+
+	resp, err := client.RegisterHITType(batch.Title, batch.Description,
+		batch.Reward, batch.AssignmentDurationInSeconds,
+		batch.AutoApprovalDelayInSeconds, batch.Keywords,
+		batch.QualificationRequirements)
+	if err == nil && len(resp.RegisterHITTypeResults) == 0 {
+		err = fmt.Errorf("AMT returned no HIT type")
+	}
+	if err != nil {
+		return manifest.Entries(), fmt.Errorf("Could not register HIT type: %v", err)
+	}
+	hitTypeId := string(resp.RegisterHITTypeResults[0].HITTypeId)
+
+	for _, i := range pending {
+		token := RequestToken("CreateHIT",
+			fmt.Sprintf("%s:%d:%s", hitTypeId, i+1, digests[i]))
+		hitId, err := createHITOnce(client, hitTypeId, questions[i], "", nil,
+			batch.LifetimeInSeconds, batch.MaxAssignments, token)
+		if err != nil {
+			return manifest.Entries(), fmt.Errorf("Could not create HIT for row %d: %v",
+				i+1, err)
+		}
+		entry := ManifestEntry{
+			Row:       i + 1,
+			HITTypeId: hitTypeId,
+			HITId:     hitId,
+			Digest:    digests[i],
+			Time:      time.Now().UTC(),
+		}
+		if err := manifest.Record(entry); err != nil {
+			return manifest.Entries(), fmt.Errorf("Could not record HIT %s for row %d: %v",
+				hitId, i+1, err)
+		}
+	}
+	return manifest.Entries(), nil
+}
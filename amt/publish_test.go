@@ -0,0 +1,160 @@
+package amt
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const BATCH_QUESTION_FORM = `<QuestionForm xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/QuestionForm.xsd">
+<Question><QuestionIdentifier>q1</QuestionIdentifier><QuestionContent><Text>Is {{.item}} a cat?</Text></QuestionContent>
+<AnswerSpecification><FreeTextAnswer/></AnswerSpecification></Question>
+</QuestionForm>`
+
+func newTestBatch(tmpl string) Batch {
+	question, err := TemplateRowQuestion(tmpl)
+	So(err, ShouldBeNil)
+	return Batch{
+		Title:                       "Cats",
+		Description:                 "Find the cats",
+		Reward:                      0.1,
+		AssignmentDurationInSeconds: 600,
+		LifetimeInSeconds:           3600,
+		MaxAssignments:              2,
+		Question:                    question,
+	}
+}
+
+func TestTemplateRowQuestion(t *testing.T) {
+	Convey("Given a QuestionForm template", t, func() {
+		question, err := TemplateRowQuestion(BATCH_QUESTION_FORM)
+		So(err, ShouldBeNil)
+
+		Convey("When I build the question for a row", func() {
+			form, err := question(map[string]string{"item": "Tom & <Jerry>"})
+
+			Convey("Then the row's values are escaped", func() {
+				So(err, ShouldBeNil)
+				encoded, err := EncodeQuestion(form)
+				So(err, ShouldBeNil)
+				So(string(encoded), ShouldContainSubstring, "<Text>Is Tom &amp; &lt;Jerry&gt; a cat?</Text>")
+			})
+		})
+
+		Convey("When a row lacks a column", func() {
+			_, err := question(map[string]string{})
+
+			Convey("Then it fails", func() {
+				So(err.Error(), ShouldStartWith, "Could not execute QuestionForm template")
+			})
+		})
+	})
+
+	Convey("Given an HTML template", t, func() {
+		question, err := TemplateRowQuestion(`<p>Is {{.item}} a cat?</p>`, WithSandboxSubmit())
+		So(err, ShouldBeNil)
+
+		Convey("When a row lacks a column", func() {
+			_, err := question(map[string]string{"itme": "<b>"})
+
+			Convey("Then it fails", func() {
+				So(err.Error(), ShouldStartWith, "Could not execute HTML question template")
+			})
+		})
+
+		Convey("When I build the question for a row", func() {
+			html, err := question(map[string]string{"item": "<b>"})
+
+			Convey("Then it is an HTMLQuestion", func() {
+				So(err, ShouldBeNil)
+				content := string(html.(*HTMLQuestion).HTMLContent)
+				So(content, ShouldContainSubstring, "<p>Is &lt;b&gt; a cat?</p>")
+				So(content, ShouldContainSubstring, SUBMIT_URL_SANDBOX)
+			})
+		})
+	})
+}
+
+func TestPublishBatch(t *testing.T) {
+	Convey("Given a batch and a manifest file", t, func() {
+		dir, err := ioutil.TempDir("", "amt-batch")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "manifest.jsonl")
+		batch := newTestBatch(`<p>Is {{.item}} a cat?</p>`)
+		rows, err := ReadBatchRows(strings.NewReader("item\none\ntwo\nthree\n"))
+		So(err, ShouldBeNil)
+
+		Convey("When I publish it", func() {
+			manifest, err := OpenBatchManifest(path)
+			So(err, ShouldBeNil)
+			client := NewDryRunClient(nil, nil)
+			entries, err := PublishBatch(client, batch, rows, manifest)
+			manifest.Close()
+
+			Convey("Then one HIT type and a HIT per row are created", func() {
+				So(err, ShouldBeNil)
+				calls := client.Calls()
+				So(calls, ShouldHaveLength, 4)
+				So(calls[0].Operation, ShouldEqual, "RegisterHITType")
+				hitTypeId := entries[0].HITTypeId
+				for i, call := range calls[1:] {
+					So(call.Operation, ShouldEqual, "CreateHIT")
+					So(call.Args.Get("HITTypeId"), ShouldEqual, hitTypeId)
+					So(call.Args.Get("UniqueRequestToken"), ShouldHaveLength, 64)
+					So(entries[i].Row, ShouldEqual, i+1)
+					So(entries[i].HITId, ShouldNotBeEmpty)
+				}
+			})
+
+			Convey("Then publishing it again creates nothing", func() {
+				reopened, err := OpenBatchManifest(path)
+				So(err, ShouldBeNil)
+				defer reopened.Close()
+				So(reopened.Entries(), ShouldResemble, entries)
+				again := NewDryRunClient(nil, nil)
+				resumed, err := PublishBatch(again, batch, rows, reopened)
+				So(err, ShouldBeNil)
+				So(again.Calls(), ShouldBeEmpty)
+				So(resumed, ShouldResemble, entries)
+			})
+
+			Convey("Then a new row is published on its own", func() {
+				reopened, err := OpenBatchManifest(path)
+				So(err, ShouldBeNil)
+				defer reopened.Close()
+				again := NewDryRunClient(nil, nil)
+				more := append(rows, map[string]string{"item": "four"})
+				resumed, err := PublishBatch(again, batch, more, reopened)
+				So(err, ShouldBeNil)
+				So(again.Calls(), ShouldHaveLength, 2)
+				So(resumed, ShouldHaveLength, 4)
+				So(resumed[:3], ShouldResemble, entries)
+			})
+
+			Convey("Then an edited row is refused", func() {
+				reopened, err := OpenBatchManifest(path)
+				So(err, ShouldBeNil)
+				defer reopened.Close()
+				edited := []map[string]string{rows[0], {"item": "TWO"}, rows[2]}
+				_, err = PublishBatch(NewDryRunClient(nil, nil), batch, edited, reopened)
+				So(err.Error(), ShouldStartWith, "Row 2 has changed since HIT")
+			})
+		})
+
+		Convey("When a row cannot be built", func() {
+			batch = newTestBatch(BATCH_QUESTION_FORM)
+			rows = append(rows, map[string]string{"other": "x"})
+			client := NewDryRunClient(nil, nil)
+			_, err := PublishBatch(client, batch, rows, NewBatchManifest(nil))
+
+			Convey("Then nothing is sent to AMT", func() {
+				So(err.Error(), ShouldStartWith, "Row 4: Could not execute QuestionForm template")
+				So(client.Calls(), ShouldBeEmpty)
+			})
+		})
+	})
+}
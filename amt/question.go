@@ -1,6 +1,7 @@
 package amt
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
 	answerkey "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/AnswerKey.xsd_go"
@@ -21,19 +22,64 @@ var (
 // HITQuestion is used to initialize HIT questions.
 type HITQuestion interface{}
 
-// Encode a question to send to Amazon
+// Encode a question to send to Amazon. Questions, answer keys, and answers
+// are written in the element order their schema requires, without the empty
+// elements encoding/xml writes for unset fields, so that the result passes
+// ValidateQuestion whenever the values set are valid. The elements of a form
+// read by DecodeQuestion, or set by its Add*() methods, are kept even if
+// they hold a zero value, such as a MinSelectionCount of 0.
 func EncodeQuestion(question HITQuestion) ([]byte, error) {
+	var form *QuestionForm
+	switch q := question.(type) {
+	case *QuestionForm:
+		form = q
+	case QuestionForm:
+		form = &q
+	}
+	if form != nil && (len(form.Overviews) > 0 || len(form.Questions) > 0) {
+		// Encode the fields rather than the XML the form was read from
+		fields := *form
+		fields.XMLContent = ""
+		question = &fields
+	}
 	result, err := xml.Marshal(question)
 	if err != nil {
 		return nil, err
 	}
+
+	// Rewrite the XML to match its schema, if it has one
+	root, err := parseXMLNode(result)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := questionSchema(root.Name.Space)
+	if err != nil {
+		return nil, err
+	} else if schema == nil || schema.elements[root.Name.Local] == nil {
+		return stripNamespaces(result), nil
+	}
+	var kept map[string]bool
+	if form != nil {
+		form.restoreOrder(root)
+		kept = form.kept
+	}
+	schema.normalize(root, schema.elements[root.Name.Local],
+		"/"+root.Name.Local, kept)
+	var buf bytes.Buffer
+	root.write(&buf, "")
+	return buf.Bytes(), nil
+}
+
+// Remove the repeated namespace declarations encoding/xml writes on child
+// elements.
+func stripNamespaces(result []byte) []byte {
 	idx := reNamespace.FindIndex(result)
 	if idx != nil {
 		ns := string(result[idx[0]:idx[1]])
 		nons := strings.Replace(string(result[idx[1]+1:]), ns, "", -1)
 		result = []byte(string(result[0:idx[1]+1]) + nons)
 	}
-	return result, nil
+	return result
 }
 
 // Decode a question in a response from Amazon
@@ -60,6 +106,9 @@ func DecodeQuestion(questionXml []byte) (HITQuestion, error) {
 
 	// Unmarshal the question
 	err := xml.Unmarshal(questionXml, question)
+	if form, ok := question.(*QuestionForm); ok && err == nil {
+		err = form.recordOrder(questionXml)
+	}
 	return question, err
 }
 
@@ -91,14 +140,17 @@ type HTMLQuestion struct {
 // These questions are hosted by Amazon using a standard form interface.
 //
 // Helper methods are provided to construct the form. In particular, Overview
-// and Question elements will be marshalled into the output XML in the order
-// added, provided you use AddOverview(), AddQuestion(), and EncodeQuestion().
+// and Question elements, and the content within each, will be marshalled into
+// the output XML in the order added, provided you use AddOverview(),
+// AddQuestion(), the Add*Content() methods, and EncodeQuestion(). A form read
+// by DecodeQuestion() keeps the order of the XML it was read from.
 type QuestionForm struct {
 
 	// The name of the wrapper element for an XML representation of the object
 	XMLName xml.Name `xml:"http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/QuestionForm.xsd QuestionForm"`
 
-	// The raw XML from which the form was parsed
+	// The raw XML from which the form was parsed. EncodeQuestion() ignores it
+	// unless the form has no overviews or questions.
 	XMLContent string `xml:",innerxml"`
 
 	// The question. Exposes the following top-level properties:
@@ -108,6 +160,14 @@ type QuestionForm struct {
 
 	// Corresponds to the addition order of overviews and questions
 	addedOverviewNext []bool `xml:"-"`
+
+	// The names of the content elements added to each overview and question,
+	// in order
+	addedContent [][]string `xml:"-"`
+
+	// The paths of the elements which were read or set with a zero value,
+	// which are encoded even so
+	kept map[string]bool `xml:"-"`
 }
 
 // Add a new Overview item, to be populated by subsequent content-adding method
@@ -116,6 +176,7 @@ func (question *QuestionForm) AddOverview() {
 	question.Overviews = append(question.Overviews,
 		&questionform.TContentType{})
 	question.addedOverviewNext = append(question.addedOverviewNext, true)
+	question.addedContent = append(question.addedContent, nil)
 }
 
 // Add a new Question item, to be populated by subsequent content-adding method
@@ -130,10 +191,127 @@ func (question *QuestionForm) AddQuestion(questionIdentifier,
 
 	question.Questions = append(question.Questions, qq)
 	question.addedOverviewNext = append(question.addedOverviewNext, false)
+	question.addedContent = append(question.addedContent, nil)
 }
 
-// Retrieve the content struct for the most recently-added Overview or Question
-func (question *QuestionForm) getCurrentContent() *questionform.TContentType {
+// Put the overviews and questions of an encoded form, and the content within
+// each, back in the order they were added.
+func (question *QuestionForm) restoreOrder(root *xmlNode) {
+	var overviews, questions, items []*xmlNode
+	for _, child := range root.Children {
+		switch child.Name.Local {
+		case "Overview":
+			overviews = append(overviews, child)
+		case "Question":
+			questions = append(questions, child)
+		default:
+			return
+		}
+	}
+	var addedOverviews int
+	for _, overviewNext := range question.addedOverviewNext {
+		if overviewNext {
+			addedOverviews++
+		}
+	}
+	if len(question.addedOverviewNext) != len(root.Children) ||
+		addedOverviews != len(overviews) {
+		return
+	}
+	for i, overviewNext := range question.addedOverviewNext {
+		var item, content *xmlNode
+		if overviewNext {
+			item, overviews = overviews[0], overviews[1:]
+			content = item
+		} else {
+			item, questions = questions[0], questions[1:]
+			for _, child := range item.Children {
+				if child.Name.Local == "QuestionContent" {
+					content = child
+				}
+			}
+		}
+		if content != nil && i < len(question.addedContent) {
+			orderChildren(content, question.addedContent[i])
+		}
+		items = append(items, item)
+	}
+	root.Children = items
+}
+
+// Record the order of the overviews and questions in the XML a form was read
+// from, and of the content within each, and which of its elements hold zero
+// values.
+func (question *QuestionForm) recordOrder(questionXml []byte) error {
+	root, err := parseXMLNode(questionXml)
+	if err != nil {
+		return err
+	}
+	question.kept = nil
+
+	// Schemas which fail to load are reported when the form is encoded
+	if schema, _ := questionSchema(root.Name.Space); schema != nil &&
+		schema.elements[root.Name.Local] != nil {
+		question.kept = schema.zeroPaths(root, schema.elements[root.Name.Local],
+			"/"+root.Name.Local, nil)
+	}
+	question.addedOverviewNext, question.addedContent = nil, nil
+	for _, child := range root.Children {
+		var content *xmlNode
+		switch child.Name.Local {
+		case "Overview":
+			question.addedOverviewNext = append(question.addedOverviewNext, true)
+			content = child
+		case "Question":
+			question.addedOverviewNext = append(question.addedOverviewNext, false)
+			for _, part := range child.Children {
+				if part.Name.Local == "QuestionContent" {
+					content = part
+				}
+			}
+		default:
+			continue
+		}
+		var names []string
+		if content != nil {
+			for _, item := range content.Children {
+				names = append(names, item.Name.Local)
+			}
+		}
+		question.addedContent = append(question.addedContent, names)
+	}
+	return nil
+}
+
+// Sort an element's children into the order of a list of names. Children not
+// named in the list go last.
+func orderChildren(node *xmlNode, names []string) {
+	var (
+		ordered []*xmlNode
+		used    = make([]bool, len(node.Children))
+	)
+	for _, name := range names {
+		for i, child := range node.Children {
+			if !used[i] && child.Name.Local == name {
+				used[i] = true
+				ordered = append(ordered, child)
+				break
+			}
+		}
+	}
+	for i, child := range node.Children {
+		if !used[i] {
+			ordered = append(ordered, child)
+		}
+	}
+	node.Children = ordered
+}
+
+// Retrieve the content struct for the most recently-added Overview or
+// Question, and record that an element is being added to it
+func (question *QuestionForm) getCurrentContent(element string) *questionform.TContentType {
+	last := len(question.addedContent) - 1
+	question.addedContent[last] = append(question.addedContent[last], element)
 	if question.addedOverviewNext[len(question.addedOverviewNext)-1] {
 		return question.Overviews[len(question.Overviews)-1]
 	} else {
@@ -154,6 +332,20 @@ func (question *QuestionForm) getCurrentAnswer() *questionform.TAnswerSpecificat
 	return qq.AnswerSpecification
 }
 
+// Record that an element of the most recent Question's answer specification
+// was set, so that it is encoded even if it holds a zero value.
+func (question *QuestionForm) keepAnswerElement(names ...string) {
+	path := fmt.Sprintf("/QuestionForm/Question[%d]/AnswerSpecification[1]",
+		len(question.Questions))
+	for _, name := range names {
+		path += "/" + name + "[1]"
+	}
+	if question.kept == nil {
+		question.kept = make(map[string]bool)
+	}
+	question.kept[path] = true
+}
+
 // Add a List item to the most recent Question/Overview added.
 func (question *QuestionForm) AddListContent(listItems []string) {
 	list := &questionform.TxsdContentTypeChoiceList{}
@@ -161,13 +353,13 @@ func (question *QuestionForm) AddListContent(listItems []string) {
 		list.ListItems = append(list.ListItems, xsdt.String(listItem))
 	}
 
-	content := question.getCurrentContent()
+	content := question.getCurrentContent("List")
 	content.Lists = append(content.Lists, list)
 }
 
 // Add a FormattedContent item to the most recent Question/Overview added.
 func (question *QuestionForm) AddFormattedContent(formattedContent string) {
-	content := question.getCurrentContent()
+	content := question.getCurrentContent("FormattedContent")
 	content.FormattedContents = append(content.FormattedContents, xsdt.String(formattedContent))
 }
 
@@ -189,7 +381,7 @@ func (question *QuestionForm) AddBinaryContent(mimeType, mimeSubType string,
 		binary.AltText = xsdt.String(altText)
 	}
 
-	content := question.getCurrentContent()
+	content := question.getCurrentContent("Binary")
 	content.Binaries = append(content.Binaries, binary)
 }
 
@@ -209,7 +401,7 @@ func (question *QuestionForm) AddFlashApplicationContent(flashMovieURL *url.URL,
 			application.Flash.ApplicationParameters, param)
 	}
 
-	content := question.getCurrentContent()
+	content := question.getCurrentContent("Application")
 	content.Applications = append(content.Applications, application)
 }
 
@@ -233,7 +425,7 @@ func (question *QuestionForm) AddJavaAppletApplicationContent(
 		application.JavaApplet.AppletPath = questionform.TURLType(appletPath.String())
 	}
 
-	content := question.getCurrentContent()
+	content := question.getCurrentContent("Application")
 	content.Applications = append(content.Applications, application)
 }
 
@@ -263,19 +455,19 @@ func (question *QuestionForm) AddEmbeddedBinaryContent(
 		}
 	}
 
-	content := question.getCurrentContent()
+	content := question.getCurrentContent("EmbeddedBinary")
 	content.EmbeddedBinaries = append(content.EmbeddedBinaries, binary)
 }
 
 // Add a Title item to the most recent Question/Overview added.
 func (question *QuestionForm) AddTitleContent(title string) {
-	content := question.getCurrentContent()
+	content := question.getCurrentContent("Title")
 	content.Titles = append(content.Titles, xsdt.String(title))
 }
 
 // Add a Text item to the most recent Question/Overview added.
 func (question *QuestionForm) AddTextContent(text string) {
-	content := question.getCurrentContent()
+	content := question.getCurrentContent("Text")
 	content.Texts = append(content.Texts, xsdt.String(text))
 }
 
@@ -336,6 +528,7 @@ func (question *QuestionForm) AddSelectionAnswerMinSelections(selections int) {
 		answer.SelectionAnswer = &questionform.TSelectionAnswerType{}
 	}
 	answer.SelectionAnswer.MinSelectionCount = xsdt.NonNegativeInteger(selections)
+	question.keepAnswerElement("SelectionAnswer", "MinSelectionCount")
 }
 
 // Add maximum number of selections for a selection answer
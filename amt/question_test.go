@@ -8,6 +8,7 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 	"net/url"
 	"testing"
+	"testing/fstest"
 )
 
 func TestHTMLQuestion(t *testing.T) {
@@ -98,6 +99,7 @@ func TestHTMLQuestion(t *testing.T) {
 						`<FrameHeight>450</FrameHeight>` +
 						`</HTMLQuestion>`
 					So(string(newxml), ShouldEqual, expected)
+					So(ValidateQuestion(newxml), ShouldBeNil)
 				})
 			})
 		})
@@ -132,10 +134,11 @@ func TestExternalQuestion(t *testing.T) {
 				Convey("Then I get the expected XML", func() {
 					So(err, ShouldBeNil)
 					expected := `<ExternalQuestion xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2006-07-14/ExternalQuestion.xsd">` +
-						`<FrameHeight>400</FrameHeight>` +
 						`<ExternalURL>https://tictactoe.amazon.com/gamesurvey.cgi?gameid=01523</ExternalURL>` +
+						`<FrameHeight>400</FrameHeight>` +
 						`</ExternalQuestion>`
 					So(string(newxml), ShouldEqual, expected)
+					So(ValidateQuestion(newxml), ShouldBeNil)
 				})
 			})
 		})
@@ -255,103 +258,164 @@ func TestQuestionForm(t *testing.T) {
 				exp.AddSelectionAnswerTextSelection("likely", "Likely")
 
 				result.XMLContent = ""
-				exp.addedOverviewNext = nil
 				So(result.Overviews[0], ShouldResemble, exp.Overviews[0])
 				So(result.Questions[0], ShouldResemble, exp.Questions[0])
 				So(result.Questions[1], ShouldResemble, exp.Questions[1])
 				So(result, ShouldResemble, &exp)
 			})
 
-			SkipConvey("When I Marshal the QuestionForm", func() {
-				// TODO: Skipped because Marshal isn't smart enough to
-				// preserve the order of subelements, and order matters here.
-
-				result.XMLContent = ""
+			Convey("When I Marshal the QuestionForm", func() {
 				newxml, err := EncodeQuestion(result)
 
-				Convey("Then I get the expected XML", func() {
+				Convey("Then I get valid XML in the original order", func() {
 					So(err, ShouldBeNil)
-					So(string(newxml), ShouldEqual,
-						`<QuestionForm xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/QuestionForm.xsd">`+
-							`<Overview>`+
-							`<Title>Game 01523, "X" to play</Title>`+
-							`<Text>
-			      You are helping to decide the next move in a game of Tic-Tac-Toe.  The board looks like this:
-			    </Text>`+
-							`<Binary>`+
-							`<MimeType>`+
-							`<SubType>gif</SubType>`+
-							`<Type>image</Type>`+
-							`</MimeType>`+
-							`<DataURL>http://tictactoe.amazon.com/game/01523/board.gif</DataURL>`+
-							`<AltText>The game board, with "X" to move.</AltText>`+
-							`</Binary>`+
-							`<Text>
-			      Player "X" has the next move.
-			    </Text>`+
-							`</Overview>`+
-							`<Question>`+
-							`<QuestionIdentifier>nextmove</QuestionIdentifier>`+
-							`<DisplayName>The Next Move</DisplayName>`+
-							`<IsRequired>true</IsRequired>`+
-							`<QuestionContent>`+
-							`<Text>
-			        What are the coordinates of the best move for player "X" in this game?
-			      </Text>`+
-							`</QuestionContent>`+
-							`<AnswerSpecification>`+
-							`<FreeTextAnswer>`+
-							`<Constraints>`+
-							`<Length minLength="2" maxLength="2">`+
-							`</Length>`+
-							`</Constraints>`+
-							`<DefaultText>C1</DefaultText>`+
-							`<NumberOfLinesSuggestion>0</NumberOfLinesSuggestion>`+
-							`</FreeTextAnswer>`+
-							`</AnswerSpecification>`+
-							`</Question>`+
-							`<Question>`+
-							`<QuestionIdentifier>likelytowin</QuestionIdentifier>`+
-							`<DisplayName>The Next Move</DisplayName>`+
-							`<IsRequired>true</IsRequired>`+
-							`<QuestionContent>`+
-							`<Text>
-			        How likely is it that player "X" will win this game?
-			      </Text>`+
-							`</QuestionContent>`+
-							`<AnswerSpecification>`+
-							`<SelectionAnswer>`+
-							`<MinSelectionCount>0</MinSelectionCount>`+
-							`<MaxSelectionCount>0</MaxSelectionCount>`+
-							`<StyleSuggestion>radiobutton</StyleSuggestion>`+
-							`<Selections>`+
-							`<Selection>`+
-							`<FormattedContent>`+
-							`</FormattedContent>`+
-							`<SelectionIdentifier>notlikely</SelectionIdentifier>`+
-							`<Text>Not likely</Text>`+
-							`</Selection>`+
-							`<Selection>`+
-							`<FormattedContent>`+
-							`</FormattedContent>`+
-							`<SelectionIdentifier>unsure</SelectionIdentifier>`+
-							`<Text>It could go either way</Text>`+
-							`</Selection>`+
-							`<Selection>`+
-							`<FormattedContent>`+
-							`</FormattedContent>`+
-							`<SelectionIdentifier>likely</SelectionIdentifier>`+
-							`<Text>Likely</Text>`+
-							`</Selection>`+
-							`</Selections>`+
-							`</SelectionAnswer>`+
-							`</AnswerSpecification>`+
-							`</Question>`+
-							`</QuestionForm>`)
+					So(ValidateQuestion(newxml), ShouldBeNil)
+					text := string(newxml)
+					So(text, ShouldStartWith, `<QuestionForm xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/QuestionForm.xsd"><Overview><Title>`)
+					So(text, ShouldContainSubstring, `</Text><Binary><MimeType><Type>image</Type><SubType>gif</SubType></MimeType>`)
+					So(text, ShouldContainSubstring, `</Binary><Text>`)
+					So(text, ShouldContainSubstring, `<Length minLength="2" maxLength="2"></Length></Constraints><DefaultText>C1</DefaultText></FreeTextAnswer>`)
+					So(text, ShouldContainSubstring, `<SelectionAnswer><StyleSuggestion>radiobutton</StyleSuggestion>`)
+					So(text, ShouldContainSubstring, `<Selection><SelectionIdentifier>notlikely</SelectionIdentifier><Text>Not likely</Text></Selection>`)
+					So(text, ShouldNotContainSubstring, `NumberOfLinesSuggestion`)
+					So(text, ShouldNotContainSubstring, `FormattedContent`)
+
+					again, err := DecodeQuestion(newxml)
+					So(err, ShouldBeNil)
+					again.(*QuestionForm).XMLContent = ""
+					result.XMLContent = ""
+					So(again, ShouldResemble, result)
 				})
 			})
 		})
 	})
+
+	Convey("Given XML for a QuestionForm with an unconstrained free text answer", t, func() {
+		xml := `<QuestionForm xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/QuestionForm.xsd">` +
+			`<Question><QuestionIdentifier>q1</QuestionIdentifier>` +
+			`<QuestionContent><Text>Why?</Text></QuestionContent>` +
+			`<AnswerSpecification><FreeTextAnswer></FreeTextAnswer></AnswerSpecification>` +
+			`</Question></QuestionForm>`
+
+		Convey("When I decode and re-encode it", func() {
+			question, err := DecodeQuestion([]byte(xml))
+			So(err, ShouldBeNil)
+			newxml, err := EncodeQuestion(question)
+
+			Convey("Then the empty FreeTextAnswer is kept", func() {
+				So(err, ShouldBeNil)
+				So(string(newxml), ShouldEqual, xml)
+				So(ValidateQuestion(newxml), ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given XML for a QuestionForm with an optional selection answer", t, func() {
+		xml := `<QuestionForm xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/QuestionForm.xsd">` +
+			`<Question><QuestionIdentifier>q1</QuestionIdentifier>` +
+			`<QuestionContent><Text>Why?</Text></QuestionContent>` +
+			`<AnswerSpecification><FreeTextAnswer></FreeTextAnswer></AnswerSpecification>` +
+			`</Question>` +
+			`<Question><QuestionIdentifier>q2</QuestionIdentifier>` +
+			`<QuestionContent><Text>Any others?</Text></QuestionContent>` +
+			`<AnswerSpecification><SelectionAnswer>` +
+			`<MinSelectionCount>0</MinSelectionCount><MaxSelectionCount>2</MaxSelectionCount>` +
+			`<Selections><Selection><SelectionIdentifier>a</SelectionIdentifier><Text>A</Text></Selection>` +
+			`<Selection><SelectionIdentifier>b</SelectionIdentifier><Text>B</Text></Selection></Selections>` +
+			`</SelectionAnswer></AnswerSpecification>` +
+			`</Question></QuestionForm>`
+
+		Convey("When I decode and re-encode it", func() {
+			question, err := DecodeQuestion([]byte(xml))
+			So(err, ShouldBeNil)
+			newxml, err := EncodeQuestion(question)
+
+			Convey("Then the MinSelectionCount of 0 is kept", func() {
+				So(err, ShouldBeNil)
+				So(string(newxml), ShouldEqual, xml)
+				So(ValidateQuestion(newxml), ShouldBeNil)
+			})
+		})
+
+		Convey("When I build the same form with the helper methods", func() {
+			var form QuestionForm
+			form.AddQuestion("q1", "", false)
+			form.AddTextContent("Why?")
+			form.AddFreeTextAnswerDefaultText("")
+			form.AddQuestion("q2", "", false)
+			form.AddTextContent("Any others?")
+			form.AddSelectionAnswerMinSelections(0)
+			form.AddSelectionAnswerMaxSelections(2)
+			form.AddSelectionAnswerTextSelection("a", "A")
+			form.AddSelectionAnswerTextSelection("b", "B")
+			newxml, err := EncodeQuestion(&form)
+
+			Convey("Then the MinSelectionCount of 0 is written", func() {
+				So(err, ShouldBeNil)
+				So(string(newxml), ShouldEqual, xml)
+			})
+		})
+	})
+
+	Convey("Given a QuestionForm built with the helper methods", t, func() {
+		var form QuestionForm
+		form.AddQuestion("q1", "", true)
+		form.AddTextContent("Which is best?")
+		imageURL, _ := url.Parse("http://example.com/a.png")
+		form.AddBinaryContent("image", "png", imageURL, "A")
+		form.AddTitleContent("Pick one")
+		form.AddSelectionAnswerMaxSelections(1)
+		form.AddSelectionAnswerTextSelection("a", "A")
+		form.AddOverview()
+		form.AddTextContent("Thanks")
+		form.AddQuestion("q2", "Second", false)
+		form.AddTextContent("Why?")
+		form.AddFreeTextAnswerNumericConstraints(1, 10)
+
+		Convey("When I Marshal the QuestionForm", func() {
+			newxml, err := EncodeQuestion(&form)
+
+			Convey("Then the elements are in the order added", func() {
+				So(err, ShouldBeNil)
+				So(ValidateQuestion(newxml), ShouldBeNil)
+				So(string(newxml), ShouldEqual,
+					`<QuestionForm xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/QuestionForm.xsd">`+
+						`<Question>`+
+						`<QuestionIdentifier>q1</QuestionIdentifier>`+
+						`<IsRequired>true</IsRequired>`+
+						`<QuestionContent>`+
+						`<Text>Which is best?</Text>`+
+						`<Binary>`+
+						`<MimeType><Type>image</Type><SubType>png</SubType></MimeType>`+
+						`<DataURL>http://example.com/a.png</DataURL>`+
+						`<AltText>A</AltText>`+
+						`</Binary>`+
+						`<Title>Pick one</Title>`+
+						`</QuestionContent>`+
+						`<AnswerSpecification>`+
+						`<SelectionAnswer>`+
+						`<MaxSelectionCount>1</MaxSelectionCount>`+
+						`<Selections>`+
+						`<Selection><SelectionIdentifier>a</SelectionIdentifier><Text>A</Text></Selection>`+
+						`</Selections>`+
+						`</SelectionAnswer>`+
+						`</AnswerSpecification>`+
+						`</Question>`+
+						`<Overview><Text>Thanks</Text></Overview>`+
+						`<Question>`+
+						`<QuestionIdentifier>q2</QuestionIdentifier>`+
+						`<DisplayName>Second</DisplayName>`+
+						`<QuestionContent><Text>Why?</Text></QuestionContent>`+
+						`<AnswerSpecification>`+
+						`<FreeTextAnswer>`+
+						`<Constraints><IsNumeric minValue="1" maxValue="10"></IsNumeric></Constraints>`+
+						`</FreeTextAnswer>`+
+						`</AnswerSpecification>`+
+						`</Question>`+
+						`</QuestionForm>`)
+			})
+		})
+	})
 }
 
 func TestAnswerKey(t *testing.T) {
@@ -419,7 +483,6 @@ func TestAnswerKey(t *testing.T) {
 						`<SelectionIdentifier>D</SelectionIdentifier>` +
 						`<AnswerScore>5</AnswerScore>` +
 						`</AnswerOption>` +
-						`<DefaultScore>0</DefaultScore>` +
 						`</Question>` +
 						`<Question>` +
 						`<QuestionIdentifier>favoritefruit</QuestionIdentifier>` +
@@ -427,7 +490,6 @@ func TestAnswerKey(t *testing.T) {
 						`<SelectionIdentifier>apples</SelectionIdentifier>` +
 						`<AnswerScore>10</AnswerScore>` +
 						`</AnswerOption>` +
-						`<DefaultScore>0</DefaultScore>` +
 						`</Question>` +
 						`<QualificationValueMapping>` +
 						`<PercentageMapping>` +
@@ -436,6 +498,7 @@ func TestAnswerKey(t *testing.T) {
 						`</QualificationValueMapping>` +
 						`</AnswerKey>`
 					So(string(newxml), ShouldEqual, expected)
+					So(ValidateQuestion(newxml), ShouldBeNil)
 				})
 			})
 		})
@@ -479,17 +542,15 @@ func TestQuestionFormAnswers(t *testing.T) {
 				So(result, ShouldResemble, expected)
 			})
 
-			SkipConvey("When I Marshal the QuestionFormAnswers", func() {
-				// TODO: Skipped because it marshals various empty fields
-
+			Convey("When I Marshal the QuestionFormAnswers", func() {
 				newxml, err := EncodeQuestion(result)
 
 				Convey("Then I get the expected XML", func() {
 					So(err, ShouldBeNil)
 					expected := `<QuestionFormAnswers xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/QuestionFormAnswers.xsd">` +
 						`<Answer>` +
-						`<FreeText>C3</FreeText>` +
 						`<QuestionIdentifier>nextmove</QuestionIdentifier>` +
+						`<FreeText>C3</FreeText>` +
 						`</Answer>` +
 						`<Answer>` +
 						`<QuestionIdentifier>likelytowin</QuestionIdentifier>` +
@@ -497,8 +558,95 @@ func TestQuestionFormAnswers(t *testing.T) {
 						`</Answer>` +
 						`</QuestionFormAnswers>`
 					So(string(newxml), ShouldEqual, expected)
+					So(ValidateQuestion(newxml), ShouldBeNil)
 				})
 			})
 		})
 	})
 }
+
+func TestValidateQuestion(t *testing.T) {
+	Convey("Given question XML which breaks its schema", t, func() {
+		form := `<QuestionForm xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/QuestionForm.xsd">` +
+			`<Question>` +
+			`<QuestionIdentifier>q1</QuestionIdentifier>` +
+			`<AnswerSpecification><FreeTextAnswer><NumberOfLinesSuggestion>0</NumberOfLinesSuggestion></FreeTextAnswer></AnswerSpecification>` +
+			`<QuestionContent><Text>Hi</Text></QuestionContent>` +
+			`</Question>` +
+			`<Question>` +
+			`<QuestionIdentifier>q2</QuestionIdentifier>` +
+			`<QuestionContent><Binary><MimeType><Type>text</Type></MimeType><DataURL>ftp://x</DataURL><AltText/></Binary></QuestionContent>` +
+			`<AnswerSpecification><FreeTextAnswer><Constraints><Length minLength="-1"/><AnswerFormatRegex/></Constraints></FreeTextAnswer></AnswerSpecification>` +
+			`<Color>red</Color>` +
+			`</Question>` +
+			`</QuestionForm>`
+
+		Convey("When I validate it", func() {
+			err := ValidateQuestion([]byte(form))
+
+			Convey("Then every violation is reported", func() {
+				So(err, ShouldNotBeNil)
+				verr, ok := err.(*ValidationError)
+				So(ok, ShouldBeTrue)
+				So(verr.Violations, ShouldResemble, []string{
+					"/QuestionForm/Question[1]: found (QuestionIdentifier, AnswerSpecification, QuestionContent), expected (QuestionIdentifier, DisplayName?, IsRequired?, QuestionContent, AnswerSpecification)",
+					`/QuestionForm/Question[1]/AnswerSpecification/FreeTextAnswer/NumberOfLinesSuggestion: "0" is not a valid positiveInteger`,
+					"/QuestionForm/Question[2]: unexpected element <Color>",
+					`/QuestionForm/Question[2]/QuestionContent/Binary/MimeType/Type: "text" is not one of image, audio, video`,
+					`/QuestionForm/Question[2]/QuestionContent/Binary/DataURL: "ftp://x" does not match the pattern ^(?:(http|https)://.*)$`,
+					`/QuestionForm/Question[2]/AnswerSpecification/FreeTextAnswer/Constraints/Length: attribute minLength "-1" is not a valid nonNegativeInteger`,
+					"/QuestionForm/Question[2]/AnswerSpecification/FreeTextAnswer/Constraints/AnswerFormatRegex: missing attribute regex",
+				})
+			})
+		})
+	})
+
+	Convey("Given XML for an unknown schema", t, func() {
+		err := ValidateQuestion([]byte(`<Question xmlns="urn:example"/>`))
+
+		Convey("Then it cannot be validated", func() {
+			So(err.Error(), ShouldEqual, `No question schema has namespace "urn:example"`)
+		})
+	})
+
+	Convey("Given an HTMLQuestion with an invalid frame height", t, func() {
+		err := ValidateQuestion([]byte(`<HTMLQuestion xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2011-11-11/HTMLQuestion.xsd">` +
+			`<HTMLContent>hi</HTMLContent><FrameHeight>tall</FrameHeight></HTMLQuestion>`))
+
+		Convey("Then the height is reported", func() {
+			So(err.Error(), ShouldEqual, `Question XML does not match its schema: /HTMLQuestion/FrameHeight: "tall" is not a valid integer`)
+		})
+	})
+
+	Convey("Given a schema with annotations", t, func() {
+		schema, err := parseSchema([]byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:example">` +
+			`<xs:annotation><xs:documentation>A note</xs:documentation></xs:annotation>` +
+			`<xs:element name="Note"><xs:annotation><xs:documentation>The note</xs:documentation></xs:annotation>` +
+			`<xs:complexType><xs:annotation/><xs:sequence><xs:annotation/>` +
+			`<xs:element name="Text" type="xs:string"/></xs:sequence></xs:complexType></xs:element>` +
+			`</xs:schema>`))
+
+		Convey("Then the annotations are ignored", func() {
+			So(err, ShouldBeNil)
+			var violations []string
+			root, _ := parseXMLNode([]byte(`<Note xmlns="urn:example"><Text>Hi</Text></Note>`))
+			schema.validate(root, schema.elements["Note"], "/Note", &violations)
+			So(violations, ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a schema which uses an unsupported construct", t, func() {
+		_, err := loadSchemas(fstest.MapFS{
+			"gen/example.com/Schemas/2020-01-01/Note.xsd": &fstest.MapFile{
+				Data: []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">` +
+					`<xs:attributeGroup name="Common"/></xs:schema>`),
+			},
+		})
+
+		Convey("Then loading it fails with an error", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "Note.xsd")
+			So(err.Error(), ShouldContainSubstring, "attributeGroup")
+		})
+	})
+}
@@ -0,0 +1,225 @@
+package amt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+var (
+	// ErrNoFixture is wrapped by the error a Replayer returns for a request
+	// which matches no recorded fixture.
+	ErrNoFixture = errors.New("no recorded response matches the request")
+
+	// Query parameters which are never recorded: the credentials, and the
+	// timestamp which changes on every request
+	unrecordedArgs = []string{"AWSAccessKeyId", "Signature", "Timestamp"}
+)
+
+// Fixture is a recorded AMT request and its response. The request is
+// identified by its Operation and its other arguments, excluding the
+// AWSAccessKeyId, Signature and Timestamp.
+type Fixture struct {
+	Operation string     `json:"operation"`
+	Args      url.Values `json:"args"`
+	Status    int        `json:"status"`
+	Body      string     `json:"body"`
+}
+
+// Extract the operation and normalized arguments of a request, from its URL
+// query and any form-encoded body, so that a request matches its fixture
+// whether it was sent as a GET or a POST. The body is left unread.
+func fixtureArgs(req *http.Request) (operation string, args url.Values) {
+	args = req.URL.Query()
+	if req.Body != nil && req.Header.Get("Content-Type") == FORM_CONTENT_TYPE {
+		var body []byte
+		if req.GetBody != nil {
+			if rc, err := req.GetBody(); err == nil {
+				body, _ = ioutil.ReadAll(rc)
+				rc.Close()
+			}
+		} else {
+			body, _ = ioutil.ReadAll(req.Body)
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		form, _ := url.ParseQuery(string(body))
+		for key, values := range form {
+			args[key] = append(args[key], values...)
+		}
+	}
+	operation = args.Get("Operation")
+	args.Del("Operation")
+	for _, name := range unrecordedArgs {
+		args.Del(name)
+	}
+	return operation, args
+}
+
+// Recorder captures the requests sent by a client, along with their
+// responses. Install it with WithMiddleware(recorder.Middleware) and call
+// Save once the requests of interest have been sent.
+type Recorder struct {
+	mu       sync.Mutex
+	fixtures []Fixture
+}
+
+// NewRecorder creates a recorder with no fixtures.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Middleware records each request which passes through it.
+func (rec *Recorder) Middleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		operation, args := fixtureArgs(req)
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return resp, err
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.fixtures = append(rec.fixtures, Fixture{
+			Operation: operation,
+			Args:      args,
+			Status:    resp.StatusCode,
+			Body:      string(body),
+		})
+		return resp, nil
+	})
+}
+
+// Fixtures returns the fixtures recorded so far, in the order the requests
+// were sent.
+func (rec *Recorder) Fixtures() []Fixture {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]Fixture(nil), rec.fixtures...)
+}
+
+// Save writes the fixtures recorded so far to a file.
+func (rec *Recorder) Save(path string) error {
+	return SaveFixtures(path, rec.Fixtures())
+}
+
+// SaveFixtures writes fixtures to a file as indented JSON. Markup is not
+// escaped, so that recorded XML stays readable in diffs.
+func SaveFixtures(path string, fixtures []Fixture) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(fixtures); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// LoadFixtures reads fixtures written by SaveFixtures.
+func LoadFixtures(path string) ([]Fixture, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures []Fixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("Could not parse fixtures in %s: %v", path, err)
+	}
+	return fixtures, nil
+}
+
+// Replayer is an http.RoundTripper which answers requests from recorded
+// fixtures instead of contacting AMT. Install it with
+// WithHTTPClient(&http.Client{Transport: replayer}).
+//
+// A request matches a fixture with the same Operation and arguments. Matching
+// fixtures are replayed in the order they were recorded, so a sequence such
+// as GetHIT, ApproveAssignment, GetHIT sees the HIT change; once every match
+// has been used, the last one is replayed again.
+type Replayer struct {
+	mu       sync.Mutex
+	fixtures []Fixture
+	used     []bool
+}
+
+// NewReplayer creates a replayer for the given fixtures.
+func NewReplayer(fixtures []Fixture) *Replayer {
+	return &Replayer{
+		fixtures: fixtures,
+		used:     make([]bool, len(fixtures)),
+	}
+}
+
+// LoadReplayer creates a replayer for the fixtures in a file.
+func LoadReplayer(path string) (*Replayer, error) {
+	fixtures, err := LoadFixtures(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(fixtures), nil
+}
+
+// RoundTrip answers a request with the next matching fixture. If none match,
+// it returns an error wrapping ErrNoFixture which names the operation and
+// arguments, so that a change in how arguments are encoded is easy to spot.
+func (rep *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	operation, args := fixtureArgs(req)
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	encoded := args.Encode()
+
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	last := -1
+	for i, fixture := range rep.fixtures {
+		if fixture.Operation != operation || fixture.Args.Encode() != encoded {
+			continue
+		}
+		last = i
+		if !rep.used[i] {
+			break
+		}
+	}
+	if last < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoFixture, operation, encoded)
+	}
+	rep.used[last] = true
+	fixture := rep.fixtures[last]
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Status, http.StatusText(fixture.Status)),
+		StatusCode:    fixture.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/xml"}},
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(fixture.Body))),
+		ContentLength: int64(len(fixture.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Unused returns the fixtures which have not been replayed, so that a test
+// can check that every recorded request was made.
+func (rep *Replayer) Unused() []Fixture {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	var unused []Fixture
+	for i, fixture := range rep.fixtures {
+		if !rep.used[i] {
+			unused = append(unused, fixture)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,175 @@
+package amt
+
+import (
+	"errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	// The question asked in testdata/session.json
+	SESSION_QUESTION = `<HTMLQuestion xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2011-11-11/HTMLQuestion.xsd"><HTMLContent>Hi</HTMLContent><FrameHeight>400</FrameHeight></HTMLQuestion>`
+)
+
+// A fixture answering GetAccountBalance with a given body
+func balanceFixture(body string) Fixture {
+	return Fixture{
+		Operation: "GetAccountBalance",
+		Args: url.Values{
+			"Service": {AMT_SERVICE},
+			"Version": {API_VERSION},
+		},
+		Status: http.StatusOK,
+		Body:   body,
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	Convey("Given a replayer with two GetAccountBalance fixtures", t, func() {
+		replayer := NewReplayer([]Fixture{
+			balanceFixture(BALANCE_RESPONSE),
+			balanceFixture("second"),
+		})
+		client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY,
+			"http://amt.invalid", WithHTTPClient(&http.Client{Transport: replayer}),
+			WithRetryPolicy(nil))
+
+		Convey("When I repeat the request", func() {
+			_, err1 := client.GetAccountBalance()
+			unused := replayer.Unused()
+			_, err2 := client.GetAccountBalance()
+			_, err3 := client.GetAccountBalance()
+
+			Convey("Then the fixtures are replayed in order, then the last repeats", func() {
+				So(err1, ShouldBeNil)
+				So(unused, ShouldHaveLength, 1)
+				So(unused[0].Body, ShouldEqual, "second")
+				So(err2, ShouldNotBeNil)
+				So(err3, ShouldNotBeNil)
+				So(errors.Is(err3, ErrNoFixture), ShouldBeFalse)
+				So(replayer.Unused(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When I send a request which was not recorded", func() {
+			_, err := client.GetHIT("HIT1")
+
+			Convey("Then the error names the operation and arguments", func() {
+				So(errors.Is(err, ErrNoFixture), ShouldBeTrue)
+				So(err.Error(), ShouldContainSubstring, "GetHIT")
+				So(err.Error(), ShouldContainSubstring, "HITId=HIT1")
+			})
+		})
+	})
+
+	Convey("Given a client recording its traffic", t, func() {
+		srv := newFlakyServer(0, http.StatusOK)
+		defer srv.Close()
+		recorder := NewRecorder()
+		client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, srv.URL,
+			WithMiddleware(recorder.Middleware))
+
+		Convey("When I send a request and save the fixtures", func() {
+			_, err := client.GetAccountBalance()
+			So(err, ShouldBeNil)
+			dir, err := ioutil.TempDir("", "amt-fixtures")
+			So(err, ShouldBeNil)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "fixtures.json")
+			So(recorder.Save(path), ShouldBeNil)
+
+			Convey("Then the response is saved without the credentials", func() {
+				data, err := ioutil.ReadFile(path)
+				So(err, ShouldBeNil)
+				So(string(data), ShouldNotContainSubstring, FAKE_ACCESS_KEY)
+				So(string(data), ShouldNotContainSubstring, "Signature")
+				fixtures, err := LoadFixtures(path)
+				So(err, ShouldBeNil)
+				So(fixtures, ShouldResemble, []Fixture{balanceFixture(BALANCE_RESPONSE)})
+			})
+		})
+	})
+
+	Convey("Given a client recording its traffic sent as POSTs", t, func() {
+		srv := newFlakyServer(0, http.StatusOK)
+		defer srv.Close()
+		recorder := NewRecorder()
+		client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY, srv.URL,
+			WithMiddleware(recorder.Middleware), WithPostThreshold(-1))
+
+		Convey("When I send a request", func() {
+			_, err := client.GetAccountBalance()
+
+			Convey("Then the body reaches the server and its arguments are recorded", func() {
+				So(err, ShouldBeNil)
+				So(srv.signatures, ShouldResemble, []bool{true})
+				So(recorder.Fixtures(), ShouldResemble,
+					[]Fixture{balanceFixture(BALANCE_RESPONSE)})
+			})
+
+			Convey("Then the fixture replays for a GET", func() {
+				replayer := NewReplayer(recorder.Fixtures())
+				client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY,
+					"http://amt.invalid", WithHTTPClient(&http.Client{Transport: replayer}),
+					WithPostThreshold(0))
+				_, err := client.GetAccountBalance()
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a replayer and a client which sends POSTs", t, func() {
+		replayer := NewReplayer([]Fixture{balanceFixture(BALANCE_RESPONSE)})
+		client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY,
+			"http://amt.invalid", WithHTTPClient(&http.Client{Transport: replayer}),
+			WithPostThreshold(-1), WithRetryPolicy(nil))
+
+		Convey("When I send a recorded request", func() {
+			_, err := client.GetAccountBalance()
+
+			Convey("Then it matches on the arguments in the body", func() {
+				So(err, ShouldBeNil)
+				So(replayer.Unused(), ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestReplaySession(t *testing.T) {
+	Convey("Given the session recorded against the simulator", t, func() {
+		replayer, err := LoadReplayer(filepath.Join("testdata", "session.json"))
+		So(err, ShouldBeNil)
+		client := NewClientWithURL(FAKE_ACCESS_KEY, FAKE_SECRET_KEY,
+			"http://amt.invalid", WithHTTPClient(&http.Client{Transport: replayer}))
+
+		Convey("When I replay it", func() {
+			hit, err := client.CreateHIT("Title", "Description", SESSION_QUESTION,
+				"", nil, 0.25, 60, 3600, 1, 600, []string{"a", "b"}, nil, nil, nil,
+				"", "")
+			So(err, ShouldBeNil)
+			hitId := string(hit.Hits[0].HITId)
+			assignments, err := client.GetAssignmentsForHIT(hitId,
+				[]string{"Submitted"}, "SubmitTime", true, 10, 1)
+			So(err, ShouldBeNil)
+			result := assignments.GetAssignmentsForHITResults[0]
+			So(result.Assignments, ShouldHaveLength, 1)
+			_, err = client.ApproveAssignment(
+				string(result.Assignments[0].AssignmentId), "")
+			So(err, ShouldBeNil)
+			balance, err := client.GetAccountBalance()
+			So(err, ShouldBeNil)
+
+			Convey("Then every response comes from the fixtures", func() {
+				So(hitId, ShouldEqual, "SIMHIT000000000003")
+				So(string(balance.GetAccountBalanceResults[0].AvailableBalance.Amount),
+					ShouldEqual, "9.70")
+				So(replayer.Unused(), ShouldBeEmpty)
+			})
+		})
+	})
+}
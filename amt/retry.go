@@ -0,0 +1,166 @@
+package amt
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+var (
+	// AMT error codes which indicate a transient failure worth retrying.
+	retryableCodes = map[string]bool{
+		CODE_SERVICE_UNAVAILABLE: true,
+		"ServiceUnavailable":     true,
+		"Throttling":             true,
+		"RequestThrottled":       true,
+		"ThrottlingException":    true,
+	}
+)
+
+// RetryPolicy controls how a client retries requests which fail for
+// transient reasons, such as network errors or AMT throttling.
+//
+// A mutating request without a UniqueRequestToken, such as a CreateHIT or
+// GrantBonus with an empty token, may have taken effect even though it
+// failed, e.g. when AMT times out after creating the HIT. Such a request is
+// retried only if it failed before it was sent, so that it is never carried
+// out twice. Pass a UniqueRequestToken to have it retried like any other.
+type RetryPolicy struct {
+
+	// The maximum number of attempts per request, including the first.
+	// Values below 1 are treated as 1.
+	MaxAttempts int
+
+	// The delay before the first retry
+	InitialBackoff time.Duration
+
+	// The upper bound on the delay between attempts
+	MaxBackoff time.Duration
+
+	// The factor by which the delay grows after each failed attempt
+	Multiplier float64
+
+	// The fraction of each delay which is randomized, from 0 to 1. A value of
+	// 0.2 spreads a 10s delay uniformly over [8s, 12s].
+	Jitter float64
+
+	// Decides whether a failed attempt should be retried. If nil,
+	// IsRetryable is used.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy returns the retry policy used by NewClient: five
+// attempts, starting at one second and doubling up to thirty seconds, with
+// 20% jitter.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// Backoff returns the delay to wait after the given failed attempt, where the
+// first attempt is number 1.
+func (policy RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(policy.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if policy.MaxBackoff > 0 && delay > float64(policy.MaxBackoff) {
+		delay = float64(policy.MaxBackoff)
+	}
+	if policy.Jitter > 0 {
+		delay += delay * policy.Jitter * (2*rand.Float64() - 1)
+	}
+	if delay < 0 {
+		return 0
+	}
+	return time.Duration(delay)
+}
+
+// Returns the number of attempts permitted by the policy
+func (policy RetryPolicy) attempts() int {
+	if policy.MaxAttempts < 1 {
+		return 1
+	}
+	return policy.MaxAttempts
+}
+
+// Decide whether an error should be retried under this policy
+func (policy RetryPolicy) shouldRetry(err error) bool {
+	if policy.Retryable != nil {
+		return policy.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// Call fn until it succeeds, fails with an error the policy does not retry,
+// or runs out of attempts, waiting out the backoff between attempts. The
+// attempt number, starting at 1, is passed to fn.
+func (policy RetryPolicy) do(ctx context.Context, fn func(attempt int) error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || attempt >= policy.attempts() || !policy.shouldRetry(err) {
+			return err
+		}
+		timer := time.NewTimer(policy.Backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Return the policy for one request of an operation, which retries a mutating
+// request without a UniqueRequestToken only if it was never sent.
+func (policy RetryPolicy) forRequest(operation string, hasToken bool) RetryPolicy {
+	if hasToken || !IsMutatingOperation(operation) {
+		return policy
+	}
+	retryable := policy.shouldRetry
+	policy.Retryable = func(err error) bool {
+		return isUnsent(err) && retryable(err)
+	}
+	return policy
+}
+
+// Whether a request failed before it was sent, e.g. while dialing AMT.
+func isUnsent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// IsRetryable reports whether an error returned by a request is likely to be
+// transient: a network failure, an HTTP 5xx or 429 status, or an AMT
+// throttling error code. Context cancellation is never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return retryableCodes[httpErr.Code] ||
+			httpErr.StatusCode == http.StatusTooManyRequests ||
+			httpErr.StatusCode >= 500
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return retryableCodes[apiErr.Code]
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,213 @@
+package amt
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	BALANCE_RESPONSE = `<?xml version="1.0"?>
+<GetAccountBalanceResponse>
+  <OperationRequest><RequestId>` + REQUEST_ID + `</RequestId></OperationRequest>
+  <GetAccountBalanceResult>
+    <Request><IsValid>True</IsValid></Request>
+    <AvailableBalance>
+      <Amount>10000.000</Amount>
+      <CurrencyCode>USD</CurrencyCode>
+      <FormattedPrice>$10,000.00</FormattedPrice>
+    </AvailableBalance>
+  </GetAccountBalanceResult>
+</GetAccountBalanceResponse>`
+
+	THROTTLED_RESPONSE = `<?xml version="1.0"?>
+<Response><Errors><Error>
+  <Code>AWS.ServiceUnavailable</Code>
+  <Message>Your request was throttled.</Message>
+</Error></Errors></Response>`
+)
+
+// A test server which fails its first requests with the given status
+type flakyServer struct {
+	*httptest.Server
+	failures   int
+	status     int
+	attempts   int
+	signatures []bool
+}
+
+func newFlakyServer(failures, status int) *flakyServer {
+	srv := &flakyServer{failures: failures, status: status}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		srv.attempts++
+		client := amtClient{SecretKey: FAKE_SECRET_KEY}
+		expected := client.signatureFor(r.Form.Get("Service"),
+			r.Form.Get("Operation"), r.Form.Get("Timestamp"))
+		srv.signatures = append(srv.signatures, expected == r.Form.Get("Signature"))
+		if srv.attempts <= srv.failures {
+			w.WriteHeader(srv.status)
+			if srv.status == http.StatusServiceUnavailable {
+				fmt.Fprint(w, THROTTLED_RESPONSE)
+			}
+			return
+		}
+		fmt.Fprint(w, BALANCE_RESPONSE)
+	}))
+	return srv
+}
+
+func newRetryClient(url string, attempts int) *amtClient {
+	return &amtClient{
+		AWSAccessKeyId: FAKE_ACCESS_KEY,
+		SecretKey:      FAKE_SECRET_KEY,
+		UrlRoot:        url,
+		Retry: &RetryPolicy{
+			MaxAttempts:    attempts,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+			Multiplier:     2,
+		},
+	}
+}
+
+func TestRetry(t *testing.T) {
+	Convey("Given a server which is throttling requests", t, func() {
+		srv := newFlakyServer(2, http.StatusServiceUnavailable)
+		defer srv.Close()
+
+		Convey("When the retry policy allows enough attempts", func() {
+			client := newRetryClient(srv.URL, 3)
+			result, err := client.GetAccountBalance()
+
+			Convey("Then the request eventually succeeds", func() {
+				So(err, ShouldBeNil)
+				So(result.GetAccountBalanceResults, ShouldHaveLength, 1)
+				So(srv.attempts, ShouldEqual, 3)
+			})
+			Convey("Then every attempt is correctly signed", func() {
+				So(srv.signatures, ShouldResemble, []bool{true, true, true})
+			})
+		})
+
+		Convey("When the retry policy gives up first", func() {
+			client := newRetryClient(srv.URL, 2)
+			_, err := client.GetAccountBalance()
+
+			Convey("Then the throttling error is returned", func() {
+				var httpErr *HTTPError
+				So(errors.As(err, &httpErr), ShouldBeTrue)
+				So(httpErr.StatusCode, ShouldEqual, http.StatusServiceUnavailable)
+				So(httpErr.Code, ShouldEqual, "AWS.ServiceUnavailable")
+				So(srv.attempts, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the client has no retry policy", func() {
+			client := newRetryClient(srv.URL, 0)
+			client.Retry = nil
+			_, err := client.GetAccountBalance()
+
+			Convey("Then the request is attempted once", func() {
+				So(err, ShouldNotBeNil)
+				So(srv.attempts, ShouldEqual, 1)
+			})
+		})
+	})
+
+	Convey("Given a server which fails a request after accepting it", t, func() {
+		srv := newFlakyServer(1, http.StatusServiceUnavailable)
+		defer srv.Close()
+		client := newRetryClient(srv.URL, 3)
+
+		Convey("When I create a HIT without a UniqueRequestToken", func() {
+			_, err := client.CreateHITFromHITTypeId("HITTYPE1", "", "LAYOUT1",
+				nil, 3600, 1, nil, nil, "", "")
+
+			Convey("Then the request is not sent again", func() {
+				So(err, ShouldNotBeNil)
+				So(srv.attempts, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When I create a HIT with a UniqueRequestToken", func() {
+			client.CreateHITFromHITTypeId("HITTYPE1", "", "LAYOUT1", nil,
+				3600, 1, nil, nil, "", "TOKEN1")
+
+			Convey("Then the request is retried", func() {
+				So(srv.attempts, ShouldEqual, 2)
+			})
+		})
+	})
+
+	Convey("Given a server which cannot be reached", t, func() {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		srv.Close()
+		var attempts int
+		client := newRetryClient(srv.URL, 3)
+		client.HTTPClient = &http.Client{
+			Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				attempts++
+				return http.DefaultTransport.RoundTrip(req)
+			}),
+		}
+
+		Convey("When I create a HIT without a UniqueRequestToken", func() {
+			_, err := client.CreateHITFromHITTypeId("HITTYPE1", "", "LAYOUT1",
+				nil, 3600, 1, nil, nil, "", "")
+
+			Convey("Then the request is retried, since it was never sent", func() {
+				So(err, ShouldNotBeNil)
+				So(attempts, ShouldEqual, 3)
+			})
+		})
+	})
+
+	Convey("Given a server which rejects requests", t, func() {
+		srv := newFlakyServer(1, http.StatusBadRequest)
+		defer srv.Close()
+
+		Convey("When I send a request", func() {
+			client := newRetryClient(srv.URL, 3)
+			_, err := client.GetAccountBalance()
+
+			Convey("Then the request is not retried", func() {
+				So(err, ShouldNotBeNil)
+				So(srv.attempts, ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	Convey("Given a retry policy without jitter", t, func() {
+		policy := RetryPolicy{
+			InitialBackoff: time.Second,
+			MaxBackoff:     5 * time.Second,
+			Multiplier:     2,
+		}
+		Convey("Then the backoff grows exponentially up to the maximum", func() {
+			So(policy.Backoff(1), ShouldEqual, time.Second)
+			So(policy.Backoff(2), ShouldEqual, 2*time.Second)
+			So(policy.Backoff(3), ShouldEqual, 4*time.Second)
+			So(policy.Backoff(4), ShouldEqual, 5*time.Second)
+		})
+	})
+
+	Convey("Given a retry policy with jitter", t, func() {
+		policy := RetryPolicy{
+			InitialBackoff: time.Second,
+			Multiplier:     2,
+			Jitter:         0.5,
+		}
+		Convey("Then the backoff stays within the jitter bounds", func() {
+			for i := 0; i < 20; i++ {
+				So(policy.Backoff(2), ShouldBeBetween, time.Second-1, 3*time.Second+1)
+			}
+		})
+	})
+}
@@ -0,0 +1,822 @@
+package amt
+
+import (
+	"bytes"
+	"embed"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The XML schemas for questions, answers, and answer keys, as published by
+// Amazon
+//
+//go:embed gen/mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/*/*.xsd
+var questionSchemaFiles embed.FS
+
+var (
+	// The parsed question schemas, by target namespace, and any error
+	// loading them. They are loaded on first use.
+	questionSchemasOnce sync.Once
+	questionSchemas     map[string]*xmlSchema
+	questionSchemasErr  error
+)
+
+// Find the bundled schema with a target namespace, loading the schemas if
+// they have not been loaded yet. Returns nil if no schema has the namespace.
+func questionSchema(namespace string) (*xmlSchema, error) {
+	questionSchemasOnce.Do(func() {
+		questionSchemas, questionSchemasErr = loadSchemas(questionSchemaFiles)
+	})
+	if questionSchemasErr != nil {
+		return nil, questionSchemasErr
+	}
+	return questionSchemas[namespace], nil
+}
+
+// ValidationError is returned by ValidateQuestion for a document which does
+// not conform to its schema.
+type ValidationError struct {
+
+	// The schema's target namespace
+	Namespace string
+
+	// Each violation found, prefixed with the path to the offending element,
+	// e.g. "/QuestionForm/Question[2]/QuestionIdentifier: missing"
+	Violations []string
+}
+
+func (err *ValidationError) Error() string {
+	return fmt.Sprintf("Question XML does not match its schema: %s",
+		strings.Join(err.Violations, "; "))
+}
+
+// ValidateQuestion checks XML for a question, answer key, or set of answers
+// against the bundled schema named by its root element's namespace, so that
+// mistakes can be found before AMT rejects a CreateHIT call. It returns a
+// *ValidationError listing every violation found.
+func ValidateQuestion(questionXml []byte) error {
+	root, err := parseXMLNode(questionXml)
+	if err != nil {
+		return err
+	}
+	schema, err := questionSchema(root.Name.Space)
+	if err != nil {
+		return err
+	} else if schema == nil {
+		return fmt.Errorf("No question schema has namespace %q", root.Name.Space)
+	}
+	decl := schema.elements[root.Name.Local]
+	if decl == nil {
+		return fmt.Errorf("Schema %s has no root element %s", root.Name.Space,
+			root.Name.Local)
+	}
+	var violations []string
+	schema.validate(root, decl, "/"+root.Name.Local, &violations)
+	if len(violations) > 0 {
+		return &ValidationError{Namespace: schema.namespace, Violations: violations}
+	}
+	return nil
+}
+
+// A generic XML element, used for both schemas and instance documents
+type xmlNode struct {
+	Name     xml.Name
+	Attrs    []xml.Attr
+	Text     string
+	Children []*xmlNode
+}
+
+// Parse a document into a tree of elements. Comments, processing
+// instructions, and text between child elements are dropped.
+func parseXMLNode(data []byte) (*xmlNode, error) {
+	var (
+		decoder = xml.NewDecoder(bytes.NewReader(data))
+		stack   []*xmlNode
+		root    *xmlNode
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: token.Name, Attrs: token.Copy().Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += string(token)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("No XML element found")
+	}
+	return root, nil
+}
+
+// Look up an attribute by local name.
+func (node *xmlNode) attr(name string) (string, bool) {
+	for _, attr := range node.Attrs {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// Write the element, declaring its namespace as the default if it differs
+// from that of the parent. Attributes are written unqualified, and text is
+// only written for elements without children.
+func (node *xmlNode) write(buf *bytes.Buffer, parentSpace string) {
+	buf.WriteString("<" + node.Name.Local)
+	if node.Name.Space != parentSpace {
+		buf.WriteString(` xmlns="`)
+		xml.EscapeText(buf, []byte(node.Name.Space))
+		buf.WriteString(`"`)
+	}
+	for _, attr := range node.Attrs {
+		buf.WriteString(" " + attr.Name.Local + `="`)
+		xml.EscapeText(buf, []byte(attr.Value))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(">")
+	if len(node.Children) == 0 {
+		xml.EscapeText(buf, []byte(node.Text))
+	}
+	for _, child := range node.Children {
+		child.write(buf, node.Name.Space)
+	}
+	buf.WriteString("</" + node.Name.Local + ">")
+}
+
+// A parsed XML schema
+type xmlSchema struct {
+	namespace string
+	elements  map[string]*schemaElement
+	types     map[string]*schemaType
+}
+
+// An element declaration
+type schemaElement struct {
+	name     string
+	typeName string
+	typ      *schemaType
+}
+
+// A simple or complex type. Complex types have a content model and
+// attributes; simple types restrict a built-in type.
+type schemaType struct {
+	complex      bool
+	content      *schemaParticle
+	attributes   []*schemaAttribute
+	base         string
+	enumeration  []string
+	pattern      *regexp.Regexp
+	maxInclusive *big.Rat
+
+	// The elements of the content model, by name
+	children map[string]*schemaChild
+}
+
+// An attribute declaration
+type schemaAttribute struct {
+	name     string
+	typeName string
+	typ      *schemaType
+	required bool
+}
+
+// An element, sequence, or choice in a content model
+type schemaParticle struct {
+	kind      string
+	element   *schemaElement
+	particles []*schemaParticle
+	min, max  int
+}
+
+// Where an element may appear in its parent's content model
+type schemaChild struct {
+	element *schemaElement
+
+	// Sorts children into the order the model requires. Elements within a
+	// repeated sequence or choice share a key, since their relative order is
+	// up to the author.
+	key []int
+
+	// Whether the element may be left out
+	optional bool
+}
+
+// Load every schema in a file system.
+func loadSchemas(files fs.FS) (map[string]*xmlSchema, error) {
+	paths, err := fs.Glob(files, "gen/*/*/*/*.xsd")
+	if err != nil {
+		return nil, err
+	}
+	schemas := make(map[string]*xmlSchema)
+	for _, path := range paths {
+		data, err := fs.ReadFile(files, path)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := parseSchema(data)
+		if err != nil {
+			return nil, fmt.Errorf("Could not parse schema %s: %v", path, err)
+		}
+		schemas[schema.namespace] = schema
+	}
+	return schemas, nil
+}
+
+// Parse an XML schema. Only the features used by the question schemas are
+// supported, and annotations are ignored.
+func parseSchema(data []byte) (*xmlSchema, error) {
+	root, err := parseXMLNode(data)
+	if err != nil {
+		return nil, err
+	}
+	if root.Name.Space != "http://www.w3.org/2001/XMLSchema" || root.Name.Local != "schema" {
+		return nil, fmt.Errorf("Not an XML schema: <%s>", root.Name.Local)
+	}
+	schema := &xmlSchema{
+		elements: make(map[string]*schemaElement),
+		types:    make(map[string]*schemaType),
+	}
+	schema.namespace, _ = root.attr("targetNamespace")
+
+	var (
+		elements   []*schemaElement
+		attributes []*schemaAttribute
+		complexes  []*schemaType
+	)
+	var parseType func(node *xmlNode) (*schemaType, error)
+	var parseParticle func(node *xmlNode) (*schemaParticle, error)
+	parseElement := func(node *xmlNode) (*schemaElement, error) {
+		element := &schemaElement{}
+		element.name, _ = node.attr("name")
+		element.typeName, _ = node.attr("type")
+		for _, child := range node.Children {
+			if child.Name.Local == "annotation" {
+				continue
+			}
+			typ, err := parseType(child)
+			if err != nil {
+				return nil, err
+			}
+			element.typ = typ
+		}
+		elements = append(elements, element)
+		return element, nil
+	}
+	parseParticle = func(node *xmlNode) (*schemaParticle, error) {
+		particle := &schemaParticle{kind: node.Name.Local, min: 1, max: 1}
+		if value, ok := node.attr("minOccurs"); ok {
+			particle.min, err = strconv.Atoi(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if value, ok := node.attr("maxOccurs"); ok && value == "unbounded" {
+			particle.max = -1
+		} else if ok {
+			particle.max, err = strconv.Atoi(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		switch particle.kind {
+		case "element":
+			element, err := parseElement(node)
+			if err != nil {
+				return nil, err
+			}
+			particle.element = element
+		case "sequence", "choice":
+			for _, child := range node.Children {
+				if child.Name.Local == "annotation" {
+					continue
+				}
+				sub, err := parseParticle(child)
+				if err != nil {
+					return nil, err
+				}
+				particle.particles = append(particle.particles, sub)
+			}
+		default:
+			return nil, fmt.Errorf("Unsupported particle <%s>", particle.kind)
+		}
+		return particle, nil
+	}
+	parseType = func(node *xmlNode) (*schemaType, error) {
+		typ := &schemaType{complex: node.Name.Local == "complexType"}
+		switch node.Name.Local {
+		case "complexType":
+			complexes = append(complexes, typ)
+			for _, child := range node.Children {
+				switch child.Name.Local {
+				case "sequence", "choice":
+					particle, err := parseParticle(child)
+					if err != nil {
+						return nil, err
+					}
+					typ.content = particle
+				case "attribute":
+					attribute := &schemaAttribute{}
+					attribute.name, _ = child.attr("name")
+					attribute.typeName, _ = child.attr("type")
+					use, _ := child.attr("use")
+					attribute.required = use == "required"
+					typ.attributes = append(typ.attributes, attribute)
+					attributes = append(attributes, attribute)
+				case "annotation":
+				default:
+					return nil, fmt.Errorf("Unsupported complex type content <%s>",
+						child.Name.Local)
+				}
+			}
+		case "simpleType":
+			for _, restriction := range node.Children {
+				if restriction.Name.Local == "annotation" {
+					continue
+				} else if restriction.Name.Local != "restriction" {
+					return nil, fmt.Errorf("Unsupported simple type content <%s>",
+						restriction.Name.Local)
+				}
+				base, _ := restriction.attr("base")
+				typ.base = localName(base)
+				for _, facet := range restriction.Children {
+					value, _ := facet.attr("value")
+					switch facet.Name.Local {
+					case "enumeration":
+						typ.enumeration = append(typ.enumeration, value)
+					case "pattern":
+						typ.pattern, err = regexp.Compile("^(?:" + value + ")$")
+						if err != nil {
+							return nil, err
+						}
+					case "maxInclusive":
+						max, ok := new(big.Rat).SetString(value)
+						if !ok {
+							return nil, fmt.Errorf("Invalid maxInclusive %q", value)
+						}
+						typ.maxInclusive = max
+					case "annotation":
+					default:
+						return nil, fmt.Errorf("Unsupported facet <%s>", facet.Name.Local)
+					}
+				}
+			}
+		default:
+			return nil, fmt.Errorf("Unsupported type <%s>", node.Name.Local)
+		}
+		return typ, nil
+	}
+
+	for _, child := range root.Children {
+		var err error
+		switch child.Name.Local {
+		case "element":
+			var element *schemaElement
+			element, err = parseElement(child)
+			if err == nil {
+				schema.elements[element.name] = element
+			}
+		case "complexType", "simpleType":
+			var typ *schemaType
+			typ, err = parseType(child)
+			if err == nil {
+				name, _ := child.attr("name")
+				schema.types[name] = typ
+			}
+		case "annotation":
+		default:
+			err = fmt.Errorf("Unsupported schema component <%s>", child.Name.Local)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Resolve references to named and built-in types
+	for _, element := range elements {
+		if element.typ == nil {
+			if element.typ, err = schema.resolveType(element.typeName); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, attribute := range attributes {
+		if attribute.typ, err = schema.resolveType(attribute.typeName); err != nil {
+			return nil, err
+		}
+	}
+	for _, typ := range complexes {
+		typ.children = make(map[string]*schemaChild)
+		if typ.content != nil {
+			typ.content.index(typ.children, nil, false)
+		}
+	}
+	return schema, nil
+}
+
+// Find a type by qualified name, e.g. "tns:ContentType" or "xs:string".
+func (schema *xmlSchema) resolveType(name string) (*schemaType, error) {
+	if name == "" {
+		return &schemaType{base: "anyType"}, nil
+	} else if strings.HasPrefix(name, "xs:") {
+		return &schemaType{base: localName(name)}, nil
+	} else if typ := schema.types[localName(name)]; typ != nil {
+		return typ, nil
+	}
+	return nil, fmt.Errorf("Unknown type %s", name)
+}
+
+// Strip the prefix from a qualified name.
+func localName(name string) string {
+	return name[strings.LastIndex(name, ":")+1:]
+}
+
+// Record where each element of a content model appears.
+func (particle *schemaParticle) index(children map[string]*schemaChild,
+	key []int, optional bool) {
+
+	optional = optional || particle.min == 0
+	if particle.kind == "element" {
+		children[particle.element.name] = &schemaChild{
+			element:  particle.element,
+			key:      key,
+			optional: optional,
+		}
+		return
+	}
+	optional = optional || (particle.kind == "choice" && len(particle.particles) > 1)
+	for i, sub := range particle.particles {
+		subKey := key
+		if particle.max == 1 {
+			subKey = append(append([]int{}, key...), i)
+		}
+		sub.index(children, subKey, optional)
+	}
+}
+
+// Find every position at which the particle could finish matching a list of
+// element names, starting from a given position.
+func (particle *schemaParticle) match(names []string, start int) map[int]bool {
+	var (
+		ends    = make(map[int]bool)
+		current = map[int]bool{start: true}
+	)
+	for count := 0; len(current) > 0; count++ {
+		if count >= particle.min {
+			for pos := range current {
+				ends[pos] = true
+			}
+		}
+		if particle.max >= 0 && count >= particle.max {
+			break
+		}
+		next := make(map[int]bool)
+		for pos := range current {
+			for end := range particle.matchOnce(names, pos) {
+				// Positions reached once the minimum is met have already
+				// been, or are being, expanded
+				if count+1 < particle.min || !ends[end] {
+					next[end] = true
+				}
+			}
+		}
+		current = next
+	}
+	return ends
+}
+
+// Match a single occurrence of the particle.
+func (particle *schemaParticle) matchOnce(names []string, start int) map[int]bool {
+	ends := make(map[int]bool)
+	switch particle.kind {
+	case "element":
+		if start < len(names) && names[start] == particle.element.name {
+			ends[start+1] = true
+		}
+	case "sequence":
+		ends[start] = true
+		for _, sub := range particle.particles {
+			next := make(map[int]bool)
+			for pos := range ends {
+				for end := range sub.match(names, pos) {
+					next[end] = true
+				}
+			}
+			ends = next
+		}
+	case "choice":
+		for _, sub := range particle.particles {
+			for end := range sub.match(names, start) {
+				ends[end] = true
+			}
+		}
+	}
+	return ends
+}
+
+// Describe a content model, e.g. "(QuestionIdentifier, DisplayName?)".
+func (particle *schemaParticle) String() string {
+	var desc string
+	switch particle.kind {
+	case "element":
+		desc = particle.element.name
+	default:
+		var parts []string
+		for _, sub := range particle.particles {
+			parts = append(parts, sub.String())
+		}
+		sep := ", "
+		if particle.kind == "choice" {
+			sep = " | "
+		}
+		desc = "(" + strings.Join(parts, sep) + ")"
+	}
+	switch {
+	case particle.min == 0 && particle.max == 1:
+		desc += "?"
+	case particle.min == 0 && particle.max < 0:
+		desc += "*"
+	case particle.min == 1 && particle.max < 0:
+		desc += "+"
+	}
+	return desc
+}
+
+// Check an element and its descendants against a declaration.
+func (schema *xmlSchema) validate(node *xmlNode, decl *schemaElement,
+	path string, violations *[]string) {
+
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, path+": "+fmt.Sprintf(format, args...))
+	}
+	typ := decl.typ
+
+	// Check attributes
+	for _, attr := range node.Attrs {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			continue
+		}
+		var found *schemaAttribute
+		for _, attribute := range typ.attributes {
+			if attr.Name.Space == "" && attribute.name == attr.Name.Local {
+				found = attribute
+			}
+		}
+		if found == nil {
+			report("unexpected attribute %s", attr.Name.Local)
+		} else if err := found.typ.check(attr.Value); err != nil {
+			report("attribute %s %v", attr.Name.Local, err)
+		}
+	}
+	for _, attribute := range typ.attributes {
+		if _, ok := node.attr(attribute.name); attribute.required && !ok {
+			report("missing attribute %s", attribute.name)
+		}
+	}
+
+	// Check simple content
+	if !typ.complex {
+		if len(node.Children) > 0 {
+			report("unexpected element <%s>", node.Children[0].Name.Local)
+		} else if err := typ.check(node.Text); err != nil {
+			report("%v", err)
+		}
+		return
+	}
+	if strings.TrimSpace(node.Text) != "" {
+		report("unexpected text %q", strings.TrimSpace(node.Text))
+	}
+
+	// Check the children against the content model
+	var (
+		names   []string
+		known   = true
+		counts  = make(map[string]int)
+		indexes = make([]int, len(node.Children))
+	)
+	for i, child := range node.Children {
+		names = append(names, child.Name.Local)
+		counts[child.Name.Local]++
+		indexes[i] = counts[child.Name.Local]
+		if typ.children[child.Name.Local] == nil || child.Name.Space != schema.namespace {
+			report("unexpected element <%s>", child.Name.Local)
+			known = false
+		}
+	}
+	if known {
+		if typ.content == nil && len(names) > 0 {
+			report("unexpected element <%s>", names[0])
+		} else if typ.content != nil && !typ.content.match(names, 0)[len(names)] {
+			report("found (%s), expected %s", strings.Join(names, ", "),
+				typ.content)
+		}
+	}
+	for i, child := range node.Children {
+		if info := typ.children[child.Name.Local]; info != nil {
+			childPath := path + "/" + child.Name.Local
+			if counts[child.Name.Local] > 1 {
+				childPath += fmt.Sprintf("[%d]", indexes[i])
+			}
+			schema.validate(child, info.element, childPath, violations)
+		}
+	}
+}
+
+// Check a value against a simple type.
+func (typ *schemaType) check(value string) error {
+	if typ.complex {
+		return fmt.Errorf("has complex type")
+	}
+	if typ.base != "string" && typ.base != "anyType" {
+		value = strings.TrimSpace(value)
+	}
+	invalid := fmt.Errorf("%q is not a valid %s", value, typ.base)
+	switch typ.base {
+	case "boolean":
+		if value != "true" && value != "false" && value != "1" && value != "0" {
+			return invalid
+		}
+	case "double":
+		if _, err := strconv.ParseFloat(value, 64); err != nil && value != "INF" &&
+			value != "-INF" && value != "NaN" {
+			return invalid
+		}
+	case "int", "integer", "positiveInteger", "nonNegativeInteger":
+		number, ok := new(big.Int).SetString(strings.TrimPrefix(value, "+"), 10)
+		if !ok {
+			return invalid
+		}
+		switch {
+		case typ.base == "int" && !number.IsInt64(),
+			typ.base == "int" && (number.Int64() < -1<<31 || number.Int64() >= 1<<31),
+			typ.base == "positiveInteger" && number.Sign() <= 0,
+			typ.base == "nonNegativeInteger" && number.Sign() < 0:
+			return invalid
+		}
+		if typ.maxInclusive != nil && new(big.Rat).SetInt(number).Cmp(typ.maxInclusive) > 0 {
+			return fmt.Errorf("%s is greater than %s", value,
+				typ.maxInclusive.RatString())
+		}
+	}
+	if len(typ.enumeration) > 0 {
+		allowed := false
+		for _, option := range typ.enumeration {
+			allowed = allowed || option == value
+		}
+		if !allowed {
+			return fmt.Errorf("%q is not one of %s", value,
+				strings.Join(typ.enumeration, ", "))
+		}
+	}
+	if typ.pattern != nil && !typ.pattern.MatchString(value) {
+		return fmt.Errorf("%q does not match the pattern %s", value, typ.pattern)
+	}
+	return nil
+}
+
+// Prepare an element and its descendants for encoding: drop namespace
+// declarations and empty attributes, drop optional elements which hold
+// nothing but the zero value of their type unless their paths are kept, and
+// sort children into the order required by the schema. Children whose
+// relative order the schema leaves open keep their order.
+func (schema *xmlSchema) normalize(node *xmlNode, decl *schemaElement,
+	path string, kept map[string]bool) {
+	typ := decl.typ
+	var attrs []xml.Attr
+	for _, attr := range node.Attrs {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" || attr.Value == "" {
+			continue
+		}
+		attr.Name.Space = ""
+		attrs = append(attrs, attr)
+	}
+	node.Attrs = attrs
+	if !typ.complex || typ.children == nil {
+		return
+	}
+	node.Text = ""
+
+	var children []*xmlNode
+	paths := childPaths(node, path)
+	for i, child := range node.Children {
+		info := typ.children[child.Name.Local]
+		if info == nil {
+			children = append(children, child)
+			continue
+		}
+		schema.normalize(child, info.element, paths[i], kept)
+		if info.optional && len(child.Children) == 0 && len(child.Attrs) == 0 &&
+			isZeroValue(child.Text, info.element.typ) && !kept[paths[i]] {
+			continue
+		}
+		children = append(children, child)
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		return compareKeys(typ.childKey(children[i]), typ.childKey(children[j])) < 0
+	})
+	node.Children = children
+}
+
+// The paths of an element's children, given its own. A path names each
+// element and its position among its siblings of that name, e.g.
+// "/QuestionForm/Question[2]/AnswerSpecification[1]", so that it is the same
+// however the siblings are sorted.
+func childPaths(node *xmlNode, path string) []string {
+	var (
+		paths = make([]string, len(node.Children))
+		seen  = make(map[string]int)
+	)
+	for i, child := range node.Children {
+		seen[child.Name.Local]++
+		paths[i] = fmt.Sprintf("%s/%s[%d]", path, child.Name.Local,
+			seen[child.Name.Local])
+	}
+	return paths
+}
+
+// Add to a set the paths of an element's descendants which hold nothing but
+// the zero value of their type, and so would be dropped by normalize. The
+// set is created if needed, and returned.
+func (schema *xmlSchema) zeroPaths(node *xmlNode, decl *schemaElement,
+	path string, paths map[string]bool) map[string]bool {
+	typ := decl.typ
+	if !typ.complex || typ.children == nil {
+		return paths
+	}
+	for i, childPath := range childPaths(node, path) {
+		child := node.Children[i]
+		info := typ.children[child.Name.Local]
+		if info == nil {
+			continue
+		}
+		if len(child.Children) == 0 && len(child.Attrs) == 0 &&
+			isZeroValue(strings.TrimSpace(child.Text), info.element.typ) {
+			if paths == nil {
+				paths = make(map[string]bool)
+			}
+			paths[childPath] = true
+		}
+		paths = schema.zeroPaths(child, info.element, childPath, paths)
+	}
+	return paths
+}
+
+// The sort key of a child element. Unknown elements sort last.
+func (typ *schemaType) childKey(child *xmlNode) []int {
+	if info := typ.children[child.Name.Local]; info != nil {
+		return info.key
+	}
+	return []int{len(typ.children)}
+}
+
+// Compare sort keys lexicographically.
+func compareKeys(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return len(a) - len(b)
+}
+
+// Whether an element's text is what encoding/xml writes for an unset field:
+// the zero value of the Go type used for its schema type. Elements of complex
+// type are held by pointers, which encoding/xml omits when unset, so an empty
+// one was set on purpose, e.g. a FreeTextAnswer without constraints.
+func isZeroValue(text string, typ *schemaType) bool {
+	switch {
+	case typ.complex:
+		return false
+	case text == "":
+		return true
+	case typ.base == "boolean":
+		return text == "false"
+	case typ.base == "string" || typ.base == "anyURI" || typ.base == "anyType":
+		return false
+	}
+	return text == "0"
+}
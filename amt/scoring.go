@@ -0,0 +1,124 @@
+package amt
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// QualificationScore is the result of grading a qualification test's
+// answers against its AnswerKey, as AMT would when the test is submitted.
+type QualificationScore struct {
+
+	// The score for each question in the answer key
+	QuestionScores map[string]int
+
+	// The sum of the question scores
+	SummedScore int
+
+	// The qualification value granted: the summed score, as mapped by the
+	// key's QualificationValueMapping if it has one
+	Value int
+}
+
+// ScoreAnswers grades a worker's answers against an answer key. Each question
+// in the key scores the AnswerScore of the first AnswerOption whose
+// SelectionIdentifiers are exactly those the worker selected, in any order,
+// or the question's DefaultScore if none match or it was not answered. Free
+// text answers never match. The summed score is then mapped to a
+// qualification value:
+//
+//   - PercentageMapping: the summed score as a percentage of
+//     MaximumSummedScore, rounded to the nearest integer
+//   - ScaleMapping: the summed score times SummedScoreMultiplier, rounded to
+//     the nearest integer
+//   - RangeMapping: the QualificationValue of the first SummedScoreRange
+//     containing the summed score, or OutOfRangeQualificationValue
+//
+// Without a mapping, the value is the summed score.
+func ScoreAnswers(key AnswerKey, answers QuestionFormAnswers) (QualificationScore, error) {
+	selections := make(map[string][]string)
+	for _, answer := range answers.Answers {
+		if answer == nil {
+			continue
+		}
+		var selected []string
+		for _, id := range answer.SelectionIdentifiers {
+			selected = append(selected, string(id))
+		}
+		selections[string(answer.QuestionIdentifier)] = selected
+	}
+
+	score := QualificationScore{QuestionScores: make(map[string]int)}
+	for _, question := range key.Questions {
+		if question == nil {
+			continue
+		}
+		id := string(question.QuestionIdentifier)
+		if _, ok := score.QuestionScores[id]; ok {
+			return QualificationScore{}, fmt.Errorf(
+				"The answer key has more than one entry for question %s", id)
+		}
+		questionScore := int(question.DefaultScore)
+		selected, answered := selections[id]
+		for _, option := range question.AnswerOptions {
+			if option == nil || !answered {
+				continue
+			}
+			var expected []string
+			for _, id := range option.SelectionIdentifiers {
+				expected = append(expected, string(id))
+			}
+			if sameSelections(selected, expected) {
+				questionScore = int(option.AnswerScore)
+				break
+			}
+		}
+		score.QuestionScores[id] = questionScore
+		score.SummedScore += questionScore
+	}
+
+	score.Value = score.SummedScore
+	mapping := key.QualificationValueMapping
+	switch {
+	case mapping == nil:
+	case mapping.PercentageMapping != nil:
+		max := int(mapping.PercentageMapping.MaximumSummedScore)
+		if max <= 0 {
+			return QualificationScore{}, fmt.Errorf(
+				"MaximumSummedScore must be positive, not %d", max)
+		}
+		score.Value = int(math.Floor(float64(score.SummedScore)*100/float64(max) + 0.5))
+	case mapping.ScaleMapping != nil:
+		multiplier := float64(mapping.ScaleMapping.SummedScoreMultiplier)
+		score.Value = int(math.Floor(float64(score.SummedScore)*multiplier + 0.5))
+	case mapping.RangeMapping != nil:
+		score.Value = int(mapping.RangeMapping.OutOfRangeQualificationValue)
+		for _, scoreRange := range mapping.RangeMapping.SummedScoreRanges {
+			if scoreRange != nil &&
+				score.SummedScore >= int(scoreRange.InclusiveLowerBound) &&
+				score.SummedScore <= int(scoreRange.InclusiveUpperBound) {
+				score.Value = int(scoreRange.QualificationValue)
+				break
+			}
+		}
+	}
+	return score, nil
+}
+
+// Whether two lists hold the same selections, ignoring order.
+func sameSelections(selected, expected []string) bool {
+	if len(selected) != len(expected) || len(selected) == 0 {
+		return false
+	}
+	a := append([]string{}, selected...)
+	b := append([]string{}, expected...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
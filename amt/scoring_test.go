@@ -0,0 +1,174 @@
+package amt
+
+import (
+	"encoding/xml"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+// Build an answer key with the given QualificationValueMapping XML.
+func scoringKey(mapping string) AnswerKey {
+	var key AnswerKey
+	err := xml.Unmarshal([]byte(`<AnswerKey xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/AnswerKey.xsd">
+  <Question>
+    <QuestionIdentifier>nextmove</QuestionIdentifier>
+    <AnswerOption>
+      <SelectionIdentifier>D</SelectionIdentifier>
+      <AnswerScore>5</AnswerScore>
+    </AnswerOption>
+  </Question>
+  <Question>
+    <QuestionIdentifier>fruits</QuestionIdentifier>
+    <AnswerOption>
+      <SelectionIdentifier>apples</SelectionIdentifier>
+      <SelectionIdentifier>pears</SelectionIdentifier>
+      <AnswerScore>10</AnswerScore>
+    </AnswerOption>
+    <AnswerOption>
+      <SelectionIdentifier>apples</SelectionIdentifier>
+      <AnswerScore>4</AnswerScore>
+    </AnswerOption>
+    <DefaultScore>-1</DefaultScore>
+  </Question>`+mapping+`
+</AnswerKey>`), &key)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// Build answers selecting the given options for each question.
+func scoringAnswers(selections map[string][]string) QuestionFormAnswers {
+	answerXml := `<QuestionFormAnswers xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/QuestionFormAnswers.xsd">`
+	for id, selected := range selections {
+		answerXml += `<Answer><QuestionIdentifier>` + id + `</QuestionIdentifier>`
+		for _, selection := range selected {
+			answerXml += `<SelectionIdentifier>` + selection + `</SelectionIdentifier>`
+		}
+		answerXml += `</Answer>`
+	}
+	var answers QuestionFormAnswers
+	if err := xml.Unmarshal([]byte(answerXml+`</QuestionFormAnswers>`), &answers); err != nil {
+		panic(err)
+	}
+	return answers
+}
+
+func TestScoreAnswers(t *testing.T) {
+	Convey("Given an answer key without a value mapping", t, func() {
+		key := scoringKey("")
+
+		Convey("When every answer is right", func() {
+			score, err := ScoreAnswers(key, scoringAnswers(map[string][]string{
+				"nextmove": {"D"},
+				"fruits":   {"pears", "apples"},
+			}))
+
+			Convey("Then the value is the summed score", func() {
+				So(err, ShouldBeNil)
+				So(score.QuestionScores, ShouldResemble, map[string]int{"nextmove": 5, "fruits": 10})
+				So(score.SummedScore, ShouldEqual, 15)
+				So(score.Value, ShouldEqual, 15)
+			})
+		})
+
+		Convey("When a selection matches an option only in part", func() {
+			score, err := ScoreAnswers(key, scoringAnswers(map[string][]string{
+				"nextmove": {"D", "E"},
+				"fruits":   {"apples"},
+			}))
+
+			Convey("Then only exact matches score", func() {
+				So(err, ShouldBeNil)
+				So(score.QuestionScores, ShouldResemble, map[string]int{"nextmove": 0, "fruits": 4})
+			})
+		})
+
+		Convey("When a question is not answered", func() {
+			score, err := ScoreAnswers(key, scoringAnswers(map[string][]string{
+				"nextmove": {"D"},
+			}))
+
+			Convey("Then it gets its default score", func() {
+				So(err, ShouldBeNil)
+				So(score.QuestionScores["fruits"], ShouldEqual, -1)
+				So(score.Value, ShouldEqual, 4)
+			})
+		})
+	})
+
+	Convey("Given an answer key with a percentage mapping", t, func() {
+		key := scoringKey(`<QualificationValueMapping><PercentageMapping>
+			<MaximumSummedScore>15</MaximumSummedScore>
+			</PercentageMapping></QualificationValueMapping>`)
+
+		Convey("When I score answers", func() {
+			score, err := ScoreAnswers(key, scoringAnswers(map[string][]string{
+				"nextmove": {"D"},
+				"fruits":   {"apples"},
+			}))
+
+			Convey("Then the value is a rounded percentage", func() {
+				So(err, ShouldBeNil)
+				So(score.SummedScore, ShouldEqual, 9)
+				So(score.Value, ShouldEqual, 60)
+			})
+		})
+
+		Convey("When the maximum is not positive", func() {
+			key.QualificationValueMapping.PercentageMapping.MaximumSummedScore = 0
+			_, err := ScoreAnswers(key, scoringAnswers(nil))
+
+			Convey("Then scoring fails", func() {
+				So(err.Error(), ShouldEqual, "MaximumSummedScore must be positive, not 0")
+			})
+		})
+	})
+
+	Convey("Given an answer key with a scale mapping", t, func() {
+		key := scoringKey(`<QualificationValueMapping><ScaleMapping>
+			<SummedScoreMultiplier>2.5</SummedScoreMultiplier>
+			</ScaleMapping></QualificationValueMapping>`)
+
+		Convey("When I score answers", func() {
+			score, err := ScoreAnswers(key, scoringAnswers(map[string][]string{
+				"nextmove": {"D"},
+			}))
+
+			Convey("Then the value is the scaled score", func() {
+				So(err, ShouldBeNil)
+				So(score.Value, ShouldEqual, 10)
+			})
+		})
+	})
+
+	Convey("Given an answer key with a range mapping", t, func() {
+		key := scoringKey(`<QualificationValueMapping><RangeMapping>
+			<SummedScoreRange><InclusiveLowerBound>0</InclusiveLowerBound><InclusiveUpperBound>5</InclusiveUpperBound><QualificationValue>1</QualificationValue></SummedScoreRange>
+			<SummedScoreRange><InclusiveLowerBound>6</InclusiveLowerBound><InclusiveUpperBound>15</InclusiveUpperBound><QualificationValue>2</QualificationValue></SummedScoreRange>
+			<OutOfRangeQualificationValue>99</OutOfRangeQualificationValue>
+			</RangeMapping></QualificationValueMapping>`)
+
+		Convey("When the score falls in a range", func() {
+			score, err := ScoreAnswers(key, scoringAnswers(map[string][]string{
+				"nextmove": {"D"},
+				"fruits":   {"apples", "pears"},
+			}))
+
+			Convey("Then the value is that range's", func() {
+				So(err, ShouldBeNil)
+				So(score.Value, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the score falls outside every range", func() {
+			score, err := ScoreAnswers(key, scoringAnswers(nil))
+
+			Convey("Then the value is the out of range value", func() {
+				So(err, ShouldBeNil)
+				So(score.SummedScore, ShouldEqual, -1)
+				So(score.Value, ShouldEqual, 99)
+			})
+		})
+	})
+}
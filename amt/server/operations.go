@@ -0,0 +1,488 @@
+package server
+
+import (
+	"fmt"
+	"github.com/jesand/crowds/amt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// An operation decodes its arguments from a request and carries it out,
+// returning a pointer to the response struct.
+type operation func(backend amt.AmtClient, form url.Values) (interface{}, error)
+
+// The supported operations, by name
+var operations = map[string]operation{
+	"ApproveAssignment": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TApproveAssignmentRequest
+		unpackArgs(form, &args)
+		resp, err := backend.ApproveAssignment(string(args.AssignmentId),
+			string(args.RequesterFeedback))
+		return &resp, err
+	},
+	"ApproveRejectedAssignment": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TApproveRejectedAssignmentRequest
+		unpackArgs(form, &args)
+		resp, err := backend.ApproveRejectedAssignment(
+			string(args.AssignmentId), string(args.RequesterFeedback))
+		return &resp, err
+	},
+	"AssignQualification": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TAssignQualificationRequest
+		unpackArgs(form, &args)
+		resp, err := backend.AssignQualification(
+			string(args.QualificationTypeId), string(args.WorkerId),
+			int(args.IntegerValue), bool(args.SendNotification))
+		return &resp, err
+	},
+	"BlockWorker": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TBlockWorkerRequest
+		unpackArgs(form, &args)
+		resp, err := backend.BlockWorker(string(args.WorkerId),
+			string(args.Reason))
+		return &resp, err
+	},
+	"ChangeHITTypeOfHIT": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TChangeHITTypeOfHITRequest
+		unpackArgs(form, &args)
+		resp, err := backend.ChangeHITTypeOfHIT(string(args.HITId),
+			string(args.HITTypeId))
+		return &resp, err
+	},
+	"CreateHIT": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TCreateHITRequest
+		unpackArgs(form, &args)
+		resp, err := backend.CreateHITFromArgs(args)
+		return &resp, err
+	},
+	"CreateQualificationType": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TCreateQualificationTypeRequest
+		unpackArgs(form, &args)
+		resp, err := backend.CreateQualificationType(string(args.Name),
+			string(args.Description), splitList(string(args.Keywords)),
+			int(args.RetryDelayInSeconds), string(args.QualificationTypeStatus),
+			string(args.Test), string(args.AnswerKey),
+			int(args.TestDurationInSeconds), bool(args.AutoGranted),
+			int(args.AutoGrantedValue))
+		return &resp, err
+	},
+	"DisableHIT": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TDisableHITRequest
+		unpackArgs(form, &args)
+		resp, err := backend.DisableHIT(string(args.HITId))
+		return &resp, err
+	},
+	"DisposeHIT": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TDisposeHITRequest
+		unpackArgs(form, &args)
+		resp, err := backend.DisposeHIT(string(args.HITId))
+		return &resp, err
+	},
+	"DisposeQualificationType": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TDisposeQualificationTypeRequest
+		unpackArgs(form, &args)
+		resp, err := backend.DisposeQualificationType(
+			string(args.QualificationTypeId))
+		return &resp, err
+	},
+	"ExtendHIT": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TExtendHITRequest
+		unpackArgs(form, &args)
+		resp, err := backend.ExtendHIT(string(args.HITId),
+			int(args.MaxAssignmentsIncrement),
+			int(args.ExpirationIncrementInSeconds),
+			string(args.UniqueRequestToken))
+		return &resp, err
+	},
+	"ForceExpireHIT": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TForceExpireHITRequest
+		unpackArgs(form, &args)
+		resp, err := backend.ForceExpireHIT(string(args.HITId))
+		return &resp, err
+	},
+	"GetAccountBalance": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		resp, err := backend.GetAccountBalance()
+		return &resp, err
+	},
+	"GetAssignment": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGetAssignmentRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GetAssignment(string(args.AssignmentId))
+		return &resp, err
+	},
+	"GetAssignmentsForHIT": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var (
+			args     amtgen.TGetAssignmentsForHITRequest
+			statuses []string
+		)
+		unpackArgs(form, &args)
+		for _, status := range args.AssignmentStatuses {
+			statuses = append(statuses, string(status))
+		}
+		resp, err := backend.GetAssignmentsForHIT(string(args.HITId), statuses,
+			string(args.SortProperty), ascending(string(args.SortDirection)),
+			int(args.PageSize), int(args.PageNumber))
+		return &resp, err
+	},
+	"GetBlockedWorkers": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGetBlockedWorkersRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GetBlockedWorkers(int(args.PageSize),
+			int(args.PageNumber))
+		return &resp, err
+	},
+	"GetBonusPayments": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGetBonusPaymentsRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GetBonusPayments(string(args.HITId),
+			string(args.AssignmentId), int(args.PageSize),
+			int(args.PageNumber))
+		return &resp, err
+	},
+	"GetFileUploadURL": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGetFileUploadURLRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GetFileUploadURL(string(args.AssignmentId),
+			string(args.QuestionIdentifier))
+		return &resp, err
+	},
+	"GetHIT": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGetHITRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GetHIT(string(args.HITId))
+		return &resp, err
+	},
+	"GetHITsForQualificationType": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGetHITsForQualificationTypeRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GetHITsForQualificationType(
+			string(args.QualificationTypeId), int(args.PageSize),
+			int(args.PageNumber))
+		return &resp, err
+	},
+	"GetQualificationRequests": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGetQualificationRequestsRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GetQualificationRequests(
+			string(args.QualificationTypeId), string(args.SortProperty),
+			ascending(string(args.SortDirection)), int(args.PageSize),
+			int(args.PageNumber))
+		return &resp, err
+	},
+	"GetQualificationScore": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGetQualificationScoreRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GetQualificationScore(
+			string(args.QualificationTypeId), string(args.SubjectId))
+		return &resp, err
+	},
+	"GetQualificationsForQualificationType": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGetQualificationsForQualificationTypeRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GetQualificationsForQualificationType(
+			string(args.QualificationTypeId), args.Status != "Revoked",
+			int(args.PageSize), int(args.PageNumber))
+		return &resp, err
+	},
+	"GetQualificationType": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGetQualificationTypeRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GetQualificationType(
+			string(args.QualificationTypeId))
+		return &resp, err
+	},
+	"GetRequesterStatistic": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGetRequesterStatisticRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GetRequesterStatistic(string(args.Statistic),
+			string(args.TimePeriod), int(args.Count))
+		return &resp, err
+	},
+	"GetRequesterWorkerStatistic": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGetRequesterWorkerStatisticRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GetRequesterWorkerStatistic(
+			string(args.Statistic), string(args.WorkerId),
+			string(args.TimePeriod), int(args.Count))
+		return &resp, err
+	},
+	"GetReviewableHITs": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGetReviewableHITsRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GetReviewableHITs(string(args.HITTypeId),
+			string(args.Status), string(args.SortProperty),
+			ascending(string(args.SortDirection)), int(args.PageSize),
+			int(args.PageNumber))
+		return &resp, err
+	},
+	"GetReviewResultsForHIT": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var (
+			args   amtgen.TGetReviewResultsForHITRequest
+			levels []string
+		)
+		unpackArgs(form, &args)
+		for _, level := range args.PolicyLevels {
+			levels = append(levels, string(level))
+		}
+		resp, err := backend.GetReviewResultsForHIT(string(args.HITId), levels,
+			bool(args.RetrieveActions), bool(args.RetrieveResults),
+			int(args.PageSize), int(args.PageNumber))
+		return &resp, err
+	},
+	"GrantBonus": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var (
+			args   amtgen.TGrantBonusRequest
+			amount float64
+		)
+		unpackArgs(form, &args)
+		if args.BonusAmount != nil {
+			amount, _ = strconv.ParseFloat(string(args.BonusAmount.Amount), 32)
+		}
+		resp, err := backend.GrantBonus(string(args.WorkerId),
+			string(args.AssignmentId), float32(amount), string(args.Reason),
+			string(args.UniqueRequestToken))
+		return &resp, err
+	},
+	"GrantQualification": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TGrantQualificationRequest
+		unpackArgs(form, &args)
+		resp, err := backend.GrantQualification(
+			string(args.QualificationRequestId), int(args.IntegerValue))
+		return &resp, err
+	},
+	"NotifyWorkers": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var (
+			args      amtgen.TNotifyWorkersRequest
+			workerIds []string
+		)
+		unpackArgs(form, &args)
+		for _, workerId := range args.WorkerIds {
+			workerIds = append(workerIds, string(workerId))
+		}
+		resp, err := backend.NotifyWorkers(string(args.Subject),
+			string(args.MessageText), workerIds)
+		return &resp, err
+	},
+	"RegisterHITType": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TRegisterHITTypeRequest
+		unpackArgs(form, &args)
+		resp, err := backend.RegisterHITTypeFromArgs(args)
+		return &resp, err
+	},
+	"RejectAssignment": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TRejectAssignmentRequest
+		unpackArgs(form, &args)
+		resp, err := backend.RejectAssignment(string(args.AssignmentId),
+			string(args.RequesterFeedback))
+		return &resp, err
+	},
+	"RejectQualificationRequest": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TRejectQualificationRequestRequest
+		unpackArgs(form, &args)
+		resp, err := backend.RejectQualificationRequest(
+			string(args.QualificationRequestId), string(args.Reason))
+		return &resp, err
+	},
+	"RevokeQualification": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TRevokeQualificationRequest
+		unpackArgs(form, &args)
+		resp, err := backend.RevokeQualification(string(args.SubjectId),
+			string(args.QualificationTypeId), string(args.Reason))
+		return &resp, err
+	},
+	"SearchHITs": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TSearchHITsRequest
+		unpackArgs(form, &args)
+		resp, err := backend.SearchHITs(string(args.SortProperty),
+			ascending(string(args.SortDirection)), int(args.PageSize),
+			int(args.PageNumber))
+		return &resp, err
+	},
+	"SearchQualificationTypes": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TSearchQualificationTypesRequest
+		unpackArgs(form, &args)
+		resp, err := backend.SearchQualificationTypes(string(args.Query),
+			string(args.SortProperty), ascending(string(args.SortDirection)),
+			int(args.PageSize), int(args.PageNumber),
+			bool(args.MustBeRequestable), bool(args.MustBeOwnedByCaller))
+		return &resp, err
+	},
+	"SendTestEventNotification": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TSendTestEventNotificationRequest
+		unpackArgs(form, &args)
+		resp, err := backend.SendTestEventNotification(args.Notification,
+			string(args.TestEventType))
+		return &resp, err
+	},
+	"SetHITAsReviewing": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TSetHITAsReviewingRequest
+		unpackArgs(form, &args)
+		resp, err := backend.SetHITAsReviewing(string(args.HITId),
+			bool(args.Revert))
+		return &resp, err
+	},
+	"SetHITTypeNotification": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TSetHITTypeNotificationRequest
+		unpackArgs(form, &args)
+		resp, err := backend.SetHITTypeNotification(string(args.HITTypeId),
+			args.Notification, bool(args.Active))
+		return &resp, err
+	},
+	"UnblockWorker": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TUnblockWorkerRequest
+		unpackArgs(form, &args)
+		resp, err := backend.UnblockWorker(string(args.WorkerId),
+			string(args.Reason))
+		return &resp, err
+	},
+	"UpdateQualificationScore": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TUpdateQualificationScoreRequest
+		unpackArgs(form, &args)
+		resp, err := backend.UpdateQualificationScore(
+			string(args.QualificationTypeId), string(args.SubjectId),
+			int(args.IntegerValue))
+		return &resp, err
+	},
+	"UpdateQualificationType": func(backend amt.AmtClient, form url.Values) (interface{}, error) {
+		var args amtgen.TUpdateQualificationTypeRequest
+		unpackArgs(form, &args)
+		resp, err := backend.UpdateQualificationType(
+			string(args.QualificationTypeId), int(args.RetryDelayInSeconds),
+			string(args.QualificationTypeStatus), string(args.Description),
+			string(args.Test), string(args.AnswerKey),
+			int(args.TestDurationInSeconds), bool(args.AutoGranted),
+			int(args.AutoGrantedValue))
+		return &resp, err
+	},
+}
+
+// Decode request arguments from a form into an amtgen request struct. This
+// reverses the flattening the client performs with packField: nested structs
+// become "Name.1.Field" parameters, slices of structs are numbered from 1,
+// and slices of simple values are joined with commas under a singular name.
+func unpackArgs(form url.Values, args interface{}) {
+	v := reflect.ValueOf(args).Elem()
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		if t.Field(i).Type.Kind() != reflect.Struct {
+			continue
+		}
+		name := t.FieldByIndex([]int{i, 0}).Name
+		unpackField(form, name, v.FieldByIndex([]int{i, 0}), false)
+	}
+}
+
+// Decode the parameter(s) with the given name into a value, returning true if
+// any were present.
+func unpackField(form url.Values, n string, v reflect.Value,
+	justIndexed bool) bool {
+
+	t := v.Type()
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(t.Elem())
+		var found bool
+		if !justIndexed {
+			found = unpackField(form, n+".1", elem.Elem(), true)
+		} else {
+			found = unpackField(form, n, elem.Elem(), false)
+		}
+		if found {
+			v.Set(elem)
+		}
+		return found
+
+	case reflect.Slice:
+		st := t.Elem()
+		if st.Kind() == reflect.Ptr {
+			st = st.Elem()
+		}
+		if st.Kind() == reflect.Struct {
+			for i := 1; ; i++ {
+				item := reflect.New(t.Elem()).Elem()
+				if !unpackField(form, fmt.Sprintf("%s.%d", n, i), item, true) {
+					break
+				}
+				v.Set(reflect.Append(v, item))
+			}
+		} else {
+			if strings.HasSuffix(n, "ses") {
+				n = n[:len(n)-2]
+			} else if strings.HasSuffix(n, "s") {
+				n = n[:len(n)-1]
+			}
+			for _, value := range splitList(form.Get(n)) {
+				item := reflect.New(t.Elem()).Elem()
+				setValue(item, value)
+				v.Set(reflect.Append(v, item))
+			}
+		}
+		return v.Len() > 0
+
+	case reflect.Struct:
+		var found bool
+		for i := 0; i < v.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			} else if f.Anonymous {
+				found = unpackField(form, n, v.Field(i), false) || found
+			} else {
+				found = unpackField(form, n+"."+f.Name, v.Field(i), true) || found
+			}
+		}
+		return found
+
+	default:
+		values, ok := form[n]
+		if !ok || len(values) == 0 {
+			return false
+		}
+		setValue(v, values[0])
+		return true
+	}
+}
+
+// Set a simple value from its string form.
+func setValue(v reflect.Value, s string) {
+	if setter, ok := v.Addr().Interface().(interface {
+		Set(string)
+	}); ok {
+		setter.Set(s)
+		return
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, _ := strconv.ParseBool(s)
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, _ := strconv.ParseInt(s, 10, 64)
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, _ := strconv.ParseUint(s, 10, 64)
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, _ := strconv.ParseFloat(s, 64)
+		v.SetFloat(f)
+	}
+}
+
+// Split a comma-separated list, ignoring empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func ascending(sortDirection string) bool {
+	return sortDirection != "Descending"
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"flag"
+	"github.com/jesand/crowds/amt"
+	"github.com/jesand/crowds/amt/sim"
+	. "github.com/smartystreets/goconvey/convey"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	// Rewrite the fixtures replayed by the amt package's TestReplaySession
+	record = flag.Bool("record", false, "record ../testdata/session.json")
+)
+
+// Run a short session against a client: create a HIT, let the simulated
+// worker answer it, approve the answer, and check the balance. The amt
+// package replays the same calls.
+func runSession(client amt.AmtClient, backend *sim.Simulator) error {
+	resp, err := client.CreateHIT("Title", "Description", QUESTION, "", nil,
+		0.25, 60, 3600, 1, 600, []string{"a", "b"}, nil, nil, nil, "", "")
+	if err != nil {
+		return err
+	}
+	hitId := string(resp.Hits[0].HITId)
+	backend.Work()
+	assignments, err := client.GetAssignmentsForHIT(hitId,
+		[]string{"Submitted"}, "SubmitTime", true, 10, 1)
+	if err != nil {
+		return err
+	}
+	assignmentId := string(
+		assignments.GetAssignmentsForHITResults[0].Assignments[0].AssignmentId)
+	if _, err := client.ApproveAssignment(assignmentId, ""); err != nil {
+		return err
+	}
+	_, err = client.GetAccountBalance()
+	return err
+}
+
+func TestRecord(t *testing.T) {
+	Convey("Given a client recording its traffic with a stand-in server", t, func() {
+		backend := sim.New(10)
+		backend.AddWorker("W1", sim.FixedAnswers(map[string]string{"q": "yes"}))
+		srv := NewTestServer(backend, ACCESS_KEY, SECRET_KEY)
+		defer srv.Close()
+		recorder := amt.NewRecorder()
+		client := amt.NewClientWithURL(ACCESS_KEY, SECRET_KEY, srv.URL,
+			amt.WithMiddleware(recorder.Middleware))
+
+		Convey("When I run a session and save the fixtures", func() {
+			So(runSession(client, backend), ShouldBeNil)
+			dir, err := ioutil.TempDir("", "amt-fixtures")
+			So(err, ShouldBeNil)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "session.json")
+			So(recorder.Save(path), ShouldBeNil)
+			if *record {
+				So(recorder.Save(filepath.Join("..", "testdata", "session.json")),
+					ShouldBeNil)
+			}
+
+			Convey("Then the credentials are not saved", func() {
+				data, err := ioutil.ReadFile(path)
+				So(err, ShouldBeNil)
+				So(string(data), ShouldNotContainSubstring, ACCESS_KEY)
+				So(string(data), ShouldNotContainSubstring, "Signature")
+				So(strings.Count(string(data), `"operation"`), ShouldEqual, 4)
+			})
+
+			Convey("Then the session can be replayed without the server", func() {
+				replayer, err := amt.LoadReplayer(path)
+				So(err, ShouldBeNil)
+				client := amt.NewClientWithURL("OtherKey", "OtherSecret",
+					"http://amt.invalid",
+					amt.WithHTTPClient(&http.Client{Transport: replayer}))
+				So(runSession(client, sim.New(0)), ShouldBeNil)
+				So(replayer.Unused(), ShouldBeEmpty)
+			})
+		})
+	})
+}
@@ -0,0 +1,163 @@
+// Package server implements a stand-in for the AMT REST endpoint. It accepts
+// the signed query-string requests produced by the amt client and answers with
+// namespaced XML, delegating each operation to an amt.AmtClient backend such
+// as the simulator in the amt/sim package.
+//
+// A Handler can be served by httptest.NewServer, so integration tests can
+// exercise the full wire encoding without the AWS sandbox:
+//
+//	srv := server.NewTestServer(sim.New(100), "key", "secret")
+//	defer srv.Close()
+//	client := amt.NewClientWithURL("key", "secret", srv.URL)
+package server
+
+import (
+	"crypto/hmac"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/jesand/crowds/amt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	xsdt "github.com/metaleap/go-xsd/types"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+)
+
+const (
+	// The XML namespace of AMT responses
+	NAMESPACE = "http://requester.mturk.amazonaws.com/doc/" + amt.API_VERSION
+
+	// Error codes reported by the server itself
+	CODE_INTERNAL_ERROR     = "AWS.InternalError"
+	CODE_INVALID_OPERATION  = "AWS.InvalidOperation"
+	CODE_INVALID_PARAMETERS = "AWS.BadParameters"
+	CODE_NOT_AUTHORIZED     = "AWS.NotAuthorized"
+)
+
+// Handler serves AMT requests by verifying their signatures and passing them
+// to a backend.
+type Handler struct {
+
+	// The client which carries out each operation
+	Backend amt.AmtClient
+
+	// The secret key for each AWSAccessKeyId permitted to make requests
+	Credentials map[string]string
+}
+
+// NewHandler creates a handler for requests signed with the given
+// credentials, keyed by AWSAccessKeyId.
+func NewHandler(backend amt.AmtClient, credentials map[string]string) *Handler {
+	return &Handler{
+		Backend:     backend,
+		Credentials: credentials,
+	}
+}
+
+// NewTestServer starts an httptest.Server which accepts requests signed with
+// a single access key. The caller should Close it when done.
+func NewTestServer(backend amt.AmtClient, accessKeyId,
+	secretKey string) *httptest.Server {
+	return httptest.NewServer(NewHandler(backend, map[string]string{
+		accessKeyId: secretKey,
+	}))
+}
+
+// ServeHTTP answers a single AMT request. Requests which cannot be
+// authenticated or parsed are answered with an HTTP error status and an
+// <ErrorResponse> body; errors reported by the backend are returned inside
+// the operation's response, as AMT does.
+func (handler *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeFault(w, http.StatusBadRequest, CODE_INVALID_PARAMETERS,
+			err.Error())
+		return
+	}
+	var (
+		operation = r.Form.Get("Operation")
+		op, found = operations[operation]
+	)
+	if !found {
+		writeFault(w, http.StatusBadRequest, CODE_INVALID_OPERATION,
+			fmt.Sprintf("The operation %q is not supported", operation))
+		return
+	} else if !handler.authorized(r) {
+		writeFault(w, http.StatusForbidden, CODE_NOT_AUTHORIZED,
+			"The request signature does not match")
+		return
+	}
+
+	response, err := op(handler.Backend, r.Form)
+	if err != nil {
+		var apiErr *amt.APIError
+		if !errors.As(err, &apiErr) {
+			writeFault(w, http.StatusInternalServerError, CODE_INTERNAL_ERROR,
+				err.Error())
+			return
+		}
+		addError(response, apiErr)
+	}
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, xml.Header)
+	xml.NewEncoder(w).EncodeElement(response, xml.StartElement{
+		Name: xml.Name{Space: NAMESPACE, Local: operation + "Response"},
+	})
+}
+
+// Decide whether a request carries a valid signature for a known access key.
+func (handler *Handler) authorized(r *http.Request) bool {
+	secretKey, ok := handler.Credentials[r.Form.Get("AWSAccessKeyId")]
+	if !ok {
+		return false
+	}
+	expected := amt.Signature(secretKey, r.Form.Get("Service"),
+		r.Form.Get("Operation"), r.Form.Get("Timestamp"))
+	return hmac.Equal([]byte(expected), []byte(r.Form.Get("Signature")))
+}
+
+// Report an API error in a response's OperationRequest element.
+func addError(response interface{}, apiErr *amt.APIError) {
+	field := reflect.ValueOf(response).Elem().FieldByName("OperationRequest")
+	opReq, _ := field.Interface().(*amtgen.TxsdOperationRequest)
+	if opReq == nil {
+		opReq = &amtgen.TxsdOperationRequest{}
+		field.Set(reflect.ValueOf(opReq))
+	}
+	if opReq.RequestId == "" {
+		opReq.RequestId = xsdt.String(apiErr.RequestId)
+	}
+	e := &amtgen.TxsdErrorsSequenceError{}
+	e.Code = xsdt.String(apiErr.Code)
+	e.Message = xsdt.String(apiErr.Message)
+	var keys []string
+	for key := range apiErr.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		pair := &amtgen.TKeyValuePair{}
+		pair.Key = xsdt.String(key)
+		pair.Value = xsdt.String(apiErr.Data[key])
+		e.Datas = append(e.Datas, pair)
+	}
+	opReq.Errors = &amtgen.TxsdErrors{}
+	opReq.Errors.Errors = append(opReq.Errors.Errors, e)
+}
+
+// The body of an HTTP error response
+type errorResponse struct {
+	XMLName xml.Name `xml:"ErrorResponse"`
+	Code    string   `xml:"Errors>Error>Code"`
+	Message string   `xml:"Errors>Error>Message"`
+}
+
+// Answer with an HTTP error status and an <ErrorResponse> body.
+func writeFault(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprint(w, xml.Header)
+	xml.NewEncoder(w).Encode(errorResponse{Code: code, Message: message})
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"errors"
+	"github.com/jesand/crowds/amt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	"github.com/jesand/crowds/amt/sim"
+	xsdt "github.com/metaleap/go-xsd/types"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/url"
+	"testing"
+)
+
+const (
+	ACCESS_KEY = "FAKE_ACCESS_KEY"
+	SECRET_KEY = "FAKE_SECRET_KEY"
+	QUESTION   = `<HTMLQuestion xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2011-11-11/HTMLQuestion.xsd"><HTMLContent>Hi</HTMLContent><FrameHeight>400</FrameHeight></HTMLQuestion>`
+)
+
+func TestServer(t *testing.T) {
+	Convey("Given a stand-in server backed by a simulator", t, func() {
+		backend := sim.New(10)
+		backend.AddWorker("W1", sim.FixedAnswers(map[string]string{"q": "yes"}))
+		srv := NewTestServer(backend, ACCESS_KEY, SECRET_KEY)
+		defer srv.Close()
+		client := amt.NewClientWithURL(ACCESS_KEY, SECRET_KEY, srv.URL)
+
+		Convey("When I create a HIT with a Qualification requirement", func() {
+			req := &amtgen.TQualificationRequirement{}
+			req.QualificationTypeId = "000000000000000000L0"
+			req.Comparator = "GreaterThan"
+			req.IntegerValues = []xsdt.Int{95}
+			resp, err := client.CreateHIT("Title", "Description", QUESTION, "",
+				nil, 0.25, 60, 3600, 1, 600, []string{"a", "b"},
+				[]*amtgen.TQualificationRequirement{req}, nil, nil, "note", "")
+			So(err, ShouldBeNil)
+			So(len(resp.Hits), ShouldEqual, 1)
+			hitId := string(resp.Hits[0].HITId)
+
+			Convey("Then the arguments arrive intact", func() {
+				resp, err := backend.GetHIT(hitId)
+				So(err, ShouldBeNil)
+				hit := resp.Hits[0]
+				So(string(hit.Title), ShouldEqual, "Title")
+				So(string(hit.Question), ShouldEqual, QUESTION)
+				So(string(hit.Keywords), ShouldEqual, "a,b")
+				So(string(hit.Reward.Amount), ShouldEqual, "0.25")
+				So(string(hit.RequesterAnnotation), ShouldEqual, "note")
+				So(len(hit.QualificationRequirements), ShouldEqual, 1)
+				So(hit.QualificationRequirements[0].IntegerValues, ShouldResemble, []xsdt.Int{95})
+			})
+
+			Convey("When the work is done and approved over the wire", func() {
+				backend.Work()
+				resp, err := client.GetAssignmentsForHIT(hitId, []string{"Submitted"},
+					"SubmitTime", true, 10, 1)
+				So(err, ShouldBeNil)
+				assignments := resp.GetAssignmentsForHITResults[0].Assignments
+				So(len(assignments), ShouldEqual, 1)
+				So(string(assignments[0].Answer), ShouldContainSubstring, "<FreeText>yes</FreeText>")
+				_, err = client.ApproveAssignment(string(assignments[0].AssignmentId), "")
+				So(err, ShouldBeNil)
+
+				Convey("Then the balance reflects the payment", func() {
+					resp, err := client.GetAccountBalance()
+					So(err, ShouldBeNil)
+					So(string(resp.GetAccountBalanceResults[0].AvailableBalance.Amount),
+						ShouldEqual, "9.70")
+				})
+			})
+		})
+
+		Convey("When I request a HIT which does not exist", func() {
+			_, err := client.GetHIT("NOSUCHHIT")
+
+			Convey("Then the backend's error is returned", func() {
+				var apiErr *amt.APIError
+				So(errors.As(err, &apiErr), ShouldBeTrue)
+				So(apiErr.Code, ShouldEqual, amt.CODE_HIT_NOT_FOUND)
+				So(apiErr.Operation, ShouldEqual, "GetHIT")
+			})
+		})
+
+		Convey("When I sign requests with the wrong secret key", func() {
+			client := amt.NewClientWithURL(ACCESS_KEY, "WRONG", srv.URL)
+			_, err := client.GetAccountBalance()
+
+			Convey("Then the request is refused", func() {
+				var httpErr *amt.HTTPError
+				So(errors.As(err, &httpErr), ShouldBeTrue)
+				So(httpErr.StatusCode, ShouldEqual, 403)
+				So(httpErr.Code, ShouldEqual, CODE_NOT_AUTHORIZED)
+			})
+		})
+	})
+}
+
+func TestUnpackArgs(t *testing.T) {
+	Convey("Given the parameters of a GetAssignmentsForHIT request", t, func() {
+		form := url.Values{
+			"HITId":            {"HIT1"},
+			"AssignmentStatus": {"Submitted,Approved"},
+			"PageSize":         {"20"},
+		}
+
+		Convey("Then they are decoded into the request struct", func() {
+			var args amtgen.TGetAssignmentsForHITRequest
+			unpackArgs(form, &args)
+			So(string(args.HITId), ShouldEqual, "HIT1")
+			So(args.AssignmentStatuses, ShouldResemble, []amtgen.TAssignmentStatus{
+				"Submitted", "Approved"})
+			So(int(args.PageSize), ShouldEqual, 20)
+			So(int(args.PageNumber), ShouldEqual, 0)
+		})
+	})
+}
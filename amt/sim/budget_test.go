@@ -0,0 +1,188 @@
+package sim
+
+import (
+	"errors"
+	"github.com/jesand/crowds/amt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+// Create a HIT with the test question and the given reward
+func createBudgetHIT(client amt.AmtClient, reward float32, maxAssignments int) error {
+	_, err := client.CreateHIT("Sky", "Name the sky's color", newTestQuestion(),
+		"", nil, reward, 60, 3600, maxAssignments, 600, nil, nil, nil, nil, "", "")
+	return err
+}
+
+// A client whose GetAccountBalance reads the balance, then waits to be
+// released before returning it
+type slowBalanceClient struct {
+	amt.AmtClient
+	called, release chan bool
+}
+
+func (client slowBalanceClient) GetAccountBalance() (
+	amtgen.TxsdGetAccountBalanceResponse, error) {
+	resp, err := client.AmtClient.GetAccountBalance()
+	client.called <- true
+	<-client.release
+	return resp, err
+}
+
+func TestBudgetGuard(t *testing.T) {
+	Convey("Given a simulator with $10 and an open HIT costing $1.20", t, func() {
+		sim := New(10)
+		So(createBudgetHIT(sim, 0.5, 2), ShouldBeNil)
+
+		Convey("When I guard it with a $5 cap", func() {
+			guard := amt.NewBudgetGuard(sim, 5)
+
+			Convey("Then a HIT within the cap is created", func() {
+				So(createBudgetHIT(guard, 0.5, 3), ShouldBeNil)
+				So(guard.Committed(), ShouldEqual, 3.0)
+				So(countHITs(sim), ShouldEqual, 2)
+			})
+
+			Convey("Then a HIT past the cap is refused", func() {
+				err := createBudgetHIT(guard, 0.5, 10)
+				So(errors.Is(err, amt.ErrOverBudget), ShouldBeTrue)
+				var budgetErr *amt.BudgetError
+				So(errors.As(err, &budgetErr), ShouldBeTrue)
+				So(budgetErr.Cost, ShouldEqual, 7.0)
+				So(budgetErr.Committed, ShouldEqual, 1.2)
+				So(budgetErr.LimitName, ShouldEqual, "spend cap")
+				So(countHITs(sim), ShouldEqual, 1)
+				So(guard.Committed(), ShouldEqual, 1.2)
+			})
+
+			Convey("Then planned bonuses count against the cap", func() {
+				So(guard.PlanBonus(2.5), ShouldBeNil)
+				So(guard.Committed(), ShouldEqual, 4.2)
+				err := createBudgetHIT(guard, 0.5, 2)
+				So(errors.Is(err, amt.ErrOverBudget), ShouldBeTrue)
+				So(guard.PlanBonus(1), ShouldNotBeNil)
+			})
+
+			Convey("Then a HIT of a known HIT type is costed", func() {
+				resp, err := guard.RegisterHITType("Sky", "Name the sky's color",
+					1, 60, 600, nil, nil)
+				So(err, ShouldBeNil)
+				hitTypeId := string(resp.RegisterHITTypeResults[0].HITTypeId)
+				_, err = guard.CreateHITFromHITTypeId(hitTypeId, newTestQuestion(),
+					"", nil, 3600, 4, nil, nil, "", "")
+				So(errors.Is(err, amt.ErrOverBudget), ShouldBeTrue)
+				_, err = guard.CreateHITFromHITTypeId(hitTypeId, newTestQuestion(),
+					"", nil, 3600, 3, nil, nil, "", "")
+				So(err, ShouldBeNil)
+				So(guard.Committed(), ShouldEqual, 4.8)
+			})
+
+			Convey("Then a HIT of an unknown HIT type is refused", func() {
+				_, err := guard.CreateHITFromHITTypeId("UNKNOWN", newTestQuestion(),
+					"", nil, 3600, 1, nil, nil, "", "")
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "reward of HIT type UNKNOWN is unknown")
+			})
+		})
+
+		Convey("When I guard it with no cap", func() {
+			guard := amt.NewBudgetGuard(sim, 0)
+
+			Convey("Then a HIT costing more than the balance is refused", func() {
+				err := createBudgetHIT(guard, 1, 8)
+				var budgetErr *amt.BudgetError
+				So(errors.As(err, &budgetErr), ShouldBeTrue)
+				So(budgetErr.LimitName, ShouldEqual, "available balance")
+				So(budgetErr.Limit, ShouldEqual, 8.8)
+				So(sim.Balance(), ShouldEqual, 8.8)
+			})
+		})
+
+		Convey("When a guarded call is waiting on the balance", func() {
+			client := slowBalanceClient{sim, make(chan bool), make(chan bool)}
+			guard := amt.NewBudgetGuard(client, 5)
+			done := make(chan error)
+			go func() { done <- guard.PlanBonus(1) }()
+			<-client.called
+			committed := make(chan float64)
+			go func() { committed <- guard.Committed() }()
+
+			Convey("Then other calls to the guard are not held up", func() {
+				select {
+				case amount := <-committed:
+					So(amount, ShouldEqual, 1.2)
+				case <-time.After(time.Second):
+					So("Committed was blocked", ShouldBeEmpty)
+				}
+				close(client.release)
+				So(<-done, ShouldBeNil)
+				So(guard.Committed(), ShouldEqual, 2.4)
+			})
+		})
+
+		Convey("When two HITs are created at once with a $3 bonus planned", func() {
+			client := slowBalanceClient{sim, make(chan bool, 3), make(chan bool, 3)}
+			guard := amt.NewBudgetGuard(client, 0)
+			client.release <- true
+			So(guard.PlanBonus(2.5), ShouldBeNil)
+			<-client.called
+			done := make(chan error)
+			go func() { done <- createBudgetHIT(guard, 1, 3) }()
+			go func() { done <- createBudgetHIT(guard, 1, 3) }()
+			<-client.called
+			<-client.called
+			client.release <- true
+			client.release <- true
+			errs := []error{<-done, <-done}
+
+			Convey("Then the second is refused to keep the bonus covered", func() {
+				var refused int
+				for _, err := range errs {
+					if errors.Is(err, amt.ErrOverBudget) {
+						refused++
+					} else {
+						So(err, ShouldBeNil)
+					}
+				}
+				So(refused, ShouldEqual, 1)
+				So(countHITs(sim), ShouldEqual, 2)
+				So(sim.Balance(), ShouldEqual, 5.2)
+				So(guard.Committed(), ShouldEqual, 7.8)
+			})
+		})
+
+		Convey("Given one of its assignments submitted and a guard with a $3 cap", func() {
+			sim.AddWorker("W1", FixedAnswers(map[string]string{"color": "blue"}))
+			sim.Work()
+			it := amt.SearchAllHITs(sim, "", true, 0)
+			it.Next()
+			aresp, _ := sim.GetAssignmentsForHIT(string(it.HIT().HITId), nil, "",
+				true, 0, 0)
+			assignmentId := string(
+				aresp.GetAssignmentsForHITResults[0].Assignments[0].AssignmentId)
+			guard := amt.NewBudgetGuard(sim, 3)
+			So(guard.PlanBonus(1), ShouldBeNil)
+
+			Convey("When I grant the planned bonus", func() {
+				_, err := guard.GrantBonus("W1", assignmentId, 1, "Thanks", "")
+
+				Convey("Then it uses up the plan", func() {
+					So(err, ShouldBeNil)
+					So(guard.Committed(), ShouldEqual, 1.8)
+					So(sim.Balance(), ShouldEqual, 7.6)
+				})
+			})
+
+			Convey("When I grant a bonus past the cap", func() {
+				_, err := guard.GrantBonus("W1", assignmentId, 3, "Thanks", "")
+
+				Convey("Then it is refused", func() {
+					So(errors.Is(err, amt.ErrOverBudget), ShouldBeTrue)
+					So(sim.Balance(), ShouldEqual, 8.8)
+				})
+			})
+		})
+	})
+}
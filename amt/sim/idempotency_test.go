@@ -0,0 +1,126 @@
+package sim
+
+import (
+	"github.com/jesand/crowds/amt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+// Create a HIT with the test question through an idempotent client
+func createIdempotentHIT(client *amt.IdempotentClient, key string) (string, error) {
+	resp, err := client.CreateHIT(key, "Sky", "Name the sky's color",
+		newTestQuestion(), "", nil, 0.5, 60, 3600, 1, 600, []string{"sky"}, nil,
+		nil, nil, "")
+	if err != nil {
+		return "", err
+	}
+	return string(resp.Hits[0].HITId), nil
+}
+
+// Count the HITs in a simulator
+func countHITs(sim *Simulator) int {
+	var count int
+	for it := amt.SearchAllHITs(sim, "", true, 0); it.Next(); {
+		count++
+	}
+	return count
+}
+
+func TestIdempotentClient(t *testing.T) {
+	Convey("Given an idempotent client for a simulator with $10", t, func() {
+		sim := New(10)
+		sim.AddWorker("W1", FixedAnswers(map[string]string{"color": "blue"}))
+		store := amt.NewMemoryTokenStore()
+		client := amt.NewIdempotentClient(sim, store)
+
+		Convey("When I create a HIT twice with the same key", func() {
+			hitId1, err1 := createIdempotentHIT(client, "row-1")
+			hitId2, err2 := createIdempotentHIT(client, "row-1")
+
+			Convey("Then one HIT is created and paid for", func() {
+				So(err1, ShouldBeNil)
+				So(err2, ShouldBeNil)
+				So(hitId2, ShouldEqual, hitId1)
+				So(countHITs(sim), ShouldEqual, 1)
+				So(sim.Balance(), ShouldEqual, 9.4)
+			})
+		})
+
+		Convey("When a HIT was created before a crash lost its outcome", func() {
+			resp, err := sim.CreateHIT("Sky", "Name the sky's color",
+				newTestQuestion(), "", nil, 0.5, 60, 3600, 1, 600, nil, nil, nil,
+				nil, "", amt.RequestToken("CreateHIT", "row-1"))
+			So(err, ShouldBeNil)
+			hitId, err := createIdempotentHIT(client, "row-1")
+
+			Convey("Then the duplicate is treated as success", func() {
+				So(err, ShouldBeNil)
+				So(hitId, ShouldEqual, string(resp.Hits[0].HITId))
+				So(countHITs(sim), ShouldEqual, 1)
+				record, ok, _ := store.Load("CreateHIT:row-1")
+				So(ok, ShouldBeTrue)
+				So(record.ResultId, ShouldEqual, hitId)
+			})
+		})
+
+		Convey("When I use different keys", func() {
+			createIdempotentHIT(client, "row-1")
+			createIdempotentHIT(client, "row-2")
+
+			Convey("Then a HIT is created for each", func() {
+				So(countHITs(sim), ShouldEqual, 2)
+			})
+		})
+
+		Convey("Given a submitted assignment", func() {
+			hitId, _ := createIdempotentHIT(client, "row-1")
+			sim.Work()
+			aresp, _ := sim.GetAssignmentsForHIT(hitId, nil, "", true, 0, 0)
+			assignmentId := string(
+				aresp.GetAssignmentsForHITResults[0].Assignments[0].AssignmentId)
+
+			Convey("When I grant a bonus twice with the same key", func() {
+				bonus1, err1 := client.GrantBonus("bonus:"+assignmentId, "W1",
+					assignmentId, 1, "Great work")
+				bonus2, err2 := client.GrantBonus("bonus:"+assignmentId, "W1",
+					assignmentId, 1, "Great work")
+
+				Convey("Then it is paid once", func() {
+					So(err1, ShouldBeNil)
+					So(err2, ShouldBeNil)
+					So(string(bonus1.WorkerId), ShouldEqual, "W1")
+					So(string(bonus2.AssignmentId), ShouldEqual, assignmentId)
+					So(string(bonus2.BonusAmount.Amount), ShouldEqual, "1.00")
+					So(sim.Balance(), ShouldEqual, 8.2)
+				})
+			})
+
+			Convey("When a bonus was paid before a crash lost its outcome", func() {
+				_, err := sim.GrantBonus("W1", assignmentId, 1, "Great work",
+					amt.RequestToken("GrantBonus", "bonus:"+assignmentId))
+				So(err, ShouldBeNil)
+				bonus, err := client.GrantBonus("bonus:"+assignmentId, "W1",
+					assignmentId, 1, "Great work")
+
+				Convey("Then the original bonus is returned", func() {
+					So(err, ShouldBeNil)
+					So(string(bonus.Reason), ShouldEqual, "Great work")
+					So(string(bonus.BonusAmount.Amount), ShouldEqual, "1.00")
+					So(sim.Balance(), ShouldEqual, 8.2)
+				})
+			})
+
+			Convey("When I extend the HIT twice with the same key", func() {
+				_, err1 := client.ExtendHIT("extend:"+hitId, hitId, 1, 60)
+				_, err2 := client.ExtendHIT("extend:"+hitId, hitId, 1, 60)
+
+				Convey("Then it is extended once", func() {
+					So(err1, ShouldBeNil)
+					So(err2, ShouldBeNil)
+					resp, _ := sim.GetHIT(hitId)
+					So(int(resp.Hits[0].MaxAssignments), ShouldEqual, 2)
+				})
+			})
+		})
+	})
+}
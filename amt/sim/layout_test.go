@@ -0,0 +1,36 @@
+package sim
+
+import (
+	"github.com/jesand/crowds/amt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestCreateHITsFromLayout(t *testing.T) {
+	Convey("Given a simulator with $10 and a registered layout", t, func() {
+		sim := New(10)
+		registry := amt.NewHITLayoutRegistry()
+		registry.Register(amt.NewHITLayout("LAYOUT1", `<p>${caption}</p>`))
+		resp, err := sim.RegisterHITType("Caption", "Check a caption", 0.5, 60,
+			600, nil, nil)
+		So(err, ShouldBeNil)
+		hitTypeId := string(resp.RegisterHITTypeResults[0].HITTypeId)
+		rows := []map[string]string{{"caption": "One"}, {"caption": "Two"}}
+
+		Convey("When I create the same HITs twice", func() {
+			hitIds1, err1 := registry.CreateHITs(sim, hitTypeId, "LAYOUT1", rows,
+				3600, 1)
+			hitIds2, err2 := registry.CreateHITs(sim, hitTypeId, "LAYOUT1", rows,
+				3600, 1)
+
+			Convey("Then the second run returns the HITs already created", func() {
+				So(err1, ShouldBeNil)
+				So(err2, ShouldBeNil)
+				So(hitIds1, ShouldHaveLength, 2)
+				So(hitIds2, ShouldResemble, hitIds1)
+				So(countHITs(sim), ShouldEqual, 2)
+				So(sim.Balance(), ShouldEqual, 8.8)
+			})
+		})
+	})
+}
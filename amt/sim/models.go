@@ -0,0 +1,141 @@
+package sim
+
+import (
+	"fmt"
+	"github.com/jesand/crowds/amt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	questionformanswers "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2005-10-01/QuestionFormAnswers.xsd_go"
+	xsdt "github.com/metaleap/go-xsd/types"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// AnswerModel decides how a simulated worker answers HITs.
+type AnswerModel interface {
+
+	// Answer returns the QuestionFormAnswers XML a worker submits for a HIT,
+	// or ok=false if the worker declines to work on it.
+	Answer(workerId string, hit *amtgen.Thit) (answer string, ok bool)
+}
+
+// AnswerFunc adapts a function to the AnswerModel interface.
+type AnswerFunc func(workerId string, hit *amtgen.Thit) (string, bool)
+
+// Answer calls the function.
+func (fn AnswerFunc) Answer(workerId string, hit *amtgen.Thit) (string, bool) {
+	return fn(workerId, hit)
+}
+
+// FixedAnswers returns a model which gives the same answers to every HIT,
+// keyed by question identifier. For QuestionForm questions with a selection
+// answer the value is submitted as a SelectionIdentifier, and otherwise as
+// FreeText. Answers are only submitted for questions the HIT asks, except for
+// HTMLQuestion and ExternalQuestion HITs, where every answer is submitted.
+func FixedAnswers(answers map[string]string) AnswerModel {
+	return AnswerFunc(func(workerId string, hit *amtgen.Thit) (string, bool) {
+		var (
+			form, _ = decodeForm(hit)
+			result  amt.QuestionFormAnswers
+		)
+		if form == nil {
+			var ids []string
+			for id := range answers {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			for _, id := range ids {
+				result.Answers = append(result.Answers,
+					freeTextAnswer(id, answers[id]))
+			}
+		} else {
+			for _, q := range form.Questions {
+				value, ok := answers[string(q.QuestionIdentifier)]
+				if !ok {
+					continue
+				}
+				id := string(q.QuestionIdentifier)
+				if q.AnswerSpecification != nil &&
+					q.AnswerSpecification.SelectionAnswer != nil {
+					result.Answers = append(result.Answers,
+						selectionAnswer(id, value))
+				} else {
+					result.Answers = append(result.Answers,
+						freeTextAnswer(id, value))
+				}
+			}
+		}
+		return encodeAnswers(&result)
+	})
+}
+
+// RandomSelections returns a model which answers QuestionForm HITs with
+// uniformly random choices: a random selection for each selection question,
+// and a random integer for each free-text question. HITs which do not use a
+// QuestionForm are declined.
+func RandomSelections(rng *rand.Rand) AnswerModel {
+	var mu sync.Mutex
+	return AnswerFunc(func(workerId string, hit *amtgen.Thit) (string, bool) {
+		form, err := decodeForm(hit)
+		if err != nil || form == nil {
+			return "", false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		var result amt.QuestionFormAnswers
+		for _, q := range form.Questions {
+			id := string(q.QuestionIdentifier)
+			spec := q.AnswerSpecification
+			switch {
+			case spec == nil:
+				continue
+			case spec.SelectionAnswer != nil &&
+				spec.SelectionAnswer.Selections != nil &&
+				len(spec.SelectionAnswer.Selections.Selections) > 0:
+				choices := spec.SelectionAnswer.Selections.Selections
+				choice := choices[rng.Intn(len(choices))]
+				result.Answers = append(result.Answers,
+					selectionAnswer(id, string(choice.SelectionIdentifier)))
+			case spec.FreeTextAnswer != nil:
+				result.Answers = append(result.Answers,
+					freeTextAnswer(id, fmt.Sprint(rng.Intn(100))))
+			}
+		}
+		return encodeAnswers(&result)
+	})
+}
+
+// Decode a HIT's question if it is a QuestionForm, or return nil.
+func decodeForm(hit *amtgen.Thit) (*amt.QuestionForm, error) {
+	if hit == nil || hit.Question == "" {
+		return nil, nil
+	}
+	question, err := amt.DecodeQuestion([]byte(hit.Question))
+	if err != nil {
+		return nil, err
+	}
+	form, _ := question.(*amt.QuestionForm)
+	return form, nil
+}
+
+func freeTextAnswer(questionId, text string) *questionformanswers.TxsdQuestionFormAnswersSequenceAnswer {
+	answer := &questionformanswers.TxsdQuestionFormAnswersSequenceAnswer{}
+	answer.QuestionIdentifier = xsdt.String(questionId)
+	answer.FreeText = xsdt.String(text)
+	return answer
+}
+
+func selectionAnswer(questionId, selectionId string) *questionformanswers.TxsdQuestionFormAnswersSequenceAnswer {
+	answer := &questionformanswers.TxsdQuestionFormAnswersSequenceAnswer{}
+	answer.QuestionIdentifier = xsdt.String(questionId)
+	answer.SelectionIdentifiers = []xsdt.String{xsdt.String(selectionId)}
+	return answer
+}
+
+func encodeAnswers(answers *amt.QuestionFormAnswers) (string, bool) {
+	xml, err := amt.EncodeQuestion(answers)
+	if err != nil {
+		return "", false
+	}
+	return string(xml), true
+}
@@ -0,0 +1,1595 @@
+package sim
+
+import (
+	"fmt"
+	"github.com/jesand/crowds/amt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	xsdt "github.com/metaleap/go-xsd/types"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ amt.AmtClient = (*Simulator)(nil)
+
+const (
+	// The auto-approval delay AMT uses when none is given: 30 days
+	DEFAULT_AUTO_APPROVAL_DELAY = 30 * 24 * time.Hour
+
+	// The bounds AMT places on assignment durations and HIT lifetimes
+	MIN_DURATION = 30 * time.Second
+	MAX_DURATION = 365 * 24 * time.Hour
+)
+
+// ApproveAssignment approves the results of a completed assignment, paying
+// the worker.
+func (sim *Simulator) ApproveAssignment(assignmentId, requesterFeedback string) (
+	amtgen.TxsdApproveAssignmentResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdApproveAssignmentResponse
+	response.OperationRequest = sim.operationRequest()
+	a, ok := sim.assignments[assignmentId]
+	if !ok {
+		return response, assignmentNotFound("ApproveAssignment", assignmentId)
+	} else if a.status != ASSIGNMENT_SUBMITTED {
+		return response, simError("ApproveAssignment",
+			CODE_INVALID_ASSIGNMENT_STATE,
+			"Assignment %s is %s, not Submitted", assignmentId, a.status)
+	}
+	sim.approve(a, requesterFeedback)
+	result := &amtgen.TApproveAssignmentResult{}
+	result.Request = validRequest()
+	response.ApproveAssignmentResults = append(
+		response.ApproveAssignmentResults, result)
+	return response, nil
+}
+
+// ApproveRejectedAssignment approves an assignment that was previously
+// rejected, charging the account for the reward.
+func (sim *Simulator) ApproveRejectedAssignment(assignmentId,
+	requesterFeedback string) (
+	amtgen.TxsdApproveRejectedAssignmentResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdApproveRejectedAssignmentResponse
+	response.OperationRequest = sim.operationRequest()
+	const op = "ApproveRejectedAssignment"
+	a, ok := sim.assignments[assignmentId]
+	if !ok {
+		return response, assignmentNotFound(op, assignmentId)
+	} else if a.status != ASSIGNMENT_REJECTED {
+		return response, simError(op, CODE_INVALID_ASSIGNMENT_STATE,
+			"Assignment %s is %s, not Rejected", assignmentId, a.status)
+	}
+	if err := sim.charge(op, sim.assignmentCost(sim.hits[a.hitId], 1).Total()); err != nil {
+		return response, err
+	}
+	sim.stats[statKey{"", "NumberAssignmentsRejected"}]--
+	sim.stats[statKey{a.workerId, "NumberAssignmentsRejected"}]--
+	sim.approve(a, requesterFeedback)
+	result := &amtgen.TApproveRejectedAssignmentResult{}
+	result.Request = validRequest()
+	response.ApproveRejectedAssignmentResults = append(
+		response.ApproveRejectedAssignmentResults, result)
+	return response, nil
+}
+
+// AssignQualification gives a worker a Qualification, or updates the score
+// of one they already hold.
+func (sim *Simulator) AssignQualification(qualificationTypeId,
+	workerId string, integerValue int, sendNotification bool) (
+	amtgen.TxsdAssignQualificationResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdAssignQualificationResponse
+	response.OperationRequest = sim.operationRequest()
+	if _, ok := sim.qualTypes[qualificationTypeId]; !ok {
+		return response, qualTypeNotFound("AssignQualification",
+			qualificationTypeId)
+	}
+	sim.grant(qualificationTypeId, workerId, integerValue)
+	result := &amtgen.TAssignQualificationResult{}
+	result.Request = validRequest()
+	response.AssignQualificationResults = append(
+		response.AssignQualificationResults, result)
+	return response, nil
+}
+
+// BlockWorker prevents a worker from accepting any of your HITs.
+func (sim *Simulator) BlockWorker(workerId, reason string) (
+	amtgen.TxsdBlockWorkerResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdBlockWorkerResponse
+	response.OperationRequest = sim.operationRequest()
+	if workerId == "" || reason == "" {
+		return response, simError("BlockWorker", CODE_INVALID_PARAMETER,
+			"WorkerId and Reason are required")
+	}
+	sim.blocks[workerId] = reason
+	result := &amtgen.TBlockWorkerResult{}
+	result.Request = validRequest()
+	response.BlockWorkerResults = append(response.BlockWorkerResults, result)
+	return response, nil
+}
+
+// ChangeHITTypeOfHIT moves a HIT to a different HIT type with the same
+// reward.
+func (sim *Simulator) ChangeHITTypeOfHIT(hitId, hitTypeId string) (
+	amtgen.TxsdChangeHITTypeOfHITResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdChangeHITTypeOfHITResponse
+	response.OperationRequest = sim.operationRequest()
+	const op = "ChangeHITTypeOfHIT"
+	h, ok := sim.hits[hitId]
+	if !ok {
+		return response, hitNotFound(op, hitId)
+	}
+	ht, ok := sim.hitTypes[hitTypeId]
+	if !ok {
+		return response, simError(op, CODE_HIT_TYPE_NOT_FOUND,
+			"HIT type %s does not exist.", hitTypeId)
+	} else if ht.reward != sim.hitTypes[h.hitTypeId].reward {
+		return response, simError(op, CODE_INVALID_PARAMETER,
+			"HIT type %s does not have the same reward as HIT %s",
+			hitTypeId, hitId)
+	}
+	h.hitTypeId = hitTypeId
+	result := &amtgen.TChangeHITTypeOfHITResult{}
+	result.Request = validRequest()
+	response.ChangeHITTypeOfHITResults = append(
+		response.ChangeHITTypeOfHITResults, result)
+	return response, nil
+}
+
+// CreateHIT creates a new HIT, registering its HIT type if needed.
+func (sim *Simulator) CreateHIT(title, description, question string,
+	hitLayoutId string, hitLayoutParameters map[string]string,
+	reward float32, assignmentDurationInSeconds,
+	lifetimeInSeconds, maxAssignments, autoApprovalDelayInSeconds int,
+	keywords []string,
+	qualificationRequirements []*amtgen.TQualificationRequirement,
+	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
+	requesterAnnotation, uniqueRequestToken string) (
+	amtgen.TxsdCreateHITResponse, error) {
+
+	var args amtgen.TCreateHITRequest
+	args.Title = xsdt.String(title)
+	args.Description = xsdt.String(description)
+	args.Question = xsdt.String(question)
+	args.HITLayoutId = xsdt.String(hitLayoutId)
+	args.Reward = &amtgen.TPrice{}
+	args.Reward.Amount = xsdt.Decimal(fmt.Sprint(reward))
+	args.Reward.CurrencyCode = amt.CURRENCY_USD
+	args.AssignmentDurationInSeconds = xsdt.Long(assignmentDurationInSeconds)
+	args.LifetimeInSeconds = xsdt.Long(lifetimeInSeconds)
+	args.MaxAssignments = xsdt.Int(maxAssignments)
+	args.AutoApprovalDelayInSeconds = xsdt.Long(autoApprovalDelayInSeconds)
+	args.Keywords = xsdt.String(strings.Join(keywords, ","))
+	args.QualificationRequirements = qualificationRequirements
+	args.RequesterAnnotation = xsdt.String(requesterAnnotation)
+	args.UniqueRequestToken = xsdt.String(uniqueRequestToken)
+	return sim.CreateHITFromArgs(args)
+}
+
+// CreateHITFromHITTypeId creates a new HIT of a registered HIT type.
+func (sim *Simulator) CreateHITFromHITTypeId(hitTypeId, question string,
+	hitLayoutId string, hitLayoutParameters map[string]string,
+	lifetimeInSeconds, maxAssignments int,
+	assignmentReviewPolicy, hitReviewPolicy *amtgen.TReviewPolicy,
+	requesterAnnotation, uniqueRequestToken string) (
+	amtgen.TxsdCreateHITResponse, error) {
+
+	var args amtgen.TCreateHITRequest
+	args.HITTypeId = xsdt.String(hitTypeId)
+	args.Question = xsdt.String(question)
+	args.HITLayoutId = xsdt.String(hitLayoutId)
+	args.LifetimeInSeconds = xsdt.Long(lifetimeInSeconds)
+	args.MaxAssignments = xsdt.Int(maxAssignments)
+	args.RequesterAnnotation = xsdt.String(requesterAnnotation)
+	args.UniqueRequestToken = xsdt.String(uniqueRequestToken)
+	return sim.CreateHITFromArgs(args)
+}
+
+// CreateHITFromArgs creates a new HIT from the given argument values. The
+// reward and commission for every assignment are deducted from the balance.
+// If the UniqueRequestToken was used before, an error with code
+// amt.CODE_DUPLICATE_REQUEST is returned whose Data["HITId"] identifies the
+// original HIT.
+func (sim *Simulator) CreateHITFromArgs(args amtgen.TCreateHITRequest) (
+	amtgen.TxsdCreateHITResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdCreateHITResponse
+	response.OperationRequest = sim.operationRequest()
+	const op = "CreateHIT"
+
+	// Check for a duplicate request
+	token := string(args.UniqueRequestToken)
+	if hitId, ok := sim.tokens[op+":"+token]; ok && token != "" {
+		err := simError(op, amt.CODE_DUPLICATE_REQUEST,
+			"There is already a HIT which exists with the same "+
+				"UniqueRequestToken: %s", token)
+		err.Data = map[string]string{"HITId": hitId}
+		return response, err
+	}
+
+	// Find or register the HIT type
+	var (
+		ht  *hitType
+		err error
+	)
+	if args.HITTypeId != "" {
+		var ok bool
+		if ht, ok = sim.hitTypes[string(args.HITTypeId)]; !ok {
+			return response, simError(op, CODE_HIT_TYPE_NOT_FOUND,
+				"HIT type %s does not exist.", args.HITTypeId)
+		}
+	} else {
+		var typeArgs amtgen.TRegisterHITTypeRequest
+		typeArgs.Title = args.Title
+		typeArgs.Description = args.Description
+		typeArgs.Reward = args.Reward
+		typeArgs.AssignmentDurationInSeconds = args.AssignmentDurationInSeconds
+		typeArgs.AutoApprovalDelayInSeconds = args.AutoApprovalDelayInSeconds
+		typeArgs.Keywords = args.Keywords
+		typeArgs.QualificationRequirements = args.QualificationRequirements
+		if ht, err = sim.registerHITType(op, typeArgs); err != nil {
+			return response, err
+		}
+	}
+
+	// Validate the HIT
+	lifetime := time.Duration(args.LifetimeInSeconds) * time.Second
+	maxAssignments := int(args.MaxAssignments)
+	if maxAssignments == 0 {
+		maxAssignments = 1
+	}
+	if args.Question == "" && args.HITLayoutId == "" {
+		return response, simError(op, CODE_INVALID_PARAMETER,
+			"Either Question or HITLayoutId is required")
+	} else if lifetime < MIN_DURATION || lifetime > MAX_DURATION {
+		return response, simError(op, CODE_INVALID_PARAMETER,
+			"LifetimeInSeconds must be between %d and %d",
+			MIN_DURATION/time.Second, MAX_DURATION/time.Second)
+	} else if maxAssignments < 1 {
+		return response, simError(op, CODE_INVALID_PARAMETER,
+			"MaxAssignments must be positive")
+	}
+
+	// Pay for the assignments and create the HIT
+	cost := amt.EstimateHITCost(float32(ht.reward), maxAssignments).Total()
+	if err := sim.charge(op, cost); err != nil {
+		return response, err
+	}
+	h := &hit{
+		id:             sim.newId("HIT"),
+		hitTypeId:      ht.id,
+		question:       string(args.Question),
+		layoutId:       string(args.HITLayoutId),
+		annotation:     string(args.RequesterAnnotation),
+		created:        sim.now,
+		expiration:     sim.now.Add(lifetime),
+		maxAssignments: maxAssignments,
+	}
+	sim.hits[h.id] = h
+	if token != "" {
+		sim.tokens[op+":"+token] = h.id
+	}
+	sim.count("", "NumberHITsCreated", 1)
+	response.Hits = append(response.Hits, sim.hitView(h))
+	return response, nil
+}
+
+// CreateQualificationType creates a new Qualification type.
+func (sim *Simulator) CreateQualificationType(name, description string,
+	keywords []string, retryDelayInSeconds int,
+	qualificationTypeStatus, test, answerKey string,
+	testDurationInSeconds int, autoGranted bool, autoGrantedValue int) (
+	amtgen.TxsdCreateQualificationTypeResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdCreateQualificationTypeResponse
+	response.OperationRequest = sim.operationRequest()
+	const op = "CreateQualificationType"
+	if name == "" || description == "" {
+		return response, simError(op, CODE_INVALID_PARAMETER,
+			"Name and Description are required")
+	} else if qualificationTypeStatus != "Active" &&
+		qualificationTypeStatus != "Inactive" {
+		return response, simError(op, CODE_INVALID_PARAMETER,
+			"QualificationTypeStatus must be Active or Inactive")
+	}
+	for _, other := range sim.qualTypes {
+		if other.name == name {
+			return response, simError(op, CODE_INVALID_PARAMETER,
+				"You have already created a QualificationType with this "+
+					"name: %s", name)
+		}
+	}
+	qt := &qualType{
+		id:               sim.newId("QUALTYPE"),
+		name:             name,
+		description:      description,
+		keywords:         strings.Join(keywords, ","),
+		status:           qualificationTypeStatus,
+		retryDelay:       int64(retryDelayInSeconds),
+		test:             test,
+		answerKey:        answerKey,
+		testDuration:     int64(testDurationInSeconds),
+		autoGranted:      autoGranted,
+		autoGrantedValue: autoGrantedValue,
+		created:          sim.now,
+	}
+	sim.qualTypes[qt.id] = qt
+	response.QualificationTypes = append(response.QualificationTypes,
+		qt.view())
+	return response, nil
+}
+
+// DisableHIT removes a HIT from the marketplace, approves any submitted
+// assignments, and refunds the cost of assignments not yet completed.
+func (sim *Simulator) DisableHIT(hitId string) (
+	amtgen.TxsdDisableHITResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdDisableHITResponse
+	response.OperationRequest = sim.operationRequest()
+	h, ok := sim.hits[hitId]
+	if !ok {
+		return response, hitNotFound("DisableHIT", hitId)
+	}
+	var kept []*assignment
+	for _, a := range h.assignments {
+		switch a.status {
+		case ASSIGNMENT_ACCEPTED:
+			continue
+		case ASSIGNMENT_SUBMITTED:
+			sim.approve(a, "")
+		}
+		kept = append(kept, a)
+	}
+	h.assignments = kept
+	sim.removeHIT(h)
+	result := &amtgen.TDisableHITResult{}
+	result.Request = validRequest()
+	response.DisableHITResults = append(response.DisableHITResults, result)
+	return response, nil
+}
+
+// DisposeHIT deletes a Reviewable HIT whose assignments have all been
+// approved or rejected, refunding the cost of any unfilled assignments.
+func (sim *Simulator) DisposeHIT(hitId string) (
+	amtgen.TxsdDisposeHITResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdDisposeHITResponse
+	response.OperationRequest = sim.operationRequest()
+	h, ok := sim.hits[hitId]
+	if !ok {
+		return response, hitNotFound("DisposeHIT", hitId)
+	} else if status := sim.hitStatus(h); status != HIT_REVIEWABLE {
+		return response, simError("DisposeHIT", CODE_INVALID_HIT_STATE,
+			"HIT %s is %s, not Reviewable", hitId, status)
+	}
+	for _, a := range h.assignments {
+		if a.status == ASSIGNMENT_SUBMITTED {
+			return response, simError("DisposeHIT", CODE_INVALID_HIT_STATE,
+				"HIT %s has assignments which have not been approved or "+
+					"rejected", hitId)
+		}
+	}
+	sim.removeHIT(h)
+	result := &amtgen.TDisposeHITResult{}
+	result.Request = validRequest()
+	response.DisposeHITResults = append(response.DisposeHITResults, result)
+	return response, nil
+}
+
+// DisposeQualificationType deletes a Qualification type, along with all
+// Qualifications and pending requests for it.
+func (sim *Simulator) DisposeQualificationType(qualificationTypeId string) (
+	amtgen.TxsdDisposeQualificationTypeResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdDisposeQualificationTypeResponse
+	response.OperationRequest = sim.operationRequest()
+	if _, ok := sim.qualTypes[qualificationTypeId]; !ok {
+		return response, qualTypeNotFound("DisposeQualificationType",
+			qualificationTypeId)
+	}
+	delete(sim.qualTypes, qualificationTypeId)
+	for key := range sim.quals {
+		if key.qualTypeId == qualificationTypeId {
+			delete(sim.quals, key)
+		}
+	}
+	for id, req := range sim.qualRequests {
+		if req.qualTypeId == qualificationTypeId {
+			delete(sim.qualRequests, id)
+		}
+	}
+	result := &amtgen.TDisposeQualificationTypeResult{}
+	result.Request = validRequest()
+	response.DisposeQualificationTypeResults = append(
+		response.DisposeQualificationTypeResults, result)
+	return response, nil
+}
+
+// ExtendHIT adds assignments to a HIT, paying for them up front, and extends
+// its expiration. An expired HIT is extended from the current time.
+func (sim *Simulator) ExtendHIT(hitId string, maxAssignmentsIncrement,
+	expirationIncrementInSeconds int, uniqueRequestToken string) (
+	amtgen.TxsdExtendHITResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdExtendHITResponse
+	response.OperationRequest = sim.operationRequest()
+	const op = "ExtendHIT"
+	h, ok := sim.hits[hitId]
+	if !ok {
+		return response, hitNotFound(op, hitId)
+	} else if maxAssignmentsIncrement < 0 || expirationIncrementInSeconds < 0 {
+		return response, simError(op, CODE_INVALID_PARAMETER,
+			"Increments must not be negative")
+	}
+	if original, ok := sim.tokens[op+":"+uniqueRequestToken]; ok &&
+		uniqueRequestToken != "" {
+		err := simError(op, amt.CODE_DUPLICATE_REQUEST,
+			"There is already an extension with the same "+
+				"UniqueRequestToken: %s", uniqueRequestToken)
+		err.Data = map[string]string{"HITId": original}
+		return response, err
+	}
+	reward := sim.hitTypes[h.hitTypeId].reward
+	cost := amt.EstimateAssignmentCost(float32(reward), maxAssignmentsIncrement,
+		h.maxAssignments+maxAssignmentsIncrement).Total()
+	if err := sim.charge(op, cost); err != nil {
+		return response, err
+	}
+	h.maxAssignments += maxAssignmentsIncrement
+	if h.expiration.Before(sim.now) {
+		h.expiration = sim.now
+	}
+	h.expiration = h.expiration.Add(
+		time.Duration(expirationIncrementInSeconds) * time.Second)
+	if uniqueRequestToken != "" {
+		sim.tokens[op+":"+uniqueRequestToken] = hitId
+	}
+	result := &amtgen.TExtendHITResult{}
+	result.Request = validRequest()
+	response.ExtendHITResults = append(response.ExtendHITResults, result)
+	return response, nil
+}
+
+// ForceExpireHIT makes a HIT unavailable to new workers immediately.
+func (sim *Simulator) ForceExpireHIT(hitId string) (
+	amtgen.TxsdForceExpireHITResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdForceExpireHITResponse
+	response.OperationRequest = sim.operationRequest()
+	h, ok := sim.hits[hitId]
+	if !ok {
+		return response, hitNotFound("ForceExpireHIT", hitId)
+	}
+	if h.expiration.After(sim.now) {
+		h.expiration = sim.now
+	}
+	result := &amtgen.TForceExpireHITResult{}
+	result.Request = validRequest()
+	response.ForceExpireHITResults = append(response.ForceExpireHITResults,
+		result)
+	return response, nil
+}
+
+// GetAccountBalance returns the available balance. The cost of open HITs has
+// already been deducted, so nothing is ever on hold.
+func (sim *Simulator) GetAccountBalance() (
+	amtgen.TxsdGetAccountBalanceResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetAccountBalanceResponse
+	response.OperationRequest = sim.operationRequest()
+	result := &amtgen.TGetAccountBalanceResult{}
+	result.Request = validRequest()
+	result.AvailableBalance = price(sim.balance)
+	result.OnHoldBalance = price(0)
+	response.GetAccountBalanceResults = append(
+		response.GetAccountBalanceResults, result)
+	return response, nil
+}
+
+// GetAssignment retrieves an assignment and its HIT.
+func (sim *Simulator) GetAssignment(assignmentId string) (
+	amtgen.TxsdGetAssignmentResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetAssignmentResponse
+	response.OperationRequest = sim.operationRequest()
+	a, ok := sim.assignments[assignmentId]
+	if !ok {
+		return response, assignmentNotFound("GetAssignment", assignmentId)
+	}
+	result := &amtgen.TGetAssignmentResult{}
+	result.Request = validRequest()
+	result.Assignment = sim.assignmentView(a)
+	result.Hit = sim.hitView(sim.hits[a.hitId])
+	response.GetAssignmentResults = append(response.GetAssignmentResults,
+		result)
+	return response, nil
+}
+
+// GetAssignmentsForHIT retrieves the submitted, approved and rejected
+// assignments for a HIT. The sort property may be AcceptTime, SubmitTime or
+// AssignmentStatus.
+func (sim *Simulator) GetAssignmentsForHIT(hitId string,
+	assignmentStatuses []string, sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetAssignmentsForHITResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetAssignmentsForHITResponse
+	response.OperationRequest = sim.operationRequest()
+	h, ok := sim.hits[hitId]
+	if !ok {
+		return response, hitNotFound("GetAssignmentsForHIT", hitId)
+	}
+	var matches []*assignment
+	for _, a := range h.assignments {
+		if a.status == ASSIGNMENT_ACCEPTED {
+			continue
+		} else if len(assignmentStatuses) > 0 &&
+			!contains(assignmentStatuses, a.status) {
+			continue
+		}
+		matches = append(matches, a)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if !sortAscending {
+			a, b = b, a
+		}
+		switch sortProperty {
+		case "AcceptTime":
+			return a.accepted.Before(b.accepted)
+		case "AssignmentStatus":
+			return a.status < b.status
+		default:
+			return a.submitted.Before(b.submitted)
+		}
+	})
+	start, end := page(len(matches), pageSize, pageNumber)
+	result := &amtgen.TGetAssignmentsForHITResult{}
+	result.Request = validRequest()
+	result.PageNumber = xsdt.Int(pageNumberOrDefault(pageNumber))
+	result.NumResults = xsdt.Int(end - start)
+	result.TotalNumResults = xsdt.Int(len(matches))
+	for _, a := range matches[start:end] {
+		result.Assignments = append(result.Assignments, sim.assignmentView(a))
+	}
+	response.GetAssignmentsForHITResults = append(
+		response.GetAssignmentsForHITResults, result)
+	return response, nil
+}
+
+// GetBlockedWorkers lists blocked workers, ordered by worker ID.
+func (sim *Simulator) GetBlockedWorkers(pageSize, pageNumber int) (
+	amtgen.TxsdGetBlockedWorkersResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetBlockedWorkersResponse
+	response.OperationRequest = sim.operationRequest()
+	var workerIds []string
+	for workerId := range sim.blocks {
+		workerIds = append(workerIds, workerId)
+	}
+	sort.Strings(workerIds)
+	start, end := page(len(workerIds), pageSize, pageNumber)
+	result := &amtgen.TGetBlockedWorkersResult{}
+	result.Request = validRequest()
+	result.PageNumber = xsdt.Int(pageNumberOrDefault(pageNumber))
+	result.NumResults = xsdt.Int(end - start)
+	result.TotalNumResults = xsdt.Int(len(workerIds))
+	for _, workerId := range workerIds[start:end] {
+		block := &amtgen.TWorkerBlock{}
+		block.WorkerId = xsdt.String(workerId)
+		block.Reason = xsdt.String(sim.blocks[workerId])
+		result.WorkerBlocks = append(result.WorkerBlocks, block)
+	}
+	response.GetBlockedWorkersResults = append(
+		response.GetBlockedWorkersResults, result)
+	return response, nil
+}
+
+// GetBonusPayments lists the bonuses paid for a HIT or for an assignment.
+func (sim *Simulator) GetBonusPayments(hitId, assignmentId string,
+	pageSize, pageNumber int) (amtgen.TxsdGetBonusPaymentsResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetBonusPaymentsResponse
+	response.OperationRequest = sim.operationRequest()
+	if (hitId == "") == (assignmentId == "") {
+		return response, simError("GetBonusPayments", CODE_INVALID_PARAMETER,
+			"Exactly one of HITId and AssignmentId is required")
+	}
+	var matches []*bonus
+	for _, b := range sim.bonuses {
+		if (hitId != "" && b.hitId == hitId) ||
+			(assignmentId != "" && b.assignmentId == assignmentId) {
+			matches = append(matches, b)
+		}
+	}
+	start, end := page(len(matches), pageSize, pageNumber)
+	result := &amtgen.TGetBonusPaymentsResult{}
+	result.Request = validRequest()
+	result.PageNumber = xsdt.Int(pageNumberOrDefault(pageNumber))
+	result.NumResults = xsdt.Int(end - start)
+	result.TotalNumResults = xsdt.Int(len(matches))
+	for _, b := range matches[start:end] {
+		payment := &amtgen.TBonusPayment{}
+		payment.WorkerId = xsdt.String(b.workerId)
+		payment.AssignmentId = xsdt.String(b.assignmentId)
+		payment.BonusAmount = price(b.amount)
+		payment.Reason = xsdt.String(b.reason)
+		payment.GrantTime = xsdt.DateTime(amt.FormatTime(b.granted))
+		result.BonusPayments = append(result.BonusPayments, payment)
+	}
+	response.GetBonusPaymentsResults = append(
+		response.GetBonusPaymentsResults, result)
+	return response, nil
+}
+
+// GetFileUploadURL returns a placeholder URL for a file uploaded as an answer.
+// The simulator does not store uploaded files.
+func (sim *Simulator) GetFileUploadURL(assignmentId,
+	questionIdentifier string) (amtgen.TxsdGetFileUploadURLResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetFileUploadURLResponse
+	response.OperationRequest = sim.operationRequest()
+	if _, ok := sim.assignments[assignmentId]; !ok {
+		return response, assignmentNotFound("GetFileUploadURL", assignmentId)
+	}
+	result := &amtgen.TGetFileUploadURLResult{}
+	result.Request = validRequest()
+	result.FileUploadURL = xsdt.String(fmt.Sprintf(
+		"https://sim.invalid/uploads/%s/%s", assignmentId, questionIdentifier))
+	response.GetFileUploadURLResults = append(
+		response.GetFileUploadURLResults, result)
+	return response, nil
+}
+
+// GetHIT retrieves a HIT.
+func (sim *Simulator) GetHIT(hitId string) (amtgen.TxsdGetHITResponse, error) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetHITResponse
+	response.OperationRequest = sim.operationRequest()
+	h, ok := sim.hits[hitId]
+	if !ok {
+		return response, hitNotFound("GetHIT", hitId)
+	}
+	response.Hits = append(response.Hits, sim.hitView(h))
+	return response, nil
+}
+
+// GetHITsForQualificationType lists the HITs which require a Qualification.
+func (sim *Simulator) GetHITsForQualificationType(qualificationTypeId string,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetHITsForQualificationTypeResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetHITsForQualificationTypeResponse
+	response.OperationRequest = sim.operationRequest()
+	if _, ok := sim.qualTypes[qualificationTypeId]; !ok {
+		return response, qualTypeNotFound("GetHITsForQualificationType",
+			qualificationTypeId)
+	}
+	var matches []*hit
+	for _, h := range sim.sortedHITs() {
+		for _, req := range sim.hitTypes[h.hitTypeId].quals {
+			if string(req.QualificationTypeId) == qualificationTypeId {
+				matches = append(matches, h)
+				break
+			}
+		}
+	}
+	start, end := page(len(matches), pageSize, pageNumber)
+	result := &amtgen.TGetHITsForQualificationTypeResult{}
+	result.Request = validRequest()
+	result.PageNumber = xsdt.Int(pageNumberOrDefault(pageNumber))
+	result.NumResults = xsdt.Int(end - start)
+	result.TotalNumResults = xsdt.Int(len(matches))
+	for _, h := range matches[start:end] {
+		result.Hits = append(result.Hits, sim.hitView(h))
+	}
+	response.GetHITsForQualificationTypeResults = append(
+		response.GetHITsForQualificationTypeResults, result)
+	return response, nil
+}
+
+// GetQualificationRequests lists pending Qualification requests, optionally
+// for a single Qualification type. The sort property may be SubmitTime or
+// QualificationTypeId.
+func (sim *Simulator) GetQualificationRequests(qualificationTypeId,
+	sortProperty string, sortAscending bool, pageSize, pageNumber int) (
+	amtgen.TxsdGetQualificationRequestsResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetQualificationRequestsResponse
+	response.OperationRequest = sim.operationRequest()
+	var matches []*qualRequest
+	for _, req := range sim.qualRequests {
+		if qualificationTypeId == "" || req.qualTypeId == qualificationTypeId {
+			matches = append(matches, req)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if !sortAscending {
+			a, b = b, a
+		}
+		if sortProperty == "QualificationTypeId" && a.qualTypeId != b.qualTypeId {
+			return a.qualTypeId < b.qualTypeId
+		} else if !a.submitted.Equal(b.submitted) {
+			return a.submitted.Before(b.submitted)
+		}
+		return a.id < b.id
+	})
+	start, end := page(len(matches), pageSize, pageNumber)
+	result := &amtgen.TGetQualificationRequestsResult{}
+	result.Request = validRequest()
+	result.PageNumber = xsdt.Int(pageNumberOrDefault(pageNumber))
+	result.NumResults = xsdt.Int(end - start)
+	result.TotalNumResults = xsdt.Int(len(matches))
+	for _, req := range matches[start:end] {
+		view := &amtgen.TQualificationRequest{}
+		view.QualificationRequestId = xsdt.String(req.id)
+		view.QualificationTypeId = xsdt.String(req.qualTypeId)
+		view.SubjectId = xsdt.String(req.workerId)
+		view.Test = xsdt.String(sim.qualTypes[req.qualTypeId].test)
+		view.Answer = xsdt.String(req.answer)
+		view.SubmitTime = xsdt.DateTime(amt.FormatTime(req.submitted))
+		result.QualificationRequests = append(result.QualificationRequests,
+			view)
+	}
+	response.GetQualificationRequestsResults = append(
+		response.GetQualificationRequestsResults, result)
+	return response, nil
+}
+
+// GetQualificationScore retrieves a worker's Qualification.
+func (sim *Simulator) GetQualificationScore(qualificationTypeId,
+	subjectId string) (amtgen.TxsdGetQualificationScoreResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetQualificationScoreResponse
+	response.OperationRequest = sim.operationRequest()
+	q, ok := sim.quals[qualKey{qualificationTypeId, subjectId}]
+	if !ok {
+		return response, simError("GetQualificationScore",
+			CODE_QUALIFICATION_NOT_FOUND,
+			"You requested a Qualification that does not exist.")
+	}
+	response.Qualifications = append(response.Qualifications, q.view())
+	return response, nil
+}
+
+// GetQualificationsForQualificationType lists the granted or revoked
+// Qualifications of a type, ordered by worker ID.
+func (sim *Simulator) GetQualificationsForQualificationType(
+	qualificationTypeId string, isGranted bool, pageSize, pageNumber int) (
+	amtgen.TxsdGetQualificationsForQualificationTypeResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetQualificationsForQualificationTypeResponse
+	response.OperationRequest = sim.operationRequest()
+	if _, ok := sim.qualTypes[qualificationTypeId]; !ok {
+		return response, qualTypeNotFound(
+			"GetQualificationsForQualificationType", qualificationTypeId)
+	}
+	var matches []*qualification
+	for key, q := range sim.quals {
+		if key.qualTypeId == qualificationTypeId && q.revoked != isGranted {
+			matches = append(matches, q)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].workerId < matches[j].workerId
+	})
+	start, end := page(len(matches), pageSize, pageNumber)
+	result := &amtgen.TGetQualificationsForQualificationTypeResult{}
+	result.Request = validRequest()
+	result.PageNumber = xsdt.Int(pageNumberOrDefault(pageNumber))
+	result.NumResults = xsdt.Int(end - start)
+	result.TotalNumResults = xsdt.Int(len(matches))
+	for _, q := range matches[start:end] {
+		result.Qualifications = append(result.Qualifications, q.view())
+	}
+	response.GetQualificationsForQualificationTypeResults = append(
+		response.GetQualificationsForQualificationTypeResults, result)
+	return response, nil
+}
+
+// GetQualificationType retrieves a Qualification type.
+func (sim *Simulator) GetQualificationType(qualificationTypeId string) (
+	amtgen.TxsdGetQualificationTypeResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetQualificationTypeResponse
+	response.OperationRequest = sim.operationRequest()
+	qt, ok := sim.qualTypes[qualificationTypeId]
+	if !ok {
+		return response, qualTypeNotFound("GetQualificationType",
+			qualificationTypeId)
+	}
+	response.QualificationTypes = append(response.QualificationTypes,
+		qt.view())
+	return response, nil
+}
+
+// GetRequesterStatistic reports a requester statistic. Only lifetime totals
+// are tracked, so a single data point is returned for any time period.
+// Supported statistics are NumberAssignmentsAccepted, NumberAssignmentsAbandoned,
+// NumberAssignmentsReturned, NumberAssignmentsSubmitted,
+// NumberAssignmentsApproved, NumberAssignmentsRejected, NumberHITsCreated,
+// PercentAssignmentsApproved, PercentAssignmentsRejected, TotalRewardPayout,
+// TotalRewardFeePayout, TotalBonusPayout, TotalBonusFeePayout and
+// AvailableBalance.
+func (sim *Simulator) GetRequesterStatistic(statistic, timePeriod string,
+	count int) (amtgen.TxsdGetRequesterStatisticResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetRequesterStatisticResponse
+	response.OperationRequest = sim.operationRequest()
+	result, err := sim.statistic("GetRequesterStatistic", "", statistic,
+		timePeriod)
+	if err != nil {
+		return response, err
+	}
+	response.GetStatisticResults = append(response.GetStatisticResults,
+		result)
+	return response, nil
+}
+
+// GetRequesterWorkerStatistic reports a statistic about a single worker. The
+// same statistics are supported as by GetRequesterStatistic, except for
+// NumberHITsCreated and AvailableBalance.
+func (sim *Simulator) GetRequesterWorkerStatistic(statistic, workerId,
+	timePeriod string, count int) (
+	amtgen.TxsdGetRequesterWorkerStatisticResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetRequesterWorkerStatisticResponse
+	response.OperationRequest = sim.operationRequest()
+	const op = "GetRequesterWorkerStatistic"
+	if workerId == "" {
+		return response, simError(op, CODE_INVALID_PARAMETER,
+			"WorkerId is required")
+	} else if statistic == "NumberHITsCreated" ||
+		statistic == "AvailableBalance" {
+		return response, simError(op, CODE_INVALID_PARAMETER,
+			"Unsupported worker statistic: %s", statistic)
+	}
+	result, err := sim.statistic(op, workerId, statistic, timePeriod)
+	if err != nil {
+		return response, err
+	}
+	response.GetStatisticResults = append(response.GetStatisticResults,
+		result)
+	return response, nil
+}
+
+// GetReviewableHITs lists HITs of a given status, Reviewable by default,
+// optionally restricted to one HIT type. The sort property may be Title,
+// Reward, Expiration or CreationTime.
+func (sim *Simulator) GetReviewableHITs(hitTypeId, status,
+	sortProperty string, sortAscending bool, pageSize, pageNumber int) (
+	amtgen.TxsdGetReviewableHITsResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetReviewableHITsResponse
+	response.OperationRequest = sim.operationRequest()
+	if status == "" {
+		status = HIT_REVIEWABLE
+	} else if status != HIT_REVIEWABLE && status != HIT_REVIEWING {
+		return response, simError("GetReviewableHITs", CODE_INVALID_PARAMETER,
+			"Status must be Reviewable or Reviewing")
+	}
+	var matches []*hit
+	for _, h := range sim.sortedHITs() {
+		if (hitTypeId == "" || h.hitTypeId == hitTypeId) &&
+			sim.hitStatus(h) == status {
+			matches = append(matches, h)
+		}
+	}
+	sim.sortHITs(matches, sortProperty, sortAscending)
+	start, end := page(len(matches), pageSize, pageNumber)
+	result := &amtgen.TGetReviewableHITsResult{}
+	result.Request = validRequest()
+	result.PageNumber = xsdt.Int(pageNumberOrDefault(pageNumber))
+	result.NumResults = xsdt.Int(end - start)
+	result.TotalNumResults = xsdt.Int(len(matches))
+	for _, h := range matches[start:end] {
+		result.Hits = append(result.Hits, sim.hitView(h))
+	}
+	response.GetReviewableHITsResults = append(
+		response.GetReviewableHITsResults, result)
+	return response, nil
+}
+
+// GetReviewResultsForHIT returns an empty review report, since the
+// simulator does not run review policies.
+func (sim *Simulator) GetReviewResultsForHIT(hitId string,
+	policyLevels []string, retrieveActions, retrieveResults bool,
+	pageSize, pageNumber int) (
+	amtgen.TxsdGetReviewResultsForHITResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGetReviewResultsForHITResponse
+	response.OperationRequest = sim.operationRequest()
+	if _, ok := sim.hits[hitId]; !ok {
+		return response, hitNotFound("GetReviewResultsForHIT", hitId)
+	}
+	result := &amtgen.TGetReviewResultsForHITResult{}
+	result.Request = validRequest()
+	result.HITId = xsdt.String(hitId)
+	response.GetReviewResultsForHITResults = append(
+		response.GetReviewResultsForHITResults, result)
+	return response, nil
+}
+
+// GrantBonus pays a worker a bonus for an assignment, plus commission. If
+// the UniqueRequestToken was used before, an error with code
+// amt.CODE_DUPLICATE_REQUEST is returned whose Data["AssignmentId"]
+// identifies the original bonus's assignment.
+func (sim *Simulator) GrantBonus(workerId, assignmentId string,
+	bonusAmount float32, reason, uniqueRequestToken string) (
+	amtgen.TxsdGrantBonusResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGrantBonusResponse
+	response.OperationRequest = sim.operationRequest()
+	const op = "GrantBonus"
+	a, ok := sim.assignments[assignmentId]
+	if !ok {
+		return response, assignmentNotFound(op, assignmentId)
+	} else if a.workerId != workerId {
+		return response, simError(op, CODE_INVALID_PARAMETER,
+			"Assignment %s was not completed by worker %s", assignmentId,
+			workerId)
+	} else if a.status == ASSIGNMENT_ACCEPTED {
+		return response, simError(op, CODE_INVALID_ASSIGNMENT_STATE,
+			"Assignment %s has not been submitted", assignmentId)
+	}
+	amount, _ := strconv.ParseFloat(fmt.Sprint(bonusAmount), 64)
+	if amount <= 0 {
+		return response, simError(op, CODE_INVALID_PARAMETER,
+			"BonusAmount must be positive")
+	} else if reason == "" {
+		return response, simError(op, CODE_INVALID_PARAMETER,
+			"Reason is required")
+	}
+	if original, ok := sim.tokens[op+":"+uniqueRequestToken]; ok &&
+		uniqueRequestToken != "" {
+		err := simError(op, amt.CODE_DUPLICATE_REQUEST,
+			"There is already a bonus with the same UniqueRequestToken: %s",
+			uniqueRequestToken)
+		err.Data = map[string]string{"AssignmentId": original}
+		return response, err
+	}
+	fee := amt.EstimateBonusCost(float32(amount)).Commission
+	if err := sim.charge(op, amount+fee); err != nil {
+		return response, err
+	}
+	sim.bonuses = append(sim.bonuses, &bonus{
+		workerId:     workerId,
+		assignmentId: assignmentId,
+		hitId:        a.hitId,
+		amount:       amount,
+		reason:       reason,
+		granted:      sim.now,
+	})
+	if uniqueRequestToken != "" {
+		sim.tokens[op+":"+uniqueRequestToken] = assignmentId
+	}
+	sim.earnings[workerId] += amount
+	sim.count(workerId, "TotalBonusPayout", amount)
+	sim.count(workerId, "TotalBonusFeePayout", fee)
+	result := &amtgen.TGrantBonusResult{}
+	result.Request = validRequest()
+	response.GrantBonusResults = append(response.GrantBonusResults, result)
+	return response, nil
+}
+
+// GrantQualification grants a worker's pending Qualification request.
+func (sim *Simulator) GrantQualification(qualificationRequestId string,
+	integerValue int) (amtgen.TxsdGrantQualificationResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdGrantQualificationResponse
+	response.OperationRequest = sim.operationRequest()
+	req, ok := sim.qualRequests[qualificationRequestId]
+	if !ok {
+		return response, qualRequestNotFound("GrantQualification",
+			qualificationRequestId)
+	}
+	delete(sim.qualRequests, qualificationRequestId)
+	sim.grant(req.qualTypeId, req.workerId, integerValue)
+	result := &amtgen.TGrantQualificationResult{}
+	result.Request = validRequest()
+	response.GrantQualificationResults = append(
+		response.GrantQualificationResults, result)
+	return response, nil
+}
+
+// NotifyWorkers records a message to workers; see Notifications.
+func (sim *Simulator) NotifyWorkers(subject, messageText string,
+	workerIds []string) (amtgen.TxsdNotifyWorkersResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdNotifyWorkersResponse
+	response.OperationRequest = sim.operationRequest()
+	if subject == "" || messageText == "" || len(workerIds) == 0 {
+		return response, simError("NotifyWorkers", CODE_INVALID_PARAMETER,
+			"Subject, MessageText and WorkerIds are required")
+	}
+	sim.notifications = append(sim.notifications, Notification{
+		Subject:     subject,
+		MessageText: messageText,
+		WorkerIds:   append([]string(nil), workerIds...),
+	})
+	result := &amtgen.TNotifyWorkersResult{}
+	result.Request = validRequest()
+	response.NotifyWorkersResults = append(response.NotifyWorkersResults,
+		result)
+	return response, nil
+}
+
+// RegisterHITType creates a HIT type, or returns the ID of an existing HIT
+// type with identical properties.
+func (sim *Simulator) RegisterHITType(title, description string,
+	reward float32, assignmentDurationInSeconds,
+	autoApprovalDelayInSeconds int, keywords []string,
+	qualificationRequirements []*amtgen.TQualificationRequirement) (
+	amtgen.TxsdRegisterHITTypeResponse, error) {
+
+	var args amtgen.TRegisterHITTypeRequest
+	args.Title = xsdt.String(title)
+	args.Description = xsdt.String(description)
+	args.Reward = &amtgen.TPrice{}
+	args.Reward.Amount = xsdt.Decimal(fmt.Sprint(reward))
+	args.Reward.CurrencyCode = amt.CURRENCY_USD
+	args.AssignmentDurationInSeconds = xsdt.Long(assignmentDurationInSeconds)
+	args.AutoApprovalDelayInSeconds = xsdt.Long(autoApprovalDelayInSeconds)
+	args.Keywords = xsdt.String(strings.Join(keywords, ","))
+	args.QualificationRequirements = qualificationRequirements
+	return sim.RegisterHITTypeFromArgs(args)
+}
+
+// RegisterHITTypeFromArgs creates a HIT type from the given argument values.
+func (sim *Simulator) RegisterHITTypeFromArgs(
+	args amtgen.TRegisterHITTypeRequest) (
+	amtgen.TxsdRegisterHITTypeResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdRegisterHITTypeResponse
+	response.OperationRequest = sim.operationRequest()
+	ht, err := sim.registerHITType("RegisterHITType", args)
+	if err != nil {
+		return response, err
+	}
+	result := &amtgen.TRegisterHITTypeResult{}
+	result.Request = validRequest()
+	result.HITTypeId = xsdt.String(ht.id)
+	response.RegisterHITTypeResults = append(response.RegisterHITTypeResults,
+		result)
+	return response, nil
+}
+
+// RejectAssignment rejects a submitted assignment, refunding its reward and
+// commission.
+func (sim *Simulator) RejectAssignment(assignmentId, requesterFeedback string) (
+	amtgen.TxsdRejectAssignmentResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdRejectAssignmentResponse
+	response.OperationRequest = sim.operationRequest()
+	a, ok := sim.assignments[assignmentId]
+	if !ok {
+		return response, assignmentNotFound("RejectAssignment", assignmentId)
+	} else if a.status != ASSIGNMENT_SUBMITTED {
+		return response, simError("RejectAssignment",
+			CODE_INVALID_ASSIGNMENT_STATE,
+			"Assignment %s is %s, not Submitted", assignmentId, a.status)
+	}
+	sim.balance = roundCents(sim.balance +
+		sim.assignmentCost(sim.hits[a.hitId], 1).Total())
+	a.status = ASSIGNMENT_REJECTED
+	a.rejected = sim.now
+	a.feedback = requesterFeedback
+	sim.count(a.workerId, "NumberAssignmentsRejected", 1)
+	result := &amtgen.TRejectAssignmentResult{}
+	result.Request = validRequest()
+	response.RejectAssignmentResults = append(
+		response.RejectAssignmentResults, result)
+	return response, nil
+}
+
+// RejectQualificationRequest rejects a worker's pending Qualification
+// request.
+func (sim *Simulator) RejectQualificationRequest(qualificationRequestId,
+	reason string) (amtgen.TxsdRejectQualificationRequestResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdRejectQualificationRequestResponse
+	response.OperationRequest = sim.operationRequest()
+	if _, ok := sim.qualRequests[qualificationRequestId]; !ok {
+		return response, qualRequestNotFound("RejectQualificationRequest",
+			qualificationRequestId)
+	}
+	delete(sim.qualRequests, qualificationRequestId)
+	result := &amtgen.TRejectQualificationRequestResult{}
+	result.Request = validRequest()
+	response.RejectQualificationRequestResults = append(
+		response.RejectQualificationRequestResults, result)
+	return response, nil
+}
+
+// RevokeQualification revokes a worker's Qualification.
+func (sim *Simulator) RevokeQualification(subjectId, qualificationTypeId,
+	reason string) (amtgen.TxsdRevokeQualificationResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdRevokeQualificationResponse
+	response.OperationRequest = sim.operationRequest()
+	q, ok := sim.quals[qualKey{qualificationTypeId, subjectId}]
+	if !ok || q.revoked {
+		return response, simError("RevokeQualification",
+			CODE_QUALIFICATION_NOT_FOUND,
+			"Worker %s does not hold Qualification %s", subjectId,
+			qualificationTypeId)
+	}
+	q.revoked = true
+	result := &amtgen.TRevokeQualificationResult{}
+	result.Request = validRequest()
+	response.RevokeQualificationResults = append(
+		response.RevokeQualificationResults, result)
+	return response, nil
+}
+
+// SearchHITs lists all HITs. The sort property may be Title, Reward,
+// Expiration or CreationTime.
+func (sim *Simulator) SearchHITs(sortProperty string, sortAscending bool,
+	pageSize, pageNumber int) (amtgen.TxsdSearchHITsResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdSearchHITsResponse
+	response.OperationRequest = sim.operationRequest()
+	hits := sim.sortedHITs()
+	sim.sortHITs(hits, sortProperty, sortAscending)
+	start, end := page(len(hits), pageSize, pageNumber)
+	result := &amtgen.TSearchHITsResult{}
+	result.Request = validRequest()
+	result.PageNumber = xsdt.Int(pageNumberOrDefault(pageNumber))
+	result.NumResults = xsdt.Int(end - start)
+	result.TotalNumResults = xsdt.Int(len(hits))
+	for _, h := range hits[start:end] {
+		result.Hits = append(result.Hits, sim.hitView(h))
+	}
+	response.SearchHITsResults = append(response.SearchHITsResults, result)
+	return response, nil
+}
+
+// SearchQualificationTypes lists Qualification types whose name,
+// description or keywords contain the query, ordered by name.
+func (sim *Simulator) SearchQualificationTypes(query, sortProperty string,
+	sortAscending bool, pageSize, pageNumber int, mustBeRequestable,
+	mustBeOwnedByCaller bool) (
+	amtgen.TxsdSearchQualificationTypesResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdSearchQualificationTypesResponse
+	response.OperationRequest = sim.operationRequest()
+	query = strings.ToLower(query)
+	var matches []*qualType
+	for _, qt := range sim.qualTypes {
+		text := strings.ToLower(qt.name + " " + qt.description + " " +
+			qt.keywords)
+		if !strings.Contains(text, query) {
+			continue
+		} else if mustBeRequestable && !bool(qt.view().IsRequestable) {
+			continue
+		}
+		matches = append(matches, qt)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if sortAscending {
+			return matches[i].name < matches[j].name
+		}
+		return matches[i].name > matches[j].name
+	})
+	start, end := page(len(matches), pageSize, pageNumber)
+	result := &amtgen.TSearchQualificationTypesResult{}
+	result.Request = validRequest()
+	result.PageNumber = xsdt.Int(pageNumberOrDefault(pageNumber))
+	result.NumResults = xsdt.Int(end - start)
+	result.TotalNumResults = xsdt.Int(len(matches))
+	for _, qt := range matches[start:end] {
+		result.QualificationTypes = append(result.QualificationTypes,
+			qt.view())
+	}
+	response.SearchQualificationTypesResults = append(
+		response.SearchQualificationTypesResults, result)
+	return response, nil
+}
+
+// SendTestEventNotification accepts the notification without sending it.
+func (sim *Simulator) SendTestEventNotification(
+	notification *amtgen.TNotificationSpecification, testEventType string) (
+	amtgen.TxsdSendTestEventNotificationResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdSendTestEventNotificationResponse
+	response.OperationRequest = sim.operationRequest()
+	result := &amtgen.TSendTestEventNotificationResult{}
+	result.Request = validRequest()
+	response.SendTestEventNotificationResults = append(
+		response.SendTestEventNotificationResults, result)
+	return response, nil
+}
+
+// SetHITAsReviewing moves a Reviewable HIT to the Reviewing status, or back
+// if revert is true.
+func (sim *Simulator) SetHITAsReviewing(hitId string, revert bool) (
+	amtgen.TxsdSetHITAsReviewingResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdSetHITAsReviewingResponse
+	response.OperationRequest = sim.operationRequest()
+	h, ok := sim.hits[hitId]
+	if !ok {
+		return response, hitNotFound("SetHITAsReviewing", hitId)
+	}
+	want := HIT_REVIEWABLE
+	if revert {
+		want = HIT_REVIEWING
+	}
+	if status := sim.hitStatus(h); status != want {
+		return response, simError("SetHITAsReviewing", CODE_INVALID_HIT_STATE,
+			"HIT %s is %s, not %s", hitId, status, want)
+	}
+	h.reviewing = !revert
+	result := &amtgen.TSetHITAsReviewingResult{}
+	result.Request = validRequest()
+	response.SetHITAsReviewingResults = append(
+		response.SetHITAsReviewingResults, result)
+	return response, nil
+}
+
+// SetHITTypeNotification accepts the notification settings without acting
+// on them.
+func (sim *Simulator) SetHITTypeNotification(hitTypeId string,
+	notification *amtgen.TNotificationSpecification, active bool) (
+	amtgen.TxsdSetHITTypeNotificationResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdSetHITTypeNotificationResponse
+	response.OperationRequest = sim.operationRequest()
+	if _, ok := sim.hitTypes[hitTypeId]; !ok {
+		return response, simError("SetHITTypeNotification",
+			CODE_HIT_TYPE_NOT_FOUND, "HIT type %s does not exist.", hitTypeId)
+	}
+	result := &amtgen.TSetHITTypeNotificationResult{}
+	result.Request = validRequest()
+	response.SetHITTypeNotificationResults = append(
+		response.SetHITTypeNotificationResults, result)
+	return response, nil
+}
+
+// UnblockWorker allows a blocked worker to accept your HITs again.
+func (sim *Simulator) UnblockWorker(workerId, reason string) (
+	amtgen.TxsdUnblockWorkerResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdUnblockWorkerResponse
+	response.OperationRequest = sim.operationRequest()
+	if _, ok := sim.blocks[workerId]; !ok {
+		return response, simError("UnblockWorker", CODE_INVALID_PARAMETER,
+			"Worker %s is not blocked", workerId)
+	}
+	delete(sim.blocks, workerId)
+	result := &amtgen.TUnblockWorkerResult{}
+	result.Request = validRequest()
+	response.UnblockWorkerResults = append(response.UnblockWorkerResults,
+		result)
+	return response, nil
+}
+
+// UpdateQualificationScore changes the score of a worker's Qualification.
+func (sim *Simulator) UpdateQualificationScore(qualificationTypeId,
+	subjectId string, integerValue int) (
+	amtgen.TxsdUpdateQualificationScoreResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdUpdateQualificationScoreResponse
+	response.OperationRequest = sim.operationRequest()
+	q, ok := sim.quals[qualKey{qualificationTypeId, subjectId}]
+	if !ok || q.revoked {
+		return response, simError("UpdateQualificationScore",
+			CODE_QUALIFICATION_NOT_FOUND,
+			"Worker %s does not hold Qualification %s", subjectId,
+			qualificationTypeId)
+	}
+	q.value = integerValue
+	result := &amtgen.TUpdateQualificationScoreResult{}
+	result.Request = validRequest()
+	response.UpdateQualificationScoreResults = append(
+		response.UpdateQualificationScoreResults, result)
+	return response, nil
+}
+
+// UpdateQualificationType changes the properties of a Qualification type.
+// Empty strings and zero durations leave the corresponding property
+// unchanged.
+func (sim *Simulator) UpdateQualificationType(qualificationTypeId string,
+	retryDelayInSeconds int, qualificationTypeStatus, description, test,
+	answerKey string, testDurationInSeconds int, autoGranted bool,
+	autoGrantedValue int) (amtgen.TxsdUpdateQualificationTypeResponse, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	var response amtgen.TxsdUpdateQualificationTypeResponse
+	response.OperationRequest = sim.operationRequest()
+	qt, ok := sim.qualTypes[qualificationTypeId]
+	if !ok {
+		return response, qualTypeNotFound("UpdateQualificationType",
+			qualificationTypeId)
+	}
+	if retryDelayInSeconds != 0 {
+		qt.retryDelay = int64(retryDelayInSeconds)
+	}
+	if qualificationTypeStatus != "" {
+		qt.status = qualificationTypeStatus
+	}
+	if description != "" {
+		qt.description = description
+	}
+	if test != "" {
+		qt.test = test
+	}
+	if answerKey != "" {
+		qt.answerKey = answerKey
+	}
+	if testDurationInSeconds != 0 {
+		qt.testDuration = int64(testDurationInSeconds)
+	}
+	qt.autoGranted = autoGranted
+	qt.autoGrantedValue = autoGrantedValue
+	response.QualificationTypes = append(response.QualificationTypes,
+		qt.view())
+	return response, nil
+}
+
+// Find or create the HIT type with the given properties.
+func (sim *Simulator) registerHITType(operation string,
+	args amtgen.TRegisterHITTypeRequest) (*hitType, error) {
+
+	var reward float64
+	if args.Reward != nil {
+		var err error
+		reward, err = strconv.ParseFloat(string(args.Reward.Amount), 64)
+		if err != nil || reward < 0 {
+			return nil, simError(operation, CODE_INVALID_PARAMETER,
+				"Invalid reward: %s", args.Reward.Amount)
+		}
+	}
+	var (
+		duration = time.Duration(args.AssignmentDurationInSeconds) * time.Second
+		delay    = time.Duration(args.AutoApprovalDelayInSeconds) * time.Second
+	)
+	if delay == 0 {
+		delay = DEFAULT_AUTO_APPROVAL_DELAY
+	}
+	if args.Title == "" || args.Description == "" {
+		return nil, simError(operation, CODE_INVALID_PARAMETER,
+			"Title and Description are required")
+	} else if duration < MIN_DURATION || duration > MAX_DURATION {
+		return nil, simError(operation, CODE_INVALID_PARAMETER,
+			"AssignmentDurationInSeconds must be between %d and %d",
+			MIN_DURATION/time.Second, MAX_DURATION/time.Second)
+	}
+	for _, req := range args.QualificationRequirements {
+		if req == nil || req.QualificationTypeId == "" {
+			return nil, simError(operation, CODE_INVALID_PARAMETER,
+				"Qualification requirements must name a QualificationTypeId")
+		}
+	}
+
+	key := fmt.Sprintf("%s\x00%s\x00%.2f\x00%d\x00%d\x00%s",
+		args.Title, args.Description, reward, duration, delay, args.Keywords)
+	for _, req := range args.QualificationRequirements {
+		key += fmt.Sprintf("\x00%s %s %v %v", req.QualificationTypeId,
+			req.Comparator, req.IntegerValues, req.RequiredToPreview)
+	}
+	if id, ok := sim.hitTypeKeys[key]; ok {
+		return sim.hitTypes[id], nil
+	}
+	ht := &hitType{
+		id:                 sim.newId("HITTYPE"),
+		title:              string(args.Title),
+		description:        string(args.Description),
+		keywords:           string(args.Keywords),
+		reward:             reward,
+		assignmentDuration: duration,
+		autoApprovalDelay:  delay,
+		quals:              args.QualificationRequirements,
+	}
+	sim.hitTypes[ht.id] = ht
+	sim.hitTypeKeys[key] = ht.id
+	return ht, nil
+}
+
+// Delete a HIT and its assignments, refunding the cost of unfilled slots.
+// Assignments still in progress are dropped and refunded.
+func (sim *Simulator) removeHIT(h *hit) {
+	var kept []*assignment
+	for _, a := range h.assignments {
+		if a.status != ASSIGNMENT_ACCEPTED {
+			kept = append(kept, a)
+		}
+	}
+	h.assignments = kept
+	sim.refundUnfilled(h)
+	for _, a := range h.assignments {
+		delete(sim.assignments, a.id)
+	}
+	delete(sim.hits, h.id)
+}
+
+// Sort HITs by the given property, preserving creation order for ties.
+func (sim *Simulator) sortHITs(hits []*hit, sortProperty string,
+	sortAscending bool) {
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		if !sortAscending {
+			a, b = b, a
+		}
+		switch sortProperty {
+		case "Title":
+			return sim.hitTypes[a.hitTypeId].title <
+				sim.hitTypes[b.hitTypeId].title
+		case "Reward":
+			return sim.hitTypes[a.hitTypeId].reward <
+				sim.hitTypes[b.hitTypeId].reward
+		case "Expiration":
+			return a.expiration.Before(b.expiration)
+		case "CreationTime":
+			return a.created.Before(b.created)
+		}
+		return false
+	})
+}
+
+// Build the result for a statistic.
+func (sim *Simulator) statistic(operation, workerId, statistic,
+	timePeriod string) (*amtgen.TGetStatisticResult, error) {
+
+	var (
+		value float64
+		get   = func(name string) float64 {
+			return sim.stats[statKey{workerId, name}]
+		}
+	)
+	switch statistic {
+	case "AvailableBalance":
+		value = sim.balance
+	case "PercentAssignmentsApproved", "PercentAssignmentsRejected":
+		approved := get("NumberAssignmentsApproved")
+		rejected := get("NumberAssignmentsRejected")
+		if approved+rejected > 0 {
+			if statistic == "PercentAssignmentsApproved" {
+				value = 100 * approved / (approved + rejected)
+			} else {
+				value = 100 * rejected / (approved + rejected)
+			}
+		}
+	case "NumberAssignmentsAccepted", "NumberAssignmentsAbandoned",
+		"NumberAssignmentsReturned", "NumberAssignmentsSubmitted",
+		"NumberAssignmentsApproved", "NumberAssignmentsRejected",
+		"NumberHITsCreated", "TotalRewardPayout", "TotalRewardFeePayout",
+		"TotalBonusPayout", "TotalBonusFeePayout":
+		value = get(statistic)
+	default:
+		return nil, simError(operation, CODE_INVALID_PARAMETER,
+			"Unsupported statistic: %s", statistic)
+	}
+	point := &amtgen.TDataPoint{}
+	point.Date = xsdt.DateTime(amt.FormatTime(sim.now))
+	if strings.HasPrefix(statistic, "Number") {
+		point.LongValue = xsdt.Long(value)
+	} else {
+		point.DoubleValue = xsdt.Double(value)
+	}
+	result := &amtgen.TGetStatisticResult{}
+	result.Request = validRequest()
+	result.Statistic = amtgen.TRequesterStatistic(statistic)
+	result.TimePeriod = amtgen.TimePeriod(timePeriod)
+	result.WorkerId = xsdt.String(workerId)
+	result.DataPoints = append(result.DataPoints, point)
+	return result, nil
+}
+
+// Build the OperationRequest element for a response.
+func (sim *Simulator) operationRequest() *amtgen.TxsdOperationRequest {
+	opReq := &amtgen.TxsdOperationRequest{}
+	opReq.RequestId = xsdt.String(sim.newId("REQUEST"))
+	return opReq
+}
+
+func qualRequestNotFound(operation, qualRequestId string) error {
+	return simError(operation, CODE_QUAL_REQUEST_NOT_FOUND,
+		"Qualification request %s does not exist.", qualRequestId)
+}
+
+func pageNumberOrDefault(pageNumber int) int {
+	if pageNumber < 1 {
+		return 1
+	}
+	return pageNumber
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,748 @@
+// Package sim implements a stateful, in-memory simulation of Amazon
+// Mechanical Turk. A Simulator implements amt.AmtClient, so crowdsourcing
+// pipelines written against that interface can be run end-to-end offline.
+//
+// The simulator tracks HIT types, HITs, assignments, Qualification types and
+// scores, worker blocks, bonuses and the account balance. Simulated workers
+// are registered with AddWorker and do work when Work is called; the
+// simulated clock only moves when Advance is called, at which point HITs
+// expire, abandoned assignments are returned and submitted assignments are
+// auto-approved.
+//
+// Money is handled the way AMT's prepaid accounts are: creating a HIT
+// deducts the reward and commission for every assignment up front, and the
+// cost of slots which are never filled, or of rejected work, is refunded.
+package sim
+
+import (
+	"fmt"
+	"github.com/jesand/crowds/amt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	xsdt "github.com/metaleap/go-xsd/types"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// The page size used when a paged operation asks for 0 results
+	DEFAULT_PAGE_SIZE = 10
+
+	// HIT statuses
+	HIT_ASSIGNABLE   = "Assignable"
+	HIT_UNASSIGNABLE = "Unassignable"
+	HIT_REVIEWABLE   = "Reviewable"
+	HIT_REVIEWING    = "Reviewing"
+
+	// Assignment statuses. Accepted assignments are in progress, and are not
+	// reported by GetAssignmentsForHIT.
+	ASSIGNMENT_ACCEPTED  = "Accepted"
+	ASSIGNMENT_SUBMITTED = "Submitted"
+	ASSIGNMENT_APPROVED  = "Approved"
+	ASSIGNMENT_REJECTED  = "Rejected"
+
+	// Error codes reported by the simulator, beyond those defined by amt
+	CODE_HIT_TYPE_NOT_FOUND         = "AWS.MechanicalTurk.HITTypeDoesNotExist"
+	CODE_INVALID_ASSIGNMENT_STATE   = "AWS.MechanicalTurk.InvalidAssignmentState"
+	CODE_INVALID_HIT_STATE          = "AWS.MechanicalTurk.InvalidHITState"
+	CODE_INVALID_PARAMETER          = "AWS.MechanicalTurk.InvalidParameterValue"
+	CODE_QUALIFICATION_NOT_FOUND    = "AWS.MechanicalTurk.QualificationDoesNotExist"
+	CODE_QUAL_REQUEST_NOT_FOUND     = "AWS.MechanicalTurk.QualificationRequestDoesNotExist"
+	CODE_QUALIFICATION_TYPE_MISSING = "AWS.MechanicalTurk.QualificationTypeDoesNotExist"
+	CODE_WORKER_BLOCKED             = "AWS.MechanicalTurk.WorkerBlocked"
+)
+
+// Simulator is an in-memory Mechanical Turk which implements amt.AmtClient.
+// It is safe for concurrent use.
+type Simulator struct {
+	mu            sync.Mutex
+	now           time.Time
+	balance       float64
+	nextId        int
+	hitTypes      map[string]*hitType
+	hitTypeKeys   map[string]string
+	hits          map[string]*hit
+	assignments   map[string]*assignment
+	qualTypes     map[string]*qualType
+	quals         map[qualKey]*qualification
+	qualRequests  map[string]*qualRequest
+	blocks        map[string]string
+	bonuses       []*bonus
+	tokens        map[string]string
+	workers       []*worker
+	earnings      map[string]float64
+	stats         map[statKey]float64
+	notifications []Notification
+}
+
+// Notification is a message sent to workers through NotifyWorkers.
+type Notification struct {
+	Subject, MessageText string
+	WorkerIds            []string
+}
+
+type hitType struct {
+	id                 string
+	title, description string
+	keywords           string
+	reward             float64
+	assignmentDuration time.Duration
+	autoApprovalDelay  time.Duration
+	quals              []*amtgen.TQualificationRequirement
+}
+
+type hit struct {
+	id, hitTypeId  string
+	question       string
+	layoutId       string
+	annotation     string
+	created        time.Time
+	expiration     time.Time
+	maxAssignments int
+	reviewing      bool
+	assignments    []*assignment
+}
+
+type assignment struct {
+	id, hitId, workerId string
+	status              string
+	answer              string
+	feedback            string
+	accepted, deadline  time.Time
+	submitted           time.Time
+	autoApproval        time.Time
+	approved, rejected  time.Time
+}
+
+type qualType struct {
+	id, name, description string
+	keywords              string
+	status                string
+	retryDelay            int64
+	test, answerKey       string
+	testDuration          int64
+	autoGranted           bool
+	autoGrantedValue      int
+	created               time.Time
+}
+
+type qualKey struct {
+	qualTypeId, workerId string
+}
+
+type qualification struct {
+	qualTypeId, workerId string
+	value                int
+	granted              time.Time
+	revoked              bool
+}
+
+type qualRequest struct {
+	id, qualTypeId, workerId string
+	answer                   string
+	submitted                time.Time
+}
+
+type statKey struct {
+	workerId, statistic string
+}
+
+type bonus struct {
+	workerId, assignmentId string
+	hitId                  string
+	amount                 float64
+	reason                 string
+	granted                time.Time
+}
+
+type worker struct {
+	id    string
+	model AnswerModel
+}
+
+// New creates a simulator whose account holds the given balance, in USD.
+func New(balance float64) *Simulator {
+	return &Simulator{
+		now:          time.Now().UTC().Truncate(time.Second),
+		balance:      balance,
+		hitTypes:     make(map[string]*hitType),
+		hitTypeKeys:  make(map[string]string),
+		hits:         make(map[string]*hit),
+		assignments:  make(map[string]*assignment),
+		qualTypes:    make(map[string]*qualType),
+		quals:        make(map[qualKey]*qualification),
+		qualRequests: make(map[string]*qualRequest),
+		blocks:       make(map[string]string),
+		tokens:       make(map[string]string),
+		earnings:     make(map[string]float64),
+		stats:        make(map[statKey]float64),
+	}
+}
+
+// Now returns the simulated time.
+func (sim *Simulator) Now() time.Time {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	return sim.now
+}
+
+// Balance returns the available account balance, in USD.
+func (sim *Simulator) Balance() float64 {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	return sim.balance
+}
+
+// Earnings returns the total rewards and bonuses paid to a worker, in USD.
+func (sim *Simulator) Earnings(workerId string) float64 {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	return sim.earnings[workerId]
+}
+
+// Notifications returns the messages sent through NotifyWorkers.
+func (sim *Simulator) Notifications() []Notification {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	return append([]Notification(nil), sim.notifications...)
+}
+
+// AddWorker registers a simulated worker who answers HITs using the given
+// model.
+func (sim *Simulator) AddWorker(workerId string, model AnswerModel) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.workers = append(sim.workers, &worker{id: workerId, model: model})
+}
+
+// Advance moves the simulated clock forward. HITs whose lifetime ends become
+// unavailable, accepted assignments past their deadline are abandoned, and
+// submitted assignments past their auto-approval time are approved.
+func (sim *Simulator) Advance(d time.Duration) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	sim.now = sim.now.Add(d)
+	for _, h := range sim.hits {
+		var kept []*assignment
+		for _, a := range h.assignments {
+			if a.status == ASSIGNMENT_ACCEPTED && !sim.now.Before(a.deadline) {
+				delete(sim.assignments, a.id)
+				sim.count(a.workerId, "NumberAssignmentsAbandoned", 1)
+				continue
+			}
+			if a.status == ASSIGNMENT_SUBMITTED && !sim.now.Before(a.autoApproval) {
+				sim.approve(a, "")
+			}
+			kept = append(kept, a)
+		}
+		h.assignments = kept
+	}
+}
+
+// Work lets every registered worker attempt each HIT available to them, in
+// the order the HITs were created. Each worker's model decides whether to
+// take the HIT and how to answer it; taken HITs are accepted and submitted
+// immediately. Returns the number of assignments submitted.
+func (sim *Simulator) Work() int {
+	sim.mu.Lock()
+	workers := append([]*worker(nil), sim.workers...)
+	sim.mu.Unlock()
+
+	var submitted int
+	for _, w := range workers {
+		for _, hitId := range sim.availableHITs(w.id) {
+			sim.mu.Lock()
+			h, ok := sim.hits[hitId]
+			var view *amtgen.Thit
+			if ok {
+				view = sim.hitView(h)
+			}
+			sim.mu.Unlock()
+			if !ok {
+				continue
+			}
+			answer, ok := w.model.Answer(w.id, view)
+			if !ok {
+				continue
+			}
+			assignmentId, err := sim.Accept(w.id, hitId)
+			if err != nil {
+				continue
+			}
+			if sim.Submit(assignmentId, answer) == nil {
+				submitted++
+			}
+		}
+	}
+	return submitted
+}
+
+// Accept assigns a HIT to a worker, as though the worker had accepted it on
+// the AMT website. Returns the new assignment's ID.
+func (sim *Simulator) Accept(workerId, hitId string) (string, error) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	h, ok := sim.hits[hitId]
+	if !ok {
+		return "", hitNotFound("Accept", hitId)
+	} else if _, blocked := sim.blocks[workerId]; blocked {
+		return "", simError("Accept", CODE_WORKER_BLOCKED,
+			"Worker %s is blocked", workerId)
+	} else if !sim.canWork(workerId, h) {
+		return "", simError("Accept", CODE_INVALID_HIT_STATE,
+			"HIT %s is not available to worker %s", hitId, workerId)
+	}
+	ht := sim.hitTypes[h.hitTypeId]
+	a := &assignment{
+		id:       sim.newId("ASSIGNMENT"),
+		hitId:    hitId,
+		workerId: workerId,
+		status:   ASSIGNMENT_ACCEPTED,
+		accepted: sim.now,
+		deadline: sim.now.Add(ht.assignmentDuration),
+	}
+	h.assignments = append(h.assignments, a)
+	sim.assignments[a.id] = a
+	sim.count(workerId, "NumberAssignmentsAccepted", 1)
+	return a.id, nil
+}
+
+// Submit completes an accepted assignment with the given answer XML.
+func (sim *Simulator) Submit(assignmentId, answer string) error {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	a, ok := sim.assignments[assignmentId]
+	if !ok {
+		return assignmentNotFound("Submit", assignmentId)
+	} else if a.status != ASSIGNMENT_ACCEPTED {
+		return simError("Submit", CODE_INVALID_ASSIGNMENT_STATE,
+			"Assignment %s has already been submitted", assignmentId)
+	}
+	ht := sim.hitTypes[sim.hits[a.hitId].hitTypeId]
+	a.status = ASSIGNMENT_SUBMITTED
+	a.answer = answer
+	a.submitted = sim.now
+	a.autoApproval = sim.now.Add(ht.autoApprovalDelay)
+	sim.count(a.workerId, "NumberAssignmentsSubmitted", 1)
+	return nil
+}
+
+// Return abandons an accepted assignment, making its slot available again.
+func (sim *Simulator) Return(assignmentId string) error {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	a, ok := sim.assignments[assignmentId]
+	if !ok {
+		return assignmentNotFound("Return", assignmentId)
+	} else if a.status != ASSIGNMENT_ACCEPTED {
+		return simError("Return", CODE_INVALID_ASSIGNMENT_STATE,
+			"Assignment %s has already been submitted", assignmentId)
+	}
+	h := sim.hits[a.hitId]
+	for i, other := range h.assignments {
+		if other == a {
+			h.assignments = append(h.assignments[:i], h.assignments[i+1:]...)
+			break
+		}
+	}
+	delete(sim.assignments, assignmentId)
+	sim.count(a.workerId, "NumberAssignmentsReturned", 1)
+	return nil
+}
+
+// RequestQualification submits a worker's request for a Qualification, with
+// the worker's answers to its test, if any. Auto-granted Qualifications are
+// granted immediately. Returns the ID of the pending request, or "" if the
+// Qualification was granted.
+func (sim *Simulator) RequestQualification(workerId, qualificationTypeId,
+	answer string) (string, error) {
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	qt, ok := sim.qualTypes[qualificationTypeId]
+	if !ok {
+		return "", qualTypeNotFound("RequestQualification", qualificationTypeId)
+	}
+	if qt.autoGranted {
+		sim.grant(qualificationTypeId, workerId, qt.autoGrantedValue)
+		return "", nil
+	}
+	req := &qualRequest{
+		id:         sim.newId("QUALREQUEST"),
+		qualTypeId: qualificationTypeId,
+		workerId:   workerId,
+		answer:     answer,
+		submitted:  sim.now,
+	}
+	sim.qualRequests[req.id] = req
+	return req.id, nil
+}
+
+// Returns the IDs of HITs the worker could accept right now, in creation
+// order.
+func (sim *Simulator) availableHITs(workerId string) []string {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	if _, blocked := sim.blocks[workerId]; blocked {
+		return nil
+	}
+	var ids []string
+	for _, h := range sim.sortedHITs() {
+		if sim.canWork(workerId, h) {
+			ids = append(ids, h.id)
+		}
+	}
+	return ids
+}
+
+// Decide whether a worker may accept an assignment for a HIT.
+func (sim *Simulator) canWork(workerId string, h *hit) bool {
+	if sim.hitStatus(h) != HIT_ASSIGNABLE {
+		return false
+	}
+	for _, a := range h.assignments {
+		if a.workerId == workerId {
+			return false
+		}
+	}
+	return sim.qualifies(workerId, sim.hitTypes[h.hitTypeId].quals)
+}
+
+// Decide whether a worker meets a set of Qualification requirements.
+// Requirements on Qualification types unknown to the simulator, such as
+// Amazon's system Qualifications, are assumed to be met.
+func (sim *Simulator) qualifies(workerId string,
+	reqs []*amtgen.TQualificationRequirement) bool {
+
+	for _, req := range reqs {
+		if _, known := sim.qualTypes[string(req.QualificationTypeId)]; !known {
+			continue
+		}
+		q := sim.quals[qualKey{string(req.QualificationTypeId), workerId}]
+		has := q != nil && !q.revoked
+		switch string(req.Comparator) {
+		case "Exists":
+			if !has {
+				return false
+			}
+		case "DoesNotExist":
+			if has {
+				return false
+			}
+		default:
+			if !has || !compare(string(req.Comparator), q.value, req.IntegerValues) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Apply a Qualification requirement comparator to a worker's score.
+func compare(comparator string, value int, targets []xsdt.Int) bool {
+	if len(targets) == 0 {
+		return false
+	}
+	target := int(targets[0])
+	switch comparator {
+	case "LessThan":
+		return value < target
+	case "LessThanOrEqualTo":
+		return value <= target
+	case "GreaterThan":
+		return value > target
+	case "GreaterThanOrEqualTo":
+		return value >= target
+	case "EqualTo":
+		return value == target
+	case "NotEqualTo":
+		return value != target
+	case "In", "NotIn":
+		var found bool
+		for _, t := range targets {
+			if int(t) == value {
+				found = true
+			}
+		}
+		return found == (comparator == "In")
+	}
+	return false
+}
+
+// Compute the status of a HIT at the current simulated time.
+func (sim *Simulator) hitStatus(h *hit) string {
+	if h.reviewing {
+		return HIT_REVIEWING
+	}
+	available, pending, _ := h.counts()
+	if available > 0 && sim.now.Before(h.expiration) {
+		return HIT_ASSIGNABLE
+	} else if pending > 0 {
+		return HIT_UNASSIGNABLE
+	}
+	return HIT_REVIEWABLE
+}
+
+// Count a HIT's available, pending and completed assignment slots.
+func (h *hit) counts() (available, pending, completed int) {
+	for _, a := range h.assignments {
+		if a.status == ASSIGNMENT_ACCEPTED {
+			pending++
+		} else {
+			completed++
+		}
+	}
+	available = h.maxAssignments - pending - completed
+	if available < 0 {
+		available = 0
+	}
+	return
+}
+
+// Build the AMT representation of a HIT.
+func (sim *Simulator) hitView(h *hit) *amtgen.Thit {
+	ht := sim.hitTypes[h.hitTypeId]
+	available, pending, completed := h.counts()
+	view := &amtgen.Thit{}
+	view.Request = validRequest()
+	view.HITId = xsdt.String(h.id)
+	view.HITTypeId = xsdt.String(ht.id)
+	view.HITGroupId = xsdt.String(ht.id)
+	view.HITLayoutId = xsdt.String(h.layoutId)
+	view.CreationTime = xsdt.DateTime(amt.FormatTime(h.created))
+	view.Expiration = xsdt.DateTime(amt.FormatTime(h.expiration))
+	view.Title = xsdt.String(ht.title)
+	view.Description = xsdt.String(ht.description)
+	view.Keywords = xsdt.String(ht.keywords)
+	view.Question = xsdt.String(h.question)
+	view.HITStatus = amtgen.THITStatus(sim.hitStatus(h))
+	view.HITReviewStatus = amtgen.THITReviewStatus("NotReviewed")
+	view.MaxAssignments = xsdt.Int(h.maxAssignments)
+	view.Reward = price(ht.reward)
+	view.AssignmentDurationInSeconds = xsdt.Long(ht.assignmentDuration / time.Second)
+	view.AutoApprovalDelayInSeconds = xsdt.Long(ht.autoApprovalDelay / time.Second)
+	view.RequesterAnnotation = xsdt.String(h.annotation)
+	view.QualificationRequirements = ht.quals
+	view.NumberOfAssignmentsAvailable = xsdt.Int(available)
+	view.NumberOfAssignmentsPending = xsdt.Int(pending)
+	view.NumberOfAssignmentsCompleted = xsdt.Int(completed)
+	return view
+}
+
+// Build the AMT representation of an assignment.
+func (sim *Simulator) assignmentView(a *assignment) *amtgen.TAssignment {
+	view := &amtgen.TAssignment{}
+	view.AssignmentId = xsdt.String(a.id)
+	view.HITId = xsdt.String(a.hitId)
+	view.WorkerId = xsdt.String(a.workerId)
+	view.AssignmentStatus = amtgen.TAssignmentStatus(a.status)
+	view.AcceptTime = formatTime(a.accepted)
+	view.Deadline = formatTime(a.deadline)
+	view.SubmitTime = formatTime(a.submitted)
+	view.AutoApprovalTime = formatTime(a.autoApproval)
+	view.ApprovalTime = formatTime(a.approved)
+	view.RejectionTime = formatTime(a.rejected)
+	view.Answer = xsdt.String(a.answer)
+	view.RequesterFeedback = xsdt.String(a.feedback)
+	return view
+}
+
+// Build the AMT representation of a Qualification type.
+func (qt *qualType) view() *amtgen.TQualificationType {
+	view := &amtgen.TQualificationType{}
+	view.Request = validRequest()
+	view.QualificationTypeId = xsdt.String(qt.id)
+	view.CreationTime = xsdt.DateTime(amt.FormatTime(qt.created))
+	view.Name = xsdt.String(qt.name)
+	view.Description = xsdt.String(qt.description)
+	view.Keywords = xsdt.String(qt.keywords)
+	view.QualificationTypeStatus = amtgen.TQualificationTypeStatus(qt.status)
+	view.RetryDelayInSeconds = xsdt.Long(qt.retryDelay)
+	view.Test = xsdt.String(qt.test)
+	view.AnswerKey = xsdt.String(qt.answerKey)
+	view.TestDurationInSeconds = xsdt.Long(qt.testDuration)
+	view.AutoGranted = xsdt.Boolean(qt.autoGranted)
+	view.AutoGrantedValue = xsdt.Int(qt.autoGrantedValue)
+	view.IsRequestable = xsdt.Boolean(qt.test != "" || qt.autoGranted)
+	return view
+}
+
+// Build the AMT representation of a Qualification.
+func (q *qualification) view() *amtgen.TQualification {
+	view := &amtgen.TQualification{}
+	view.Request = validRequest()
+	view.QualificationTypeId = xsdt.String(q.qualTypeId)
+	view.SubjectId = xsdt.String(q.workerId)
+	view.GrantTime = xsdt.DateTime(amt.FormatTime(q.granted))
+	view.IntegerValue = xsdt.Int(q.value)
+	if q.revoked {
+		view.Status = amtgen.TQualificationStatus("Revoked")
+	} else {
+		view.Status = amtgen.TQualificationStatus("Granted")
+	}
+	return view
+}
+
+// Approve a submitted or rejected assignment, paying the worker.
+func (sim *Simulator) approve(a *assignment, feedback string) {
+	reward := sim.hitTypes[sim.hits[a.hitId].hitTypeId].reward
+	a.status = ASSIGNMENT_APPROVED
+	a.approved = sim.now
+	a.rejected = time.Time{}
+	if feedback != "" {
+		a.feedback = feedback
+	}
+	sim.earnings[a.workerId] += reward
+	sim.count(a.workerId, "NumberAssignmentsApproved", 1)
+	sim.count(a.workerId, "TotalRewardPayout", reward)
+	sim.count(a.workerId, "TotalRewardFeePayout",
+		sim.assignmentCost(sim.hits[a.hitId], 1).Commission)
+}
+
+// Add to a statistic, both for the requester and for the given worker.
+func (sim *Simulator) count(workerId, statistic string, delta float64) {
+	sim.stats[statKey{"", statistic}] += delta
+	if workerId != "" {
+		sim.stats[statKey{workerId, statistic}] += delta
+	}
+}
+
+// Grant a Qualification to a worker, or update an existing score.
+func (sim *Simulator) grant(qualTypeId, workerId string, value int) {
+	key := qualKey{qualTypeId, workerId}
+	if q, ok := sim.quals[key]; ok && !q.revoked {
+		q.value = value
+		return
+	}
+	sim.quals[key] = &qualification{
+		qualTypeId: qualTypeId,
+		workerId:   workerId,
+		value:      value,
+		granted:    sim.now,
+	}
+}
+
+// Compute the cost of paying for some of a HIT's assignments, including
+// commission, as amt.BudgetGuard estimates it.
+func (sim *Simulator) assignmentCost(h *hit, assignments int) amt.CostEstimate {
+	reward := sim.hitTypes[h.hitTypeId].reward
+	return amt.EstimateAssignmentCost(float32(reward), assignments,
+		h.maxAssignments)
+}
+
+// Deduct an amount from the balance, or fail if funds are insufficient.
+func (sim *Simulator) charge(operation string, amount float64) error {
+	if amount > sim.balance+0.000001 {
+		return simError(operation, amt.CODE_INSUFFICIENT_FUNDS,
+			"This operation costs $%.2f, but your balance is $%.2f", amount,
+			sim.balance)
+	}
+	sim.balance = roundCents(sim.balance - amount)
+	return nil
+}
+
+// Refund the cost of a HIT's assignment slots which were never filled.
+func (sim *Simulator) refundUnfilled(h *hit) {
+	available, _, _ := h.counts()
+	sim.balance = roundCents(sim.balance + sim.assignmentCost(h, available).Total())
+	h.maxAssignments -= available
+}
+
+// Return HITs sorted by creation order.
+func (sim *Simulator) sortedHITs() []*hit {
+	var hits []*hit
+	for _, h := range sim.hits {
+		hits = append(hits, h)
+	}
+	sort.Sort(hitsByCreation(hits))
+	return hits
+}
+
+// Generate a new unique ID with the given prefix.
+func (sim *Simulator) newId(prefix string) string {
+	sim.nextId++
+	return fmt.Sprintf("SIM%s%012d", prefix, sim.nextId)
+}
+
+type hitsByCreation []*hit
+
+func (hits hitsByCreation) Len() int      { return len(hits) }
+func (hits hitsByCreation) Swap(i, j int) { hits[i], hits[j] = hits[j], hits[i] }
+func (hits hitsByCreation) Less(i, j int) bool {
+	if hits[i].created.Equal(hits[j].created) {
+		return hits[i].id < hits[j].id
+	}
+	return hits[i].created.Before(hits[j].created)
+}
+
+// Select the requested page from a list of n results.
+func page(n, pageSize, pageNumber int) (start, end int) {
+	if pageSize <= 0 {
+		pageSize = DEFAULT_PAGE_SIZE
+	} else if pageSize > amt.MAX_PAGE_SIZE {
+		pageSize = amt.MAX_PAGE_SIZE
+	}
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+	start = (pageNumber - 1) * pageSize
+	if start > n {
+		start = n
+	}
+	end = start + pageSize
+	if end > n {
+		end = n
+	}
+	return start, end
+}
+
+func roundCents(amount float64) float64 {
+	return math.Floor(amount*100+0.5) / 100
+}
+
+func price(amount float64) *amtgen.TPrice {
+	p := &amtgen.TPrice{}
+	p.Amount = xsdt.Decimal(fmt.Sprintf("%.2f", amount))
+	p.CurrencyCode = amt.CURRENCY_USD
+	p.FormattedPrice = xsdt.String(fmt.Sprintf("$%.2f", amount))
+	return p
+}
+
+func formatTime(t time.Time) xsdt.DateTime {
+	if t.IsZero() {
+		return ""
+	}
+	return xsdt.DateTime(amt.FormatTime(t))
+}
+
+func validRequest() *amtgen.TxsdRequest {
+	req := &amtgen.TxsdRequest{}
+	req.IsValid = "True"
+	return req
+}
+
+func simError(operation, code, format string,
+	args ...interface{}) *amt.APIError {
+
+	return &amt.APIError{
+		Operation: operation,
+		Code:      code,
+		Message:   fmt.Sprintf(format, args...),
+	}
+}
+
+func hitNotFound(operation, hitId string) error {
+	return simError(operation, amt.CODE_HIT_NOT_FOUND,
+		"Hit %s does not exist.", hitId)
+}
+
+func assignmentNotFound(operation, assignmentId string) error {
+	return simError(operation, amt.CODE_ASSIGNMENT_NOT_FOUND,
+		"Assignment %s does not exist.", assignmentId)
+}
+
+func qualTypeNotFound(operation, qualTypeId string) error {
+	return simError(operation, CODE_QUALIFICATION_TYPE_MISSING,
+		"Qualification type %s does not exist.", qualTypeId)
+}
@@ -0,0 +1,310 @@
+package sim
+
+import (
+	"errors"
+	"github.com/jesand/crowds/amt"
+	amtgen "github.com/jesand/crowds/amt/gen/mechanicalturk.amazonaws.com/AWSMechanicalTurk/2014-08-15/AWSMechanicalTurkRequester.xsd_go"
+	xsdt "github.com/metaleap/go-xsd/types"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A QuestionForm with one selection question and one free-text question
+func newTestQuestion() string {
+	var form amt.QuestionForm
+	form.AddQuestion("color", "Color", true)
+	form.AddTextContent("What color is the sky?")
+	form.AddSelectionAnswerTextSelection("blue", "Blue")
+	form.AddSelectionAnswerTextSelection("green", "Green")
+	form.AddQuestion("comment", "Comment", false)
+	form.AddTextContent("Any comments?")
+	form.AddFreeTextAnswerDefaultText("")
+	xml, _ := amt.EncodeQuestion(&form)
+	return string(xml)
+}
+
+// Create a HIT with the test question
+func createTestHIT(sim *Simulator, maxAssignments int,
+	quals []*amtgen.TQualificationRequirement) (string, error) {
+	resp, err := sim.CreateHIT("Sky", "Name the sky's color", newTestQuestion(),
+		"", nil, 0.5, 60, 3600, maxAssignments, 600, []string{"sky"}, quals,
+		nil, nil, "", "")
+	if err != nil {
+		return "", err
+	}
+	return string(resp.Hits[0].HITId), nil
+}
+
+func TestSimulator(t *testing.T) {
+	Convey("Given a simulator with $10 and two workers", t, func() {
+		sim := New(10)
+		sim.AddWorker("W1", FixedAnswers(map[string]string{
+			"color":   "blue",
+			"comment": "easy",
+		}))
+		sim.AddWorker("W2", RandomSelections(rand.New(rand.NewSource(1))))
+
+		Convey("When I create a HIT with two assignments", func() {
+			hitId, err := createTestHIT(sim, 2, nil)
+			So(err, ShouldBeNil)
+
+			Convey("Then the reward and commission are deducted", func() {
+				So(sim.Balance(), ShouldEqual, 8.8)
+			})
+			Convey("Then the HIT is Assignable", func() {
+				resp, err := sim.GetHIT(hitId)
+				So(err, ShouldBeNil)
+				So(string(resp.Hits[0].HITStatus), ShouldEqual, HIT_ASSIGNABLE)
+				So(int(resp.Hits[0].NumberOfAssignmentsAvailable), ShouldEqual, 2)
+			})
+
+			Convey("When the workers do the work", func() {
+				So(sim.Work(), ShouldEqual, 2)
+
+				Convey("Then the HIT is Reviewable", func() {
+					resp, err := sim.GetReviewableHITs("", "", "", true, 0, 0)
+					So(err, ShouldBeNil)
+					result := resp.GetReviewableHITsResults[0]
+					So(int(result.TotalNumResults), ShouldEqual, 1)
+					So(string(result.Hits[0].HITId), ShouldEqual, hitId)
+				})
+				Convey("Then both assignments are Submitted with answers", func() {
+					resp, err := sim.GetAssignmentsForHIT(hitId, nil, "", true, 0, 0)
+					So(err, ShouldBeNil)
+					assignments := resp.GetAssignmentsForHITResults[0].Assignments
+					So(len(assignments), ShouldEqual, 2)
+					So(string(assignments[0].WorkerId), ShouldEqual, "W1")
+					So(string(assignments[0].AssignmentStatus), ShouldEqual, ASSIGNMENT_SUBMITTED)
+					So(string(assignments[0].Answer), ShouldContainSubstring,
+						"<SelectionIdentifier>blue</SelectionIdentifier>")
+					So(string(assignments[0].Answer), ShouldContainSubstring,
+						"<FreeText>easy</FreeText>")
+					So(string(assignments[1].Answer), ShouldContainSubstring,
+						"<QuestionIdentifier>color</QuestionIdentifier>")
+				})
+				Convey("Then the workers do not work on it again", func() {
+					So(sim.Work(), ShouldEqual, 0)
+				})
+
+				Convey("When I approve one assignment and reject the other", func() {
+					resp, _ := sim.GetAssignmentsForHIT(hitId, nil, "", true, 0, 0)
+					assignments := resp.GetAssignmentsForHITResults[0].Assignments
+					_, err := sim.ApproveAssignment(string(assignments[0].AssignmentId), "Thanks")
+					So(err, ShouldBeNil)
+					_, err = sim.RejectAssignment(string(assignments[1].AssignmentId), "Wrong")
+					So(err, ShouldBeNil)
+
+					Convey("Then the approved worker is paid", func() {
+						So(sim.Earnings("W1"), ShouldEqual, 0.5)
+						So(sim.Earnings("W2"), ShouldEqual, 0.0)
+					})
+					Convey("Then the rejected assignment is refunded", func() {
+						So(sim.Balance(), ShouldEqual, 9.4)
+					})
+					Convey("Then the assignment cannot be approved twice", func() {
+						_, err := sim.ApproveAssignment(string(assignments[0].AssignmentId), "")
+						var apiErr *amt.APIError
+						So(errors.As(err, &apiErr), ShouldBeTrue)
+						So(apiErr.Code, ShouldEqual, CODE_INVALID_ASSIGNMENT_STATE)
+					})
+
+					Convey("When I dispose of the HIT", func() {
+						_, err := sim.DisposeHIT(hitId)
+						So(err, ShouldBeNil)
+
+						Convey("Then it no longer exists", func() {
+							_, err := sim.GetHIT(hitId)
+							So(errors.Is(err, amt.ErrHITNotFound), ShouldBeTrue)
+						})
+					})
+				})
+			})
+
+			Convey("When the HIT cannot be disposed while Assignable", func() {
+				_, err := sim.DisposeHIT(hitId)
+				var apiErr *amt.APIError
+				So(errors.As(err, &apiErr), ShouldBeTrue)
+				So(apiErr.Code, ShouldEqual, CODE_INVALID_HIT_STATE)
+			})
+
+			Convey("When I disable the HIT before it is worked", func() {
+				_, err := sim.DisableHIT(hitId)
+				So(err, ShouldBeNil)
+
+				Convey("Then its cost is refunded", func() {
+					So(sim.Balance(), ShouldEqual, 10.0)
+				})
+			})
+		})
+
+		Convey("When a worker accepts but does not submit", func() {
+			hitId, _ := createTestHIT(sim, 1, nil)
+			_, err := sim.Accept("W3", hitId)
+			So(err, ShouldBeNil)
+
+			Convey("Then the HIT is Unassignable", func() {
+				resp, _ := sim.GetHIT(hitId)
+				So(string(resp.Hits[0].HITStatus), ShouldEqual, HIT_UNASSIGNABLE)
+			})
+
+			Convey("When the assignment duration passes", func() {
+				sim.Advance(2 * time.Minute)
+
+				Convey("Then the HIT is Assignable again", func() {
+					resp, _ := sim.GetHIT(hitId)
+					So(string(resp.Hits[0].HITStatus), ShouldEqual, HIT_ASSIGNABLE)
+				})
+			})
+		})
+
+		Convey("When I extend a HIT which has expired", func() {
+			hitId, _ := createTestHIT(sim, 1, nil)
+			sim.Advance(2 * time.Hour)
+			_, err := sim.ExtendHIT(hitId, 0, 600, "")
+			So(err, ShouldBeNil)
+
+			Convey("Then it is Assignable until the increment from now passes", func() {
+				resp, _ := sim.GetHIT(hitId)
+				So(string(resp.Hits[0].HITStatus), ShouldEqual, HIT_ASSIGNABLE)
+				sim.Advance(11 * time.Minute)
+				resp, _ = sim.GetHIT(hitId)
+				So(string(resp.Hits[0].HITStatus), ShouldNotEqual, HIT_ASSIGNABLE)
+			})
+		})
+
+		Convey("When submitted work is not reviewed before the auto-approval delay", func() {
+			hitId, _ := createTestHIT(sim, 1, nil)
+			sim.Work()
+			sim.Advance(11 * time.Minute)
+
+			Convey("Then it is approved automatically", func() {
+				resp, _ := sim.GetAssignmentsForHIT(hitId, nil, "", true, 0, 0)
+				assignments := resp.GetAssignmentsForHITResults[0].Assignments
+				So(string(assignments[0].AssignmentStatus), ShouldEqual, ASSIGNMENT_APPROVED)
+				So(sim.Earnings("W1"), ShouldEqual, 0.5)
+			})
+		})
+
+		Convey("When a HIT requires a Qualification only W1 holds", func() {
+			qresp, err := sim.CreateQualificationType("Sky expert", "Knows skies",
+				nil, 0, "Active", "", "", 0, false, 0)
+			So(err, ShouldBeNil)
+			qualId := string(qresp.QualificationTypes[0].QualificationTypeId)
+			_, err = sim.AssignQualification(qualId, "W1", 90, false)
+			So(err, ShouldBeNil)
+			req := &amtgen.TQualificationRequirement{}
+			req.QualificationTypeId = xsdt.String(qualId)
+			req.Comparator = "GreaterThan"
+			req.IntegerValues = []xsdt.Int{50}
+			hitId, err := createTestHIT(sim, 2, []*amtgen.TQualificationRequirement{req})
+			So(err, ShouldBeNil)
+
+			Convey("Then only W1 works on it", func() {
+				So(sim.Work(), ShouldEqual, 1)
+				resp, _ := sim.GetAssignmentsForHIT(hitId, nil, "", true, 0, 0)
+				assignments := resp.GetAssignmentsForHITResults[0].Assignments
+				So(string(assignments[0].WorkerId), ShouldEqual, "W1")
+			})
+		})
+
+		Convey("When a worker is blocked", func() {
+			_, err := sim.BlockWorker("W1", "Spam")
+			So(err, ShouldBeNil)
+			createTestHIT(sim, 2, nil)
+
+			Convey("Then they do no work", func() {
+				So(sim.Work(), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When I create a HIT with ten assignments", func() {
+			_, err := createTestHIT(sim, 10, nil)
+			So(err, ShouldBeNil)
+
+			Convey("Then the higher commission is deducted, as amt estimates it", func() {
+				So(sim.Balance(), ShouldEqual, 10-amt.EstimateHITCost(0.5, 10).Total())
+				So(sim.Balance(), ShouldEqual, 3.0)
+			})
+		})
+
+		Convey("When I create a HIT the balance cannot cover", func() {
+			_, err := sim.CreateHIT("Sky", "Name the sky's color", newTestQuestion(),
+				"", nil, 5, 60, 3600, 2, 600, nil, nil, nil, nil, "", "")
+
+			Convey("Then I get an InsufficientFunds error", func() {
+				So(errors.Is(err, amt.ErrInsufficientFunds), ShouldBeTrue)
+				So(sim.Balance(), ShouldEqual, 10.0)
+			})
+		})
+
+		Convey("When I reuse a UniqueRequestToken", func() {
+			resp, err := sim.CreateHIT("Sky", "Name the sky's color", newTestQuestion(),
+				"", nil, 0.5, 60, 3600, 1, 600, nil, nil, nil, nil, "", "token")
+			So(err, ShouldBeNil)
+			_, err = sim.CreateHIT("Sky", "Name the sky's color", newTestQuestion(),
+				"", nil, 0.5, 60, 3600, 1, 600, nil, nil, nil, nil, "", "token")
+
+			Convey("Then the error identifies the original HIT", func() {
+				var apiErr *amt.APIError
+				So(errors.As(err, &apiErr), ShouldBeTrue)
+				So(apiErr.Code, ShouldEqual, amt.CODE_DUPLICATE_REQUEST)
+				So(apiErr.Data["HITId"], ShouldEqual, string(resp.Hits[0].HITId))
+				So(sim.Balance(), ShouldEqual, 9.4)
+			})
+		})
+
+		Convey("When I grant a bonus", func() {
+			hitId, _ := createTestHIT(sim, 1, nil)
+			sim.Work()
+			aresp, _ := sim.GetAssignmentsForHIT(hitId, nil, "", true, 0, 0)
+			assignmentId := string(aresp.GetAssignmentsForHITResults[0].Assignments[0].AssignmentId)
+			_, err := sim.GrantBonus("W1", assignmentId, 1, "Great work", "")
+			So(err, ShouldBeNil)
+
+			Convey("Then it is listed and paid for", func() {
+				resp, err := sim.GetBonusPayments(hitId, "", 0, 0)
+				So(err, ShouldBeNil)
+				payments := resp.GetBonusPaymentsResults[0].BonusPayments
+				So(len(payments), ShouldEqual, 1)
+				So(string(payments[0].BonusAmount.Amount), ShouldEqual, "1.00")
+				So(sim.Balance(), ShouldEqual, 8.2)
+			})
+		})
+
+		Convey("When I page through HITs", func() {
+			for i := 0; i < 5; i++ {
+				createTestHIT(sim, 1, nil)
+			}
+			var count int
+			it := amt.SearchAllHITs(sim, "", true, 2)
+			for it.Next() {
+				count++
+			}
+
+			Convey("Then every HIT is returned", func() {
+				So(it.Err(), ShouldBeNil)
+				So(count, ShouldEqual, 5)
+			})
+		})
+	})
+}
+
+func TestRandomSelections(t *testing.T) {
+	Convey("Given a HIT which is not a QuestionForm", t, func() {
+		hit := &amtgen.Thit{}
+		hit.Question = `<HTMLQuestion xmlns="http://mechanicalturk.amazonaws.com/AWSMechanicalTurkDataSchemas/2011-11-11/HTMLQuestion.xsd"></HTMLQuestion>`
+
+		Convey("Then RandomSelections declines it", func() {
+			_, ok := RandomSelections(rand.New(rand.NewSource(1))).Answer("W1", hit)
+			So(ok, ShouldBeFalse)
+		})
+		Convey("Then FixedAnswers submits every answer as free text", func() {
+			answer, ok := FixedAnswers(map[string]string{"a": "1"}).Answer("W1", hit)
+			So(ok, ShouldBeTrue)
+			So(strings.Contains(answer, "<FreeText>1</FreeText>"), ShouldBeTrue)
+		})
+	})
+}
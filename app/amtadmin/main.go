@@ -1,15 +1,23 @@
 package main
 
 import (
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"github.com/docopt/docopt-go"
 	"github.com/jesand/crowds/amt"
+	"github.com/jesand/crowds/amt/server"
+	"github.com/jesand/crowds/amt/sim"
 	xsdt "github.com/metaleap/go-xsd/types"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
 	"os"
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -17,14 +25,26 @@ const (
 
 Usage:
   amtadmin assns --hit=<id> [--status=<str>] [--sort=<field>] [--desc] ` +
-		`[--page=<num>] [--pageSize=<num>] --amt=<path> [--sandbox]
-  amtadmin balance --amt=<path> [--sandbox]
+		`[--page=<num>] [--pageSize=<num>] [--amt=<path>] [--profile=<name>] ` +
+		`[--sandbox]
+  amtadmin balance [--amt=<path>] [--profile=<name>] [--sandbox]
   amtadmin bonus --worker=<id> --assn=<id> --amount=<num> --reason=<str> ` +
-		`--token=<str> --amt=<path> [--sandbox]
-  amtadmin expire [--hit=<id>] [--all] --amt=<path> [--sandbox]
+		`--token=<str> [--amt=<path>] [--profile=<name>] [--sandbox] ` +
+		`[--audit=<path>] [--dry-run]
+  amtadmin expire [--hit=<id>] [--all] [--amt=<path>] [--profile=<name>] ` +
+		`[--sandbox] [--audit=<path>] [--dry-run]
   amtadmin hits [--sort=<field>] [--desc] [--page=<num>] [--pageSize=<num>] ` +
-		`--amt=<path> [--sandbox]
-  amtadmin show [--hit=<id>] [--assn=<id>] --amt=<path> [--sandbox]
+		`[--amt=<path>] [--profile=<name>] [--sandbox]
+  amtadmin preview --question=<file> [--output=<file>] [--addr=<addr>]
+  amtadmin publish --template=<file> --input=<file> --title=<str> ` +
+		`--description=<str> --reward=<num> [--keywords=<str>] ` +
+		`[--duration=<sec>] [--lifetime=<sec>] [--assignments=<num>] ` +
+		`[--manifest=<path>] [--amt=<path>] [--profile=<name>] [--sandbox] ` +
+		`[--audit=<path>] [--dry-run]
+  amtadmin serve-fake [--addr=<addr>] [--balance=<num>] [--workers=<num>] ` +
+		`[--amt=<path>] [--profile=<name>]
+  amtadmin show [--hit=<id>] [--assn=<id>] [--amt=<path>] [--profile=<name>] ` +
+		`[--sandbox]
   amtadmin -h | --help
   amtadmin --version
 
@@ -34,51 +54,125 @@ Options:
   bonus             Grant a worker bonus
   expire            Force-expire the specified HIT
   hits              Find matching HITs
+  preview           Render a question's XML as workers would see it, writing
+                    the page to --output or serving it on --addr
+  publish           Create a HIT for each row of a CSV file, from a template
+                    for an HTMLQuestion or QuestionForm. HITs already created
+                    for a row are recorded in the manifest, and are not
+                    created again
+  serve-fake        Serve a simulated AMT endpoint which accepts requests
+                    signed with the AMT credentials
   show              Display the status of a HIT or Assignment
+  --addr=<addr>     The address to listen on [default: localhost:8080]
   --all             Operate on all applicable objects
   --amount=<num>    The amount of money
-  --amt=<path>      The path to a file containing AMT credentials
+  --amt=<path>      The path to a JSON file containing AMT credentials
+  --assignments=<num>
+                    The number of assignments for each HIT [default: 1]
   --assn=<id>       The ID of the assignment you want to view
+  --audit=<path>    Append a JSON Lines record of each change made to AMT to
+                    this file
+  --balance=<num>   The simulated account balance [default: 10000]
   --desc            Sort results in descending order
+  --description=<str>
+                    The description of the HITs
+  --dry-run         Print the changes which would be made to AMT, without
+                    making them
+  --duration=<sec>  The seconds a worker has to complete an assignment
+                    [default: 3600]
   --hit=<id>        The ID of the HIT you want to view
+  --input=<file>    A CSV file whose header row names the template's fields,
+                    and which has one row for each HIT
+  --keywords=<str>  Comma-separated keywords for the HITs
+  --lifetime=<sec>  The seconds each HIT is available to workers
+                    [default: 86400]
+  --manifest=<path>
+                    The JSON Lines file recording the HIT created for each
+                    row. Defaults to the input file's path plus ".manifest"
+  --output=<file>   The file to write to
   --page=<num>      The page number of results to display [default: 1]
   --pageSize=<num>  The number of results to display per page [default: 10]
+  --profile=<name>  The profile to read from the shared AWS credentials file
+  --question=<file>
+                    The file containing a question's XML
   --reason=<str>    The reason to communicate to the worker
+  --reward=<num>    The reward for each assignment, in USD
   --sandbox         Address the AMT sandbox instead of the production site
   --sort=<field>    The field to sort by. For hits, one of: CreationTime,
                     Enumeration, Expiration, Reward, or Title. For assns, one
                     of: AcceptTime, SubmitTime, or AssignmentStatus.
   --status=<str>    The assignment status to search for. Can be:
                     Submitted, Approved, or Rejected.
+  --template=<file>
+                    An html/template for the task of an HTMLQuestion, or a
+                    text/template of QuestionForm XML, executed with each row
+  --title=<str>     The title of the HITs
   --token=<str>     A unique token to prevent duplicate requests
   --worker=<id>     The id of the worker
+  --workers=<num>   The number of simulated workers, who answer QuestionForm
+                    HITs at random [default: 0]
+
+AMT credentials are read from the --amt file or the --profile profile in
+~/.aws/credentials, whichever is given, trying the file first. If neither is
+given, they are read from the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+environment variables, or else the "default" profile in ~/.aws/credentials.
 `
 )
 
-type AmtCred struct {
-	AccessKey, SecretKey string
-}
-
 func main() {
 
 	// Parse the command line
 	args, _ := docopt.Parse(USAGE, nil, true, "1.0", false)
 
+	// Previews do not need AMT credentials
+	if args["preview"].(bool) {
+		var (
+			questionPath, _ = args["--question"].(string)
+			outputPath, _   = args["--output"].(string)
+			addr, _         = args["--addr"].(string)
+		)
+		RunPreview(questionPath, outputPath, addr)
+		return
+	}
+
 	// Initialize the AMT client
 	var (
-		credPath = args["--amt"].(string)
-		sandbox  = args["--sandbox"].(bool)
-		amtCred  AmtCred
-		client   amt.AmtClient
+		credPath, _  = args["--amt"].(string)
+		profile, _   = args["--profile"].(string)
+		auditPath, _ = args["--audit"].(string)
+		sandbox      = args["--sandbox"].(bool)
+		dryRun, _    = args["--dry-run"].(bool)
+		client       amt.AmtClient
+		opts         []amt.Option
 	)
-	if f, err := os.Open(credPath); err != nil {
-		fmt.Printf("Error: Could not open %s - %v", credPath, err)
-		return
-	} else if err = json.NewDecoder(f).Decode(&amtCred); err != nil {
-		fmt.Printf("Error: Could not parse %s - %v", credPath, err)
+	amtCred, err := amt.DefaultCredentialChain(profile, credPath, "", "").Retrieve()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
 		return
-	} else {
-		client = amt.NewClient(amtCred.AccessKey, amtCred.SecretKey, sandbox)
+	}
+	if auditPath != "" {
+		f, err := os.OpenFile(auditPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			fmt.Printf("Error: Could not open %s - %v\n", auditPath, err)
+			return
+		}
+		defer f.Close()
+		audit := amt.NewAuditLog(f)
+		defer func() {
+			if err := audit.Err(); err != nil {
+				fmt.Printf("Error: Could not write to %s - %v\n", auditPath, err)
+			}
+		}()
+		opts = append(opts, amt.WithAuditLog(audit))
+	}
+	client = amt.NewClient(amtCred.AccessKey, amtCred.SecretKey, sandbox, opts...)
+	if dryRun {
+		dryClient := amt.NewDryRunClient(client, os.Stdout)
+		defer func() {
+			fmt.Printf("Dry run: %d changes were not sent to AMT\n",
+				len(dryClient.Calls()))
+		}()
+		client = dryClient
 	}
 
 	switch {
@@ -148,6 +242,58 @@ func main() {
 			RunHits(client, sort, desc, page, pageSize)
 		}
 
+	case args["publish"].(bool):
+		var (
+			templatePath, _             = args["--template"].(string)
+			inputPath, _                = args["--input"].(string)
+			manifestPath, _             = args["--manifest"].(string)
+			keywords, _                 = args["--keywords"].(string)
+			reward, rewardErr           = strconv.ParseFloat(args["--reward"].(string), 32)
+			duration, durationErr       = strconv.Atoi(args["--duration"].(string))
+			lifetime, lifetimeErr       = strconv.Atoi(args["--lifetime"].(string))
+			assignments, assignmentsErr = strconv.Atoi(args["--assignments"].(string))
+		)
+		if manifestPath == "" {
+			manifestPath = inputPath + ".manifest"
+		}
+		batch := amt.Batch{
+			Title:                       args["--title"].(string),
+			Description:                 args["--description"].(string),
+			Reward:                      float32(reward),
+			AssignmentDurationInSeconds: duration,
+			LifetimeInSeconds:           lifetime,
+			MaxAssignments:              assignments,
+		}
+		if keywords != "" {
+			batch.Keywords = strings.Split(keywords, ",")
+		}
+		if rewardErr != nil {
+			fmt.Printf("Invalid --reward argument\n")
+		} else if durationErr != nil {
+			fmt.Printf("Invalid --duration argument\n")
+		} else if lifetimeErr != nil {
+			fmt.Printf("Invalid --lifetime argument\n")
+		} else if assignmentsErr != nil {
+			fmt.Printf("Invalid --assignments argument\n")
+		} else {
+			RunPublish(client, batch, templatePath, inputPath, manifestPath,
+				sandbox, dryRun)
+		}
+
+	case args["serve-fake"].(bool):
+		var (
+			addr, _             = args["--addr"].(string)
+			balance, balanceErr = strconv.ParseFloat(args["--balance"].(string), 64)
+			workers, workersErr = strconv.Atoi(args["--workers"].(string))
+		)
+		if balanceErr != nil {
+			fmt.Printf("Invalid --balance argument\n")
+		} else if workersErr != nil {
+			fmt.Printf("Invalid --workers argument\n")
+		} else {
+			RunServeFake(amtCred, addr, balance, workers)
+		}
+
 	case args["show"].(bool):
 		hitId, _ := args["--hit"].(string)
 		assnId, _ := args["--assn"].(string)
@@ -211,12 +357,8 @@ func RunAssns(client amt.AmtClient, hitId string, statuses []string, sort string
 
 		fmt.Printf("Error: The AMT request failed: %v\n", err)
 		return
-	} else if len(resp.GetAssignmentsForHITResults) > 0 &&
-		resp.GetAssignmentsForHITResults[0].Request != nil &&
-		resp.GetAssignmentsForHITResults[0].Request.Errors != nil {
-
-		printObject(resp.GetAssignmentsForHITResults[0].Request)
-	} else if len(resp.GetAssignmentsForHITResults[0].Assignments) == 0 {
+	} else if len(resp.GetAssignmentsForHITResults) == 0 ||
+		len(resp.GetAssignmentsForHITResults[0].Assignments) == 0 {
 		fmt.Println("Found no assignments for this HIT")
 	} else {
 		for i, assn := range resp.GetAssignmentsForHITResults[0].Assignments {
@@ -248,28 +390,23 @@ func RunBonus(client amt.AmtClient, workerId, assnId string, amount float32,
 
 func RunExpire(client amt.AmtClient, hitId string, all bool) {
 	if all {
-		const maxHits = 100
-		if resp, err := client.SearchHITs("CreationTime", false, maxHits, 1); err != nil {
+		var (
+			it    = amt.SearchAllHITs(client, "CreationTime", false, 0)
+			found bool
+		)
+		for it.Next() {
+			found = true
+			hit := it.HIT()
+			if hit.HITStatus == "Assignable" {
+				fmt.Printf("Expire HIT %q with %d available assignments\n",
+					hit.HITId, hit.NumberOfAssignmentsAvailable)
+				RunExpire(client, string(hit.HITId), false)
+			}
+		}
+		if err := it.Err(); err != nil {
 			fmt.Printf("Error: The AMT request failed: %v\n", err)
-			return
-		} else if len(resp.SearchHITsResults) > 0 &&
-			resp.SearchHITsResults[0].Request != nil &&
-			resp.SearchHITsResults[0].Request.Errors != nil {
-
-			printObject(resp.SearchHITsResults[0].Request)
-		} else if len(resp.SearchHITsResults[0].Hits) == 0 {
+		} else if !found {
 			fmt.Println("Found no HITs for this account")
-		} else {
-			for _, hit := range resp.SearchHITsResults[0].Hits {
-				if hit.HITStatus == "Assignable" {
-					fmt.Printf("Expire HIT %q with %d available assignments\n",
-						hit.HITId, hit.NumberOfAssignmentsAvailable)
-					RunExpire(client, string(hit.HITId), false)
-				}
-			}
-			if len(resp.SearchHITsResults[0].Hits) == maxHits {
-				fmt.Println("Retrieved the maximum number of HITs. Repeat the command to find any remaining HITs.")
-			}
 		}
 	} else if resp, err := client.ForceExpireHIT(hitId); err != nil {
 		fmt.Printf("Error: Could not expire HIT - %v", err)
@@ -282,12 +419,8 @@ func RunHits(client amt.AmtClient, sort string, desc bool, page, pageSize int) {
 	if resp, err := client.SearchHITs(sort, !desc, pageSize, page); err != nil {
 		fmt.Printf("Error: The AMT request failed: %v\n", err)
 		return
-	} else if len(resp.SearchHITsResults) > 0 &&
-		resp.SearchHITsResults[0].Request != nil &&
-		resp.SearchHITsResults[0].Request.Errors != nil {
-
-		printObject(resp.SearchHITsResults[0].Request)
-	} else if len(resp.SearchHITsResults[0].Hits) == 0 {
+	} else if len(resp.SearchHITsResults) == 0 ||
+		len(resp.SearchHITsResults[0].Hits) == 0 {
 		fmt.Println("Found no HITs for this account")
 	} else {
 		for i, hit := range resp.SearchHITsResults[0].Hits {
@@ -298,16 +431,131 @@ func RunHits(client amt.AmtClient, sort string, desc bool, page, pageSize int) {
 	}
 }
 
+func RunPreview(questionPath, outputPath, addr string) {
+	render := func(w io.Writer) error {
+		questionXml, err := ioutil.ReadFile(questionPath)
+		if err != nil {
+			return err
+		}
+		return amt.RenderPreviewXML(w, questionXml)
+	}
+	if outputPath != "" {
+		var page bytes.Buffer
+		if err := render(&page); err != nil {
+			fmt.Printf("Error: Could not preview %s - %v\n", questionPath, err)
+		} else if err := ioutil.WriteFile(outputPath, page.Bytes(), 0644); err != nil {
+			fmt.Printf("Error: Could not write %s - %v\n", outputPath, err)
+		}
+		return
+	}
+
+	// Read the question for each request, so edits show on reload
+	fmt.Printf("Serving a preview of %s at http://%s/\n", questionPath, addr)
+	err := http.ListenAndServe(addr, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var page bytes.Buffer
+		if err := render(&page); err != nil {
+			http.Error(w, fmt.Sprintf("Could not preview %s - %v", questionPath, err),
+				http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		page.WriteTo(w)
+	}))
+	if err != nil {
+		fmt.Printf("Error: Could not serve - %v\n", err)
+	}
+}
+
+func RunPublish(client amt.AmtClient, batch amt.Batch, templatePath, inputPath,
+	manifestPath string, sandbox, dryRun bool) {
+
+	tmpl, err := ioutil.ReadFile(templatePath)
+	if err != nil {
+		fmt.Printf("Error: Could not read %s - %v\n", templatePath, err)
+		return
+	}
+	var options []amt.HTMLQuestionOption
+	if sandbox {
+		options = append(options, amt.WithSandboxSubmit())
+	}
+	if batch.Question, err = amt.TemplateRowQuestion(string(tmpl), options...); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	input, err := os.Open(inputPath)
+	if err != nil {
+		fmt.Printf("Error: Could not read %s - %v\n", inputPath, err)
+		return
+	}
+	rows, err := amt.ReadBatchRows(input)
+	input.Close()
+	if err != nil {
+		fmt.Printf("Error: Could not read %s - %v\n", inputPath, err)
+		return
+	}
+
+	// A dry run reads the manifest, but records nothing in it
+	var manifest *amt.BatchManifest
+	if dryRun {
+		f, err := os.Open(manifestPath)
+		if os.IsNotExist(err) {
+			manifest = amt.NewBatchManifest(nil)
+		} else if err != nil {
+			fmt.Printf("Error: Could not read %s - %v\n", manifestPath, err)
+			return
+		} else {
+			manifest, err = amt.ReadBatchManifest(f)
+			f.Close()
+		}
+	} else {
+		manifest, err = amt.OpenBatchManifest(manifestPath)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer manifest.Close()
+
+	done := len(manifest.Entries())
+	entries, err := amt.PublishBatch(client, batch, rows, manifest)
+	for _, entry := range entries {
+		fmt.Printf("Row %d: HIT %s\n", entry.Row, entry.HITId)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("Created %d HITs; %d rows already had one\n",
+		len(entries)-done, done)
+}
+
+func RunServeFake(amtCred amt.Credentials, addr string, balance float64, workers int) {
+	backend := sim.New(balance)
+	for i := 0; i < workers; i++ {
+		backend.AddWorker(fmt.Sprintf("SIMWORKER%d", i+1),
+			sim.RandomSelections(rand.New(rand.NewSource(int64(i)))))
+	}
+	if workers > 0 {
+		go func() {
+			for range time.Tick(time.Second) {
+				backend.Work()
+			}
+		}()
+	}
+	fmt.Printf("Serving simulated AMT at http://%s/\n", addr)
+	err := http.ListenAndServe(addr, server.NewHandler(backend,
+		map[string]string{amtCred.AccessKey: amtCred.SecretKey}))
+	if err != nil {
+		fmt.Printf("Error: Could not serve - %v\n", err)
+	}
+}
+
 func RunShow(client amt.AmtClient, hitId, assnId string) {
 	switch {
 	case hitId != "":
 		if resp, err := client.GetHIT(hitId); err != nil {
 			fmt.Printf("Error: The AMT request failed: %v\n", err)
 			return
-		} else if len(resp.Hits) > 0 && resp.Hits[0].Request != nil &&
-			resp.Hits[0].Request.Errors != nil {
-
-			printObject(resp.Hits[0].Request)
 		} else {
 			printObject(resp)
 		}
@@ -316,11 +564,6 @@ func RunShow(client amt.AmtClient, hitId, assnId string) {
 		if resp, err := client.GetAssignment(assnId); err != nil {
 			fmt.Printf("Error: The AMT request failed: %v\n", err)
 			return
-		} else if len(resp.GetAssignmentResults) > 0 &&
-			resp.GetAssignmentResults[0].Request != nil &&
-			resp.GetAssignmentResults[0].Request.Errors != nil {
-
-			printObject(resp.GetAssignmentResults[0].Request)
 		} else {
 			printObject(resp)
 		}